@@ -193,12 +193,52 @@ type EndPointMeta struct {
 }
 
 type CacheMeta struct {
-	Disabled               bool   `bson:"disabled" json:"disabled"`
-	Method                 string `bson:"method" json:"method"`
-	Path                   string `bson:"path" json:"path"`
-	CacheKeyRegex          string `bson:"cache_key_regex" json:"cache_key_regex"`
-	CacheOnlyResponseCodes []int  `bson:"cache_response_codes" json:"cache_response_codes"`
-	Timeout                int64  `bson:"timeout" json:"timeout"`
+	Disabled               bool           `bson:"disabled" json:"disabled"`
+	Method                 string         `bson:"method" json:"method"`
+	Path                   string         `bson:"path" json:"path"`
+	CacheKeyRegex          string         `bson:"cache_key_regex" json:"cache_key_regex"`
+	CacheOnlyResponseCodes []int          `bson:"cache_response_codes" json:"cache_response_codes"`
+	Timeout                int64          `bson:"timeout" json:"timeout"`
+	SurrogateKeys          []string       `bson:"surrogate_keys" json:"surrogate_keys,omitempty"`
+	CacheKey               CacheKeyConfig `bson:"cache_key" json:"cache_key"`
+}
+
+const (
+	// CacheKeySession is the cache key component of the auth token, or the client IP of keyless requests.
+	CacheKeySession = "session"
+	// CacheKeyOrg is the cache key component of the organisation of the session.
+	CacheKeyOrg = "org"
+)
+
+// CacheKeyConfig declares the composition of the cache key of an endpoint.
+type CacheKeyConfig struct {
+	// Enabled replaces the default cache key, made of the auth token or
+	// client IP, the request URL and the `cache_by_headers`, with this one.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Components are the session attributes in the key, CacheKeySession or
+	// CacheKeyOrg. Responses are shared by all clients when empty.
+	Components []string `bson:"components" json:"components"`
+	// Headers are the request headers in the key.
+	Headers []string `bson:"headers" json:"headers"`
+	// Cookies are the request cookies in the key.
+	Cookies []string `bson:"cookies" json:"cookies"`
+	// Claims are the JWT claims in the key, context variables must be enabled.
+	Claims []string `bson:"claims" json:"claims"`
+	// QueryParams selects the query parameters in the key.
+	QueryParams CacheKeyQueryParams `bson:"query_params" json:"query_params"`
+	// HonourVary stores a variant of the response for each combination of
+	// the request headers listed in the upstream Vary header.
+	HonourVary bool `bson:"honour_vary" json:"honour_vary"`
+}
+
+// CacheKeyQueryParams selects the query parameters of a cache key.
+type CacheKeyQueryParams struct {
+	// Include lists the only parameters in the key, all when empty.
+	Include []string `bson:"include" json:"include"`
+	// Exclude lists parameters left out of the key.
+	Exclude []string `bson:"exclude" json:"exclude"`
+	// Sort makes the key independent of the order of the parameters.
+	Sort bool `bson:"sort" json:"sort"`
 }
 
 type RequestInputType string
@@ -243,6 +283,27 @@ type HardTimeoutMeta struct {
 	// Deprecated: Use TimeoutDuration instead.
 	TimeOut         int                      `bson:"timeout" json:"timeout"`
 	TimeoutDuration tyktime.ReadableDuration `bson:"duration,omitempty" json:"duration,omitempty"`
+	// Hedge configures the hedging of requests to the endpoint.
+	Hedge HedgeConfig `bson:"hedge" json:"hedge"`
+}
+
+// HedgeConfig configures request hedging: when no response came back within
+// the hedge delay, a second request is sent to the next load balanced target.
+// The first response wins and the other request is cancelled.
+type HedgeConfig struct {
+	// Enabled activates request hedging. Only GET, HEAD and OPTIONS requests
+	// are hedged.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Delay is how long to wait for a response before sending the hedged
+	// request. When Percentile is set, it is used until enough upstream
+	// latencies were observed.
+	Delay tyktime.ReadableDuration `bson:"delay" json:"delay"`
+	// Percentile of the recently observed upstream latencies of the endpoint
+	// used as hedge delay, e.g. `95`.
+	Percentile float64 `bson:"percentile" json:"percentile"`
+	// Budget is the maximum share of requests that may be hedged, e.g. `0.1`
+	// for one in ten. Defaults to 0.1.
+	Budget float64 `bson:"budget" json:"budget"`
 }
 
 type TrackEndpointMeta struct {
@@ -259,6 +320,27 @@ type RateLimitMeta struct {
 
 	Rate float64 `bson:"rate" json:"rate"`
 	Per  float64 `bson:"per" json:"per"`
+
+	// Algorithm selects the rate limiting algorithm, e.g. "gcra".
+	// When empty, the algorithm of the API rate limit is used.
+	Algorithm string `bson:"algorithm,omitempty" json:"algorithm,omitempty"`
+}
+
+// ConcurrencyLimitMeta limits the number of requests to an endpoint that are in flight at the same time.
+type ConcurrencyLimitMeta struct {
+	Disabled bool   `bson:"disabled" json:"disabled"`
+	Path     string `bson:"path" json:"path"`
+	Method   string `bson:"method" json:"method"`
+
+	MaxConcurrentRequests int `bson:"max_concurrent_requests" json:"max_concurrent_requests"`
+}
+
+// UpstreamRetryMeta overrides the upstream retry configuration of the API for an endpoint.
+type UpstreamRetryMeta struct {
+	Path   string `bson:"path" json:"path"`
+	Method string `bson:"method" json:"method"`
+
+	Retry UpstreamRetryConfig `bson:"retry" json:"retry"`
 }
 
 // Valid will return true if the rate limit should be applied.
@@ -297,6 +379,15 @@ type RequestSizeMeta struct {
 	SizeLimit int64  `bson:"size_limit" json:"size_limit"`
 }
 
+const (
+	// CircuitBreakerScopePath keeps the breaker state per path, the default.
+	CircuitBreakerScopePath = "path"
+	// CircuitBreakerScopeAPI shares the breaker state between all the paths of an API.
+	CircuitBreakerScopeAPI = "api"
+	// CircuitBreakerScopeHost keeps the breaker state per upstream host.
+	CircuitBreakerScopeHost = "host"
+)
+
 type CircuitBreakerMeta struct {
 	Disabled             bool    `bson:"disabled" json:"disabled"`
 	Path                 string  `bson:"path" json:"path"`
@@ -305,6 +396,20 @@ type CircuitBreakerMeta struct {
 	Samples              int64   `bson:"samples" json:"samples"`
 	ReturnToServiceAfter int     `bson:"return_to_service_after" json:"return_to_service_after"`
 	DisableHalfOpenState bool    `bson:"disable_half_open_state" json:"disable_half_open_state"`
+	// Scope is what the breaker state is kept for, CircuitBreakerScopePath,
+	// CircuitBreakerScopeAPI or CircuitBreakerScopeHost. Defaults to path.
+	Scope string `bson:"scope" json:"scope,omitempty"`
+	// HalfOpenRequests is the number of probe requests let through once
+	// ReturnToServiceAfter has passed. The breaker closes when all of them
+	// succeed and opens again on the first failure. When 0 the breaker closes
+	// as soon as ReturnToServiceAfter has passed.
+	HalfOpenRequests int `bson:"half_open_requests" json:"half_open_requests,omitempty"`
+	// HalfOpenTimeout is how long, in seconds, half-open probes may take to
+	// report their outcome before they are given up on and other requests
+	// can probe the upstream. Defaults to 30.
+	HalfOpenTimeout int `bson:"half_open_timeout" json:"half_open_timeout,omitempty"`
+	// Shared propagates the breaker state to all the gateways of the cluster.
+	Shared bool `bson:"shared" json:"shared,omitempty"`
 }
 
 type StringRegexMap struct {
@@ -434,6 +539,9 @@ type ExtendedPathsSet struct {
 	GoPlugin                []GoPluginMeta        `bson:"go_plugin" json:"go_plugin,omitempty"`
 	PersistGraphQL          []PersistGraphQLMeta  `bson:"persist_graphql" json:"persist_graphql"`
 	RateLimit               []RateLimitMeta       `bson:"rate_limit" json:"rate_limit"`
+
+	ConcurrencyLimit []ConcurrencyLimitMeta `bson:"concurrency_limit" json:"concurrency_limit,omitempty"`
+	UpstreamRetry    []UpstreamRetryMeta    `bson:"upstream_retry" json:"upstream_retry,omitempty"`
 }
 
 // Clear omits values that have OAS API definition conversions in place.
@@ -634,6 +742,31 @@ type CacheOptions struct {
 	EnableUpstreamCacheControl bool     `bson:"enable_upstream_cache_control" json:"enable_upstream_cache_control"`
 	CacheControlTTLHeader      string   `bson:"cache_control_ttl_header" json:"cache_control_ttl_header"`
 	CacheByHeaders             []string `bson:"cache_by_headers" json:"cache_by_headers"`
+	// StaleWhileRevalidate is the number of seconds an expired response is
+	// served while it is refreshed in the background, as per RFC 5861.
+	StaleWhileRevalidate int64 `bson:"stale_while_revalidate" json:"stale_while_revalidate"`
+	// StaleIfError is the number of seconds an expired response is served
+	// when the upstream fails, as per RFC 5861.
+	StaleIfError int64 `bson:"stale_if_error" json:"stale_if_error"`
+	// RevalidationWindow is the number of seconds an expired response
+	// carrying an ETag or Last-Modified header is kept to be revalidated
+	// with a conditional upstream request.
+	RevalidationWindow int64 `bson:"revalidation_window" json:"revalidation_window"`
+	// RequestCoalescing makes concurrent cache misses wait for the response
+	// of a single upstream request.
+	RequestCoalescing RequestCoalescingConfig `bson:"request_coalescing" json:"request_coalescing"`
+}
+
+// RequestCoalescingConfig configures the coalescing of concurrent requests
+// missing the same cache entry.
+type RequestCoalescingConfig struct {
+	// Enabled activates request coalescing.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Timeout is how long a request waits for the response of the request
+	// fetching it before going to the upstream itself.
+	Timeout tyktime.ReadableDuration `bson:"timeout" json:"timeout"`
+	// Distributed coalesces requests across gateways with a Redis lock.
+	Distributed bool `bson:"distributed" json:"distributed"`
 }
 
 type ResponseProcessor struct {
@@ -732,39 +865,39 @@ type APIDefinition struct {
 	// CertificatePinningDisabled disables public key pinning
 	CertificatePinningDisabled bool `bson:"certificate_pinning_disabled" json:"certificate_pinning_disabled,omitempty"`
 
-	EnableJWT                            bool                   `bson:"enable_jwt" json:"enable_jwt"`
-	UseStandardAuth                      bool                   `bson:"use_standard_auth" json:"use_standard_auth"`
-	UseGoPluginAuth                      bool                   `bson:"use_go_plugin_auth" json:"use_go_plugin_auth"`       // Deprecated. Use CustomPluginAuthEnabled instead.
-	EnableCoProcessAuth                  bool                   `bson:"enable_coprocess_auth" json:"enable_coprocess_auth"` // Deprecated. Use CustomPluginAuthEnabled instead.
-	CustomPluginAuthEnabled              bool                   `bson:"custom_plugin_auth_enabled" json:"custom_plugin_auth_enabled"`
-	JWTSigningMethod                     string                 `bson:"jwt_signing_method" json:"jwt_signing_method"`
-	JWTSource                            string                 `bson:"jwt_source" json:"jwt_source"`
-	JWTJwksURIs                          []JWK                  `bson:"jwt_jwks_uris" json:"jwt_jwks_uris"`
-	JWTIdentityBaseField                 string                 `bson:"jwt_identit_base_field" json:"jwt_identity_base_field"`
-	JWTClientIDBaseField                 string                 `bson:"jwt_client_base_field" json:"jwt_client_base_field"`
-	JWTPolicyFieldName                   string                 `bson:"jwt_policy_field_name" json:"jwt_policy_field_name"`
-	JWTDefaultPolicies                   []string               `bson:"jwt_default_policies" json:"jwt_default_policies"`
-	JWTIssuedAtValidationSkew            uint64                 `bson:"jwt_issued_at_validation_skew" json:"jwt_issued_at_validation_skew"`
-	JWTExpiresAtValidationSkew           uint64                 `bson:"jwt_expires_at_validation_skew" json:"jwt_expires_at_validation_skew"`
-	JWTNotBeforeValidationSkew           uint64                 `bson:"jwt_not_before_validation_skew" json:"jwt_not_before_validation_skew"`
-	JWTSkipKid                           bool                   `bson:"jwt_skip_kid" json:"jwt_skip_kid"`
-	Scopes                               Scopes                 `bson:"scopes" json:"scopes,omitempty"`
-	IDPClientIDMappingDisabled           bool                   `bson:"idp_client_id_mapping_disabled" json:"idp_client_id_mapping_disabled"`
-	JWTScopeToPolicyMapping              map[string]string      `bson:"jwt_scope_to_policy_mapping" json:"jwt_scope_to_policy_mapping"` // Deprecated: use Scopes.JWT.ScopeToPolicy or Scopes.OIDC.ScopeToPolicy
-	JWTScopeClaimName                    string                 `bson:"jwt_scope_claim_name" json:"jwt_scope_claim_name"`               // Deprecated: use Scopes.JWT.ScopeClaimName or Scopes.OIDC.ScopeClaimName
-	NotificationsDetails                 NotificationsManager   `bson:"notifications" json:"notifications"`
-	EnableSignatureChecking              bool                   `bson:"enable_signature_checking" json:"enable_signature_checking"`
-	HmacAllowedClockSkew                 float64                `bson:"hmac_allowed_clock_skew" json:"hmac_allowed_clock_skew"`
-	HmacAllowedAlgorithms                []string               `bson:"hmac_allowed_algorithms" json:"hmac_allowed_algorithms"`
-	RequestSigning                       RequestSigningMeta     `bson:"request_signing" json:"request_signing"`
-	BaseIdentityProvidedBy               AuthTypeEnum           `bson:"base_identity_provided_by" json:"base_identity_provided_by"`
-	VersionDefinition                    VersionDefinition      `bson:"definition" json:"definition"`
-	VersionData                          VersionData            `bson:"version_data" json:"version_data"` // Deprecated. Use VersionDefinition instead.
-	UptimeTests                          UptimeTests            `bson:"uptime_tests" json:"uptime_tests"`
-	Proxy                                ProxyConfig            `bson:"proxy" json:"proxy"`
-	DisableRateLimit                     bool                   `bson:"disable_rate_limit" json:"disable_rate_limit"`
-	DisableQuota                         bool                   `bson:"disable_quota" json:"disable_quota"`
-	CustomMiddleware                     MiddlewareSection      `bson:"custom_middleware" json:"custom_middleware"`
+	EnableJWT                  bool                 `bson:"enable_jwt" json:"enable_jwt"`
+	UseStandardAuth            bool                 `bson:"use_standard_auth" json:"use_standard_auth"`
+	UseGoPluginAuth            bool                 `bson:"use_go_plugin_auth" json:"use_go_plugin_auth"`       // Deprecated. Use CustomPluginAuthEnabled instead.
+	EnableCoProcessAuth        bool                 `bson:"enable_coprocess_auth" json:"enable_coprocess_auth"` // Deprecated. Use CustomPluginAuthEnabled instead.
+	CustomPluginAuthEnabled    bool                 `bson:"custom_plugin_auth_enabled" json:"custom_plugin_auth_enabled"`
+	JWTSigningMethod           string               `bson:"jwt_signing_method" json:"jwt_signing_method"`
+	JWTSource                  string               `bson:"jwt_source" json:"jwt_source"`
+	JWTJwksURIs                []JWK                `bson:"jwt_jwks_uris" json:"jwt_jwks_uris"`
+	JWTIdentityBaseField       string               `bson:"jwt_identit_base_field" json:"jwt_identity_base_field"`
+	JWTClientIDBaseField       string               `bson:"jwt_client_base_field" json:"jwt_client_base_field"`
+	JWTPolicyFieldName         string               `bson:"jwt_policy_field_name" json:"jwt_policy_field_name"`
+	JWTDefaultPolicies         []string             `bson:"jwt_default_policies" json:"jwt_default_policies"`
+	JWTIssuedAtValidationSkew  uint64               `bson:"jwt_issued_at_validation_skew" json:"jwt_issued_at_validation_skew"`
+	JWTExpiresAtValidationSkew uint64               `bson:"jwt_expires_at_validation_skew" json:"jwt_expires_at_validation_skew"`
+	JWTNotBeforeValidationSkew uint64               `bson:"jwt_not_before_validation_skew" json:"jwt_not_before_validation_skew"`
+	JWTSkipKid                 bool                 `bson:"jwt_skip_kid" json:"jwt_skip_kid"`
+	Scopes                     Scopes               `bson:"scopes" json:"scopes,omitempty"`
+	IDPClientIDMappingDisabled bool                 `bson:"idp_client_id_mapping_disabled" json:"idp_client_id_mapping_disabled"`
+	JWTScopeToPolicyMapping    map[string]string    `bson:"jwt_scope_to_policy_mapping" json:"jwt_scope_to_policy_mapping"` // Deprecated: use Scopes.JWT.ScopeToPolicy or Scopes.OIDC.ScopeToPolicy
+	JWTScopeClaimName          string               `bson:"jwt_scope_claim_name" json:"jwt_scope_claim_name"`               // Deprecated: use Scopes.JWT.ScopeClaimName or Scopes.OIDC.ScopeClaimName
+	NotificationsDetails       NotificationsManager `bson:"notifications" json:"notifications"`
+	EnableSignatureChecking    bool                 `bson:"enable_signature_checking" json:"enable_signature_checking"`
+	HmacAllowedClockSkew       float64              `bson:"hmac_allowed_clock_skew" json:"hmac_allowed_clock_skew"`
+	HmacAllowedAlgorithms      []string             `bson:"hmac_allowed_algorithms" json:"hmac_allowed_algorithms"`
+	RequestSigning             RequestSigningMeta   `bson:"request_signing" json:"request_signing"`
+	BaseIdentityProvidedBy     AuthTypeEnum         `bson:"base_identity_provided_by" json:"base_identity_provided_by"`
+	VersionDefinition          VersionDefinition    `bson:"definition" json:"definition"`
+	VersionData                VersionData          `bson:"version_data" json:"version_data"` // Deprecated. Use VersionDefinition instead.
+	UptimeTests                UptimeTests          `bson:"uptime_tests" json:"uptime_tests"`
+	Proxy                      ProxyConfig          `bson:"proxy" json:"proxy"`
+	DisableRateLimit           bool                 `bson:"disable_rate_limit" json:"disable_rate_limit"`
+	DisableQuota               bool                 `bson:"disable_quota" json:"disable_quota"`
+	CustomMiddleware           MiddlewareSection    `bson:"custom_middleware" json:"custom_middleware"`
 	// CustomMiddlewareBundle is the bundle filename (or comma-separated list of
 	// bundle filenames) resolved against the gateway's bundle_base_url. A single
 	// name takes the legacy single-bundle load path unchanged. Two or more
@@ -802,6 +935,9 @@ type APIDefinition struct {
 	ConfigDataDisabled                   bool                   `bson:"config_data_disabled" json:"config_data_disabled"`
 	TagHeaders                           []string               `bson:"tag_headers" json:"tag_headers"`
 	GlobalRateLimit                      GlobalRateLimit        `bson:"global_rate_limit" json:"global_rate_limit"`
+	RateLimitQueue                       RateLimitQueueConfig   `bson:"rate_limit_queue" json:"rate_limit_queue"`
+	TrafficMirror                        TrafficMirrorConfig    `bson:"traffic_mirror" json:"traffic_mirror"`
+	ExternalAuthorization                ExternalAuthzConfig    `bson:"external_authorization" json:"external_authorization"`
 	StripAuthData                        bool                   `bson:"strip_auth_data" json:"strip_auth_data"`
 	EnableDetailedRecording              bool                   `bson:"enable_detailed_recording" json:"enable_detailed_recording"`
 	GraphQL                              GraphQLConfig          `bson:"graphql" json:"graphql"`
@@ -1067,6 +1203,96 @@ type GlobalRateLimit struct {
 	Disabled bool    `bson:"disabled" json:"disabled"`
 	Rate     float64 `bson:"rate" json:"rate"`
 	Per      float64 `bson:"per" json:"per"`
+
+	// Algorithm selects the rate limiting algorithm, e.g. "gcra". It applies
+	// to the API rate limit, and to key rate limits which don't set their own.
+	// When empty, the rate limiter configured for the gateway is used.
+	Algorithm string `bson:"algorithm,omitempty" json:"algorithm,omitempty"`
+}
+
+// RateLimitQueueConfig configures the queuing of requests that exceed a rate
+// or concurrency limit. Queued requests wait for the limit to let them through
+// instead of being rejected, served by priority class.
+type RateLimitQueueConfig struct {
+	// Enabled activates queuing of limited requests.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxSize is the number of requests that can wait at the same time.
+	MaxSize int `bson:"max_size" json:"max_size"`
+	// MaxWait is how long a request waits before it is rejected.
+	MaxWait tyktime.ReadableDuration `bson:"max_wait" json:"max_wait"`
+	// PriorityHeader is the request header carrying the priority class of a
+	// request, used when the key has no `rate_limit_priority` metadata.
+	PriorityHeader string `bson:"priority_header" json:"priority_header"`
+	// PriorityClasses maps priority class names to priorities. Requests with
+	// a higher priority are served first, unknown classes have priority 0.
+	PriorityClasses map[string]int `bson:"priority_classes" json:"priority_classes"`
+}
+
+// TrafficMirrorConfig configures the mirroring of a share of the API traffic
+// to a shadow upstream. Mirrored requests are sent asynchronously and their
+// responses are discarded, optionally after comparing them to the responses of
+// the primary upstream.
+type TrafficMirrorConfig struct {
+	// Enabled activates traffic mirroring.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// TargetURL is the URL of the shadow upstream.
+	TargetURL string `bson:"target_url" json:"target_url"`
+	// Percentage is the share of requests that are mirrored, from 0 to 100.
+	Percentage float64 `bson:"percentage" json:"percentage"`
+	// MaxBodySize is the size in bytes of the largest request body that is
+	// mirrored, requests with larger bodies aren't mirrored. Defaults to 1MB.
+	MaxBodySize int64 `bson:"max_body_size" json:"max_body_size"`
+	// Timeout is how long a mirrored request may take. Defaults to 30 seconds.
+	Timeout tyktime.ReadableDuration `bson:"timeout" json:"timeout"`
+	// Compare fires a TrafficMirrorMismatch event when the status or body of
+	// the shadow response differs from the primary response.
+	Compare bool `bson:"compare" json:"compare"`
+}
+
+const (
+	// ExternalAuthzHTTP is the type of policy decision points implementing the Open Policy Agent data API.
+	ExternalAuthzHTTP = "http"
+	// ExternalAuthzGRPC is the type of policy decision points implementing the Envoy external authorization service.
+	ExternalAuthzGRPC = "grpc"
+	// ExternalAuthzEmbedded is the type of JavaScript policies evaluated by the gateway.
+	ExternalAuthzEmbedded = "embedded"
+)
+
+// ExternalAuthzConfig configures the authorization of requests by a policy
+// decision point. An input document describing the request, its session and
+// token claims, and the API and endpoint is sent to the decision point,
+// which allows or denies the request and may mutate its headers.
+type ExternalAuthzConfig struct {
+	// Enabled activates external authorization.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Type is the type of policy decision point, ExternalAuthzHTTP,
+	// ExternalAuthzGRPC or ExternalAuthzEmbedded.
+	Type string `bson:"type" json:"type"`
+	// URL is the URL of the decision, e.g. `http://opa:8181/v1/data/tyk/authz`
+	// for http decision points, or the address of grpc decision points, e.g.
+	// `grpc://opa:9191`, using `grpcs` for TLS.
+	URL string `bson:"url" json:"url"`
+	// Policy is the JavaScript source of embedded policies, defining an
+	// `authorize(input)` function.
+	Policy string `bson:"policy" json:"policy"`
+	// Timeout is how long a decision may take. Defaults to 1 second.
+	Timeout tyktime.ReadableDuration `bson:"timeout" json:"timeout"`
+	// CacheTTL is how long decisions are cached, they aren't cached when zero.
+	// Decisions are cached by the request method, host, path and query, the
+	// client IP and credential headers, the session, claims and endpoint,
+	// and the headers in CacheKeyHeaders.
+	CacheTTL tyktime.ReadableDuration `bson:"cache_ttl" json:"cache_ttl"`
+	// CacheKeyHeaders are the request headers that decisions are cached by.
+	CacheKeyHeaders []string `bson:"cache_key_headers" json:"cache_key_headers"`
+	// CacheAcrossClients leaves the client IP, the credential headers and the
+	// claims that change with every token out of the cache key, sharing the
+	// decisions of policies that don't depend on them between clients.
+	CacheAcrossClients bool `bson:"cache_across_clients" json:"cache_across_clients"`
+	// CacheMaxEntries bounds the number of cached decisions. Defaults to 10000.
+	CacheMaxEntries int `bson:"cache_max_entries" json:"cache_max_entries"`
+	// FailOpen allows requests when no decision can be made, requests are
+	// denied by default.
+	FailOpen bool `bson:"fail_open" json:"fail_open"`
 }
 
 type BundleManifest struct {
@@ -1096,6 +1322,9 @@ type ProxyConfig struct {
 	Targets                     []string                      `bson:"target_list" json:"target_list"`
 	StructuredTargetList        *HostList                     `bson:"-" json:"-"`
 	CheckHostAgainstUptimeTests bool                          `bson:"check_host_against_uptime_tests" json:"check_host_against_uptime_tests"`
+	LoadBalancing               LoadBalancingConfig           `bson:"load_balancing" json:"load_balancing"`
+	Retry                       UpstreamRetryConfig           `bson:"retry" json:"retry"`
+	AdaptiveConcurrency         AdaptiveConcurrencyConfig     `bson:"adaptive_concurrency" json:"adaptive_concurrency"`
 	ServiceDiscovery            ServiceDiscoveryConfiguration `bson:"service_discovery" json:"service_discovery"`
 	Transport                   struct {
 		SSLInsecureSkipVerify   bool     `bson:"ssl_insecure_skip_verify" json:"ssl_insecure_skip_verify"`
@@ -1107,6 +1336,73 @@ type ProxyConfig struct {
 	} `bson:"transport" json:"transport"`
 }
 
+// LoadBalancingConfig configures the strategy used to pick a target from `proxy.target_list`.
+type LoadBalancingConfig struct {
+	// Algorithm is the name of the load balancing strategy. Empty means round robin.
+	Algorithm string `bson:"algorithm" json:"algorithm"`
+	// HashSource is the request attribute hashed by the consistent hash strategy:
+	// `header`, `cookie`, `session` or `ip`.
+	HashSource string `bson:"hash_source" json:"hash_source"`
+	// HashName is the header or cookie name when HashSource is `header` or `cookie`.
+	HashName string `bson:"hash_name" json:"hash_name"`
+	// OutlierDetection configures the passive ejection of failing targets.
+	OutlierDetection OutlierDetectionConfig `bson:"outlier_detection" json:"outlier_detection"`
+}
+
+// OutlierDetectionConfig configures passive outlier detection, which ejects targets
+// that keep failing proxied requests from the load balancing pool.
+type OutlierDetectionConfig struct {
+	// Enabled activates outlier detection.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// ConsecutiveFailures is the number of consecutive 5xx responses or connection errors that eject a target.
+	ConsecutiveFailures int `bson:"consecutive_failures" json:"consecutive_failures"`
+	// BaseEjectionTime is how long a target is ejected the first time, doubled on every further ejection.
+	BaseEjectionTime tyktime.ReadableDuration `bson:"base_ejection_time" json:"base_ejection_time"`
+	// MaxEjectionTime caps how long a target is ejected.
+	MaxEjectionTime tyktime.ReadableDuration `bson:"max_ejection_time" json:"max_ejection_time"`
+	// MaxEjectionPercent caps the percentage of targets that can be ejected at once.
+	MaxEjectionPercent int `bson:"max_ejection_percent" json:"max_ejection_percent"`
+}
+
+// UpstreamRetryConfig configures automatic retries of failed upstream requests.
+type UpstreamRetryConfig struct {
+	// Enabled activates retries.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// MaxAttempts is the total number of upstream attempts, including the first one.
+	MaxAttempts int `bson:"max_attempts" json:"max_attempts"`
+	// RetryOn lists the failure classes that are retried: `connectFailure`, `reset`,
+	// `timeout`, `gatewayError` and `5xx`. Empty means `connectFailure` and `gatewayError`.
+	RetryOn []string `bson:"retry_on" json:"retry_on"`
+	// StatusCodes lists additional upstream response codes that are retried.
+	StatusCodes []int `bson:"status_codes" json:"status_codes"`
+	// BackoffBase is the delay before the first retry, doubled on every further retry.
+	BackoffBase tyktime.ReadableDuration `bson:"backoff_base" json:"backoff_base"`
+	// BackoffMax caps the delay between two attempts.
+	BackoffMax tyktime.ReadableDuration `bson:"backoff_max" json:"backoff_max"`
+	// RetryNonIdempotent allows retrying requests using non-idempotent methods such as POST.
+	RetryNonIdempotent bool `bson:"retry_non_idempotent" json:"retry_non_idempotent"`
+}
+
+// AdaptiveConcurrencyConfig configures a concurrency limit per upstream host that
+// adapts to the observed upstream latency and failures.
+type AdaptiveConcurrencyConfig struct {
+	// Enabled activates adaptive concurrency limiting.
+	Enabled bool `bson:"enabled" json:"enabled"`
+	// Algorithm is `gradient` (default), which follows the upstream latency, or `aimd`,
+	// which grows the limit on success and cuts it on failures.
+	Algorithm string `bson:"algorithm" json:"algorithm"`
+	// InitialLimit is the limit used until the upstream latency is known.
+	InitialLimit int `bson:"initial_limit" json:"initial_limit"`
+	// MinLimit is the lowest the limit can go.
+	MinLimit int `bson:"min_limit" json:"min_limit"`
+	// MaxLimit is the highest the limit can go.
+	MaxLimit int `bson:"max_limit" json:"max_limit"`
+	// QueueSize is the number of requests that can wait for a slot once the limit is reached.
+	QueueSize int `bson:"queue_size" json:"queue_size"`
+	// QueueTimeout is how long a queued request waits for a slot before it is shed.
+	QueueTimeout tyktime.ReadableDuration `bson:"queue_timeout" json:"queue_timeout"`
+}
+
 type CORSConfig struct {
 	Enable             bool     `bson:"enable" json:"enable"`
 	AllowedOrigins     []string `bson:"allowed_origins" json:"allowed_origins"`
@@ -163,6 +163,14 @@ type Global struct {
 	// IgnoreCase contains the configuration to treat routes as case-insensitive.
 	IgnoreCase *IgnoreCase `bson:"ignoreCase,omitempty" json:"ignoreCase,omitempty"`
 
+	// TrafficMirror contains the configuration related to mirroring requests to a shadow upstream.
+	// Tyk classic API definition: `traffic_mirror`.
+	TrafficMirror *TrafficMirror `bson:"trafficMirror,omitempty" json:"trafficMirror,omitempty"`
+
+	// ExternalAuthorization contains the configuration related to the authorization of requests by a policy decision point.
+	// Tyk classic API definition: `external_authorization`.
+	ExternalAuthorization *ExternalAuthorization `bson:"externalAuthorization,omitempty" json:"externalAuthorization,omitempty"`
+
 	// SkipRateLimit determines whether the rate-limiting middleware logic should be skipped.
 	// Tyk classic API definition: `disable_rate_limit`.
 	SkipRateLimit bool `bson:"skipRateLimit,omitempty" json:"skipRateLimit,omitempty"`
@@ -305,6 +313,10 @@ func (g *Global) Fill(api apidef.APIDefinition) {
 
 	g.fillRequestSizeLimit(api)
 
+	g.fillTrafficMirror(api)
+
+	g.fillExternalAuthorization(api)
+
 	g.fillSkips(api)
 }
 
@@ -330,6 +342,28 @@ func (g *Global) fillRequestSizeLimit(api apidef.APIDefinition) {
 	}
 }
 
+func (g *Global) fillTrafficMirror(api apidef.APIDefinition) {
+	if g.TrafficMirror == nil {
+		g.TrafficMirror = &TrafficMirror{}
+	}
+
+	g.TrafficMirror.Fill(api.TrafficMirror)
+	if ShouldOmit(g.TrafficMirror) {
+		g.TrafficMirror = nil
+	}
+}
+
+func (g *Global) fillExternalAuthorization(api apidef.APIDefinition) {
+	if g.ExternalAuthorization == nil {
+		g.ExternalAuthorization = &ExternalAuthorization{}
+	}
+
+	g.ExternalAuthorization.Fill(api.ExternalAuthorization)
+	if ShouldOmit(g.ExternalAuthorization) {
+		g.ExternalAuthorization = nil
+	}
+}
+
 func (g *Global) fillContextVariables(api apidef.APIDefinition) {
 	if g.ContextVariables == nil {
 		g.ContextVariables = &ContextVariables{}
@@ -423,6 +457,10 @@ func (g *Global) ExtractTo(api *apidef.APIDefinition) {
 
 	g.extractRequestSizeLimitTo(api)
 
+	g.extractTrafficMirrorTo(api)
+
+	g.extractExternalAuthorizationTo(api)
+
 	g.extractSkipsTo(api)
 }
 
@@ -448,6 +486,28 @@ func (g *Global) extractRequestSizeLimitTo(api *apidef.APIDefinition) {
 	g.RequestSizeLimit.ExtractTo(api)
 }
 
+func (g *Global) extractTrafficMirrorTo(api *apidef.APIDefinition) {
+	if g.TrafficMirror == nil {
+		g.TrafficMirror = &TrafficMirror{}
+		defer func() {
+			g.TrafficMirror = nil
+		}()
+	}
+
+	g.TrafficMirror.ExtractTo(&api.TrafficMirror)
+}
+
+func (g *Global) extractExternalAuthorizationTo(api *apidef.APIDefinition) {
+	if g.ExternalAuthorization == nil {
+		g.ExternalAuthorization = &ExternalAuthorization{}
+		defer func() {
+			g.ExternalAuthorization = nil
+		}()
+	}
+
+	g.ExternalAuthorization.ExtractTo(&api.ExternalAuthorization)
+}
+
 func (g *Global) extractContextVariablesTo(api *apidef.APIDefinition) {
 	if g.ContextVariables == nil {
 		g.ContextVariables = &ContextVariables{}
@@ -771,6 +831,72 @@ type Cache struct {
 	//
 	// Tyk classic API definition: `cache_options.cache_control_ttl_header`
 	ControlTTLHeaderName string `bson:"controlTTLHeaderName,omitempty" json:"controlTTLHeaderName,omitempty"`
+
+	// StaleWhileRevalidate is the number of seconds a cached object is still served after it expired, while it is
+	// refreshed from the upstream in the background. Only one refresh runs at a time for each cached object.
+	// When `enableUpstreamCacheControl` is set, the `stale-while-revalidate` directive of the upstream
+	// `Cache-Control` response header takes precedence.
+	//
+	// Tyk classic API definition: `cache_options.stale_while_revalidate`
+	StaleWhileRevalidate int64 `bson:"staleWhileRevalidate,omitempty" json:"staleWhileRevalidate,omitempty"`
+
+	// StaleIfError is the number of seconds a cached object is still served after it expired, when the upstream
+	// responds with a `5xx` status code, can't be reached or its circuit breaker is open.
+	// When `enableUpstreamCacheControl` is set, the `stale-if-error` directive of the upstream
+	// `Cache-Control` response header takes precedence.
+	//
+	// Tyk classic API definition: `cache_options.stale_if_error`
+	StaleIfError int64 `bson:"staleIfError,omitempty" json:"staleIfError,omitempty"`
+
+	// RevalidationWindow is the number of seconds a cached object carrying an `ETag` or `Last-Modified` header is
+	// kept after it expired, to be revalidated with an `If-None-Match` or `If-Modified-Since` upstream request.
+	// When the upstream responds with `304 Not Modified`, the cached object is served and its TTL refreshed
+	// without downloading the body again.
+	//
+	// Tyk classic API definition: `cache_options.revalidation_window`
+	RevalidationWindow int64 `bson:"revalidationWindow,omitempty" json:"revalidationWindow,omitempty"`
+
+	// RequestCoalescing contains the configuration for coalescing concurrent requests that miss the same
+	// cached object, so that only one of them is sent to the upstream.
+	//
+	// Tyk classic API definition: `cache_options.request_coalescing`
+	RequestCoalescing *RequestCoalescing `bson:"requestCoalescing,omitempty" json:"requestCoalescing,omitempty"`
+}
+
+// RequestCoalescing holds the configuration for coalescing cache misses. When a cached object is missing,
+// the first request fetches it from the upstream while concurrent requests for the same object wait for
+// its response instead of reaching the upstream too.
+type RequestCoalescing struct {
+	// Enabled activates request coalescing.
+	//
+	// Tyk classic API definition: `cache_options.request_coalescing.enabled`
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// Timeout is how long a request waits for the response of the request fetching it, using a
+	// human-readable format (e.g. `500ms`). Requests that time out are sent to the upstream.
+	// Defaults to `5s`.
+	//
+	// Tyk classic API definition: `cache_options.request_coalescing.timeout`
+	Timeout ReadableDuration `bson:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Distributed coalesces requests across gateways sharing the cache, using a short lived Redis lock.
+	//
+	// Tyk classic API definition: `cache_options.request_coalescing.distributed`
+	Distributed bool `bson:"distributed,omitempty" json:"distributed,omitempty"`
+}
+
+// Fill fills *RequestCoalescing from apidef.RequestCoalescingConfig.
+func (c *RequestCoalescing) Fill(conf apidef.RequestCoalescingConfig) {
+	c.Enabled = conf.Enabled
+	c.Timeout = conf.Timeout
+	c.Distributed = conf.Distributed
+}
+
+// ExtractTo extracts *RequestCoalescing into *apidef.RequestCoalescingConfig.
+func (c *RequestCoalescing) ExtractTo(conf *apidef.RequestCoalescingConfig) {
+	conf.Enabled = c.Enabled
+	conf.Timeout = c.Timeout
+	conf.Distributed = c.Distributed
 }
 
 // Fill fills *Cache from apidef.CacheOptions.
@@ -782,6 +908,18 @@ func (c *Cache) Fill(cache apidef.CacheOptions) {
 	c.CacheByHeaders = cache.CacheByHeaders
 	c.EnableUpstreamCacheControl = cache.EnableUpstreamCacheControl
 	c.ControlTTLHeaderName = cache.CacheControlTTLHeader
+	c.StaleWhileRevalidate = cache.StaleWhileRevalidate
+	c.StaleIfError = cache.StaleIfError
+	c.RevalidationWindow = cache.RevalidationWindow
+
+	if c.RequestCoalescing == nil {
+		c.RequestCoalescing = &RequestCoalescing{}
+	}
+
+	c.RequestCoalescing.Fill(cache.RequestCoalescing)
+	if ShouldOmit(c.RequestCoalescing) {
+		c.RequestCoalescing = nil
+	}
 }
 
 // ExtractTo extracts *Cache into *apidef.CacheOptions.
@@ -793,6 +931,18 @@ func (c *Cache) ExtractTo(cache *apidef.CacheOptions) {
 	cache.CacheByHeaders = c.CacheByHeaders
 	cache.EnableUpstreamCacheControl = c.EnableUpstreamCacheControl
 	cache.CacheControlTTLHeader = c.ControlTTLHeaderName
+	cache.StaleWhileRevalidate = c.StaleWhileRevalidate
+	cache.StaleIfError = c.StaleIfError
+	cache.RevalidationWindow = c.RevalidationWindow
+
+	if c.RequestCoalescing == nil {
+		c.RequestCoalescing = &RequestCoalescing{}
+		defer func() {
+			c.RequestCoalescing = nil
+		}()
+	}
+
+	c.RequestCoalescing.ExtractTo(&cache.RequestCoalescing)
 }
 
 // Paths is a mapping of API endpoints to Path plugin configurations. This field is part of the [Middleware](#middleware) structure.
@@ -1285,6 +1435,133 @@ type CachePlugin struct {
 	//
 	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].timeout`.
 	Timeout int64 `bson:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// SurrogateKeys contains templates of the surrogate keys, or tags, attached to the cached responses,
+	// in addition to those set by the upstream in the `Surrogate-Key` or `Cache-Tag` headers.
+	// Cached responses can be purged by surrogate key with the Gateway API.
+	// Tyk context variables are replaced, e.g. `tenant-$tyk_context.headers_X_Tenant`.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].surrogate_keys`.
+	SurrogateKeys []string `bson:"surrogateKeys,omitempty" json:"surrogateKeys,omitempty"`
+
+	// CacheKey declares the composition of the cache key of the endpoint, so that responses can be shared between
+	// clients instead of being cached for each of them.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key`.
+	CacheKey *CacheKey `bson:"cacheKey,omitempty" json:"cacheKey,omitempty"`
+}
+
+// CacheKey declares the composition of the cache key of an endpoint.
+type CacheKey struct {
+	// Enabled replaces the default cache key, made of the auth token or client IP, the request URL and the
+	// `cacheByHeaders`, with this one.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// Components are the session attributes in the cache key:
+	// - `session`: the auth token, or the client IP of keyless requests,
+	// - `org`: the organisation of the session.
+	// Responses are shared by all clients when empty.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.components`.
+	Components []string `bson:"components,omitempty" json:"components,omitempty"`
+
+	// Headers are the request headers in the cache key.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.headers`.
+	Headers []string `bson:"headers,omitempty" json:"headers,omitempty"`
+
+	// Cookies are the request cookies in the cache key.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.cookies`.
+	Cookies []string `bson:"cookies,omitempty" json:"cookies,omitempty"`
+
+	// Claims are the JWT claims in the cache key. Context variables must be enabled.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.claims`.
+	Claims []string `bson:"claims,omitempty" json:"claims,omitempty"`
+
+	// QueryParams selects the query parameters in the cache key.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.query_params`.
+	QueryParams *CacheKeyQueryParams `bson:"queryParams,omitempty" json:"queryParams,omitempty"`
+
+	// HonourVary stores a variant of the response for each combination of the request headers listed in the
+	// upstream `Vary` response header. Responses with `Vary: *` aren't cached.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.honour_vary`.
+	HonourVary bool `bson:"honourVary,omitempty" json:"honourVary,omitempty"`
+}
+
+// Fill fills *CacheKey from apidef.CacheKeyConfig.
+func (c *CacheKey) Fill(conf apidef.CacheKeyConfig) {
+	c.Enabled = conf.Enabled
+	c.Components = conf.Components
+	c.Headers = conf.Headers
+	c.Cookies = conf.Cookies
+	c.Claims = conf.Claims
+	c.HonourVary = conf.HonourVary
+
+	if c.QueryParams == nil {
+		c.QueryParams = &CacheKeyQueryParams{}
+	}
+
+	c.QueryParams.Fill(conf.QueryParams)
+	if ShouldOmit(c.QueryParams) {
+		c.QueryParams = nil
+	}
+}
+
+// ExtractTo extracts *CacheKey into *apidef.CacheKeyConfig.
+func (c *CacheKey) ExtractTo(conf *apidef.CacheKeyConfig) {
+	conf.Enabled = c.Enabled
+	conf.Components = c.Components
+	conf.Headers = c.Headers
+	conf.Cookies = c.Cookies
+	conf.Claims = c.Claims
+	conf.HonourVary = c.HonourVary
+
+	if c.QueryParams == nil {
+		c.QueryParams = &CacheKeyQueryParams{}
+		defer func() {
+			c.QueryParams = nil
+		}()
+	}
+
+	c.QueryParams.ExtractTo(&conf.QueryParams)
+}
+
+// CacheKeyQueryParams selects the query parameters of a cache key.
+type CacheKeyQueryParams struct {
+	// Include lists the only query parameters in the cache key, all are included when empty.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.query_params.include`.
+	Include []string `bson:"include,omitempty" json:"include,omitempty"`
+
+	// Exclude lists query parameters left out of the cache key.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.query_params.exclude`.
+	Exclude []string `bson:"exclude,omitempty" json:"exclude,omitempty"`
+
+	// Sort makes the cache key independent of the order of the query parameters.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.advance_cache_config[].cache_key.query_params.sort`.
+	Sort bool `bson:"sort,omitempty" json:"sort,omitempty"`
+}
+
+// Fill fills *CacheKeyQueryParams from apidef.CacheKeyQueryParams.
+func (q *CacheKeyQueryParams) Fill(params apidef.CacheKeyQueryParams) {
+	q.Include = params.Include
+	q.Exclude = params.Exclude
+	q.Sort = params.Sort
+}
+
+// ExtractTo extracts *CacheKeyQueryParams into *apidef.CacheKeyQueryParams.
+func (q *CacheKeyQueryParams) ExtractTo(params *apidef.CacheKeyQueryParams) {
+	params.Include = q.Include
+	params.Exclude = q.Exclude
+	params.Sort = q.Sort
 }
 
 // Fill fills *CachePlugin from apidef.CacheMeta.
@@ -1293,6 +1570,16 @@ func (a *CachePlugin) Fill(cm apidef.CacheMeta) {
 	a.CacheByRegex = cm.CacheKeyRegex
 	a.CacheResponseCodes = cm.CacheOnlyResponseCodes
 	a.Timeout = cm.Timeout
+	a.SurrogateKeys = cm.SurrogateKeys
+
+	if a.CacheKey == nil {
+		a.CacheKey = &CacheKey{}
+	}
+
+	a.CacheKey.Fill(cm.CacheKey)
+	if ShouldOmit(a.CacheKey) {
+		a.CacheKey = nil
+	}
 
 	//TT-14102: Default cache timeout in seconds if none is specified but caching is enabled
 	if a.Enabled && a.Timeout == 0 {
@@ -1306,6 +1593,16 @@ func (a *CachePlugin) ExtractTo(cm *apidef.CacheMeta) {
 	cm.CacheKeyRegex = a.CacheByRegex
 	cm.CacheOnlyResponseCodes = a.CacheResponseCodes
 	cm.Timeout = a.Timeout
+	cm.SurrogateKeys = a.SurrogateKeys
+
+	if a.CacheKey == nil {
+		a.CacheKey = &CacheKey{}
+		defer func() {
+			a.CacheKey = nil
+		}()
+	}
+
+	a.CacheKey.ExtractTo(&cm.CacheKey)
 }
 
 // EnforceTimeout holds the configuration for enforcing request timeouts.
@@ -1325,6 +1622,52 @@ type EnforceTimeout struct {
 	//
 	// Tyk classic API definition: `version_data.versions.{version-name}.extended_paths.hard_timeouts[].duration`.
 	Duration tyktime.ReadableDuration `bson:"duration,omitempty" json:"duration,omitempty"`
+
+	// Hedge contains the configuration for hedging requests to the endpoint.
+	//
+	// Tyk classic API definition: `version_data.versions.{version-name}.extended_paths.hard_timeouts[].hedge`.
+	Hedge *Hedge `bson:"hedge,omitempty" json:"hedge,omitempty"`
+}
+
+// Hedge holds the configuration for request hedging. When no response came back within the hedge delay, a second
+// request is sent to the next load balanced target. The first response wins and the other request is cancelled.
+type Hedge struct {
+	// Enabled activates request hedging. Only GET, HEAD and OPTIONS requests are hedged.
+	//
+	// Tyk classic API definition: `version_data.versions.{version-name}.extended_paths.hard_timeouts[].hedge.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// Delay is how long to wait for a response before sending the hedged request, using a human-readable format
+	// (e.g. `50ms`). When `percentile` is set, it is used until enough upstream latencies were observed.
+	//
+	// Tyk classic API definition: `version_data.versions.{version-name}.extended_paths.hard_timeouts[].hedge.delay`.
+	Delay tyktime.ReadableDuration `bson:"delay,omitempty" json:"delay,omitempty"`
+
+	// Percentile of the recently observed upstream latencies of the endpoint used as hedge delay, e.g. `95`.
+	//
+	// Tyk classic API definition: `version_data.versions.{version-name}.extended_paths.hard_timeouts[].hedge.percentile`.
+	Percentile float64 `bson:"percentile,omitempty" json:"percentile,omitempty"`
+
+	// Budget is the maximum share of requests that may be hedged, e.g. `0.1` for one in ten. Defaults to `0.1`.
+	//
+	// Tyk classic API definition: `version_data.versions.{version-name}.extended_paths.hard_timeouts[].hedge.budget`.
+	Budget float64 `bson:"budget,omitempty" json:"budget,omitempty"`
+}
+
+// Fill fills *Hedge from apidef.HedgeConfig.
+func (h *Hedge) Fill(conf apidef.HedgeConfig) {
+	h.Enabled = conf.Enabled
+	h.Delay = conf.Delay
+	h.Percentile = conf.Percentile
+	h.Budget = conf.Budget
+}
+
+// ExtractTo extracts *Hedge into *apidef.HedgeConfig.
+func (h *Hedge) ExtractTo(conf *apidef.HedgeConfig) {
+	conf.Enabled = h.Enabled
+	conf.Delay = h.Delay
+	conf.Percentile = h.Percentile
+	conf.Budget = h.Budget
 }
 
 // Fill fills *EnforceTimeout from apidef.HardTimeoutMeta.
@@ -1337,6 +1680,15 @@ func (et *EnforceTimeout) Fill(meta apidef.HardTimeoutMeta) {
 	} else {
 		et.Value = meta.TimeOut
 	}
+
+	if et.Hedge == nil {
+		et.Hedge = &Hedge{}
+	}
+
+	et.Hedge.Fill(meta.Hedge)
+	if ShouldOmit(et.Hedge) {
+		et.Hedge = nil
+	}
 }
 
 // ExtractTo extracts *EnforceTimeout to *apidef.HardTimeoutMeta.
@@ -1349,6 +1701,15 @@ func (et *EnforceTimeout) ExtractTo(meta *apidef.HardTimeoutMeta) {
 	} else {
 		meta.TimeOut = et.Value
 	}
+
+	if et.Hedge == nil {
+		et.Hedge = &Hedge{}
+		defer func() {
+			et.Hedge = nil
+		}()
+	}
+
+	et.Hedge.ExtractTo(&meta.Hedge)
 }
 
 // CustomPlugin configures custom plugin.
@@ -1729,6 +2090,26 @@ type CircuitBreaker struct {
 	//
 	// Tyk classic API definition: `version_data.versions..extended_paths.circuit_breakers[*].disable_half_open_state` (negated).
 	HalfOpenStateEnabled bool `bson:"halfOpenStateEnabled" json:"halfOpenStateEnabled"`
+	// Scope is what the circuit breaker state is kept for: `path` (default) for the endpoint, `api` for all the
+	// endpoints of the API, or `host` for each upstream host.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.circuit_breakers[*].scope`.
+	Scope string `bson:"scope,omitempty" json:"scope,omitempty"`
+	// HalfOpenRequests is the number of probe requests let through once the cool down period has passed. The
+	// circuit breaker is reset when all of them succeed, and opens again on the first failure.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.circuit_breakers[*].half_open_requests`.
+	HalfOpenRequests int `bson:"halfOpenRequests,omitempty" json:"halfOpenRequests,omitempty"`
+	// HalfOpenTimeout is how long, in seconds, half-open probes may take to report their outcome before they are
+	// given up on and other requests can probe the upstream. Defaults to 30.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.circuit_breakers[*].half_open_timeout`.
+	HalfOpenTimeout int `bson:"halfOpenTimeout,omitempty" json:"halfOpenTimeout,omitempty"`
+	// Shared propagates the circuit breaker state to all the gateways of the cluster, so that they all stop
+	// sending requests to a failing upstream together.
+	//
+	// Tyk classic API definition: `version_data.versions..extended_paths.circuit_breakers[*].shared`.
+	Shared bool `bson:"shared,omitempty" json:"shared,omitempty"`
 }
 
 // Fill fills *CircuitBreaker from apidef.CircuitBreakerMeta.
@@ -1738,6 +2119,10 @@ func (cb *CircuitBreaker) Fill(circuitBreaker apidef.CircuitBreakerMeta) {
 	cb.SampleSize = int(circuitBreaker.Samples)
 	cb.CoolDownPeriod = circuitBreaker.ReturnToServiceAfter
 	cb.HalfOpenStateEnabled = !circuitBreaker.DisableHalfOpenState
+	cb.Scope = circuitBreaker.Scope
+	cb.HalfOpenRequests = circuitBreaker.HalfOpenRequests
+	cb.HalfOpenTimeout = circuitBreaker.HalfOpenTimeout
+	cb.Shared = circuitBreaker.Shared
 }
 
 // ExtractTo extracts *CircuitBreaker into *apidef.CircuitBreakerMeta.
@@ -1747,6 +2132,10 @@ func (cb *CircuitBreaker) ExtractTo(circuitBreaker *apidef.CircuitBreakerMeta) {
 	circuitBreaker.Samples = int64(cb.SampleSize)
 	circuitBreaker.ReturnToServiceAfter = cb.CoolDownPeriod
 	circuitBreaker.DisableHalfOpenState = !cb.HalfOpenStateEnabled
+	circuitBreaker.Scope = cb.Scope
+	circuitBreaker.HalfOpenRequests = cb.HalfOpenRequests
+	circuitBreaker.HalfOpenTimeout = cb.HalfOpenTimeout
+	circuitBreaker.Shared = cb.Shared
 }
 
 // RequestSizeLimit limits the maximum allowed size of the request body in bytes.
@@ -1892,6 +2281,163 @@ func (g *GlobalRequestSizeLimit) ExtractTo(api *apidef.APIDefinition) {
 	mainVersion.GlobalSizeLimit = g.Value
 }
 
+// TrafficMirror holds the configuration for mirroring a share of the API requests to a shadow upstream, e.g. to test
+// a new version of a service with production traffic. Mirrored requests are sent asynchronously and their responses
+// are discarded.
+type TrafficMirror struct {
+	// Enabled activates traffic mirroring.
+	//
+	// Tyk classic API definition: `traffic_mirror.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// TargetURL is the URL of the shadow upstream.
+	//
+	// Tyk classic API definition: `traffic_mirror.target_url`.
+	TargetURL string `bson:"targetUrl" json:"targetUrl"`
+
+	// Percentage is the share of requests that are mirrored, from `0` to `100`.
+	//
+	// Tyk classic API definition: `traffic_mirror.percentage`.
+	Percentage float64 `bson:"percentage" json:"percentage"`
+
+	// MaxBodySize is the size in bytes of the largest request body that is mirrored. Requests with larger bodies
+	// aren't mirrored. Defaults to 1MB.
+	//
+	// Tyk classic API definition: `traffic_mirror.max_body_size`.
+	MaxBodySize int64 `bson:"maxBodySize,omitempty" json:"maxBodySize,omitempty"`
+
+	// Timeout is how long a mirrored request may take, using a human-readable format (e.g. `5s`). Defaults to `30s`.
+	//
+	// Tyk classic API definition: `traffic_mirror.timeout`.
+	Timeout tyktime.ReadableDuration `bson:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Compare fires a `TrafficMirrorMismatch` event when the status code or body of the shadow response differs
+	// from the response of the primary upstream.
+	//
+	// Tyk classic API definition: `traffic_mirror.compare`.
+	Compare bool `bson:"compare,omitempty" json:"compare,omitempty"`
+}
+
+// Fill fills *TrafficMirror from apidef.TrafficMirrorConfig.
+func (t *TrafficMirror) Fill(conf apidef.TrafficMirrorConfig) {
+	t.Enabled = conf.Enabled
+	t.TargetURL = conf.TargetURL
+	t.Percentage = conf.Percentage
+	t.MaxBodySize = conf.MaxBodySize
+	t.Timeout = conf.Timeout
+	t.Compare = conf.Compare
+}
+
+// ExtractTo extracts *TrafficMirror into *apidef.TrafficMirrorConfig.
+func (t *TrafficMirror) ExtractTo(conf *apidef.TrafficMirrorConfig) {
+	conf.Enabled = t.Enabled
+	conf.TargetURL = t.TargetURL
+	conf.Percentage = t.Percentage
+	conf.MaxBodySize = t.MaxBodySize
+	conf.Timeout = t.Timeout
+	conf.Compare = t.Compare
+}
+
+// ExternalAuthorization holds the configuration for the authorization of requests by a policy decision point, to
+// manage authorization as policy-as-code. An input document describing the request, its session and token claims,
+// and the API and endpoint is sent to the decision point, which allows or denies the request and may set or remove
+// request headers.
+type ExternalAuthorization struct {
+	// Enabled activates external authorization.
+	//
+	// Tyk classic API definition: `external_authorization.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// Type is the type of policy decision point:
+	// - `http`: a service implementing the Open Policy Agent data API, the decision is read from the `result` member of
+	// the response, either a boolean or an object with `allow`, `status`, `reason`, `headers`, `removeHeaders` and
+	// `responseHeaders` members.
+	// - `grpc`: a service implementing the Envoy external authorization service, the input document is sent as the
+	// `tyk` filter metadata.
+	// - `embedded`: a JavaScript policy evaluated by the gateway.
+	//
+	// Tyk classic API definition: `external_authorization.type`.
+	Type string `bson:"type" json:"type"`
+
+	// URL is the URL of the decision of `http` decision points, e.g. `http://opa:8181/v1/data/tyk/authz`, or the
+	// address of `grpc` decision points, e.g. `grpc://opa:9191`, using `grpcs` for TLS.
+	//
+	// Tyk classic API definition: `external_authorization.url`.
+	URL string `bson:"url,omitempty" json:"url,omitempty"`
+
+	// Policy is the JavaScript source of `embedded` policies, defining an `authorize(input)` function that returns
+	// a boolean or a decision object.
+	//
+	// Tyk classic API definition: `external_authorization.policy`.
+	Policy string `bson:"policy,omitempty" json:"policy,omitempty"`
+
+	// Timeout is how long a decision may take, using a human-readable format (e.g. `500ms`). Defaults to `1s`.
+	//
+	// Tyk classic API definition: `external_authorization.timeout`.
+	Timeout tyktime.ReadableDuration `bson:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// CacheTTL is how long decisions are cached, using a human-readable format (e.g. `30s`). Decisions aren't cached
+	// when it's not set. Decisions are cached by the method, host, path and query of requests, their client IP and
+	// credential headers (e.g. `Authorization`, `Cookie` and the authentication headers of the API), their session,
+	// claims and endpoint, and the headers in `cacheKeyHeaders`. Other headers aren't part of the cache key, so list
+	// the headers policies depend on in `cacheKeyHeaders`.
+	//
+	// Tyk classic API definition: `external_authorization.cache_ttl`.
+	CacheTTL tyktime.ReadableDuration `bson:"cacheTtl,omitempty" json:"cacheTtl,omitempty"`
+
+	// CacheKeyHeaders are the names of the request headers that decisions are cached by.
+	//
+	// Tyk classic API definition: `external_authorization.cache_key_headers`.
+	CacheKeyHeaders []string `bson:"cacheKeyHeaders,omitempty" json:"cacheKeyHeaders,omitempty"`
+
+	// CacheAcrossClients leaves the client IP, the credential headers and the claims that change with every issued
+	// token (`exp`, `iat`, `nbf` and `jti`) out of the cache key, so that decisions are shared by the clients making
+	// the same request. Only enable it for policies that don't depend on them.
+	//
+	// Tyk classic API definition: `external_authorization.cache_across_clients`.
+	CacheAcrossClients bool `bson:"cacheAcrossClients,omitempty" json:"cacheAcrossClients,omitempty"`
+
+	// CacheMaxEntries bounds the number of cached decisions, the least recently used decisions are evicted first.
+	// Defaults to `10000`.
+	//
+	// Tyk classic API definition: `external_authorization.cache_max_entries`.
+	CacheMaxEntries int `bson:"cacheMaxEntries,omitempty" json:"cacheMaxEntries,omitempty"`
+
+	// FailOpen allows requests when no decision can be made, e.g. when the decision point is unavailable.
+	// Requests are denied by default.
+	//
+	// Tyk classic API definition: `external_authorization.fail_open`.
+	FailOpen bool `bson:"failOpen,omitempty" json:"failOpen,omitempty"`
+}
+
+// Fill fills *ExternalAuthorization from apidef.ExternalAuthzConfig.
+func (e *ExternalAuthorization) Fill(conf apidef.ExternalAuthzConfig) {
+	e.Enabled = conf.Enabled
+	e.Type = conf.Type
+	e.URL = conf.URL
+	e.Policy = conf.Policy
+	e.Timeout = conf.Timeout
+	e.CacheTTL = conf.CacheTTL
+	e.CacheKeyHeaders = conf.CacheKeyHeaders
+	e.CacheAcrossClients = conf.CacheAcrossClients
+	e.CacheMaxEntries = conf.CacheMaxEntries
+	e.FailOpen = conf.FailOpen
+}
+
+// ExtractTo extracts *ExternalAuthorization into *apidef.ExternalAuthzConfig.
+func (e *ExternalAuthorization) ExtractTo(conf *apidef.ExternalAuthzConfig) {
+	conf.Enabled = e.Enabled
+	conf.Type = e.Type
+	conf.URL = e.URL
+	conf.Policy = e.Policy
+	conf.Timeout = e.Timeout
+	conf.CacheTTL = e.CacheTTL
+	conf.CacheKeyHeaders = e.CacheKeyHeaders
+	conf.CacheAcrossClients = e.CacheAcrossClients
+	conf.CacheMaxEntries = e.CacheMaxEntries
+	conf.FailOpen = e.FailOpen
+}
+
 // ContextVariables holds the configuration related to Tyk context variables.
 type ContextVariables struct {
 	// Enabled provides access to context variables from specific Tyk middleware (URL rewrite, header and body transform).
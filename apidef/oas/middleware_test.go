@@ -443,6 +443,149 @@ func TestCache(t *testing.T) {
 	assert.Equal(t, emptyCache, resultCache)
 }
 
+func TestRequestCoalescing(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		cache := Cache{
+			Enabled: true,
+			RequestCoalescing: &RequestCoalescing{
+				Enabled:     true,
+				Timeout:     ReadableDuration(time.Second),
+				Distributed: true,
+			},
+		}
+
+		var converted apidef.CacheOptions
+		cache.ExtractTo(&converted)
+		assert.Equal(t, apidef.RequestCoalescingConfig{
+			Enabled:     true,
+			Timeout:     ReadableDuration(time.Second),
+			Distributed: true,
+		}, converted.RequestCoalescing)
+
+		var result Cache
+		result.Fill(converted)
+		assert.Equal(t, cache.RequestCoalescing, result.RequestCoalescing)
+	})
+
+	t.Run("omitted when empty", func(t *testing.T) {
+		var result Cache
+		result.Fill(apidef.CacheOptions{EnableCache: true})
+		assert.Nil(t, result.RequestCoalescing)
+	})
+}
+
+func TestHedge(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		timeout := EnforceTimeout{
+			Enabled: true,
+			Value:   5,
+			Hedge: &Hedge{
+				Enabled:    true,
+				Delay:      ReadableDuration(50 * time.Millisecond),
+				Percentile: 95,
+				Budget:     0.05,
+			},
+		}
+
+		var converted apidef.HardTimeoutMeta
+		timeout.ExtractTo(&converted)
+		assert.Equal(t, apidef.HedgeConfig{
+			Enabled:    true,
+			Delay:      ReadableDuration(50 * time.Millisecond),
+			Percentile: 95,
+			Budget:     0.05,
+		}, converted.Hedge)
+
+		var result EnforceTimeout
+		result.Fill(converted)
+		assert.Equal(t, timeout, result)
+	})
+
+	t.Run("omitted when empty", func(t *testing.T) {
+		var result EnforceTimeout
+		result.Fill(apidef.HardTimeoutMeta{TimeOut: 5})
+		assert.Nil(t, result.Hedge)
+	})
+}
+
+func TestTrafficMirror(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		global := Global{
+			TrafficMirror: &TrafficMirror{
+				Enabled:     true,
+				TargetURL:   "http://shadow:8080",
+				Percentage:  10,
+				MaxBodySize: 1024,
+				Timeout:     ReadableDuration(5 * time.Second),
+				Compare:     true,
+			},
+		}
+
+		var converted apidef.APIDefinition
+		global.ExtractTo(&converted)
+		assert.Equal(t, apidef.TrafficMirrorConfig{
+			Enabled:     true,
+			TargetURL:   "http://shadow:8080",
+			Percentage:  10,
+			MaxBodySize: 1024,
+			Timeout:     ReadableDuration(5 * time.Second),
+			Compare:     true,
+		}, converted.TrafficMirror)
+
+		var result Global
+		result.Fill(converted)
+		assert.Equal(t, global.TrafficMirror, result.TrafficMirror)
+	})
+
+	t.Run("omitted when empty", func(t *testing.T) {
+		var result Global
+		result.Fill(apidef.APIDefinition{})
+		assert.Nil(t, result.TrafficMirror)
+	})
+}
+
+func TestExternalAuthorization(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		global := Global{
+			ExternalAuthorization: &ExternalAuthorization{
+				Enabled:            true,
+				Type:               apidef.ExternalAuthzHTTP,
+				URL:                "http://opa:8181/v1/data/tyk/authz",
+				Timeout:            ReadableDuration(500 * time.Millisecond),
+				CacheTTL:           ReadableDuration(30 * time.Second),
+				CacheKeyHeaders:    []string{"X-Tenant"},
+				CacheAcrossClients: true,
+				CacheMaxEntries:    100,
+				FailOpen:           true,
+			},
+		}
+
+		var converted apidef.APIDefinition
+		global.ExtractTo(&converted)
+		assert.Equal(t, apidef.ExternalAuthzConfig{
+			Enabled:            true,
+			Type:               apidef.ExternalAuthzHTTP,
+			URL:                "http://opa:8181/v1/data/tyk/authz",
+			Timeout:            ReadableDuration(500 * time.Millisecond),
+			CacheTTL:           ReadableDuration(30 * time.Second),
+			CacheKeyHeaders:    []string{"X-Tenant"},
+			CacheAcrossClients: true,
+			CacheMaxEntries:    100,
+			FailOpen:           true,
+		}, converted.ExternalAuthorization)
+
+		var result Global
+		result.Fill(converted)
+		assert.Equal(t, global.ExternalAuthorization, result.ExternalAuthorization)
+	})
+
+	t.Run("omitted when empty", func(t *testing.T) {
+		var result Global
+		result.Fill(apidef.APIDefinition{})
+		assert.Nil(t, result.ExternalAuthorization)
+	})
+}
+
 func TestExtendedPaths(t *testing.T) {
 	t.Run("empty", func(t *testing.T) {
 		paths := make(Paths)
@@ -1009,6 +1152,10 @@ func TestCircuitBreaker(t *testing.T) {
 			SampleSize:           5,
 			CoolDownPeriod:       50,
 			HalfOpenStateEnabled: true,
+			Scope:                apidef.CircuitBreakerScopeHost,
+			HalfOpenRequests:     3,
+			HalfOpenTimeout:      10,
+			Shared:               true,
 		}
 
 		meta := apidef.CircuitBreakerMeta{}
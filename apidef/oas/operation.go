@@ -87,6 +87,12 @@ type Operation struct {
 	// RateLimit contains endpoint level rate limit configuration.
 	RateLimit *RateLimitEndpoint `bson:"rateLimit,omitempty" json:"rateLimit,omitempty"`
 
+	// ConcurrencyLimit contains endpoint level concurrency limit configuration.
+	ConcurrencyLimit *ConcurrencyLimit `bson:"concurrencyLimit,omitempty" json:"concurrencyLimit,omitempty"`
+
+	// UpstreamRetry overrides the upstream retry configuration of the API for this endpoint.
+	UpstreamRetry *UpstreamRetry `bson:"upstreamRetry,omitempty" json:"upstreamRetry,omitempty"`
+
 	// ScopeCheck toggles the operation-level OAuth 2.0 scope check.
 	ScopeCheck *ScopeCheck `bson:"scopeCheck,omitempty" json:"scopeCheck,omitempty"`
 
@@ -127,6 +133,8 @@ func (o *Operation) ExtractToExtendedPaths(ep *apidef.ExtendedPathsSet, path str
 	o.extractDoNotTrackEndpointTo(ep, path, method)
 	o.extractRequestSizeLimitTo(ep, path, method)
 	o.extractRateLimitEndpointTo(ep, path, method)
+	o.extractConcurrencyLimitTo(ep, path, method)
+	o.extractUpstreamRetryTo(ep, path, method)
 }
 
 // AllowanceType holds the valid allowance types values.
@@ -209,6 +217,8 @@ func (s *OAS) fillPathsAndOperations(ep apidef.ExtendedPathsSet) {
 	s.fillDoNotTrackEndpoint(ep.DoNotTrackEndpoints)
 	s.fillRequestSizeLimit(ep.SizeLimit)
 	s.fillRateLimitEndpoints(ep.RateLimit)
+	s.fillConcurrencyLimits(ep.ConcurrencyLimit)
+	s.fillUpstreamRetries(ep.UpstreamRetry)
 	s.fillMockResponsePaths(s.Paths, ep)
 }
 
@@ -311,6 +321,8 @@ func (s *OAS) extractPathsAndOperations(ep *apidef.ExtendedPathsSet) {
 					tykOp.extractDoNotTrackEndpointTo(ep, path, method)
 					tykOp.extractRequestSizeLimitTo(ep, path, method)
 					tykOp.extractRateLimitEndpointTo(ep, path, method)
+					tykOp.extractConcurrencyLimitTo(ep, path, method)
+					tykOp.extractUpstreamRetryTo(ep, path, method)
 					break
 				}
 			}
@@ -1016,6 +1028,54 @@ func (o *Operation) extractRateLimitEndpointTo(ep *apidef.ExtendedPathsSet, path
 	ep.RateLimit = append(ep.RateLimit, meta)
 }
 
+func (s *OAS) fillConcurrencyLimits(endpointMetas []apidef.ConcurrencyLimitMeta) {
+	for _, em := range endpointMetas {
+		operationID := s.getOperationID(em.Path, em.Method)
+		operation := s.GetTykExtension().getOperation(operationID)
+		if operation.ConcurrencyLimit == nil {
+			operation.ConcurrencyLimit = &ConcurrencyLimit{}
+		}
+
+		operation.ConcurrencyLimit.Fill(em)
+		if ShouldOmit(operation.ConcurrencyLimit) {
+			operation.ConcurrencyLimit = nil
+		}
+	}
+}
+
+func (o *Operation) extractConcurrencyLimitTo(ep *apidef.ExtendedPathsSet, path string, method string) {
+	if o.ConcurrencyLimit == nil {
+		return
+	}
+
+	meta := apidef.ConcurrencyLimitMeta{Path: path, Method: method}
+	o.ConcurrencyLimit.ExtractTo(&meta)
+	ep.ConcurrencyLimit = append(ep.ConcurrencyLimit, meta)
+}
+
+func (s *OAS) fillUpstreamRetries(endpointMetas []apidef.UpstreamRetryMeta) {
+	for _, em := range endpointMetas {
+		operationID := s.getOperationID(em.Path, em.Method)
+		operation := s.GetTykExtension().getOperation(operationID)
+		if operation.UpstreamRetry == nil {
+			operation.UpstreamRetry = &UpstreamRetry{}
+		}
+
+		// a disabled override turns retries off for the endpoint, so it isn't omitted
+		operation.UpstreamRetry.Fill(em.Retry)
+	}
+}
+
+func (o *Operation) extractUpstreamRetryTo(ep *apidef.ExtendedPathsSet, path string, method string) {
+	if o.UpstreamRetry == nil {
+		return
+	}
+
+	meta := apidef.UpstreamRetryMeta{Path: path, Method: method}
+	o.UpstreamRetry.ExtractTo(&meta.Retry)
+	ep.UpstreamRetry = append(ep.UpstreamRetry, meta)
+}
+
 func (s *OAS) fillEndpointPostPlugins(endpointMetas []apidef.GoPluginMeta) {
 	for _, em := range endpointMetas {
 		operationID := s.getOperationID(em.Path, em.Method)
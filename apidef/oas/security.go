@@ -231,6 +231,12 @@ type JWT struct {
 	// JTIValidation contains the configuration for the validation of the JWT ID.
 	JTIValidation JTIValidation `bson:"jtiValidation,omitempty" json:"jtiValidation,omitempty"`
 
+	// DPoP contains the configuration for the validation of DPoP proofs of sender-constrained tokens.
+	DPoP *DPoP `bson:"dpop,omitempty" json:"dpop,omitempty"`
+
+	// CertificateBinding contains the configuration for the validation of certificate-bound tokens.
+	CertificateBinding *CertificateBinding `bson:"certificateBinding,omitempty" json:"certificateBinding,omitempty"`
+
 	// AllowedSubjects contains a list of accepted subjects for JWT validation.
 	// When configured, the subject from kid/identityBaseField/sub must match one of these values.
 	AllowedSubjects []string `bson:"allowedSubjects,omitempty" json:"allowedSubjects,omitempty"`
@@ -309,6 +315,38 @@ type JTIValidation struct {
 	Enabled bool `bson:"enabled" json:"enabled"`
 }
 
+// DPoP contains the configuration for the validation of DPoP proofs (RFC 9449).
+// Tokens bound to a key with the `cnf.jkt` claim must be sent with a proof, signed
+// with that key, in the `DPoP` header.
+type DPoP struct {
+	// Enabled activates the validation of DPoP proofs.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// AllowBearerTokens allows tokens without a `cnf.jkt` claim to be used without a proof.
+	// When false, all tokens must be sender-constrained.
+	AllowBearerTokens bool `bson:"allowBearerTokens,omitempty" json:"allowBearerTokens,omitempty"`
+
+	// ProofMaxAge contains the duration in seconds for which proofs are accepted after they were issued.
+	// Defaults to 60 seconds.
+	ProofMaxAge uint64 `bson:"proofMaxAge,omitempty" json:"proofMaxAge,omitempty"`
+
+	// AllowedClockSkew contains the duration in seconds for which proofs can be issued in the future.
+	// Defaults to 5 seconds.
+	AllowedClockSkew uint64 `bson:"allowedClockSkew,omitempty" json:"allowedClockSkew,omitempty"`
+}
+
+// CertificateBinding contains the configuration for the validation of certificate-bound
+// access tokens (RFC 8705). Tokens bound to a client certificate with the `cnf.x5t#S256`
+// claim must be sent over a mutual TLS connection using that certificate.
+type CertificateBinding struct {
+	// Enabled activates the validation of certificate-bound tokens.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// AllowUnboundTokens allows tokens without a `cnf.x5t#S256` claim to be used.
+	// When false, all tokens must be bound to the client certificate.
+	AllowUnboundTokens bool `bson:"allowUnboundTokens,omitempty" json:"allowUnboundTokens,omitempty"`
+}
+
 // Import populates *JWT based on arguments.
 func (j *JWT) Import(enable bool) {
 	j.Enabled = enable
@@ -413,6 +451,8 @@ func (s *OAS) fillJWT(api apidef.APIDefinition) {
 		jwt.AllowedAudiences = existing.AllowedAudiences
 		jwt.AllowedSubjects = existing.AllowedSubjects
 		jwt.JTIValidation.Enabled = existing.JTIValidation.Enabled
+		jwt.DPoP = existing.DPoP
+		jwt.CertificateBinding = existing.CertificateBinding
 
 		if existing.Scopes != nil {
 			jwt.Scopes.Claims = mergeStringFirst(api.Scopes.JWT.ScopeClaimName, existing.Scopes.Claims)
@@ -875,6 +915,12 @@ type ExternalOAuth struct {
 	//
 	// Tyk classic API definition: `external_oauth.providers`.
 	Providers []OAuthProvider `bson:"providers" json:"providers"` // required
+
+	// DPoP contains the configuration for the validation of DPoP proofs of sender-constrained tokens.
+	DPoP *DPoP `bson:"dpop,omitempty" json:"dpop,omitempty"`
+
+	// CertificateBinding contains the configuration for the validation of certificate-bound tokens.
+	CertificateBinding *CertificateBinding `bson:"certificateBinding,omitempty" json:"certificateBinding,omitempty"`
 }
 
 func (s *OAS) fillExternalOAuth(api apidef.APIDefinition) {
@@ -924,6 +970,11 @@ func (s *OAS) fillExternalOAuth(api apidef.APIDefinition) {
 		externalOAuth.Providers = nil
 	}
 
+	if existing := s.getTykExternalOAuthAuth(authConfig.Name); existing != nil {
+		externalOAuth.DPoP = existing.DPoP
+		externalOAuth.CertificateBinding = existing.CertificateBinding
+	}
+
 	if ShouldOmit(externalOAuth) {
 		externalOAuth = nil
 	}
@@ -1504,6 +1555,12 @@ func (s *OAS) GetJWTConfiguration() *JWT {
 	return nil
 }
 
+// GetExternalOAuthConfiguration returns the external OAuth configuration of the security scheme name,
+// or nil if there isn't one.
+func (s *OAS) GetExternalOAuthConfiguration(name string) *ExternalOAuth {
+	return s.getTykExternalOAuthAuth(name)
+}
+
 func resetSecuritySchemes(api *apidef.APIDefinition) {
 	api.AuthConfigs = nil
 
@@ -36,6 +36,10 @@ type Upstream struct {
 	// Tyk classic API definition: `global_rate_limit`.
 	RateLimit *RateLimit `bson:"rateLimit,omitempty" json:"rateLimit,omitempty"`
 
+	// RateLimitQueue contains the configuration for queuing requests that exceed a rate or concurrency limit.
+	// Tyk classic API definition: `rate_limit_queue`.
+	RateLimitQueue *RateLimitQueue `bson:"rateLimitQueue,omitempty" json:"rateLimitQueue,omitempty"`
+
 	// Authentication contains the configuration related to upstream authentication.
 	// Tyk classic API definition: `upstream_auth`.
 	Authentication *UpstreamAuth `bson:"authentication,omitempty" json:"authentication,omitempty"`
@@ -64,6 +68,15 @@ type Upstream struct {
 	// EnforceTimeout contains the configuration related to API level timeout duration.
 	// Tyk classic API definition: `version_data.versions.<version_name>.global_enforce_timeout`.
 	EnforceTimeout *GlobalEnforceTimeout `bson:"enforceTimeout,omitempty" json:"enforceTimeout,omitempty"`
+
+	// Retry contains the configuration for retrying failed upstream requests.
+	// Tyk classic API definition: `proxy.retry`.
+	Retry *UpstreamRetry `bson:"retry,omitempty" json:"retry,omitempty"`
+
+	// AdaptiveConcurrency contains the configuration for limiting the requests in flight to each upstream host
+	// to a limit that adapts to the upstream latency.
+	// Tyk classic API definition: `proxy.adaptive_concurrency`.
+	AdaptiveConcurrency *AdaptiveConcurrency `bson:"adaptiveConcurrency,omitempty" json:"adaptiveConcurrency,omitempty"`
 }
 
 // Fill fills *Upstream from apidef.APIDefinition.
@@ -115,6 +128,15 @@ func (u *Upstream) Fill(api apidef.APIDefinition) {
 		u.RateLimit = nil
 	}
 
+	if u.RateLimitQueue == nil {
+		u.RateLimitQueue = &RateLimitQueue{}
+	}
+
+	u.RateLimitQueue.Fill(api.RateLimitQueue)
+	if ShouldOmit(u.RateLimitQueue) {
+		u.RateLimitQueue = nil
+	}
+
 	if u.Authentication == nil {
 		u.Authentication = &UpstreamAuth{}
 	}
@@ -149,6 +171,24 @@ func (u *Upstream) Fill(api apidef.APIDefinition) {
 		u.EnforceTimeout = nil
 	}
 
+	if u.Retry == nil {
+		u.Retry = &UpstreamRetry{}
+	}
+
+	u.Retry.Fill(api.Proxy.Retry)
+	if ShouldOmit(u.Retry) {
+		u.Retry = nil
+	}
+
+	if u.AdaptiveConcurrency == nil {
+		u.AdaptiveConcurrency = &AdaptiveConcurrency{}
+	}
+
+	u.AdaptiveConcurrency.Fill(api.Proxy.AdaptiveConcurrency)
+	if ShouldOmit(u.AdaptiveConcurrency) {
+		u.AdaptiveConcurrency = nil
+	}
+
 	u.fillLoadBalancing(api)
 	u.fillPreserveHostHeader(api)
 	u.fillPreserveTrailingSlash(api)
@@ -226,6 +266,15 @@ func (u *Upstream) ExtractTo(api *apidef.APIDefinition) {
 
 	u.RateLimit.ExtractTo(api)
 
+	if u.RateLimitQueue == nil {
+		u.RateLimitQueue = &RateLimitQueue{}
+		defer func() {
+			u.RateLimitQueue = nil
+		}()
+	}
+
+	u.RateLimitQueue.ExtractTo(&api.RateLimitQueue)
+
 	if u.Authentication == nil {
 		u.Authentication = &UpstreamAuth{}
 		defer func() {
@@ -261,6 +310,22 @@ func (u *Upstream) ExtractTo(api *apidef.APIDefinition) {
 	}
 	u.EnforceTimeout.ExtractTo(api)
 
+	if u.Retry == nil {
+		u.Retry = &UpstreamRetry{}
+		defer func() {
+			u.Retry = nil
+		}()
+	}
+	u.Retry.ExtractTo(&api.Proxy.Retry)
+
+	if u.AdaptiveConcurrency == nil {
+		u.AdaptiveConcurrency = &AdaptiveConcurrency{}
+		defer func() {
+			u.AdaptiveConcurrency = nil
+		}()
+	}
+	u.AdaptiveConcurrency.ExtractTo(&api.Proxy.AdaptiveConcurrency)
+
 	u.preserveHostHeaderExtractTo(api)
 	u.preserveTrailingSlashExtractTo(api)
 }
@@ -959,6 +1024,13 @@ type RateLimit struct {
 	//
 	// Tyk classic API definition: `global_rate_limit.per`.
 	Per ReadableDuration `json:"per" bson:"per"`
+	// Algorithm selects the rate limiting algorithm. The only supported value is
+	// `gcra`, the generic cell rate algorithm, which allows a burst of up to `rate`
+	// requests and reports an exact `Retry-After` to blocked clients.
+	// When empty, the rate limiter configured for the gateway is used.
+	//
+	// Tyk classic API definition: `global_rate_limit.algorithm`.
+	Algorithm string `json:"algorithm,omitempty" bson:"algorithm,omitempty"`
 }
 
 // Fill fills *RateLimit from apidef.APIDefinition.
@@ -966,6 +1038,7 @@ func (r *RateLimit) Fill(api apidef.APIDefinition) {
 	r.Enabled = !api.GlobalRateLimit.Disabled
 	r.Rate = int(api.GlobalRateLimit.Rate)
 	r.Per = ReadableDuration(time.Duration(api.GlobalRateLimit.Per) * time.Second)
+	r.Algorithm = api.GlobalRateLimit.Algorithm
 }
 
 // ExtractTo extracts *Ratelimit into *apidef.APIDefinition.
@@ -973,6 +1046,7 @@ func (r *RateLimit) ExtractTo(api *apidef.APIDefinition) {
 	api.GlobalRateLimit.Disabled = !r.Enabled
 	api.GlobalRateLimit.Rate = float64(r.Rate)
 	api.GlobalRateLimit.Per = r.Per.Seconds()
+	api.GlobalRateLimit.Algorithm = r.Algorithm
 }
 
 // RateLimitEndpoint carries same settings as RateLimit but for endpoints.
@@ -983,6 +1057,7 @@ func (r *RateLimitEndpoint) Fill(api apidef.RateLimitMeta) {
 	r.Enabled = !api.Disabled
 	r.Rate = int(api.Rate)
 	r.Per = ReadableDuration(time.Duration(api.Per) * time.Second)
+	r.Algorithm = api.Algorithm
 }
 
 // ExtractTo extracts *Ratelimit into *apidef.RateLimitMeta.
@@ -990,6 +1065,34 @@ func (r *RateLimitEndpoint) ExtractTo(meta *apidef.RateLimitMeta) {
 	meta.Disabled = !r.Enabled
 	meta.Rate = float64(r.Rate)
 	meta.Per = r.Per.Seconds()
+	meta.Algorithm = r.Algorithm
+}
+
+// ConcurrencyLimit limits the number of requests to an endpoint that are in flight at the same time.
+// Requests over the limit are rejected with `429 Too Many Requests` until an earlier request completes.
+//
+// Tyk classic API definition: `version_data.versions..extended_paths.concurrency_limit[]`.
+type ConcurrencyLimit struct {
+	// Enabled activates the concurrency limit for this endpoint.
+	//
+	// Tyk classic API definition: `!disabled`.
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// MaxConcurrentRequests is the maximum number of requests to the endpoint that can be in flight at the same time.
+	//
+	// Tyk classic API definition: `max_concurrent_requests`.
+	MaxConcurrentRequests int `json:"maxConcurrentRequests" bson:"maxConcurrentRequests"`
+}
+
+// Fill fills *ConcurrencyLimit from apidef.ConcurrencyLimitMeta.
+func (c *ConcurrencyLimit) Fill(meta apidef.ConcurrencyLimitMeta) {
+	c.Enabled = !meta.Disabled
+	c.MaxConcurrentRequests = meta.MaxConcurrentRequests
+}
+
+// ExtractTo extracts *ConcurrencyLimit into *apidef.ConcurrencyLimitMeta.
+func (c *ConcurrencyLimit) ExtractTo(meta *apidef.ConcurrencyLimitMeta) {
+	meta.Disabled = !c.Enabled
+	meta.MaxConcurrentRequests = c.MaxConcurrentRequests
 }
 
 // UpstreamAuth holds the configurations related to upstream API authentication.
@@ -1340,6 +1443,94 @@ type LoadBalancing struct {
 	SkipUnavailableHosts bool `json:"skipUnavailableHosts,omitempty" bson:"skipUnavailableHosts,omitempty"`
 	// Targets defines the list of targets with their respective weights for load balancing.
 	Targets []LoadBalancingTarget `json:"targets,omitempty" bson:"targets,omitempty"`
+	// Algorithm selects the strategy used to pick a target. Valid values are:
+	//
+	// - `roundRobin` cycles through the targets, repeating each one by its weight (default),
+	// - `weightedRoundRobin` spreads the picks of heavier targets evenly across the cycle,
+	// - `leastConnections` picks the target with the fewest in-flight requests relative to its weight,
+	// - `peakEwma` picks the cheaper of two random targets, based on latency and in-flight requests,
+	// - `consistentHash` keeps requests with the same hashed attribute on the same target.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.algorithm`.
+	Algorithm string `json:"algorithm,omitempty" bson:"algorithm,omitempty"`
+	// ConsistentHash configures the request attribute used by the `consistentHash` algorithm.
+	ConsistentHash *ConsistentHash `json:"consistentHash,omitempty" bson:"consistentHash,omitempty"`
+	// OutlierDetection configures the passive ejection of targets that keep failing.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.outlier_detection`.
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty" bson:"outlierDetection,omitempty"`
+}
+
+// OutlierDetection configures passive outlier detection. Targets answering with consecutive
+// 5xx responses or connection errors are ejected from the load balancing pool for a growing
+// period of time. `HostDown` and `HostUp` events are fired on ejection and return.
+type OutlierDetection struct {
+	// Enabled activates outlier detection.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.outlier_detection.enabled`.
+	Enabled bool `json:"enabled" bson:"enabled"` // required
+	// ConsecutiveFailures is the number of consecutive failures that eject a target. Defaults to 5.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.outlier_detection.consecutive_failures`.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty" bson:"consecutiveFailures,omitempty"`
+	// BaseEjectionTime is how long a target is ejected the first time, doubled on every
+	// further ejection. Defaults to `30s`.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.outlier_detection.base_ejection_time`.
+	BaseEjectionTime time.ReadableDuration `json:"baseEjectionTime,omitempty" bson:"baseEjectionTime,omitempty"`
+	// MaxEjectionTime caps how long a target is ejected. Defaults to `5m`.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.outlier_detection.max_ejection_time`.
+	MaxEjectionTime time.ReadableDuration `json:"maxEjectionTime,omitempty" bson:"maxEjectionTime,omitempty"`
+	// MaxEjectionPercent caps the percentage of targets that can be ejected at once. Defaults to 10.
+	// At least one target can be ejected, but never the last one.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.outlier_detection.max_ejection_percent`.
+	MaxEjectionPercent int `json:"maxEjectionPercent,omitempty" bson:"maxEjectionPercent,omitempty"`
+}
+
+// Fill fills *OutlierDetection from apidef.OutlierDetectionConfig.
+func (o *OutlierDetection) Fill(od apidef.OutlierDetectionConfig) {
+	o.Enabled = od.Enabled
+	o.ConsecutiveFailures = od.ConsecutiveFailures
+	o.BaseEjectionTime = od.BaseEjectionTime
+	o.MaxEjectionTime = od.MaxEjectionTime
+	o.MaxEjectionPercent = od.MaxEjectionPercent
+}
+
+// ExtractTo extracts *OutlierDetection into *apidef.OutlierDetectionConfig.
+func (o *OutlierDetection) ExtractTo(od *apidef.OutlierDetectionConfig) {
+	od.Enabled = o.Enabled
+	od.ConsecutiveFailures = o.ConsecutiveFailures
+	od.BaseEjectionTime = o.BaseEjectionTime
+	od.MaxEjectionTime = o.MaxEjectionTime
+	od.MaxEjectionPercent = o.MaxEjectionPercent
+}
+
+// ConsistentHash configures the request attribute that is hashed to pick a target,
+// so that requests sharing the attribute value are sent to the same upstream.
+type ConsistentHash struct {
+	// Source is the request attribute to hash. Valid values are `header`, `cookie`,
+	// `session` (the authenticated key) and `ip` (the client IP address).
+	//
+	// Tyk classic API definition: `proxy.load_balancing.hash_source`.
+	Source string `json:"source" bson:"source"` // required
+	// Name is the header or cookie name when Source is `header` or `cookie`.
+	//
+	// Tyk classic API definition: `proxy.load_balancing.hash_name`.
+	Name string `json:"name,omitempty" bson:"name,omitempty"`
+}
+
+// Fill fills *ConsistentHash from apidef.LoadBalancingConfig.
+func (c *ConsistentHash) Fill(lb apidef.LoadBalancingConfig) {
+	c.Source = lb.HashSource
+	c.Name = lb.HashName
+}
+
+// ExtractTo extracts *ConsistentHash into *apidef.LoadBalancingConfig.
+func (c *ConsistentHash) ExtractTo(lb *apidef.LoadBalancingConfig) {
+	lb.HashSource = c.Source
+	lb.HashName = c.Name
 }
 
 // LoadBalancingTarget represents a single upstream target for load balancing with a URL and an associated weight.
@@ -1361,6 +1552,23 @@ func (l *LoadBalancing) Fill(api apidef.APIDefinition) {
 
 	l.Enabled = api.Proxy.EnableLoadBalancing
 	l.SkipUnavailableHosts = api.Proxy.CheckHostAgainstUptimeTests
+	l.Algorithm = api.Proxy.LoadBalancing.Algorithm
+
+	if l.ConsistentHash == nil {
+		l.ConsistentHash = &ConsistentHash{}
+	}
+	l.ConsistentHash.Fill(api.Proxy.LoadBalancing)
+	if ShouldOmit(l.ConsistentHash) {
+		l.ConsistentHash = nil
+	}
+
+	if l.OutlierDetection == nil {
+		l.OutlierDetection = &OutlierDetection{}
+	}
+	l.OutlierDetection.Fill(api.Proxy.LoadBalancing.OutlierDetection)
+	if ShouldOmit(l.OutlierDetection) {
+		l.OutlierDetection = nil
+	}
 
 	targetCounter := make(map[string]*LoadBalancingTarget)
 	for _, target := range api.Proxy.Targets {
@@ -1409,12 +1617,31 @@ func (l *LoadBalancing) ExtractTo(api *apidef.APIDefinition) {
 		api.Proxy.EnableLoadBalancing = false
 		api.Proxy.CheckHostAgainstUptimeTests = false
 		api.Proxy.Targets = nil
+		api.Proxy.LoadBalancing = apidef.LoadBalancingConfig{}
 		return
 	}
 
 	proxyConfTargets := make([]string, 0, len(l.Targets))
 	api.Proxy.EnableLoadBalancing = l.Enabled
 	api.Proxy.CheckHostAgainstUptimeTests = l.SkipUnavailableHosts
+	api.Proxy.LoadBalancing.Algorithm = l.Algorithm
+
+	if l.ConsistentHash == nil {
+		l.ConsistentHash = &ConsistentHash{}
+		defer func() {
+			l.ConsistentHash = nil
+		}()
+	}
+	l.ConsistentHash.ExtractTo(&api.Proxy.LoadBalancing)
+
+	if l.OutlierDetection == nil {
+		l.OutlierDetection = &OutlierDetection{}
+		defer func() {
+			l.OutlierDetection = nil
+		}()
+	}
+	l.OutlierDetection.ExtractTo(&api.Proxy.LoadBalancing.OutlierDetection)
+
 	for _, target := range l.Targets {
 		for i := 0; i < target.Weight; i++ {
 			proxyConfTargets = append(proxyConfTargets, target.URL)
@@ -1494,3 +1721,197 @@ func (g *GlobalEnforceTimeout) ExtractTo(api *apidef.APIDefinition) {
 	mainVersion.GlobalEnforceTimeout = g.Duration
 	api.VersionData.Versions[Main] = mainVersion
 }
+
+// UpstreamRetry holds the configuration for retrying failed upstream requests.
+// Retries are subject to the gateway wide retry budget, so that they can't amplify an outage.
+type UpstreamRetry struct {
+	// Enabled activates retries of failed upstream requests.
+	//
+	// Tyk classic API definition: `proxy.retry.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// MaxAttempts is the total number of upstream attempts, including the first one.
+	//
+	// Tyk classic API definition: `proxy.retry.max_attempts`.
+	MaxAttempts int `bson:"maxAttempts,omitempty" json:"maxAttempts,omitempty"`
+
+	// RetryOn lists the failure classes that are retried:
+	// - `connectFailure`: the connection to the upstream couldn't be established,
+	// - `reset`: the connection was reset after the request was sent,
+	// - `timeout`: the upstream didn't respond in time,
+	// - `gatewayError`: the upstream responded with 502, 503 or 504,
+	// - `5xx`: the upstream responded with any 5xx status code.
+	//
+	// When empty, `connectFailure` and `gatewayError` are retried.
+	//
+	// Tyk classic API definition: `proxy.retry.retry_on`.
+	RetryOn []string `bson:"retryOn,omitempty" json:"retryOn,omitempty"`
+
+	// StatusCodes lists additional upstream response status codes that are retried.
+	//
+	// Tyk classic API definition: `proxy.retry.status_codes`.
+	StatusCodes []int `bson:"statusCodes,omitempty" json:"statusCodes,omitempty"`
+
+	// BackoffBase is the delay before the first retry, doubled on every further retry
+	// and randomised with full jitter, using a human-readable format (e.g. `25ms`).
+	//
+	// Tyk classic API definition: `proxy.retry.backoff_base`.
+	BackoffBase time.ReadableDuration `bson:"backoffBase,omitempty" json:"backoffBase,omitempty"`
+
+	// BackoffMax caps the delay between two attempts, using a human-readable format (e.g. `1s`).
+	//
+	// Tyk classic API definition: `proxy.retry.backoff_max`.
+	BackoffMax time.ReadableDuration `bson:"backoffMax,omitempty" json:"backoffMax,omitempty"`
+
+	// RetryNonIdempotent allows retrying requests using non-idempotent methods such as POST or PATCH.
+	// By default only idempotent methods are retried.
+	//
+	// Tyk classic API definition: `proxy.retry.retry_non_idempotent`.
+	RetryNonIdempotent bool `bson:"retryNonIdempotent,omitempty" json:"retryNonIdempotent,omitempty"`
+}
+
+// Fill fills *UpstreamRetry from apidef.UpstreamRetryConfig.
+func (r *UpstreamRetry) Fill(retry apidef.UpstreamRetryConfig) {
+	r.Enabled = retry.Enabled
+	r.MaxAttempts = retry.MaxAttempts
+	r.RetryOn = retry.RetryOn
+	r.StatusCodes = retry.StatusCodes
+	r.BackoffBase = retry.BackoffBase
+	r.BackoffMax = retry.BackoffMax
+	r.RetryNonIdempotent = retry.RetryNonIdempotent
+}
+
+// ExtractTo extracts *UpstreamRetry into *apidef.UpstreamRetryConfig.
+func (r *UpstreamRetry) ExtractTo(retry *apidef.UpstreamRetryConfig) {
+	retry.Enabled = r.Enabled
+	retry.MaxAttempts = r.MaxAttempts
+	retry.RetryOn = r.RetryOn
+	retry.StatusCodes = r.StatusCodes
+	retry.BackoffBase = r.BackoffBase
+	retry.BackoffMax = r.BackoffMax
+	retry.RetryNonIdempotent = r.RetryNonIdempotent
+}
+
+// AdaptiveConcurrency holds the configuration for adaptive concurrency limiting. Each upstream host gets
+// a limit on the requests in flight that is adjusted from the observed round trip time and failures, so
+// that the gateway backs off when an upstream slows down. Requests over the limit are queued or rejected
+// with `503 Service Unavailable`.
+type AdaptiveConcurrency struct {
+	// Enabled activates adaptive concurrency limiting.
+	//
+	// Tyk classic API definition: `proxy.adaptive_concurrency.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// Algorithm selects how the limit is adjusted:
+	// - `gradient`: the limit follows the ratio of the long term to the current round trip time,
+	// - `aimd`: the limit grows by one on success and is cut by a tenth on failures.
+	//
+	// Defaults to `gradient`.
+	//
+	// Tyk classic API definition: `proxy.adaptive_concurrency.algorithm`.
+	Algorithm string `bson:"algorithm,omitempty" json:"algorithm,omitempty"`
+
+	// InitialLimit is the limit used until the upstream latency is known. Defaults to 20.
+	//
+	// Tyk classic API definition: `proxy.adaptive_concurrency.initial_limit`.
+	InitialLimit int `bson:"initialLimit,omitempty" json:"initialLimit,omitempty"`
+
+	// MinLimit is the lowest the limit can go. Defaults to 1.
+	//
+	// Tyk classic API definition: `proxy.adaptive_concurrency.min_limit`.
+	MinLimit int `bson:"minLimit,omitempty" json:"minLimit,omitempty"`
+
+	// MaxLimit is the highest the limit can go. Defaults to 1000.
+	//
+	// Tyk classic API definition: `proxy.adaptive_concurrency.max_limit`.
+	MaxLimit int `bson:"maxLimit,omitempty" json:"maxLimit,omitempty"`
+
+	// QueueSize is the number of requests that can wait for a slot once the limit is reached.
+	// When zero, requests over the limit are rejected immediately.
+	//
+	// Tyk classic API definition: `proxy.adaptive_concurrency.queue_size`.
+	QueueSize int `bson:"queueSize,omitempty" json:"queueSize,omitempty"`
+
+	// QueueTimeout is how long a queued request waits for a slot, using a human-readable format (e.g. `500ms`).
+	// Defaults to `1s`.
+	//
+	// Tyk classic API definition: `proxy.adaptive_concurrency.queue_timeout`.
+	QueueTimeout time.ReadableDuration `bson:"queueTimeout,omitempty" json:"queueTimeout,omitempty"`
+}
+
+// Fill fills *AdaptiveConcurrency from apidef.AdaptiveConcurrencyConfig.
+func (a *AdaptiveConcurrency) Fill(conf apidef.AdaptiveConcurrencyConfig) {
+	a.Enabled = conf.Enabled
+	a.Algorithm = conf.Algorithm
+	a.InitialLimit = conf.InitialLimit
+	a.MinLimit = conf.MinLimit
+	a.MaxLimit = conf.MaxLimit
+	a.QueueSize = conf.QueueSize
+	a.QueueTimeout = conf.QueueTimeout
+}
+
+// ExtractTo extracts *AdaptiveConcurrency into *apidef.AdaptiveConcurrencyConfig.
+func (a *AdaptiveConcurrency) ExtractTo(conf *apidef.AdaptiveConcurrencyConfig) {
+	conf.Enabled = a.Enabled
+	conf.Algorithm = a.Algorithm
+	conf.InitialLimit = a.InitialLimit
+	conf.MinLimit = a.MinLimit
+	conf.MaxLimit = a.MaxLimit
+	conf.QueueSize = a.QueueSize
+	conf.QueueTimeout = a.QueueTimeout
+}
+
+// RateLimitQueue holds the configuration for queuing requests that exceed the API, key or endpoint rate limit,
+// or a concurrency limit. Instead of being rejected with `429 Too Many Requests` right away, such requests wait
+// for the limit to let them through, up to a maximum wait time. Waiting requests are served by priority class,
+// then in arrival order.
+//
+// The priority class of a request is taken from the `rate_limit_priority` metadata of the key, which can be
+// set by a policy, or from the priority header.
+type RateLimitQueue struct {
+	// Enabled activates queuing of limited requests.
+	//
+	// Tyk classic API definition: `rate_limit_queue.enabled`.
+	Enabled bool `bson:"enabled" json:"enabled"`
+
+	// MaxSize is the number of requests that can wait at the same time. Defaults to 100.
+	//
+	// Tyk classic API definition: `rate_limit_queue.max_size`.
+	MaxSize int `bson:"maxSize,omitempty" json:"maxSize,omitempty"`
+
+	// MaxWait is how long a request waits before it is rejected, using a human-readable format (e.g. `500ms`).
+	// Defaults to `1s`.
+	//
+	// Tyk classic API definition: `rate_limit_queue.max_wait`.
+	MaxWait time.ReadableDuration `bson:"maxWait,omitempty" json:"maxWait,omitempty"`
+
+	// PriorityHeader is the name of the request header carrying the priority class, used when the key has no
+	// `rate_limit_priority` metadata.
+	//
+	// Tyk classic API definition: `rate_limit_queue.priority_header`.
+	PriorityHeader string `bson:"priorityHeader,omitempty" json:"priorityHeader,omitempty"`
+
+	// PriorityClasses maps priority class names to priorities. Requests with a higher priority are served first,
+	// requests without a known class have priority 0.
+	//
+	// Tyk classic API definition: `rate_limit_queue.priority_classes`.
+	PriorityClasses map[string]int `bson:"priorityClasses,omitempty" json:"priorityClasses,omitempty"`
+}
+
+// Fill fills *RateLimitQueue from apidef.RateLimitQueueConfig.
+func (r *RateLimitQueue) Fill(conf apidef.RateLimitQueueConfig) {
+	r.Enabled = conf.Enabled
+	r.MaxSize = conf.MaxSize
+	r.MaxWait = conf.MaxWait
+	r.PriorityHeader = conf.PriorityHeader
+	r.PriorityClasses = conf.PriorityClasses
+}
+
+// ExtractTo extracts *RateLimitQueue into *apidef.RateLimitQueueConfig.
+func (r *RateLimitQueue) ExtractTo(conf *apidef.RateLimitQueueConfig) {
+	conf.Enabled = r.Enabled
+	conf.MaxSize = r.MaxSize
+	conf.MaxWait = r.MaxWait
+	conf.PriorityHeader = r.PriorityHeader
+	conf.PriorityClasses = r.PriorityClasses
+}
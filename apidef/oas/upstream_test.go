@@ -268,6 +268,27 @@ func TestUpstream(t *testing.T) {
 			assert.Equal(t, rateLimitUpstream, resultUpstream)
 		})
 
+		t.Run("algorithm", func(t *testing.T) {
+			rateLimitUpstream := Upstream{
+				RateLimit: &RateLimit{
+					Enabled:   true,
+					Rate:      10,
+					Per:       ReadableDuration(time.Minute),
+					Algorithm: "gcra",
+				},
+			}
+
+			var convertedAPI apidef.APIDefinition
+			convertedAPI.SetDisabledFlags()
+			rateLimitUpstream.ExtractTo(&convertedAPI)
+
+			assert.Equal(t, "gcra", convertedAPI.GlobalRateLimit.Algorithm)
+
+			var resultUpstream Upstream
+			resultUpstream.Fill(convertedAPI)
+
+			assert.Equal(t, rateLimitUpstream, resultUpstream)
+		})
 	})
 }
 
@@ -1111,6 +1132,90 @@ func TestLoadBalancing(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("algorithm round trip", func(t *testing.T) {
+		t.Parallel()
+
+		upstream := Upstream{
+			LoadBalancing: &LoadBalancing{
+				Enabled:   true,
+				Algorithm: "consistentHash",
+				ConsistentHash: &ConsistentHash{
+					Source: "header",
+					Name:   "X-Tenant-ID",
+				},
+				Targets: []LoadBalancingTarget{
+					{URL: "http://upstream-one", Weight: 2},
+					{URL: "http://upstream-two", Weight: 1},
+				},
+			},
+		}
+
+		var api apidef.APIDefinition
+		api.SetDisabledFlags()
+		upstream.ExtractTo(&api)
+
+		assert.Equal(t, apidef.LoadBalancingConfig{
+			Algorithm:  "consistentHash",
+			HashSource: "header",
+			HashName:   "X-Tenant-ID",
+		}, api.Proxy.LoadBalancing)
+
+		var result Upstream
+		result.Fill(api)
+
+		assert.Equal(t, upstream, result)
+	})
+
+	t.Run("algorithm reset without targets", func(t *testing.T) {
+		t.Parallel()
+
+		var api apidef.APIDefinition
+		api.Proxy.LoadBalancing.Algorithm = "leastConnections"
+
+		upstream := Upstream{LoadBalancing: &LoadBalancing{Enabled: true, Algorithm: "leastConnections"}}
+		upstream.ExtractTo(&api)
+
+		assert.Empty(t, api.Proxy.LoadBalancing)
+	})
+
+	t.Run("outlier detection round trip", func(t *testing.T) {
+		t.Parallel()
+
+		upstream := Upstream{
+			LoadBalancing: &LoadBalancing{
+				Enabled: true,
+				OutlierDetection: &OutlierDetection{
+					Enabled:             true,
+					ConsecutiveFailures: 3,
+					BaseEjectionTime:    ReadableDuration(10 * time.Second),
+					MaxEjectionTime:     ReadableDuration(time.Minute),
+					MaxEjectionPercent:  50,
+				},
+				Targets: []LoadBalancingTarget{
+					{URL: "http://upstream-one", Weight: 1},
+					{URL: "http://upstream-two", Weight: 1},
+				},
+			},
+		}
+
+		var api apidef.APIDefinition
+		api.SetDisabledFlags()
+		upstream.ExtractTo(&api)
+
+		assert.Equal(t, apidef.OutlierDetectionConfig{
+			Enabled:             true,
+			ConsecutiveFailures: 3,
+			BaseEjectionTime:    ReadableDuration(10 * time.Second),
+			MaxEjectionTime:     ReadableDuration(time.Minute),
+			MaxEjectionPercent:  50,
+		}, api.Proxy.LoadBalancing.OutlierDetection)
+
+		var result Upstream
+		result.Fill(api)
+
+		assert.Equal(t, upstream, result)
+	})
 }
 
 func TestLoadBalancingWeightZeroTargets(t *testing.T) {
@@ -1526,3 +1631,170 @@ func TestPreserveHostHeader(t *testing.T) {
 		}
 	})
 }
+
+func TestUpstreamRetry(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var emptyRetry UpstreamRetry
+
+		var convertedRetry apidef.UpstreamRetryConfig
+		emptyRetry.ExtractTo(&convertedRetry)
+
+		var resultRetry UpstreamRetry
+		resultRetry.Fill(convertedRetry)
+
+		assert.Equal(t, emptyRetry, resultRetry)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		upstream := Upstream{
+			Retry: &UpstreamRetry{
+				Enabled:            true,
+				MaxAttempts:        3,
+				RetryOn:            []string{"connectFailure", "5xx"},
+				StatusCodes:        []int{http.StatusTooManyRequests},
+				BackoffBase:        ReadableDuration(50 * time.Millisecond),
+				BackoffMax:         ReadableDuration(time.Second),
+				RetryNonIdempotent: true,
+			},
+		}
+
+		var api apidef.APIDefinition
+		upstream.ExtractTo(&api)
+
+		assert.Equal(t, apidef.UpstreamRetryConfig{
+			Enabled:            true,
+			MaxAttempts:        3,
+			RetryOn:            []string{"connectFailure", "5xx"},
+			StatusCodes:        []int{http.StatusTooManyRequests},
+			BackoffBase:        ReadableDuration(50 * time.Millisecond),
+			BackoffMax:         ReadableDuration(time.Second),
+			RetryNonIdempotent: true,
+		}, api.Proxy.Retry)
+
+		var result Upstream
+		result.Fill(api)
+
+		assert.Equal(t, upstream.Retry, result.Retry)
+	})
+
+	t.Run("reset when omitted", func(t *testing.T) {
+		var api apidef.APIDefinition
+		api.Proxy.Retry = apidef.UpstreamRetryConfig{Enabled: true, MaxAttempts: 2}
+
+		var upstream Upstream
+		upstream.ExtractTo(&api)
+
+		assert.Empty(t, api.Proxy.Retry)
+		assert.Nil(t, upstream.Retry)
+	})
+}
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var emptyAdaptiveConcurrency AdaptiveConcurrency
+
+		var converted apidef.AdaptiveConcurrencyConfig
+		emptyAdaptiveConcurrency.ExtractTo(&converted)
+
+		var result AdaptiveConcurrency
+		result.Fill(converted)
+
+		assert.Equal(t, emptyAdaptiveConcurrency, result)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		upstream := Upstream{
+			AdaptiveConcurrency: &AdaptiveConcurrency{
+				Enabled:      true,
+				Algorithm:    "aimd",
+				InitialLimit: 10,
+				MinLimit:     2,
+				MaxLimit:     100,
+				QueueSize:    20,
+				QueueTimeout: ReadableDuration(500 * time.Millisecond),
+			},
+		}
+
+		var api apidef.APIDefinition
+		upstream.ExtractTo(&api)
+
+		assert.Equal(t, apidef.AdaptiveConcurrencyConfig{
+			Enabled:      true,
+			Algorithm:    "aimd",
+			InitialLimit: 10,
+			MinLimit:     2,
+			MaxLimit:     100,
+			QueueSize:    20,
+			QueueTimeout: ReadableDuration(500 * time.Millisecond),
+		}, api.Proxy.AdaptiveConcurrency)
+
+		var result Upstream
+		result.Fill(api)
+
+		assert.Equal(t, upstream.AdaptiveConcurrency, result.AdaptiveConcurrency)
+	})
+
+	t.Run("reset when omitted", func(t *testing.T) {
+		var api apidef.APIDefinition
+		api.Proxy.AdaptiveConcurrency = apidef.AdaptiveConcurrencyConfig{Enabled: true, MaxLimit: 10}
+
+		var upstream Upstream
+		upstream.ExtractTo(&api)
+
+		assert.Empty(t, api.Proxy.AdaptiveConcurrency)
+		assert.Nil(t, upstream.AdaptiveConcurrency)
+	})
+}
+
+func TestRateLimitQueue(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var emptyRateLimitQueue RateLimitQueue
+
+		var converted apidef.RateLimitQueueConfig
+		emptyRateLimitQueue.ExtractTo(&converted)
+
+		var result RateLimitQueue
+		result.Fill(converted)
+
+		assert.Equal(t, emptyRateLimitQueue, result)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		upstream := Upstream{
+			RateLimitQueue: &RateLimitQueue{
+				Enabled:         true,
+				MaxSize:         50,
+				MaxWait:         ReadableDuration(2 * time.Second),
+				PriorityHeader:  "X-Priority",
+				PriorityClasses: map[string]int{"premium": 10, "free": -1},
+			},
+		}
+
+		var api apidef.APIDefinition
+		upstream.ExtractTo(&api)
+
+		assert.Equal(t, apidef.RateLimitQueueConfig{
+			Enabled:         true,
+			MaxSize:         50,
+			MaxWait:         ReadableDuration(2 * time.Second),
+			PriorityHeader:  "X-Priority",
+			PriorityClasses: map[string]int{"premium": 10, "free": -1},
+		}, api.RateLimitQueue)
+
+		var result Upstream
+		result.Fill(api)
+
+		assert.Equal(t, upstream.RateLimitQueue, result.RateLimitQueue)
+	})
+
+	t.Run("reset when omitted", func(t *testing.T) {
+		var api apidef.APIDefinition
+		api.RateLimitQueue = apidef.RateLimitQueueConfig{Enabled: true, MaxSize: 10}
+
+		var upstream Upstream
+		upstream.ExtractTo(&api)
+
+		assert.Empty(t, api.RateLimitQueue)
+		assert.Nil(t, upstream.RateLimitQueue)
+	})
+}
@@ -4,8 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
 	"sort"
 	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
+	"github.com/TykTechnologies/tyk/internal/rate/adaptive"
+	"github.com/TykTechnologies/tyk/internal/rate/limiter"
+	"github.com/TykTechnologies/tyk/internal/retry"
 )
 
 type ValidationResult struct {
@@ -60,6 +66,17 @@ var DefaultValidationRuleSet = ValidationRuleSet{
 	&RuleValidateEnforceTimeout{},
 	&RuleUpstreamAuth{},
 	&RuleLoadBalancingTargets{},
+	&RuleLoadBalancingAlgorithm{},
+	&RuleUpstreamRetry{},
+	&RuleOutlierDetection{},
+	&RuleRateLimitAlgorithm{},
+	&RuleAdaptiveConcurrency{},
+	&RuleRateLimitQueue{},
+	&RuleCacheKey{},
+	&RuleCircuitBreaker{},
+	&RuleHedge{},
+	&RuleTrafficMirror{},
+	&RuleExternalAuthorization{},
 }
 
 func Validate(definition *APIDefinition, ruleSet ValidationRuleSet) ValidationResult {
@@ -230,6 +247,42 @@ var (
 	ErrInvalidUpstreamOAuthClientAuthMethod = errors.New("invalid upstream OAuth client authentication method, valid values are: client_secret_basic, client_secret_post")
 	// ErrAllLoadBalancingTargetsZeroWeight is the error to return when all load balancing targets have weight 0.
 	ErrAllLoadBalancingTargetsZeroWeight = errors.New("all load balancing targets have weight 0, at least one target must have weight > 0")
+	// ErrInvalidLoadBalancingAlgorithm is the error to return when the configured load balancing algorithm is unknown.
+	ErrInvalidLoadBalancingAlgorithm = errors.New("invalid load balancing algorithm, valid values are: roundRobin, weightedRoundRobin, leastConnections, peakEwma, consistentHash")
+	// ErrInvalidLoadBalancingHashSource is the error to return when consistent hashing has no valid hash source.
+	ErrInvalidLoadBalancingHashSource = errors.New("invalid load balancing hash source, valid values are: header, cookie, session, ip")
+	// ErrLoadBalancingHashNameRequired is the error to return when a header or cookie hash source has no name.
+	ErrLoadBalancingHashNameRequired = errors.New("load balancing hash name is required for header and cookie hash sources")
+	// ErrInvalidUpstreamRetryMaxAttempts is the error to return when retries are enabled without at least two attempts.
+	ErrInvalidUpstreamRetryMaxAttempts = errors.New("upstream retry max attempts must be at least 2")
+	// ErrInvalidUpstreamRetryCondition is the error to return when an unknown retry condition is configured.
+	ErrInvalidUpstreamRetryCondition = errors.New("invalid upstream retry condition, valid values are: connectFailure, reset, timeout, gatewayError, 5xx")
+	// ErrInvalidUpstreamRetryStatusCode is the error to return when a retried status code is not a valid HTTP status code.
+	ErrInvalidUpstreamRetryStatusCode = errors.New("invalid upstream retry status code, valid values are between 100 and 599")
+	// ErrInvalidUpstreamRetryBackoff is the error to return when the maximum backoff is lower than the base backoff.
+	ErrInvalidUpstreamRetryBackoff = errors.New("upstream retry max backoff must not be lower than the base backoff")
+	// ErrInvalidOutlierDetectionMaxEjectionPercent is the error to return when the max ejection percent is out of range.
+	ErrInvalidOutlierDetectionMaxEjectionPercent = errors.New("outlier detection max ejection percent must be between 0 and 100")
+	// ErrInvalidOutlierDetectionEjectionTime is the error to return when the max ejection time is lower than the base ejection time.
+	ErrInvalidOutlierDetectionEjectionTime = errors.New("outlier detection max ejection time must not be lower than the base ejection time")
+	// ErrInvalidRateLimitAlgorithm is the error to return when an unknown rate limiting algorithm is configured.
+	ErrInvalidRateLimitAlgorithm = errors.New("invalid rate limit algorithm, valid values are: gcra")
+	// ErrInvalidAdaptiveConcurrencyAlgorithm is the error to return when an unknown adaptive concurrency algorithm is configured.
+	ErrInvalidAdaptiveConcurrencyAlgorithm = errors.New("invalid adaptive concurrency algorithm, valid values are: gradient, aimd")
+	// ErrInvalidAdaptiveConcurrencyLimits is the error to return when the adaptive concurrency limits are negative or inconsistent.
+	ErrInvalidAdaptiveConcurrencyLimits = errors.New("adaptive concurrency limits must not be negative and the max limit must not be lower than the min limit")
+	// ErrInvalidRateLimitQueue is the error to return when the rate limit queue size or wait time is negative.
+	ErrInvalidRateLimitQueue = errors.New("rate limit queue max size and max wait must not be negative")
+	// ErrInvalidCacheKeyComponent is the error to return when a cache key component is unknown.
+	ErrInvalidCacheKeyComponent = errors.New("cache key components must be session or org")
+	// ErrInvalidCircuitBreaker is the error to return when a circuit breaker scope is unknown or its half-open requests are negative.
+	ErrInvalidCircuitBreaker = errors.New("circuit breaker scope must be path, api or host and half-open requests and timeout must not be negative")
+	// ErrInvalidHedge is the error to return when request hedging has no delay, or an invalid percentile or budget.
+	ErrInvalidHedge = errors.New("request hedging needs a delay or a percentile between 0 and 100, and a budget between 0 and 1")
+	// ErrInvalidTrafficMirror is the error to return when the traffic mirror target, percentage or limits are invalid.
+	ErrInvalidTrafficMirror = errors.New("traffic mirror requires an absolute http(s) target url, a percentage between 0 and 100 and non-negative limits")
+	// ErrInvalidExternalAuthorization is the error to return when the policy decision point of external authorization is invalid.
+	ErrInvalidExternalAuthorization = errors.New("external authorization requires an http(s) url, a grpc(s) address or an embedded policy, and non-negative durations and cache sizes")
 )
 
 // RuleUpstreamAuth implements validations for upstream authentication configurations.
@@ -295,3 +348,273 @@ func (r *RuleLoadBalancingTargets) Validate(apiDef *APIDefinition, validationRes
 		validationResult.AppendError(ErrAllLoadBalancingTargetsZeroWeight)
 	}
 }
+
+// RuleLoadBalancingAlgorithm implements validations for the load balancing strategy.
+type RuleLoadBalancingAlgorithm struct{}
+
+// Validate validates the load balancing algorithm and its consistent hash settings.
+func (r *RuleLoadBalancingAlgorithm) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	lb := apiDef.Proxy.LoadBalancing
+
+	algorithm := loadbalancer.Algorithm(lb.Algorithm)
+	if !algorithm.Valid() {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidLoadBalancingAlgorithm)
+		return
+	}
+
+	if algorithm != loadbalancer.ConsistentHash {
+		return
+	}
+
+	if !loadbalancer.ValidHashSource(lb.HashSource) {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidLoadBalancingHashSource)
+		return
+	}
+
+	if (lb.HashSource == loadbalancer.HashSourceHeader || lb.HashSource == loadbalancer.HashSourceCookie) && lb.HashName == "" {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrLoadBalancingHashNameRequired)
+	}
+}
+
+// RuleUpstreamRetry implements validations for upstream retries.
+type RuleUpstreamRetry struct{}
+
+// Validate validates the upstream retry configuration of the API and its endpoints when retries are enabled.
+func (r *RuleUpstreamRetry) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	r.validate(apiDef.Proxy.Retry, validationResult)
+
+	for _, version := range apiDef.VersionData.Versions {
+		for _, meta := range version.ExtendedPaths.UpstreamRetry {
+			r.validate(meta.Retry, validationResult)
+		}
+	}
+}
+
+func (r *RuleUpstreamRetry) validate(conf UpstreamRetryConfig, validationResult *ValidationResult) {
+	if !conf.Enabled {
+		return
+	}
+
+	if conf.MaxAttempts < 2 {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidUpstreamRetryMaxAttempts)
+	}
+
+	for _, condition := range conf.RetryOn {
+		if !retry.Condition(condition).Valid() {
+			validationResult.IsValid = false
+			validationResult.AppendError(ErrInvalidUpstreamRetryCondition)
+			break
+		}
+	}
+
+	for _, code := range conf.StatusCodes {
+		if code < 100 || code > 599 {
+			validationResult.IsValid = false
+			validationResult.AppendError(ErrInvalidUpstreamRetryStatusCode)
+			break
+		}
+	}
+
+	if conf.BackoffMax > 0 && conf.BackoffMax < conf.BackoffBase {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidUpstreamRetryBackoff)
+	}
+}
+
+// RuleOutlierDetection implements validations for passive outlier detection.
+type RuleOutlierDetection struct{}
+
+// Validate validates the outlier detection configuration when it is enabled.
+func (r *RuleOutlierDetection) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	conf := apiDef.Proxy.LoadBalancing.OutlierDetection
+	if !conf.Enabled {
+		return
+	}
+
+	if conf.MaxEjectionPercent < 0 || conf.MaxEjectionPercent > 100 {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidOutlierDetectionMaxEjectionPercent)
+	}
+
+	if conf.MaxEjectionTime > 0 && conf.MaxEjectionTime < conf.BaseEjectionTime {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidOutlierDetectionEjectionTime)
+	}
+}
+
+// RuleRateLimitAlgorithm implements validations for the rate limiting algorithm.
+type RuleRateLimitAlgorithm struct{}
+
+// Validate validates the algorithm of the API and endpoint rate limits.
+func (r *RuleRateLimitAlgorithm) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	valid := limiter.ValidAlgorithm(apiDef.GlobalRateLimit.Algorithm)
+
+	for _, version := range apiDef.VersionData.Versions {
+		for _, rl := range version.ExtendedPaths.RateLimit {
+			valid = valid && limiter.ValidAlgorithm(rl.Algorithm)
+		}
+	}
+
+	if !valid {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidRateLimitAlgorithm)
+	}
+}
+
+// RuleAdaptiveConcurrency implements validations for adaptive concurrency limiting.
+type RuleAdaptiveConcurrency struct{}
+
+// Validate validates the adaptive concurrency configuration when it is enabled.
+func (r *RuleAdaptiveConcurrency) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	conf := apiDef.Proxy.AdaptiveConcurrency
+	if !conf.Enabled {
+		return
+	}
+
+	if !adaptive.ValidAlgorithm(conf.Algorithm) {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidAdaptiveConcurrencyAlgorithm)
+	}
+
+	negative := conf.InitialLimit < 0 || conf.MinLimit < 0 || conf.MaxLimit < 0 || conf.QueueSize < 0
+	if negative || (conf.MaxLimit > 0 && conf.MaxLimit < conf.MinLimit) {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidAdaptiveConcurrencyLimits)
+	}
+}
+
+// RuleRateLimitQueue implements validations for the rate limit queue.
+type RuleRateLimitQueue struct{}
+
+// Validate validates the rate limit queue configuration when it is enabled.
+func (r *RuleRateLimitQueue) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	conf := apiDef.RateLimitQueue
+	if !conf.Enabled {
+		return
+	}
+
+	if conf.MaxSize < 0 || conf.MaxWait < 0 {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidRateLimitQueue)
+	}
+}
+
+// RuleCacheKey implements validations for the cache key composition of endpoints.
+type RuleCacheKey struct{}
+
+// Validate validates the components of the enabled cache keys.
+func (r *RuleCacheKey) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, version := range apiDef.VersionData.Versions {
+		for _, cacheMeta := range version.ExtendedPaths.AdvanceCacheConfig {
+			if !cacheMeta.CacheKey.Enabled {
+				continue
+			}
+
+			for _, component := range cacheMeta.CacheKey.Components {
+				if component != CacheKeySession && component != CacheKeyOrg {
+					validationResult.IsValid = false
+					validationResult.AppendError(ErrInvalidCacheKeyComponent)
+					return
+				}
+			}
+		}
+	}
+}
+
+// RuleCircuitBreaker implements validations for circuit breakers.
+type RuleCircuitBreaker struct{}
+
+// Validate validates the scope and half-open requests of circuit breakers.
+func (r *RuleCircuitBreaker) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, version := range apiDef.VersionData.Versions {
+		for _, breaker := range version.ExtendedPaths.CircuitBreaker {
+			switch breaker.Scope {
+			case "", CircuitBreakerScopePath, CircuitBreakerScopeAPI, CircuitBreakerScopeHost:
+			default:
+				validationResult.IsValid = false
+				validationResult.AppendError(ErrInvalidCircuitBreaker)
+				return
+			}
+
+			if breaker.HalfOpenRequests < 0 || breaker.HalfOpenTimeout < 0 {
+				validationResult.IsValid = false
+				validationResult.AppendError(ErrInvalidCircuitBreaker)
+				return
+			}
+		}
+	}
+}
+
+// RuleHedge implements validations for request hedging.
+type RuleHedge struct{}
+
+// Validate validates the delay, percentile and budget of enabled request hedging.
+func (r *RuleHedge) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	for _, version := range apiDef.VersionData.Versions {
+		for _, timeout := range version.ExtendedPaths.HardTimeouts {
+			hedge := timeout.Hedge
+			if !hedge.Enabled {
+				continue
+			}
+
+			if hedge.Delay < 0 || hedge.Percentile < 0 || hedge.Percentile >= 100 ||
+				(hedge.Delay == 0 && hedge.Percentile == 0) || hedge.Budget < 0 || hedge.Budget > 1 {
+				validationResult.IsValid = false
+				validationResult.AppendError(ErrInvalidHedge)
+				return
+			}
+		}
+	}
+}
+
+// RuleTrafficMirror implements validations for traffic mirroring.
+type RuleTrafficMirror struct{}
+
+// Validate validates the target, percentage and limits of enabled traffic mirroring.
+func (r *RuleTrafficMirror) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	conf := apiDef.TrafficMirror
+	if !conf.Enabled {
+		return
+	}
+
+	target, err := url.Parse(conf.TargetURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" ||
+		conf.Percentage < 0 || conf.Percentage > 100 || conf.MaxBodySize < 0 || conf.Timeout < 0 {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidTrafficMirror)
+	}
+}
+
+// RuleExternalAuthorization implements validations for external authorization.
+type RuleExternalAuthorization struct{}
+
+// Validate validates the policy decision point of enabled external authorization.
+func (r *RuleExternalAuthorization) Validate(apiDef *APIDefinition, validationResult *ValidationResult) {
+	conf := apiDef.ExternalAuthorization
+	if !conf.Enabled {
+		return
+	}
+
+	valid := conf.Timeout >= 0 && conf.CacheTTL >= 0 && conf.CacheMaxEntries >= 0
+	switch conf.Type {
+	case ExternalAuthzHTTP:
+		target, err := url.Parse(conf.URL)
+		valid = valid && err == nil && (target.Scheme == "http" || target.Scheme == "https") && target.Host != ""
+	case ExternalAuthzGRPC:
+		target, err := url.Parse(conf.URL)
+		valid = valid && err == nil && (target.Scheme == "grpc" || target.Scheme == "grpcs") && target.Host != ""
+	case ExternalAuthzEmbedded:
+		valid = valid && strings.TrimSpace(conf.Policy) != ""
+	default:
+		valid = false
+	}
+
+	if !valid {
+		validationResult.IsValid = false
+		validationResult.AppendError(ErrInvalidExternalAuthorization)
+	}
+}
@@ -704,3 +704,651 @@ func TestRuleLoadBalancingTargets_Validate(t *testing.T) {
 		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
 	}
 }
+
+func TestRuleLoadBalancingAlgorithm_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleLoadBalancingAlgorithm{},
+	}
+
+	testCases := []struct {
+		name   string
+		config LoadBalancingConfig
+		result ValidationResult
+	}{
+		{
+			name:   "default algorithm",
+			config: LoadBalancingConfig{},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "least connections",
+			config: LoadBalancingConfig{Algorithm: "leastConnections"},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "unknown algorithm",
+			config: LoadBalancingConfig{Algorithm: "random"},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadBalancingAlgorithm},
+			},
+		},
+		{
+			name:   "consistent hash on session",
+			config: LoadBalancingConfig{Algorithm: "consistentHash", HashSource: "session"},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "consistent hash without source",
+			config: LoadBalancingConfig{Algorithm: "consistentHash"},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidLoadBalancingHashSource},
+			},
+		},
+		{
+			name:   "consistent hash on header without name",
+			config: LoadBalancingConfig{Algorithm: "consistentHash", HashSource: "header"},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrLoadBalancingHashNameRequired},
+			},
+		},
+		{
+			name:   "consistent hash on cookie",
+			config: LoadBalancingConfig{Algorithm: "consistentHash", HashSource: "cookie", HashName: "session_id"},
+			result: ValidationResult{IsValid: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{
+			Proxy: ProxyConfig{
+				LoadBalancing: tc.config,
+			},
+		}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleUpstreamRetry_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleUpstreamRetry{},
+	}
+
+	testCases := []struct {
+		name   string
+		config UpstreamRetryConfig
+		result ValidationResult
+	}{
+		{
+			name:   "disabled",
+			config: UpstreamRetryConfig{RetryOn: []string{"unknown"}},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name: "valid",
+			config: UpstreamRetryConfig{
+				Enabled:     true,
+				MaxAttempts: 3,
+				RetryOn:     []string{"connectFailure", "5xx"},
+				StatusCodes: []int{429},
+				BackoffBase: tyktime.ReadableDuration(10 * time.Millisecond),
+				BackoffMax:  tyktime.ReadableDuration(time.Second),
+			},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "single attempt",
+			config: UpstreamRetryConfig{Enabled: true, MaxAttempts: 1},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidUpstreamRetryMaxAttempts},
+			},
+		},
+		{
+			name:   "unknown condition",
+			config: UpstreamRetryConfig{Enabled: true, MaxAttempts: 2, RetryOn: []string{"4xx"}},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidUpstreamRetryCondition},
+			},
+		},
+		{
+			name:   "invalid status code",
+			config: UpstreamRetryConfig{Enabled: true, MaxAttempts: 2, StatusCodes: []int{600}},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidUpstreamRetryStatusCode},
+			},
+		},
+		{
+			name: "max backoff lower than base",
+			config: UpstreamRetryConfig{
+				Enabled:     true,
+				MaxAttempts: 2,
+				BackoffBase: tyktime.ReadableDuration(time.Second),
+				BackoffMax:  tyktime.ReadableDuration(time.Millisecond),
+			},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidUpstreamRetryBackoff},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{
+			Proxy: ProxyConfig{
+				Retry: tc.config,
+			},
+		}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+
+		apiDef = &APIDefinition{
+			VersionData: VersionData{
+				Versions: map[string]VersionInfo{
+					"Default": {
+						ExtendedPaths: ExtendedPathsSet{
+							UpstreamRetry: []UpstreamRetryMeta{{Path: "/retry", Method: "GET", Retry: tc.config}},
+						},
+					},
+				},
+			},
+		}
+		t.Run(tc.name+" endpoint", runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleOutlierDetection_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleOutlierDetection{},
+	}
+
+	testCases := []struct {
+		name   string
+		config OutlierDetectionConfig
+		result ValidationResult
+	}{
+		{
+			name:   "disabled",
+			config: OutlierDetectionConfig{MaxEjectionPercent: 200},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name: "valid",
+			config: OutlierDetectionConfig{
+				Enabled:             true,
+				ConsecutiveFailures: 3,
+				BaseEjectionTime:    tyktime.ReadableDuration(time.Second),
+				MaxEjectionTime:     tyktime.ReadableDuration(time.Minute),
+				MaxEjectionPercent:  50,
+			},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "max ejection percent out of range",
+			config: OutlierDetectionConfig{Enabled: true, MaxEjectionPercent: 101},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidOutlierDetectionMaxEjectionPercent},
+			},
+		},
+		{
+			name: "max ejection time lower than base",
+			config: OutlierDetectionConfig{
+				Enabled:          true,
+				BaseEjectionTime: tyktime.ReadableDuration(time.Minute),
+				MaxEjectionTime:  tyktime.ReadableDuration(time.Second),
+			},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidOutlierDetectionEjectionTime},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{
+			Proxy: ProxyConfig{
+				LoadBalancing: LoadBalancingConfig{OutlierDetection: tc.config},
+			},
+		}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleRateLimitAlgorithm_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleRateLimitAlgorithm{},
+	}
+
+	endpointRateLimit := func(algorithm string) VersionData {
+		return VersionData{
+			Versions: map[string]VersionInfo{
+				"Default": {
+					ExtendedPaths: ExtendedPathsSet{
+						RateLimit: []RateLimitMeta{{Path: "/", Method: "GET", Rate: 10, Per: 1, Algorithm: algorithm}},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		apiDef *APIDefinition
+		result ValidationResult
+	}{
+		{
+			name:   "default",
+			apiDef: &APIDefinition{},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "gcra",
+			apiDef: &APIDefinition{GlobalRateLimit: GlobalRateLimit{Algorithm: "gcra"}, VersionData: endpointRateLimit("gcra")},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "unknown api algorithm",
+			apiDef: &APIDefinition{GlobalRateLimit: GlobalRateLimit{Algorithm: "leaky-bucket"}},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRateLimitAlgorithm},
+			},
+		},
+		{
+			name:   "unknown endpoint algorithm",
+			apiDef: &APIDefinition{VersionData: endpointRateLimit("unknown")},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRateLimitAlgorithm},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, runValidationTest(tc.apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleAdaptiveConcurrency_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleAdaptiveConcurrency{},
+	}
+
+	testCases := []struct {
+		name   string
+		config AdaptiveConcurrencyConfig
+		result ValidationResult
+	}{
+		{
+			name:   "disabled",
+			config: AdaptiveConcurrencyConfig{Algorithm: "unknown", MinLimit: -1},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name: "valid",
+			config: AdaptiveConcurrencyConfig{
+				Enabled:      true,
+				Algorithm:    "aimd",
+				InitialLimit: 10,
+				MinLimit:     2,
+				MaxLimit:     100,
+				QueueSize:    10,
+				QueueTimeout: tyktime.ReadableDuration(time.Second),
+			},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "unknown algorithm",
+			config: AdaptiveConcurrencyConfig{Enabled: true, Algorithm: "vegas"},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidAdaptiveConcurrencyAlgorithm},
+			},
+		},
+		{
+			name:   "negative queue size",
+			config: AdaptiveConcurrencyConfig{Enabled: true, QueueSize: -1},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidAdaptiveConcurrencyLimits},
+			},
+		},
+		{
+			name:   "max limit lower than min",
+			config: AdaptiveConcurrencyConfig{Enabled: true, MinLimit: 10, MaxLimit: 5},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidAdaptiveConcurrencyLimits},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{
+			Proxy: ProxyConfig{AdaptiveConcurrency: tc.config},
+		}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleRateLimitQueue_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleRateLimitQueue{},
+	}
+
+	testCases := []struct {
+		name   string
+		config RateLimitQueueConfig
+		result ValidationResult
+	}{
+		{
+			name:   "disabled",
+			config: RateLimitQueueConfig{MaxSize: -1},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name: "valid",
+			config: RateLimitQueueConfig{
+				Enabled:         true,
+				MaxSize:         50,
+				MaxWait:         tyktime.ReadableDuration(time.Second),
+				PriorityHeader:  "X-Priority",
+				PriorityClasses: map[string]int{"premium": 10},
+			},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "negative max size",
+			config: RateLimitQueueConfig{Enabled: true, MaxSize: -1},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRateLimitQueue},
+			},
+		},
+		{
+			name:   "negative max wait",
+			config: RateLimitQueueConfig{Enabled: true, MaxWait: tyktime.ReadableDuration(-time.Second)},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidRateLimitQueue},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{RateLimitQueue: tc.config}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleCacheKey_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleCacheKey{},
+	}
+
+	testCases := []struct {
+		name     string
+		cacheKey CacheKeyConfig
+		result   ValidationResult
+	}{
+		{
+			name:     "disabled",
+			cacheKey: CacheKeyConfig{Components: []string{"unknown"}},
+			result:   ValidationResult{IsValid: true},
+		},
+		{
+			name:     "valid",
+			cacheKey: CacheKeyConfig{Enabled: true, Components: []string{CacheKeySession, CacheKeyOrg}},
+			result:   ValidationResult{IsValid: true},
+		},
+		{
+			name:     "unknown component",
+			cacheKey: CacheKeyConfig{Enabled: true, Components: []string{"unknown"}},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidCacheKeyComponent},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{VersionData: VersionData{Versions: map[string]VersionInfo{
+			"Default": {ExtendedPaths: ExtendedPathsSet{AdvanceCacheConfig: []CacheMeta{{CacheKey: tc.cacheKey}}}},
+		}}}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleCircuitBreaker_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleCircuitBreaker{},
+	}
+
+	testCases := []struct {
+		name    string
+		breaker CircuitBreakerMeta
+		result  ValidationResult
+	}{
+		{
+			name:    "default scope",
+			breaker: CircuitBreakerMeta{},
+			result:  ValidationResult{IsValid: true},
+		},
+		{
+			name:    "host scope",
+			breaker: CircuitBreakerMeta{Scope: CircuitBreakerScopeHost, HalfOpenRequests: 3},
+			result:  ValidationResult{IsValid: true},
+		},
+		{
+			name:    "unknown scope",
+			breaker: CircuitBreakerMeta{Scope: "unknown"},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidCircuitBreaker},
+			},
+		},
+		{
+			name:    "negative half-open requests",
+			breaker: CircuitBreakerMeta{HalfOpenRequests: -1},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidCircuitBreaker},
+			},
+		},
+		{
+			name:    "negative half-open timeout",
+			breaker: CircuitBreakerMeta{HalfOpenRequests: 1, HalfOpenTimeout: -1},
+			result: ValidationResult{
+				IsValid: false,
+				Errors:  []error{ErrInvalidCircuitBreaker},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{VersionData: VersionData{Versions: map[string]VersionInfo{
+			"Default": {ExtendedPaths: ExtendedPathsSet{CircuitBreaker: []CircuitBreakerMeta{tc.breaker}}},
+		}}}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleHedge_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleHedge{},
+	}
+
+	invalid := ValidationResult{
+		IsValid: false,
+		Errors:  []error{ErrInvalidHedge},
+	}
+
+	testCases := []struct {
+		name   string
+		hedge  HedgeConfig
+		result ValidationResult
+	}{
+		{
+			name:   "disabled",
+			hedge:  HedgeConfig{Percentile: 200},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "delay",
+			hedge:  HedgeConfig{Enabled: true, Delay: tyktime.ReadableDuration(50 * time.Millisecond)},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "percentile",
+			hedge:  HedgeConfig{Enabled: true, Percentile: 95, Budget: 0.05},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "no delay",
+			hedge:  HedgeConfig{Enabled: true},
+			result: invalid,
+		},
+		{
+			name:   "invalid percentile",
+			hedge:  HedgeConfig{Enabled: true, Percentile: 100},
+			result: invalid,
+		},
+		{
+			name:   "invalid budget",
+			hedge:  HedgeConfig{Enabled: true, Percentile: 95, Budget: 2},
+			result: invalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{VersionData: VersionData{Versions: map[string]VersionInfo{
+			"Default": {ExtendedPaths: ExtendedPathsSet{HardTimeouts: []HardTimeoutMeta{{Hedge: tc.hedge}}}},
+		}}}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleTrafficMirror_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleTrafficMirror{},
+	}
+
+	invalid := ValidationResult{
+		IsValid: false,
+		Errors:  []error{ErrInvalidTrafficMirror},
+	}
+
+	testCases := []struct {
+		name   string
+		mirror TrafficMirrorConfig
+		result ValidationResult
+	}{
+		{
+			name:   "disabled",
+			mirror: TrafficMirrorConfig{Percentage: 200},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid",
+			mirror: TrafficMirrorConfig{Enabled: true, TargetURL: "http://shadow:8080/v2", Percentage: 10, MaxBodySize: 1024},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "relative target",
+			mirror: TrafficMirrorConfig{Enabled: true, TargetURL: "/shadow", Percentage: 10},
+			result: invalid,
+		},
+		{
+			name:   "invalid percentage",
+			mirror: TrafficMirrorConfig{Enabled: true, TargetURL: "http://shadow", Percentage: 101},
+			result: invalid,
+		},
+		{
+			name:   "negative body size",
+			mirror: TrafficMirrorConfig{Enabled: true, TargetURL: "http://shadow", Percentage: 10, MaxBodySize: -1},
+			result: invalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{TrafficMirror: tc.mirror}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
+
+func TestRuleExternalAuthorization_Validate(t *testing.T) {
+	ruleSet := ValidationRuleSet{
+		&RuleExternalAuthorization{},
+	}
+
+	invalid := ValidationResult{
+		IsValid: false,
+		Errors:  []error{ErrInvalidExternalAuthorization},
+	}
+
+	testCases := []struct {
+		name   string
+		authz  ExternalAuthzConfig
+		result ValidationResult
+	}{
+		{
+			name:   "disabled",
+			authz:  ExternalAuthzConfig{Type: "unknown"},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid http",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzHTTP, URL: "http://opa:8181/v1/data/tyk/authz"},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid grpc",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzGRPC, URL: "grpcs://opa:9191"},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "valid embedded",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzEmbedded, Policy: "function authorize(input) { return true }"},
+			result: ValidationResult{IsValid: true},
+		},
+		{
+			name:   "unknown type",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: "ldap", URL: "http://opa:8181"},
+			result: invalid,
+		},
+		{
+			name:   "relative http url",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzHTTP, URL: "/v1/data/tyk/authz"},
+			result: invalid,
+		},
+		{
+			name:   "http url for grpc",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzGRPC, URL: "http://opa:9191"},
+			result: invalid,
+		},
+		{
+			name:   "missing policy",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzEmbedded},
+			result: invalid,
+		},
+		{
+			name:   "negative timeout",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzHTTP, URL: "http://opa:8181", Timeout: -1},
+			result: invalid,
+		},
+		{
+			name:   "negative cache size",
+			authz:  ExternalAuthzConfig{Enabled: true, Type: ExternalAuthzHTTP, URL: "http://opa:8181", CacheMaxEntries: -1},
+			result: invalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		apiDef := &APIDefinition{ExternalAuthorization: tc.authz}
+		t.Run(tc.name, runValidationTest(apiDef, ruleSet, tc.result))
+	}
+}
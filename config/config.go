@@ -336,6 +336,103 @@ type AnalyticsConfigConfig struct {
 
 	// Determines the serialization engine for analytics. Available options: msgpack, and protobuf. By default, msgpack.
 	SerializerType string `json:"serializer_type"`
+
+	// Sinks configures where the analytics workers write records. Records are written to every configured sink.
+	// When no sinks are configured, records are written to Redis for Tyk Pump to process.
+	//
+	// A sink that fails to accept records is retried a few times with a backoff, after which its records are
+	// dropped and counted in the tyk.analytics.records.dropped metric. Records that don't fit in the records
+	// buffer while the sinks catch up are dropped too, rather than holding up request processing.
+	Sinks []AnalyticsSinkConfig `json:"sinks"`
+
+	// Spool configures an on-disk spool for records that can't be written to Redis.
+	Spool AnalyticsSpoolConfig `json:"spool"`
+}
+
+// AnalyticsSpoolConfig configures the on-disk analytics spool. When enabled, records that can't be
+// written to Redis are spooled to disk and replayed in order once Redis is available again,
+// including after a gateway restart.
+type AnalyticsSpoolConfig struct {
+	// Enabled enables the analytics spool.
+	Enabled bool `json:"enabled"`
+
+	// Path is the directory the spool segment files are stored in.
+	Path string `json:"path"`
+
+	// MaxSizeMB is the size limit of the spool in megabytes. Records that don't fit are dropped. Defaults to 1024.
+	MaxSizeMB int `json:"max_size_mb"`
+}
+
+// AnalyticsSinkConfig configures a destination for analytics records.
+type AnalyticsSinkConfig struct {
+	// Type is the type of the sink. Available options:
+	//
+	// - `redis` writes records to Redis for Tyk Pump to process.
+	// - `file` writes records as newline delimited JSON to a rotating file.
+	// - `http` sends batches of records as a JSON array to an HTTP endpoint.
+	// - `otlp` exports records as OpenTelemetry logs over OTLP/HTTP.
+	// - `kafka` produces records as JSON messages to a Kafka compatible topic.
+	Type string `json:"type"`
+
+	// File configures the `file` sink.
+	File AnalyticsFileSinkConfig `json:"file"`
+
+	// HTTP configures the `http` sink.
+	HTTP AnalyticsHTTPSinkConfig `json:"http"`
+
+	// OTLP configures the `otlp` sink.
+	OTLP AnalyticsOTLPSinkConfig `json:"otlp"`
+
+	// Kafka configures the `kafka` sink.
+	Kafka AnalyticsKafkaSinkConfig `json:"kafka"`
+}
+
+// AnalyticsFileSinkConfig configures the file analytics sink.
+type AnalyticsFileSinkConfig struct {
+	// Path is the path of the file records are written to.
+	Path string `json:"path"`
+
+	// MaxSizeMB is the size in megabytes at which the file is rotated. Defaults to 100.
+	MaxSizeMB int `json:"max_size_mb"`
+
+	// MaxBackups is the number of rotated files to keep. Defaults to 5.
+	MaxBackups int `json:"max_backups"`
+}
+
+// AnalyticsHTTPSinkConfig configures the HTTP batch analytics sink.
+type AnalyticsHTTPSinkConfig struct {
+	// URL is the endpoint batches of records are sent to with a POST request.
+	URL string `json:"url"`
+
+	// Headers are added to every request.
+	Headers map[string]string `json:"headers"`
+
+	// Timeout is the request timeout in seconds. Defaults to 10.
+	Timeout int `json:"timeout"`
+}
+
+// AnalyticsOTLPSinkConfig configures the OTLP logs analytics sink.
+type AnalyticsOTLPSinkConfig struct {
+	// Endpoint is the OTLP/HTTP logs endpoint, for example `http://collector:4318/v1/logs`.
+	Endpoint string `json:"endpoint"`
+
+	// Headers are added to every export request.
+	Headers map[string]string `json:"headers"`
+
+	// Timeout is the export timeout in seconds. Defaults to 10.
+	Timeout int `json:"timeout"`
+}
+
+// AnalyticsKafkaSinkConfig configures the Kafka analytics sink.
+type AnalyticsKafkaSinkConfig struct {
+	// Brokers is the list of Kafka compatible brokers.
+	Brokers []string `json:"brokers"`
+
+	// Topic is the topic records are produced to.
+	Topic string `json:"topic"`
+
+	// Timeout is the produce timeout in seconds. Defaults to 10.
+	Timeout int `json:"timeout"`
 }
 
 // AccessLogsConfig defines the type of transactions logs printed to stdout.
@@ -538,6 +635,25 @@ type SlaveOptionsConfig struct {
 	SyncUsedCertsOnly bool `json:"sync_used_certs_only"`
 }
 
+// ResponseCacheL1Config configures the in-memory response cache tier.
+type ResponseCacheL1Config struct {
+	// Enabled keeps parsed cached responses in memory, so that cache hits skip Redis.
+	// Invalidating the cache of an API clears the in-memory tier of every gateway.
+	Enabled bool `json:"enabled"`
+	// MaxBytes bounds the size of the in-memory tier, least recently used responses
+	// are evicted first. Defaults to 64MB.
+	MaxBytes int64 `json:"max_bytes"`
+}
+
+// ResponseCacheCompressionConfig configures the compression of cached responses.
+type ResponseCacheCompressionConfig struct {
+	// Enabled stores cached responses compressed with Zstd instead of base64 encoded.
+	// Previously stored entries are still read, so it can be enabled on existing deployments.
+	Enabled bool `json:"enabled"`
+	// MinSize is the size in bytes from which cached responses are compressed. Defaults to 1024.
+	MinSize int `json:"min_size"`
+}
+
 type LocalSessionCacheConf struct {
 	// By default sessions are set to cache. Set this to `true` to stop Tyk from caching keys locally on the node.
 	DisableCacheSessionState bool `json:"disable_cached_session_state"`
@@ -696,6 +812,51 @@ type HttpServerOptionsConfig struct {
 	//
 	// **Note:** The limit is applied only when the [Response Body Transform middleware](/api-management/traffic-transformation/response-body) is enabled.
 	MaxResponseBodySize int64 `json:"max_response_body_size"`
+
+	// ACME configures the automatic provisioning of the TLS certificates of API custom domains with an ACME certificate authority, such as Let's Encrypt.
+	ACME ACMEConfig `json:"acme"`
+}
+
+// ACMEConfig configures the automatic provisioning and renewal of TLS certificates with the ACME protocol.
+//
+// Certificates are ordered for the custom domains of APIs, when `enable_custom_domains` is set, and for the configured
+// domains. They are stored in the certificate store and served for their domain. In a cluster, a lock in Redis ensures that
+// only one Gateway orders the certificate of a domain, and the challenges of the order can be answered by any Gateway.
+type ACMEConfig struct {
+	// Enabled turns on the automatic provisioning of certificates.
+	Enabled bool `json:"enabled"`
+
+	// DirectoryURL is the directory URL of the ACME certificate authority.
+	// Default: https://acme-v02.api.letsencrypt.org/directory
+	DirectoryURL string `json:"directory_url"`
+
+	// Email is the contact address of the ACME account, used by the certificate authority for expiry notices.
+	Email string `json:"email"`
+
+	// Challenges are the challenge types used to prove the control of domains, in order of preference: `tls-alpn-01`, answered
+	// by the Gateway TLS listener which must be reachable on port 443, and `http-01`, answered on `http_challenge_address`.
+	// Both are used by default.
+	Challenges []string `json:"challenges"`
+
+	// Domains are the domains certificates are provisioned for, in addition to the custom domains of APIs.
+	Domains []string `json:"domains"`
+
+	// RenewBeforeDays is the number of days before their expiry certificates are renewed.
+	// Default: 30 days
+	RenewBeforeDays int `json:"renew_before_days"`
+
+	// CheckInterval is the interval, in seconds, between the checks of the certificates that need to be ordered or renewed.
+	// Certificates of new API domains are also ordered when the APIs are loaded.
+	// Default: 3600 seconds (1 hour)
+	CheckInterval int `json:"check_interval"`
+
+	// HTTPChallengeAddress is the address of a plain HTTP listener serving `http-01` challenges, e.g. `:80`. The listener
+	// only serves challenges, it isn't started when not set.
+	HTTPChallengeAddress string `json:"http_challenge_address"`
+
+	// CAFile is the path to a PEM file of CA certificates trusted for connections to the ACME server, in addition to the
+	// system ones, e.g. for test servers such as Pebble.
+	CAFile string `json:"ca_file"`
 }
 
 type AuthOverrideConf struct {
@@ -723,6 +884,20 @@ type UptimeTestsConfig struct {
 	PollerGroup string                  `json:"poller_group"`
 	Config      UptimeTestsConfigDetail `json:"config"`
 }
+
+// UpstreamRetryBudgetConfig limits the upstream retries of all APIs on this node,
+// so that retries can't multiply the load on an upstream that is already failing.
+type UpstreamRetryBudgetConfig struct {
+	// Ratio is the maximum number of retries as a share of upstream requests, e.g. `0.2` allows
+	// one retry for every five requests. Defaults to 0.2.
+	Ratio float64 `json:"ratio"`
+	// MinRetriesPerSecond is the number of retries per second that are always allowed, regardless
+	// of the ratio, so that APIs with little traffic can still retry. Defaults to 10.
+	MinRetriesPerSecond int `json:"min_retries_per_second"`
+	// Window is the period, in seconds, over which requests and retries are counted. Defaults to 10.
+	Window int `json:"window"`
+}
+
 type ServiceDiscoveryConf struct {
 	// Service discovery cache timeout
 	DefaultCacheTimeout int `json:"default_cache_timeout"`
@@ -784,6 +959,33 @@ type CertificateExpiryMonitorConfig struct {
 	EventCooldownSeconds int `json:"event_cooldown_seconds"`
 }
 
+// CertificateRevocationConfig configures the revocation checking of client certificates used in mutual TLS
+type CertificateRevocationConfig struct {
+	// Enabled turns on the revocation checking of client certificates, both during the TLS handshake and in APIs using mutual TLS
+	Enabled bool `json:"enabled"`
+
+	// CRL enables checking client certificates against the CRLs uploaded through the `/tyk/certs` API and the CRLs published at the distribution points of the certificates
+	CRL bool `json:"crl"`
+
+	// OCSP enables checking client certificates with the OCSP responders of their issuers. When both CRL and OCSP are enabled, OCSP is tried first
+	OCSP bool `json:"ocsp"`
+
+	// HardFail rejects client certificates whose revocation status can't be determined, e.g. because the OCSP responder is unreachable and no CRL is available.
+	// By default (soft-fail) such certificates are accepted
+	HardFail bool `json:"hard_fail"`
+
+	// CacheTTLSeconds specifies how long CRLs and OCSP responses without a next update time are cached
+	// Default: 3600 seconds (1 hour)
+	CacheTTLSeconds int `json:"cache_ttl_seconds"`
+
+	// TimeoutSeconds specifies the timeout of requests fetching CRLs and OCSP responses
+	// Default: 5 seconds
+	TimeoutSeconds int `json:"timeout_seconds"`
+
+	// OCSPStapling staples the OCSP responses of the Gateway server certificates to TLS handshakes, when their issuers publish an OCSP responder
+	OCSPStapling bool `json:"ocsp_stapling"`
+}
+
 type SecurityConfig struct {
 	// Set the AES256 secret which is used to encode certificate private keys when they uploaded via certificate storage
 	PrivateCertificateEncodingSecret string `json:"private_certificate_encoding_secret" structviewer:"obfuscate"`
@@ -803,6 +1005,9 @@ type SecurityConfig struct {
 
 	// CertificateExpiryMonitor configures the certificate expiry monitoring and notification feature
 	CertificateExpiryMonitor CertificateExpiryMonitorConfig `json:"certificate_expiry_monitor"`
+
+	// CertificateRevocation configures the revocation checking of client certificates with CRLs and OCSP
+	CertificateRevocation CertificateRevocationConfig `json:"certificate_revocation"`
 }
 
 type JWKSConfig struct {
@@ -1129,6 +1334,9 @@ type Config struct {
 	// This can cause a file-handler limit to be exceeded. Setting to false can have performance benefits as the connection can be reused.
 	ProxyCloseConnections bool `json:"proxy_close_connections"`
 
+	// UpstreamRetryBudget limits the retries APIs with `proxy.retry` enabled may send to their upstreams.
+	UpstreamRetryBudget UpstreamRetryBudgetConfig `json:"upstream_retry_budget"`
+
 	// Tyk nodes can provide uptime awareness, uptime testing and analytics for your underlying APIs uptime and availability.
 	// Tyk can also notify you when a service goes down.
 	UptimeTests UptimeTestsConfig `json:"uptime_tests"`
@@ -1232,6 +1440,12 @@ type Config struct {
 	EnableSeperateCacheStore bool               `json:"enable_separate_cache_store"`
 	CacheStorage             StorageOptionsConf `json:"cache_storage"`
 
+	// ResponseCacheL1 configures an in-memory tier in front of the Redis response cache.
+	ResponseCacheL1 ResponseCacheL1Config `json:"response_cache_l1"`
+
+	// ResponseCacheCompression configures the compression of cached responses stored in Redis.
+	ResponseCacheCompression ResponseCacheCompressionConfig `json:"response_cache_compression"`
+
 	// Enable downloading Plugin bundles
 	// Example:
 	// ```
@@ -4,7 +4,7 @@ import "fmt"
 
 // ExternalServiceConfig provides centralized HTTP client management for Tyk Gateway's external service interactions.
 // This enterprise-grade feature supports proxy configuration, mTLS client certificates, and service-specific settings
-// for OAuth, Storage, Webhooks, Health Checks, and Service Discovery.
+// for OAuth, Storage, Webhooks, Health Checks, Service Discovery, Traffic Mirroring, External Authorization and ACME.
 type ExternalServiceConfig struct {
 	// Global proxy configuration that applies to all external services unless overridden at the service level
 	Global GlobalProxyConfig `json:"global"`
@@ -18,6 +18,12 @@ type ExternalServiceConfig struct {
 	Health ServiceConfig `json:"health"`
 	// Service discovery-specific configuration for service registry interactions and load balancer operations
 	Discovery ServiceConfig `json:"discovery"`
+	// Traffic mirroring-specific configuration for requests copied to shadow upstreams
+	Mirror ServiceConfig `json:"mirror"`
+	// Authorization-specific configuration for requests to external policy decision points
+	Authorization ServiceConfig `json:"authorization"`
+	// ACME-specific configuration for requests to the ACME certificate authority
+	ACME ServiceConfig `json:"acme"`
 }
 
 // GlobalProxyConfig defines global HTTP proxy configuration that applies to all external services.
@@ -92,11 +98,14 @@ type MTLSConfig struct {
 
 // Service type constants for identifying different external service types
 const (
-	ServiceTypeOAuth     = "oauth"
-	ServiceTypeStorage   = "storage"
-	ServiceTypeWebhook   = "webhook"
-	ServiceTypeHealth    = "health"
-	ServiceTypeDiscovery = "discovery"
+	ServiceTypeOAuth         = "oauth"
+	ServiceTypeStorage       = "storage"
+	ServiceTypeWebhook       = "webhook"
+	ServiceTypeHealth        = "health"
+	ServiceTypeDiscovery     = "discovery"
+	ServiceTypeMirror        = "mirror"
+	ServiceTypeAuthorization = "authorization"
+	ServiceTypeACME          = "acme"
 )
 
 // Validate validates the MTLSConfig for consistency and completeness.
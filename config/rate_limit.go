@@ -42,6 +42,21 @@ type RateLimit struct {
 	// This controls whether rate limit headers (X-RateLimit-Limit, X-RateLimit-Remaining, etc.)
 	// are populated from quota data or rate limit data. Valid values: "quotas", "rate_limits".
 	RateLimitResponseHeaders RateLimitSource `json:"rate_limit_response_headers"`
+
+	// EnableIETFRateLimitHeaders adds the IETF draft `RateLimit` and `RateLimit-Policy` headers to responses.
+	// Each applicable limit is reported as a separate named policy: `rate` for the key rate limit,
+	// `endpoint` for per-endpoint key rate limits, `api` for the API rate limit and `quota` for the key quota.
+	// The headers are sent in addition to the headers selected by `rate_limit_response_headers`.
+	EnableIETFRateLimitHeaders bool `json:"enable_ietf_rate_limit_headers"`
+
+	// EnableDistributedConcurrencyLimit counts in-flight requests for `max_concurrent_requests` limits in Redis,
+	// so that the limit is shared by all Gateways. By default every Gateway counts its own in-flight requests.
+	EnableDistributedConcurrencyLimit bool `json:"enable_distributed_concurrency_limit"`
+
+	// ConcurrencyLimitLeaseTTL is the number of seconds after which a Redis concurrency slot is freed if it wasn't
+	// released, for example when a Gateway stops while proxying a request. The Gateway holding a slot renews its
+	// lease every third of the TTL, so requests can take longer than the TTL. Default: 60 seconds.
+	ConcurrencyLimitLeaseTTL int `json:"concurrency_limit_lease_ttl"`
 }
 
 type RateLimitSource string
@@ -81,6 +81,21 @@ const (
 	// in the JWT middleware. The value (a *gateway.Binding) is type-asserted on
 	// the gateway side; only the key lives here to avoid an import cycle.
 	MatchedIdPBinding
+	// LoadBalancerTarget holds the upstream target picked by the load balancer,
+	// used to track in-flight requests and latency per target.
+	LoadBalancerTarget
+	// UpstreamAttempts holds the attempts made to proxy the request when
+	// upstream retries are enabled, for analytics.
+	UpstreamAttempts
+	// ConcurrencySlots holds the functions releasing the concurrency slots
+	// taken for the request, called once the request completes.
+	ConcurrencySlots
+	// RateLimitQueueResult holds how long the request waited in the rate
+	// limit queue and the queue depth it found, for analytics.
+	RateLimitQueueResult
+	// TokenClaims holds the claims of the validated JWT or introspected
+	// access token of the request, for external authorization.
+	TokenClaims
 )
 
 func ctxSetSession(r *http.Request, s *user.SessionState, scheduleUpdate bool, hashKey bool) {
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/rate/adaptive"
+)
+
+// newAdaptiveConcurrency creates the adaptive concurrency limiters of an API,
+// or returns nil when adaptive concurrency limiting is disabled. Limit changes
+// are reported to the OpenTelemetry metrics.
+func (gw *Gateway) newAdaptiveConcurrency(spec *APISpec) *adaptive.Group {
+	conf := spec.Proxy.AdaptiveConcurrency
+	if !conf.Enabled {
+		return nil
+	}
+
+	onChange := func(upstream string, limit int) {
+		if gw.MetricInstruments != nil {
+			gw.MetricInstruments.RecordUpstreamConcurrencyLimit(gw.ctx, spec.APIID, upstream, limit)
+		}
+	}
+
+	return adaptive.NewGroup(adaptive.Config{
+		Algorithm:    conf.Algorithm,
+		InitialLimit: conf.InitialLimit,
+		MinLimit:     conf.MinLimit,
+		MaxLimit:     conf.MaxLimit,
+		QueueSize:    conf.QueueSize,
+		QueueTimeout: time.Duration(conf.QueueTimeout),
+	}, onChange)
+}
+
+// acquireUpstreamSlot takes a slot of the adaptive concurrency limit of the
+// upstream host of outreq, waiting in its queue if needed. The returned
+// function must be called with the outcome of the upstream request. It returns
+// adaptive.ErrLimitExceeded when the request should be shed, or the error of
+// the request context when the client went away while queued.
+func (p *ReverseProxy) acquireUpstreamSlot(outreq *http.Request) (func(*http.Response, error), error) {
+	group := p.TykAPISpec.AdaptiveConcurrency
+	if group == nil {
+		return func(*http.Response, error) {}, nil
+	}
+
+	token, err := group.Get(outreq.URL.Host).Acquire(outreq.Context())
+	if err != nil {
+		if errors.Is(err, adaptive.ErrLimitExceeded) && p.Gw.MetricInstruments != nil {
+			p.Gw.MetricInstruments.RecordUpstreamConcurrencyShed(p.Gw.ctx, p.TykAPISpec.APIID, outreq.URL.Host)
+		}
+		return nil, err
+	}
+
+	return func(res *http.Response, err error) {
+		switch {
+		case errors.Is(err, context.Canceled) || outreq.Context().Err() == context.Canceled:
+			// the client went away, the latency says nothing about the upstream
+			token.Ignore()
+		case err != nil || (res != nil && (res.StatusCode/100 == 5 || res.StatusCode == http.StatusTooManyRequests)):
+			token.Dropped()
+		default:
+			token.Success()
+		}
+	}, nil
+}
@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream, arrived, release := blockingUpstream(t)
+
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/adaptive/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UseKeylessAccess = true
+		spec.Proxy.AdaptiveConcurrency = apidef.AdaptiveConcurrencyConfig{
+			Enabled:      true,
+			InitialLimit: 1,
+			MinLimit:     1,
+			MaxLimit:     1,
+		}
+	})[0]
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = ts.Run(t, test.TestCase{Path: "/adaptive/", Code: http.StatusOK})
+	}()
+	<-arrived
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/adaptive/", Code: http.StatusServiceUnavailable})
+
+	close(release)
+	<-done
+
+	assertSlotReleased(t, ts, test.TestCase{Path: "/adaptive/"})
+
+	loaded := ts.Gw.getApiSpec(spec.APIID)
+	if assert.NotNil(t, loaded.AdaptiveConcurrency) {
+		assert.Equal(t, 0, loaded.AdaptiveConcurrency.Get(upstream.Listener.Addr().String()).Inflight())
+	}
+
+	t.Run("cancelled while queued", func(t *testing.T) {
+		upstream, arrived, release := blockingUpstream(t)
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/adaptive-queued/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = true
+			spec.Proxy.AdaptiveConcurrency = apidef.AdaptiveConcurrencyConfig{
+				Enabled:      true,
+				InitialLimit: 1,
+				MinLimit:     1,
+				MaxLimit:     1,
+				QueueSize:    1,
+				QueueTimeout: tyktime.ReadableDuration(time.Minute),
+			}
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = ts.Run(t, test.TestCase{Path: "/adaptive-queued/", Code: http.StatusOK})
+		}()
+		<-arrived
+
+		// the client is gone by the time the request is queued
+		reqCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		rec := httptest.NewRecorder()
+		ts.mainRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/adaptive-queued/", nil).WithContext(reqCtx))
+		assert.Equal(t, 499, rec.Code)
+
+		close(release)
+		<-done
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/not-adaptive/"
+		})[0]
+
+		assert.Nil(t, ts.Gw.getApiSpec(spec.APIID).AdaptiveConcurrency)
+	})
+}
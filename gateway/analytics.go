@@ -1,7 +1,7 @@
 package gateway
 
 import (
-	"fmt"
+	"context"
 	mathrand "math/rand"
 	"strings"
 	"sync"
@@ -14,6 +14,7 @@ import (
 	maxminddb "github.com/oschwald/maxminddb-golang"
 
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/analytics/spool"
 	"github.com/TykTechnologies/tyk/regexp"
 	"github.com/TykTechnologies/tyk/storage"
 )
@@ -23,6 +24,9 @@ const analyticsKeyName = "tyk-system-analytics"
 const (
 	recordsBufferFlushInterval       = 200 * time.Millisecond
 	recordsBufferForcedFlushInterval = 1 * time.Second
+	// recordsFinalFlushTimeout bounds writing the buffered records when the
+	// analytics processing stops, the gateway context may be done by then.
+	recordsFinalFlushTimeout = 10 * time.Second
 )
 
 func (gw *Gateway) initNormalisationPatterns() (pats config.NormaliseURLPatterns) {
@@ -49,12 +53,15 @@ type RedisAnalyticsHandler struct {
 	recordsChan                 chan *analytics.AnalyticsRecord
 	workerBufferSize            uint64
 	shouldStop                  uint32
+	droppedRecords              uint64
 	poolWg                      sync.WaitGroup
 	enableMultipleAnalyticsKeys bool
 	Clean                       Purger
 	Gw                          *Gateway `json:"-"`
 	mu                          sync.Mutex
 	analyticsSerializer         serializer.AnalyticsSerializer
+	sink                        AnalyticsSink
+	spool                       *spool.Spool
 
 	// testing purposes
 	mockEnabled   bool
@@ -80,6 +87,15 @@ func (r *RedisAnalyticsHandler) Init() {
 	r.enableMultipleAnalyticsKeys = r.globalConf.AnalyticsConfig.EnableMultipleAnalyticsKeys
 	r.analyticsSerializer = serializer.NewAnalyticsSerializer(r.globalConf.AnalyticsConfig.SerializerType)
 
+	r.initSpool()
+
+	sink, err := r.newSink()
+	if err != nil {
+		log.WithError(err).Error("Failed to init analytics sinks, writing analytics to Redis")
+		sink = r.droppingSink(analyticsSinkRedis, r.redisSink())
+	}
+	r.sink = sink
+
 	r.Start()
 }
 
@@ -109,6 +125,25 @@ func (r *RedisAnalyticsHandler) Stop() {
 	r.poolWg.Wait()
 }
 
+// Close stops the analytics processing, writing the buffered records, and closes the analytics sinks and spool.
+func (r *RedisAnalyticsHandler) Close() {
+	if atomic.LoadUint32(&r.shouldStop) == 0 {
+		r.Stop()
+	}
+
+	if r.sink != nil {
+		if err := r.sink.Close(); err != nil {
+			log.WithError(err).Error("Error closing analytics sinks")
+		}
+	}
+
+	if r.spool != nil {
+		if err := r.spool.Close(); err != nil {
+			log.WithError(err).Error("Error closing analytics spool")
+		}
+	}
+}
+
 // Flush will stop the analytics processing and empty the analytics buffer and then re-init the workers again
 func (r *RedisAnalyticsHandler) Flush() {
 	r.Stop()
@@ -116,7 +151,7 @@ func (r *RedisAnalyticsHandler) Flush() {
 	r.Start()
 }
 
-// RecordHit will store an analytics.Record in Redis
+// RecordHit will store an analytics.Record in the analytics sinks
 func (r *RedisAnalyticsHandler) RecordHit(record *analytics.AnalyticsRecord) error {
 	if r.mockEnabled {
 		r.mockRecordHit(record)
@@ -129,33 +164,44 @@ func (r *RedisAnalyticsHandler) RecordHit(record *analytics.AnalyticsRecord) err
 	}
 
 	// just send record to channel consumed by pool of workers
-	// leave all data crunching and Redis I/O work for pool workers
+	// leave all data crunching and Redis I/O work for pool workers,
+	// and drop the record rather than block the request when they fall behind
 	r.mu.Lock()
-	r.recordsChan <- record
+	select {
+	case r.recordsChan <- record:
+	default:
+		r.dropRecords(analyticsSinkBuffer, 1)
+	}
 	r.mu.Unlock()
 
 	return nil
 }
 
+// DroppedRecords returns the number of records dropped because the records
+// buffer was full or a sink failed to write them.
+func (r *RedisAnalyticsHandler) DroppedRecords() uint64 {
+	return atomic.LoadUint64(&r.droppedRecords)
+}
+
+// dropRecords counts records dropped by a sink.
+func (r *RedisAnalyticsHandler) dropRecords(sinkType string, records int) {
+	atomic.AddUint64(&r.droppedRecords, uint64(records))
+	if r.Gw.MetricInstruments != nil {
+		r.Gw.MetricInstruments.RecordAnalyticsDropped(r.Gw.ctx, sinkType, records)
+	}
+}
+
 func (r *RedisAnalyticsHandler) recordWorker() {
 	defer r.poolWg.Done()
 
-	// this is buffer to send one pipelined command to redis
+	// this is buffer to send one batch to the analytics sink
 	// use r.recordsBufferSize as cap to reduce slice re-allocations
-	recordsBuffer := make([][]byte, 0, r.workerBufferSize)
+	recordsBuffer := make([]*analytics.AnalyticsRecord, 0, r.workerBufferSize)
 	mathrand.Seed(time.Now().Unix())
 
 	// read records from channel and process
 	lastSentTs := time.Now()
 	for {
-		analyticKey := analyticsKeyName
-		if r.enableMultipleAnalyticsKeys {
-			suffix := mathrand.Intn(10)
-			analyticKey = fmt.Sprintf("%v_%v", analyticKey, suffix)
-		}
-		serliazerSuffix := r.analyticsSerializer.GetSuffix()
-		analyticKey += serliazerSuffix
-
 		readyToSend := false
 
 		flushTimer := time.NewTimer(recordsBufferFlushInterval)
@@ -170,7 +216,7 @@ func (r *RedisAnalyticsHandler) recordWorker() {
 			// check if channel was closed and it is time to exit from worker
 			if !ok {
 				// send what is left in buffer
-				r.Store.AppendToSetPipelined(analyticKey, recordsBuffer)
+				r.flushRecords(recordsBuffer)
 				return
 			}
 
@@ -211,11 +257,7 @@ func (r *RedisAnalyticsHandler) recordWorker() {
 				record.OriginalPath = "/" + record.OriginalPath
 			}
 
-			if encoded, err := r.analyticsSerializer.Encode(record); err != nil {
-				log.WithError(err).Error("Error encoding analytics data")
-			} else {
-				recordsBuffer = append(recordsBuffer, encoded)
-			}
+			recordsBuffer = append(recordsBuffer, record)
 
 			// identify that buffer is ready to be sent
 			readyToSend = uint64(len(recordsBuffer)) == r.workerBufferSize
@@ -225,15 +267,35 @@ func (r *RedisAnalyticsHandler) recordWorker() {
 			readyToSend = true
 		}
 
-		// send data to Redis and reset buffer
+		// send data to the analytics sink and reset buffer
 		if len(recordsBuffer) > 0 && (readyToSend || time.Since(lastSentTs) >= recordsBufferForcedFlushInterval) {
-			r.Store.AppendToSetPipelined(analyticKey, recordsBuffer)
+			r.writeRecords(r.Gw.ctx, recordsBuffer)
 			recordsBuffer = recordsBuffer[:0]
 			lastSentTs = time.Now()
 		}
 	}
 }
 
+// writeRecords writes a batch of records to the analytics sink. The sinks
+// drop and count the records they fail to write.
+func (r *RedisAnalyticsHandler) writeRecords(ctx context.Context, records []*analytics.AnalyticsRecord) {
+	if len(records) == 0 {
+		return
+	}
+
+	_ = r.sink.Write(ctx, records)
+}
+
+// flushRecords writes the records left when a worker stops. It doesn't use
+// the gateway context, which is cancelled before the analytics are closed on
+// shutdown, so that the sinks don't drop them.
+func (r *RedisAnalyticsHandler) flushRecords(records []*analytics.AnalyticsRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), recordsFinalFlushTimeout)
+	defer cancel()
+
+	r.writeRecords(ctx, records)
+}
+
 func DurationToMillisecond(d time.Duration) float64 {
 	return float64(d) / 1e6
 }
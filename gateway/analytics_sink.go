@@ -0,0 +1,187 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	mathrand "math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/TykTechnologies/tyk-pump/serializer"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/analytics/sink"
+	"github.com/TykTechnologies/tyk/internal/analytics/spool"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// Analytics sink types, as configured in analytics_config.sinks.
+const (
+	analyticsSinkRedis = "redis"
+	analyticsSinkFile  = "file"
+	analyticsSinkHTTP  = "http"
+	analyticsSinkOTLP  = "otlp"
+	analyticsSinkKafka = "kafka"
+
+	// analyticsSinkBuffer counts the records dropped before they reach the
+	// sinks in the dropped records metric.
+	analyticsSinkBuffer = "buffer"
+)
+
+// AnalyticsSink is a destination the analytics workers write batches of records to.
+type AnalyticsSink = sink.Sink
+
+// redisAnalyticsSink writes serialized records to Redis for Tyk Pump to process.
+// With a spool, records that can't be written to Redis are spooled instead,
+// and so are the records that follow them until the spool is replayed.
+type redisAnalyticsSink struct {
+	store        storage.AnalyticsHandler
+	serializer   serializer.AnalyticsSerializer
+	multipleKeys bool
+	spool        *spool.Spool
+}
+
+// Write appends the records to the analytics key in one pipelined command.
+func (s *redisAnalyticsSink) Write(_ context.Context, records []*analytics.AnalyticsRecord) error {
+	analyticKey := analyticsKeyName
+	if s.multipleKeys {
+		suffix := mathrand.Intn(10)
+		analyticKey = fmt.Sprintf("%v_%v", analyticKey, suffix)
+	}
+	analyticKey += s.serializer.GetSuffix()
+
+	encoded := make([][]byte, 0, len(records))
+	for _, record := range records {
+		data, err := s.serializer.Encode(record)
+		if err != nil {
+			log.WithError(err).Error("Error encoding analytics data")
+			continue
+		}
+		encoded = append(encoded, data)
+	}
+
+	if len(encoded) == 0 {
+		return nil
+	}
+
+	// keep records in order while older ones wait in the spool
+	if s.spool != nil && s.spool.Stats().Batches > 0 {
+		return s.spool.Append(analyticKey, encoded)
+	}
+
+	err := s.store.AppendToSetPipelined(analyticKey, encoded)
+	if err != nil && s.spool != nil {
+		return s.spool.Append(analyticKey, encoded)
+	}
+	return err
+}
+
+// Close is a no-op, the analytics store is shared with the purger.
+func (s *redisAnalyticsSink) Close() error {
+	return nil
+}
+
+// droppingSink drops the records its sink fails to write, counting them in
+// the dropped records of the sink type. Write never fails, so that a fan out
+// reports each failing sink once.
+type droppingSink struct {
+	AnalyticsSink
+	sinkType string
+	handler  *RedisAnalyticsHandler
+}
+
+// Write writes records to the sink, dropping them when it fails.
+func (s *droppingSink) Write(ctx context.Context, records []*analytics.AnalyticsRecord) error {
+	if err := s.AnalyticsSink.Write(ctx, records); err != nil {
+		log.WithError(err).WithFields(logrus.Fields{
+			"sink":    s.sinkType,
+			"records": len(records),
+		}).Error("Dropping analytics records, the analytics sink is unavailable")
+		s.handler.dropRecords(s.sinkType, len(records))
+	}
+	return nil
+}
+
+// newSink creates the sink records are written to. Every configured sink is
+// retried a bounded number of times on failure, after which its records are
+// dropped, so that an unavailable sink doesn't stall the analytics workers.
+// Without configured sinks, records are written to Redis.
+func (r *RedisAnalyticsHandler) newSink() (AnalyticsSink, error) {
+	confs := r.globalConf.AnalyticsConfig.Sinks
+	if len(confs) == 0 {
+		return r.droppingSink(analyticsSinkRedis, r.redisSink()), nil
+	}
+
+	sinks := make(sink.FanOut, 0, len(confs))
+	for _, conf := range confs {
+		s, err := r.newSinkOfType(conf)
+		if err != nil {
+			_ = sinks.Close()
+			return nil, err
+		}
+
+		sinkType := conf.Type
+		sinks = append(sinks, r.droppingSink(sinkType, sink.WithRetry(s, sink.RetryOptions{
+			OnError: func(err error, attempt int, backoff time.Duration) {
+				log.WithError(err).WithFields(logrus.Fields{
+					"sink":    sinkType,
+					"attempt": attempt,
+					"backoff": backoff,
+				}).Warning("Analytics sink failed, retrying")
+			},
+		})))
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return sinks, nil
+}
+
+func (r *RedisAnalyticsHandler) newSinkOfType(conf config.AnalyticsSinkConfig) (AnalyticsSink, error) {
+	switch conf.Type {
+	case analyticsSinkRedis:
+		return r.redisSink(), nil
+	case analyticsSinkFile:
+		return sink.NewFile(sink.FileConfig{
+			Path:       conf.File.Path,
+			MaxSize:    int64(conf.File.MaxSizeMB) << 20,
+			MaxBackups: conf.File.MaxBackups,
+		})
+	case analyticsSinkHTTP:
+		return sink.NewHTTP(sink.HTTPConfig{
+			URL:     conf.HTTP.URL,
+			Headers: conf.HTTP.Headers,
+			Timeout: time.Duration(conf.HTTP.Timeout) * time.Second,
+		})
+	case analyticsSinkOTLP:
+		return sink.NewOTLP(sink.HTTPConfig{
+			URL:     conf.OTLP.Endpoint,
+			Headers: conf.OTLP.Headers,
+			Timeout: time.Duration(conf.OTLP.Timeout) * time.Second,
+		})
+	case analyticsSinkKafka:
+		return sink.NewKafka(sink.KafkaConfig{
+			Brokers: conf.Kafka.Brokers,
+			Topic:   conf.Kafka.Topic,
+			Timeout: time.Duration(conf.Kafka.Timeout) * time.Second,
+		})
+	default:
+		return nil, fmt.Errorf("unknown analytics sink type %q", conf.Type)
+	}
+}
+
+func (r *RedisAnalyticsHandler) droppingSink(sinkType string, s AnalyticsSink) AnalyticsSink {
+	return &droppingSink{AnalyticsSink: s, sinkType: sinkType, handler: r}
+}
+
+func (r *RedisAnalyticsHandler) redisSink() *redisAnalyticsSink {
+	return &redisAnalyticsSink{
+		store:        r.Store,
+		serializer:   r.analyticsSerializer,
+		multipleKeys: r.enableMultipleAnalyticsKeys,
+		spool:        r.spool,
+	}
+}
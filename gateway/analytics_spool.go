@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/internal/analytics/spool"
+)
+
+// analyticsSpoolReplayInterval is how often the analytics spool is replayed
+// to Redis, once Redis is available.
+const analyticsSpoolReplayInterval = time.Second
+
+// initSpool opens the analytics spool when it's enabled and starts replaying it.
+func (r *RedisAnalyticsHandler) initSpool() {
+	conf := r.globalConf.AnalyticsConfig.Spool
+	if !conf.Enabled || r.spool != nil {
+		return
+	}
+
+	s, err := spool.Open(spool.Config{
+		Dir:     conf.Path,
+		MaxSize: int64(conf.MaxSizeMB) << 20,
+		OnCorrupt: func(segment string, err error) {
+			log.WithError(err).WithField("segment", segment).Error("Discarding corrupt analytics spool entries")
+		},
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to open analytics spool")
+		return
+	}
+
+	stats := s.Stats()
+	log.WithFields(logrus.Fields{
+		"records": stats.Records,
+		"bytes":   stats.Bytes,
+	}).Info("Opened analytics spool")
+
+	r.spool = s
+	go r.replaySpool()
+}
+
+// replaySpool replays the spooled records to Redis in order whenever Redis is
+// connected, and records the spool depth and size metrics.
+func (r *RedisAnalyticsHandler) replaySpool() {
+	ticker := time.NewTicker(analyticsSpoolReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Gw.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if r.spool.Stats().Batches > 0 && r.Store.Connect() {
+			replayed, err := r.spool.Replay(r.Store.AppendToSetPipelined)
+			if err != nil {
+				log.WithError(err).Warning("Analytics spool replay interrupted")
+			}
+			if replayed > 0 {
+				log.WithField("batches", replayed).Info("Replayed spooled analytics records")
+			}
+		}
+
+		if r.Gw.MetricInstruments != nil {
+			stats := r.spool.Stats()
+			r.Gw.MetricInstruments.RecordAnalyticsSpool(r.Gw.ctx, stats.Records, stats.Bytes)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+	"github.com/TykTechnologies/tyk-pump/serializer"
+
+	"github.com/TykTechnologies/tyk/internal/analytics/spool"
+)
+
+type unavailableAnalyticsStore struct {
+	down     bool
+	appended map[string][][]byte
+}
+
+func (s *unavailableAnalyticsStore) Connect() bool { return !s.down }
+
+func (s *unavailableAnalyticsStore) AppendToSetPipelined(key string, values [][]byte) error {
+	if s.down {
+		return errors.New("redis is down")
+	}
+	s.appended[key] = append(s.appended[key], values...)
+	return nil
+}
+
+func (s *unavailableAnalyticsStore) GetAndDeleteSet(string) []interface{} { return nil }
+func (s *unavailableAnalyticsStore) SetExp(string, int64) error           { return nil }
+func (s *unavailableAnalyticsStore) GetExp(string) (int64, error)         { return 0, nil }
+
+func TestRedisAnalyticsSink_Spool(t *testing.T) {
+	store := &unavailableAnalyticsStore{down: true, appended: map[string][][]byte{}}
+
+	s, err := spool.Open(spool.Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	defer s.Close()
+
+	sink := &redisAnalyticsSink{
+		store:      store,
+		serializer: serializer.NewAnalyticsSerializer(""),
+		spool:      s,
+	}
+	key := analyticsKeyName + sink.serializer.GetSuffix()
+
+	write := func(apiID string) {
+		t.Helper()
+		require.NoError(t, sink.Write(context.Background(), []*analytics.AnalyticsRecord{{APIID: apiID}}))
+	}
+
+	write("first")
+	assert.Equal(t, 1, s.Stats().Records)
+
+	// records are spooled while older ones wait, even when Redis is back
+	store.down = false
+	write("second")
+	assert.Equal(t, 2, s.Stats().Records)
+	assert.Empty(t, store.appended)
+
+	replayed, err := s.Replay(store.AppendToSetPipelined)
+	require.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+
+	write("third")
+	assert.Equal(t, 0, s.Stats().Records)
+
+	var apiIDs []string
+	for _, value := range store.appended[key] {
+		var record analytics.AnalyticsRecord
+		require.NoError(t, sink.serializer.Decode(value, &record))
+		apiIDs = append(apiIDs, record.APIID)
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, apiIDs)
+}
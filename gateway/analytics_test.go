@@ -1,18 +1,26 @@
 package gateway
 
 import (
+	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/TykTechnologies/tyk-pump/analytics"
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/analytics/sink"
 	"github.com/TykTechnologies/tyk/test"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -526,6 +534,189 @@ func TestAnalytics_Write(t *testing.T) {
 
 }
 
+func TestAnalytics_Sinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analytics.log")
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.AnalyticsConfig.Sinks = []config.AnalyticsSinkConfig{
+			{Type: analyticsSinkRedis},
+			{Type: analyticsSinkFile, File: config.AnalyticsFileSinkConfig{Path: path}},
+		}
+	})
+	defer ts.Close()
+
+	redisAnalyticsKeyName := analyticsKeyName + ts.Gw.Analytics.analyticsSerializer.GetSuffix()
+	ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "sinks"
+		spec.UseKeylessAccess = true
+		spec.Proxy.ListenPath = "/"
+	})
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/", Code: http.StatusOK})
+
+	ts.Gw.Analytics.Flush()
+
+	results := ts.Gw.Analytics.Store.GetAndDeleteSet(redisAnalyticsKeyName)
+	assert.Len(t, results, 1)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 1)
+
+	var record analytics.AnalyticsRecord
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "sinks", record.APIID)
+	assert.Contains(t, record.Tags, "api-sinks")
+}
+
+func TestAnalytics_InvalidSinks(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.AnalyticsConfig.Sinks = []config.AnalyticsSinkConfig{{Type: "unknown"}}
+	})
+	defer ts.Close()
+
+	dropping, ok := ts.Gw.Analytics.sink.(*droppingSink)
+	require.True(t, ok)
+	_, isRedis := dropping.AnalyticsSink.(*redisAnalyticsSink)
+	assert.True(t, isRedis, "should fall back to Redis")
+}
+
+// contextSink records what it writes, failing like network sinks do when
+// the write context is done.
+type contextSink struct {
+	mu      sync.Mutex
+	written []*analytics.AnalyticsRecord
+}
+
+func (s *contextSink) Write(ctx context.Context, records []*analytics.AnalyticsRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written = append(s.written, records...)
+	return nil
+}
+
+func (s *contextSink) Close() error {
+	return nil
+}
+
+func TestAnalytics_CloseWritesBufferedRecords(t *testing.T) {
+	conf := config.Config{}
+	conf.AnalyticsConfig.PoolSize = 1
+	conf.AnalyticsConfig.RecordsBufferSize = 10
+
+	// the gateway context is cancelled before the analytics are closed on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	gw := NewGateway(conf, ctx)
+	cancel()
+
+	sink := &contextSink{}
+	handler := &RedisAnalyticsHandler{
+		Gw:               gw,
+		globalConf:       conf,
+		workerBufferSize: 10,
+		sink:             sink,
+	}
+	handler.Start()
+
+	assert.NoError(t, handler.RecordHit(&analytics.AnalyticsRecord{APIID: "api", Path: "/"}))
+	handler.Close()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if assert.Len(t, sink.written, 1) {
+		assert.Equal(t, "api", sink.written[0].APIID)
+	}
+}
+
+// stalledSink blocks writes until it's released, like an unreachable sink.
+type stalledSink struct {
+	release chan struct{}
+}
+
+func (s *stalledSink) Write(ctx context.Context, _ []*analytics.AnalyticsRecord) error {
+	select {
+	case <-s.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *stalledSink) Close() error {
+	return nil
+}
+
+// failingSink fails every write.
+type failingSink struct{}
+
+func (failingSink) Write(context.Context, []*analytics.AnalyticsRecord) error {
+	return errors.New("unavailable")
+}
+
+func (failingSink) Close() error {
+	return nil
+}
+
+func TestAnalytics_DropsRecords(t *testing.T) {
+	conf := config.Config{}
+	conf.AnalyticsConfig.PoolSize = 1
+	conf.AnalyticsConfig.RecordsBufferSize = 1
+
+	t.Run("stalled sink doesn't block RecordHit", func(t *testing.T) {
+		gw := NewGateway(conf, context.Background())
+		sink := &stalledSink{release: make(chan struct{})}
+		handler := &RedisAnalyticsHandler{
+			Gw:               gw,
+			globalConf:       conf,
+			workerBufferSize: 1,
+			sink:             sink,
+		}
+		handler.Start()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 10; i++ {
+				assert.NoError(t, handler.RecordHit(&analytics.AnalyticsRecord{APIID: "api", Path: "/"}))
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("RecordHit blocked on a stalled sink")
+		}
+
+		// one record is buffered and one is held by the worker at most
+		assert.GreaterOrEqual(t, handler.DroppedRecords(), uint64(8))
+
+		close(sink.release)
+		handler.Close()
+	})
+
+	t.Run("failing sink drops its records", func(t *testing.T) {
+		handler := &RedisAnalyticsHandler{
+			Gw:         NewGateway(conf, context.Background()),
+			globalConf: conf,
+		}
+		handler.sink = sink.FanOut{
+			handler.droppingSink(analyticsSinkHTTP, failingSink{}),
+			handler.droppingSink(analyticsSinkFile, &contextSink{}),
+		}
+
+		handler.writeRecords(context.Background(), []*analytics.AnalyticsRecord{{APIID: "a"}, {APIID: "b"}})
+		assert.Equal(t, uint64(2), handler.DroppedRecords())
+	})
+}
+
 func TestGeoIPLookup(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
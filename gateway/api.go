@@ -63,6 +63,7 @@ import (
 	"github.com/TykTechnologies/tyk/internal/model"
 	"github.com/TykTechnologies/tyk/internal/osutil"
 	"github.com/TykTechnologies/tyk/internal/otel"
+	"github.com/TykTechnologies/tyk/internal/rate/queue"
 	"github.com/TykTechnologies/tyk/internal/redis"
 	"github.com/TykTechnologies/tyk/internal/sanitize"
 	"github.com/TykTechnologies/tyk/internal/uuid"
@@ -3121,6 +3122,15 @@ func (gw *Gateway) invalidateCacheHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if gw.GetConfig().ResponseCacheL1.Enabled {
+		// clear the in-memory tier of the other gateways, Redis is already invalidated
+		gw.MainNotifier.Notify(Notification{
+			Command: NoticeDeleteAPICacheL1,
+			Payload: apiID,
+			Gw:      gw,
+		})
+	}
+
 	doJSONWrite(w, http.StatusOK, apiOk("cache invalidated"))
 }
 
@@ -3389,6 +3399,71 @@ func ctxGetRequestStartTime(r *http.Request) time.Time {
 	return time.Time{}
 }
 
+func ctxSetLoadBalancerTarget(r *http.Request, target string) {
+	setCtxValue(r, ctx.LoadBalancerTarget, target)
+}
+
+func ctxGetLoadBalancerTarget(r *http.Request) string {
+	if v := r.Context().Value(ctx.LoadBalancerTarget); v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func ctxSetUpstreamAttempts(r *http.Request, attempts []upstreamAttempt) {
+	setCtxValue(r, ctx.UpstreamAttempts, attempts)
+}
+
+func ctxGetUpstreamAttempts(r *http.Request) []upstreamAttempt {
+	if v := r.Context().Value(ctx.UpstreamAttempts); v != nil {
+		if attempts, ok := v.([]upstreamAttempt); ok {
+			return attempts
+		}
+	}
+	return nil
+}
+
+func ctxSetConcurrencySlots(r *http.Request, releases []func()) {
+	setCtxValue(r, ctx.ConcurrencySlots, releases)
+}
+
+func ctxGetConcurrencySlots(r *http.Request) []func() {
+	if v := r.Context().Value(ctx.ConcurrencySlots); v != nil {
+		if releases, ok := v.([]func()); ok {
+			return releases
+		}
+	}
+	return nil
+}
+
+func ctxSetRateLimitQueueResult(r *http.Request, result queue.Result) {
+	setCtxValue(r, ctx.RateLimitQueueResult, result)
+}
+
+func ctxGetRateLimitQueueResult(r *http.Request) (queue.Result, bool) {
+	if v := r.Context().Value(ctx.RateLimitQueueResult); v != nil {
+		if result, ok := v.(queue.Result); ok {
+			return result, true
+		}
+	}
+	return queue.Result{}, false
+}
+
+func ctxSetTokenClaims(r *http.Request, claims map[string]interface{}) {
+	setCtxValue(r, ctx.TokenClaims, claims)
+}
+
+func ctxGetTokenClaims(r *http.Request) map[string]interface{} {
+	if v := r.Context().Value(ctx.TokenClaims); v != nil {
+		if claims, ok := v.(map[string]interface{}); ok {
+			return claims
+		}
+	}
+	return nil
+}
+
 func ctxSetOriginalRequestPath(r *http.Request, path string) {
 	setCtxValue(r, ctx.OriginalRequestPath, path)
 }
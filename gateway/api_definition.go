@@ -19,7 +19,6 @@ import (
 	"time"
 
 	"github.com/Masterminds/sprig/v3"
-	"github.com/cenk/backoff"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/routers/gorillamux"
 	"github.com/sirupsen/logrus"
@@ -85,6 +84,8 @@ const (
 	PersistGraphQL
 	RateLimit
 	OASMockResponse
+	ConcurrencyLimit
+	UpstreamRetry
 )
 
 // RequestStatus is a custom type to avoid collisions
@@ -126,6 +127,8 @@ const (
 	StatusGoPlugin                        RequestStatus = "Go plugin"
 	StatusPersistGraphQL                  RequestStatus = "Persist GraphQL"
 	StatusRateLimit                       RequestStatus = "Rate Limited"
+	StatusConcurrencyLimit                RequestStatus = "Concurrency Limited"
+	StatusUpstreamRetry                   RequestStatus = "Upstream Retry"
 	// MCPPrimitiveNotFound is returned when a primitive VEM is accessed directly (not via JSON-RPC routing).
 	// It intentionally maps to HTTP 404 to avoid exposing internal-only endpoints.
 	MCPPrimitiveNotFound RequestStatus = "MCP Primitive Not Found"
@@ -136,6 +139,8 @@ type EndPointCacheMeta struct {
 	CacheKeyRegex          string
 	CacheOnlyResponseCodes []int
 	Timeout                int64
+	SurrogateKeys          []string
+	CacheKey               apidef.CacheKeyConfig
 }
 
 type TransformSpec struct {
@@ -146,6 +151,10 @@ type TransformSpec struct {
 type ExtendedCircuitBreakerMeta struct {
 	apidef.CircuitBreakerMeta
 	CB *circuit.Breaker `json:"-"`
+
+	breakers *circuitBreakers
+	// version is the name of the API version of the breaker.
+	version string
 }
 
 type OAuthManagerInterface interface {
@@ -173,15 +182,14 @@ func (s *APISpec) Unload() {
 	defer s.Unlock()
 
 	// release circuit breaker resources
-	for _, path := range s.RxPaths {
-		for _, urlSpec := range path {
-			if urlSpec.CircuitBreaker.CB != nil {
-				// this will force CB-event reading Go-routine and subscriber Go-routine to exit
-				urlSpec.CircuitBreaker.CB.Stop()
-			}
-		}
+	if s.circuitBreakers != nil {
+		// this will force CB-event reading Go-routines and subscriber Go-routines to exit
+		s.circuitBreakers.stop()
 	}
 
+	// release traffic mirror connections
+	s.trafficMirror.close()
+
 	// cancel execution contexts
 	if s.GraphEngine != nil {
 		s.GraphEngine.Cancel()
@@ -336,6 +344,10 @@ func (a APIDefinitionLoader) MakeSpec(def *model.MergedAPI, logger *logrus.Entry
 	}
 
 	spec.GlobalConfig = a.Gw.GetConfig()
+	spec.RetryPolicy = newRetryPolicy(def.Proxy.Retry)
+	spec.OutlierDetector = newOutlierDetector(spec)
+	spec.AdaptiveConcurrency = a.Gw.newAdaptiveConcurrency(spec)
+	spec.WaitQueue = newRateLimitQueue(def.RateLimitQueue)
 
 	if err = a.Gw.loadBundle(spec); err != nil {
 		logger.WithError(err).Error("Couldn't load bundle")
@@ -1049,6 +1061,8 @@ func (a APIDefinitionLoader) compileCachedPathSpec(oldpaths []string, newpaths [
 		newSpec.CacheConfig.CacheKeyRegex = spec.CacheKeyRegex
 		newSpec.CacheConfig.CacheOnlyResponseCodes = spec.CacheOnlyResponseCodes
 		newSpec.CacheConfig.Timeout = spec.Timeout
+		newSpec.CacheConfig.SurrogateKeys = spec.SurrogateKeys
+		newSpec.CacheConfig.CacheKey = spec.CacheKey
 		// Extend with method actions
 		urlSpec = append(urlSpec, newSpec)
 	}
@@ -1189,6 +1203,7 @@ func (a APIDefinitionLoader) compileTimeoutPathSpec(paths []apidef.HardTimeoutMe
 		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
 		// Extend with method actions
 		newSpec.HardTimeout = stringSpec
+		newSpec.hedge = newHedgePolicy(stringSpec.Hedge)
 
 		urlSpec = append(urlSpec, newSpec)
 	}
@@ -1217,11 +1232,15 @@ func (a APIDefinitionLoader) compileRequestSizePathSpec(paths []apidef.RequestSi
 	return urlSpec
 }
 
-func (a APIDefinitionLoader) compileCircuitBreakerPathSpec(paths []apidef.CircuitBreakerMeta, stat URLStatus, apiSpec *APISpec, conf config.Config) []URLSpec {
+func (a APIDefinitionLoader) compileCircuitBreakerPathSpec(paths []apidef.CircuitBreakerMeta, stat URLStatus, apiSpec *APISpec, version string, conf config.Config) []URLSpec {
 	// transform an extended configuration URL into an array of URLSpecs
 	// This way we can iterate the whole array once, on match we break with status
 	urlSpec := []URLSpec{}
 
+	if apiSpec.circuitBreakers == nil {
+		apiSpec.circuitBreakers = newCircuitBreakers(a.Gw, apiSpec)
+	}
+
 	for _, stringSpec := range paths {
 		if stringSpec.Disabled {
 			continue
@@ -1230,70 +1249,13 @@ func (a APIDefinitionLoader) compileCircuitBreakerPathSpec(paths []apidef.Circui
 		newSpec := URLSpec{}
 		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
 		// Extend with method actions
-		newSpec.CircuitBreaker = ExtendedCircuitBreakerMeta{CircuitBreakerMeta: stringSpec}
+		newSpec.CircuitBreaker = ExtendedCircuitBreakerMeta{CircuitBreakerMeta: stringSpec, breakers: apiSpec.circuitBreakers, version: version}
 		log.Debug("Initialising circuit breaker for: ", stringSpec.Path)
-		newSpec.CircuitBreaker.CB = circuit.NewRateBreaker(stringSpec.ThresholdPercent, stringSpec.Samples)
-
-		// override backoff algorithm when is not desired to recheck the upstream before the ReturnToServiceAfter happens
-		if stringSpec.DisableHalfOpenState {
-			newSpec.CircuitBreaker.CB.BackOff = &backoff.StopBackOff{}
-		}
-
-		events := newSpec.CircuitBreaker.CB.Subscribe()
-		go func(path string, spec *APISpec, breakerPtr *circuit.Breaker) {
-			for e := range events {
-				switch e {
-				case circuit.BreakerTripped:
-					log.Warning("[PROXY] [CIRCUIT BREAKER] Breaker tripped for path: ", path)
-					log.Debug("Breaker tripped: ", e)
-
-					go func(timeout int, breaker *circuit.Breaker) {
-						log.Debug("-- Sleeping for (s): ", timeout)
-						time.Sleep(time.Duration(timeout) * time.Second)
-						log.Debug("-- Resetting breaker")
-						breaker.Reset()
-					}(newSpec.CircuitBreaker.ReturnToServiceAfter, breakerPtr)
-
-					if spec.Proxy.ServiceDiscovery.UseDiscoveryService {
-						log.Warning("[PROXY] [CIRCUIT BREAKER] Refreshing host list")
-						a.Gw.ServiceCache.Delete(spec.APIID)
-					}
 
-					spec.FireEvent(EventBreakerTriggered, EventCurcuitBreakerMeta{
-						EventMetaDefault: EventMetaDefault{Message: "Breaker Tripped"},
-						CircuitEvent:     e,
-						Path:             path,
-						APIID:            spec.APIID,
-					})
-
-					spec.FireEvent(EventBreakerTripped, EventCurcuitBreakerMeta{
-						EventMetaDefault: EventMetaDefault{Message: "Breaker Tripped"},
-						CircuitEvent:     e,
-						Path:             path,
-						APIID:            spec.APIID,
-					})
-
-				case circuit.BreakerReset:
-					spec.FireEvent(EventBreakerTriggered, EventCurcuitBreakerMeta{
-						EventMetaDefault: EventMetaDefault{Message: "Breaker Reset"},
-						CircuitEvent:     e,
-						Path:             path,
-						APIID:            spec.APIID,
-					})
-
-					spec.FireEvent(EventBreakerReset, EventCurcuitBreakerMeta{
-						EventMetaDefault: EventMetaDefault{Message: "Breaker Reset"},
-						CircuitEvent:     e,
-						Path:             path,
-						APIID:            spec.APIID,
-					})
-
-				case circuit.BreakerStop:
-					// time to stop this Go-routine
-					return
-				}
-			}
-		}(stringSpec.Path, apiSpec, newSpec.CircuitBreaker.CB)
+		// breakers of upstream hosts are created on their first request
+		if stringSpec.Scope != apidef.CircuitBreakerScopeHost {
+			newSpec.CircuitBreaker.CB = apiSpec.circuitBreakers.get(stringSpec, version, "").Breaker
+		}
 
 		urlSpec = append(urlSpec, newSpec)
 	}
@@ -1500,6 +1462,39 @@ func (a APIDefinitionLoader) compileRateLimitPathsSpec(paths []apidef.RateLimitM
 	return urlSpec
 }
 
+func (a APIDefinitionLoader) compileConcurrencyLimitPathsSpec(paths []apidef.ConcurrencyLimitMeta, stat URLStatus, conf config.Config) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		if stringSpec.Disabled {
+			continue
+		}
+
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		// Extend with method actions
+		newSpec.ConcurrencyLimit = stringSpec
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
+func (a APIDefinitionLoader) compileUpstreamRetryPathsSpec(paths []apidef.UpstreamRetryMeta, stat URLStatus, conf config.Config) []URLSpec {
+	urlSpec := []URLSpec{}
+
+	for _, stringSpec := range paths {
+		newSpec := URLSpec{}
+		a.generateRegex(stringSpec.Path, &newSpec, stat, conf)
+		// Extend with method actions
+		newSpec.UpstreamRetry = stringSpec
+		newSpec.UpstreamRetryPolicy = newRetryPolicy(stringSpec.Retry)
+		urlSpec = append(urlSpec, newSpec)
+	}
+
+	return urlSpec
+}
+
 // compileOASValidateRequestPathSpec extracts ValidateRequest operations from OAS middleware
 // and converts them to URLSpec entries that use the standard regex-based path matching algorithm.
 // This ensures OAS validateRequest middleware respects gateway configurations like
@@ -1990,7 +1985,7 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	headerTransformPaths := a.compileInjectedHeaderSpec(apiVersionDef.ExtendedPaths.TransformHeader, HeaderInjected, conf)
 	headerTransformPathsOnResponse := a.compileInjectedHeaderSpec(apiVersionDef.ExtendedPaths.TransformResponseHeader, HeaderInjectedResponse, conf)
 	hardTimeouts := a.compileTimeoutPathSpec(apiVersionDef.ExtendedPaths.HardTimeouts, HardTimeout, conf)
-	circuitBreakers := a.compileCircuitBreakerPathSpec(apiVersionDef.ExtendedPaths.CircuitBreaker, CircuitBreaker, apiSpec, conf)
+	circuitBreakers := a.compileCircuitBreakerPathSpec(apiVersionDef.ExtendedPaths.CircuitBreaker, CircuitBreaker, apiSpec, apiVersionDef.Name, conf)
 	urlRewrites := a.compileURLRewritesPathSpec(apiVersionDef.ExtendedPaths.URLRewrite, URLRewrite, conf)
 	virtualPaths := a.compileVirtualPathsSpec(apiVersionDef.ExtendedPaths.Virtual, VirtualPath, apiSpec, conf)
 	requestSizes := a.compileRequestSizePathSpec(apiVersionDef.ExtendedPaths.SizeLimit, RequestSizeLimit, conf)
@@ -2002,6 +1997,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	goPlugins := a.compileGopluginPathsSpec(apiVersionDef.ExtendedPaths.GoPlugin, GoPlugin, apiSpec, conf)
 	persistGraphQL := a.compilePersistGraphQLPathSpec(apiVersionDef.ExtendedPaths.PersistGraphQL, PersistGraphQL, apiSpec, conf)
 	rateLimitPaths := a.compileRateLimitPathsSpec(apiVersionDef.ExtendedPaths.RateLimit, RateLimit, conf)
+	concurrencyLimitPaths := a.compileConcurrencyLimitPathsSpec(apiVersionDef.ExtendedPaths.ConcurrencyLimit, ConcurrencyLimit, conf)
+	upstreamRetryPaths := a.compileUpstreamRetryPathsSpec(apiVersionDef.ExtendedPaths.UpstreamRetry, UpstreamRetry, conf)
 
 	// OAS-specific middleware paths - compiled alongside Classic middleware
 	// The compile functions handle nil/empty OAS gracefully by returning empty slices
@@ -2033,6 +2030,8 @@ func (a APIDefinitionLoader) getExtendedPathSpecs(apiVersionDef apidef.VersionIn
 	combinedPath = append(combinedPath, validateJSON...)
 	combinedPath = append(combinedPath, internalPaths...)
 	combinedPath = append(combinedPath, rateLimitPaths...)
+	combinedPath = append(combinedPath, concurrencyLimitPaths...)
+	combinedPath = append(combinedPath, upstreamRetryPaths...)
 	combinedPath = append(combinedPath, oasValidateRequestPaths...)
 	combinedPath = append(combinedPath, oasMockResponsePaths...)
 
@@ -2132,6 +2131,10 @@ func (a *APISpec) getURLStatus(stat URLStatus) RequestStatus {
 		return StatusPersistGraphQL
 	case RateLimit:
 		return StatusRateLimit
+	case ConcurrencyLimit:
+		return StatusConcurrencyLimit
+	case UpstreamRetry:
+		return StatusUpstreamRetry
 	default:
 		log.Error("URL Status was not one of Ignored, Blacklist or WhiteList! Blocking.")
 		return EndPointNotAllowed
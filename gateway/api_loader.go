@@ -374,6 +374,7 @@ func (gw *Gateway) processSpec(
 
 	// Already vetted
 	spec.target, _ = url.Parse(spec.Proxy.TargetURL)
+	spec.trafficMirror = gw.newTrafficMirror(spec, logger)
 
 	var proxy ReturningHttpHandler
 	if enableVersionOverrides {
@@ -614,6 +615,8 @@ func (gw *Gateway) processSpec(
 		gw.mwAppendEnabled(&chainArray, &KeyExpired{baseMid.Copy()})
 		gw.mwAppendEnabled(&chainArray, &AccessRightsCheck{baseMid.Copy()})
 		gw.mwAppendEnabled(&chainArray, &GranularAccessMiddleware{baseMid.Copy()})
+		// requests denied by the policy decision point don't use up rate limits and quotas
+		gw.mwAppendEnabled(&chainArray, &ExternalAuthorizationMiddleware{BaseMiddleware: baseMid.Copy()})
 		gw.mwAppendEnabled(&chainArray, &RateLimitAndQuotaCheck{baseMid.Copy()})
 	}
 
@@ -622,6 +625,10 @@ func (gw *Gateway) processSpec(
 		gw.mwAppendEnabled(&chainArray, &MCPAccessControlMiddleware{baseMid.Copy()})
 	}
 
+	if spec.UseKeylessAccess {
+		gw.mwAppendEnabled(&chainArray, &ExternalAuthorizationMiddleware{BaseMiddleware: baseMid.Copy()})
+	}
+
 	gw.mwAppendEnabled(&chainArray, &OAuth2Middleware{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, getOAuth2ExchangeMw(baseMid.Copy()))
 	gw.mwAppendEnabledForRequest(
@@ -631,6 +638,7 @@ func (gw *Gateway) processSpec(
 	)
 
 	gw.mwAppendEnabled(&chainArray, &RateLimitForAPI{BaseMiddleware: baseMid.Copy(), quotaKey: options.quotaKey})
+	gw.mwAppendEnabled(&chainArray, &ConcurrencyLimitCheck{BaseMiddleware: baseMid.Copy()})
 	gw.mwAppendEnabled(&chainArray, &GraphQLMiddleware{BaseMiddleware: baseMid.Copy()})
 
 	if streamMw := getStreamingMiddleware(baseMid); streamMw != nil {
@@ -1489,6 +1497,9 @@ func (gw *Gateway) loadApps(specs []*APISpec) {
 
 	mainLog.Debug("Checker host Done")
 
+	// Order the certificates of new API domains
+	gw.renewACMECertificates()
+
 	mainLog.Info("Initialised API Definitions")
 
 	gwListenPort := gw.GetConfig().ListenPort
@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultCoalescingTimeout is how long coalesced requests wait for the
+	// response of the request fetching it when no timeout is configured.
+	defaultCoalescingTimeout = 5 * time.Second
+	// coalescingPollInterval is how often requests waiting for another
+	// gateway check the cache for its response.
+	coalescingPollInterval = 50 * time.Millisecond
+	// coalescingLockPrefix prefixes the Redis locks held by the gateway
+	// fetching a cache entry when coalescing is distributed.
+	coalescingLockPrefix = "cache-coalescing-"
+)
+
+// errNotCoalesced is returned when a cache miss must be fetched from the upstream.
+var errNotCoalesced = errors.New("request not coalesced")
+
+// cacheLocker is implemented by cache stores supporting distributed locks.
+type cacheLocker interface {
+	Lock(key string, timeout time.Duration) (bool, error)
+}
+
+// cacheFlight tracks the request fetching a missing cache entry. done is
+// closed once the request finished, payload holds the entry it cached.
+type cacheFlight struct {
+	done    chan struct{}
+	payload string
+	locked  bool
+}
+
+// coalescingTimeout returns how long coalesced requests wait for the request
+// fetching their response.
+func (m *RedisCacheMiddleware) coalescingTimeout() time.Duration {
+	if timeout := time.Duration(m.Spec.CacheOptions.RequestCoalescing.Timeout); timeout > 0 {
+		return timeout
+	}
+	return defaultCoalescingTimeout
+}
+
+// coalesce handles a cache miss when request coalescing is enabled. The first
+// request missing an entry becomes the leader and continues to the upstream,
+// concurrent requests wait for the payload it caches. errNotCoalesced is
+// returned when the request must go to the upstream, either as the leader or
+// because no payload was cached in time.
+func (m *RedisCacheMiddleware) coalesce(r *http.Request, options *cacheOptions) (string, error) {
+	conf := m.Spec.CacheOptions.RequestCoalescing
+	if !conf.Enabled {
+		return "", errNotCoalesced
+	}
+
+	timeout := m.coalescingTimeout()
+
+	flight := &cacheFlight{done: make(chan struct{})}
+	if existing, loaded := m.flights.LoadOrStore(options.key, flight); loaded {
+		return m.waitForFlight(r, existing.(*cacheFlight), timeout)
+	}
+
+	if conf.Distributed {
+		if locker, ok := m.store.(cacheLocker); ok {
+			locked, err := locker.Lock(coalescingLockPrefix+options.key, timeout)
+			if err != nil {
+				m.Logger().WithError(err).Warning("Could not acquire request coalescing lock")
+			}
+
+			if err == nil && !locked {
+				// another gateway is fetching the entry, share its payload with local requests
+				payload, err := m.waitForStore(r, options.key, timeout)
+				flight.payload = payload
+				m.finishFlight(options.key, flight)
+				return payload, err
+			}
+
+			flight.locked = locked
+		}
+	}
+
+	options.flight = flight
+	return "", errNotCoalesced
+}
+
+// waitForFlight waits for the leader of a flight to cache its payload.
+func (m *RedisCacheMiddleware) waitForFlight(r *http.Request, flight *cacheFlight, timeout time.Duration) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-flight.done:
+		if flight.payload == "" {
+			return "", errNotCoalesced
+		}
+		return flight.payload, nil
+	case <-timer.C:
+		m.Logger().Debug("Timed out waiting for coalesced request")
+		return "", errNotCoalesced
+	case <-r.Context().Done():
+		return "", r.Context().Err()
+	}
+}
+
+// waitForStore polls the cache store until another gateway caches key.
+func (m *RedisCacheMiddleware) waitForStore(r *http.Request, key string, timeout time.Duration) (string, error) {
+	ticker := time.NewTicker(coalescingPollInterval)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ticker.C:
+			if payload, err := m.store.GetKey(key); err == nil {
+				return payload, nil
+			}
+		case <-r.Context().Done():
+			return "", r.Context().Err()
+		}
+	}
+
+	m.Logger().Debug("Timed out waiting for coalesced request on another gateway")
+	return "", errNotCoalesced
+}
+
+// finishFlight releases the requests waiting for flight.
+func (m *RedisCacheMiddleware) finishFlight(key string, flight *cacheFlight) {
+	m.flights.CompareAndDelete(key, flight)
+	close(flight.done)
+}
+
+// finishRequest releases the requests coalesced on r once it has been
+// handled, and the distributed lock it holds.
+func (m *RedisCacheMiddleware) finishRequest(r *http.Request) {
+	options := ctxGetCacheOptions(r)
+	if options == nil || options.flight == nil {
+		return
+	}
+
+	m.finishFlight(options.key, options.flight)
+
+	if options.flight.locked {
+		m.store.DeleteRawKey(coalescingLockPrefix + options.key)
+	}
+}
@@ -0,0 +1,133 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestRedisCacheMiddleware_RequestCoalescing(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("coalesced"))
+	}))
+	defer upstream.Close()
+
+	load := func(listenPath string, coalescing apidef.RequestCoalescingConfig) {
+		hits.Store(0)
+
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = listenPath
+			spec.Proxy.TargetURL = upstream.URL
+			spec.CacheOptions.EnableCache = true
+			spec.CacheOptions.CacheAllSafeRequests = true
+			spec.CacheOptions.CacheTimeout = 60
+			spec.CacheOptions.RequestCoalescing = coalescing
+		})
+	}
+
+	concurrently := func(t *testing.T, path string, n int) {
+		t.Helper()
+
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = ts.Run(t, test.TestCase{Path: path, Code: http.StatusOK, BodyMatch: "coalesced"})
+			}()
+		}
+		wg.Wait()
+	}
+
+	t.Run("coalesced", func(t *testing.T) {
+		load("/coalesced/", apidef.RequestCoalescingConfig{Enabled: true})
+		concurrently(t, "/coalesced/", 10)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("distributed", func(t *testing.T) {
+		load("/coalesced-distributed/", apidef.RequestCoalescingConfig{Enabled: true, Distributed: true})
+		concurrently(t, "/coalesced-distributed/", 10)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("locked by another gateway", func(t *testing.T) {
+		const apiID = "coalesced-locked"
+		hits.Store(0)
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = apiID
+			spec.Proxy.ListenPath = "/coalesced-locked/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.CacheOptions.EnableCache = true
+			spec.CacheOptions.CacheAllSafeRequests = true
+			spec.CacheOptions.CacheTimeout = 60
+			spec.CacheOptions.RequestCoalescing = apidef.RequestCoalescingConfig{Enabled: true, Distributed: true}
+		})
+
+		require.Eventually(t, func() bool { return isCached(ts, "/coalesced-locked/") }, 5*time.Second, 10*time.Millisecond)
+
+		// take the entry away and lock it, as another gateway fetching it would
+		store := ts.Gw.apiCacheStore(apiID)
+		var key string
+		for _, cached := range store.GetKeys("") {
+			if strings.HasPrefix(cached, apiID) {
+				key = cached
+			}
+		}
+		require.NotEmpty(t, key)
+
+		payload, err := store.GetKey(key)
+		require.NoError(t, err)
+		store.DeleteKey(key)
+
+		locked, err := store.(cacheLocker).Lock(coalescingLockPrefix+key, 5*time.Second)
+		require.NoError(t, err)
+		require.True(t, locked)
+		defer store.DeleteRawKey(coalescingLockPrefix + key)
+
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			assert.NoError(t, store.SetKey(key, payload, 60))
+		}()
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/coalesced-locked/",
+			Code:         http.StatusOK,
+			BodyMatch:    "coalesced",
+			HeadersMatch: map[string]string{cachedResponseHeader: "1"},
+		})
+		assert.Equal(t, int32(1), hits.Load())
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		load("/coalesced-timeout/", apidef.RequestCoalescingConfig{
+			Enabled: true,
+			Timeout: tyktime.ReadableDuration(10 * time.Millisecond),
+		})
+		concurrently(t, "/coalesced-timeout/", 5)
+		assert.Greater(t, hits.Load(), int32(1))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		load("/not-coalesced/", apidef.RequestCoalescingConfig{})
+		concurrently(t, "/not-coalesced/", 5)
+		assert.Greater(t, hits.Load(), int32(1))
+	})
+}
@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/internal/compression"
+)
+
+const (
+	// compressedPayloadPrefix marks cache entries holding a Zstd compressed
+	// response, it carries the version of the entry format.
+	compressedPayloadPrefix = "zstd1|"
+	// defaultCacheCompressionMinSize is the size from which responses are
+	// compressed when no threshold is configured.
+	defaultCacheCompressionMinSize = 1024
+)
+
+// compressPayload encodes the payload of an entry with the given timestamp as
+// `zstd1|<timestamp>|<compressed payload>`. It returns false if compression
+// is disabled, fails, or the payload is below the compression threshold.
+func (m *ResponseCacheMiddleware) compressPayload(payload []byte, timestamp string) (string, bool) {
+	conf := m.Gw.GetConfig().ResponseCacheCompression
+	if !conf.Enabled {
+		return "", false
+	}
+
+	minSize := conf.MinSize
+	if minSize <= 0 {
+		minSize = defaultCacheCompressionMinSize
+	}
+
+	if len(payload) < minSize {
+		return "", false
+	}
+
+	compressed, err := compression.CompressZstd(payload)
+	if err != nil {
+		m.logger().WithError(err).Warning("could not compress cached response")
+		return "", false
+	}
+
+	return compressedPayloadPrefix + timestamp + "|" + string(compressed), true
+}
+
+// decompressPayload decodes the payload and timestamp of a compressed entry,
+// without its prefix.
+func decompressPayload(payload string) (string, string, error) {
+	timestamp, compressed, ok := strings.Cut(payload, "|")
+	if !ok {
+		return "", "", errors.New("Decoding failed, invalid compressed entry")
+	}
+
+	data, err := compression.DecompressZstd([]byte(compressed))
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(data), timestamp, nil
+}
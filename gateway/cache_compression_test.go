@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/compression"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestDecodeCompressedPayload(t *testing.T) {
+	mw := &RedisCacheMiddleware{BaseMiddleware: &BaseMiddleware{}}
+
+	compressed, err := compression.CompressZstd([]byte("testing\n"))
+	require.NoError(t, err)
+
+	data, timestamp, err := mw.decodePayload(compressedPayloadPrefix + "123:30:0|" + string(compressed))
+	require.NoError(t, err)
+	assert.Equal(t, "testing\n", data)
+	assert.Equal(t, "123:30:0", timestamp)
+
+	for _, invalid := range []string{compressedPayloadPrefix + "123", compressedPayloadPrefix + "123|invalid"} {
+		_, _, err := mw.decodePayload(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestResponseCacheMiddleware_Compression(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.ResponseCacheCompression.Enabled = true
+		globalConf.ResponseCacheCompression.MinSize = 512
+	})
+	defer ts.Close()
+
+	large := strings.Repeat(`{"field":"value"}`, 100)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large" {
+			_, _ = w.Write([]byte(large))
+			return
+		}
+		_, _ = w.Write([]byte("small"))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "compressed-cache"
+		spec.Proxy.ListenPath = "/compressed/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheAllSafeRequests = true
+		spec.CacheOptions.CacheTimeout = 60
+	})
+
+	for _, path := range []string{"/compressed/large", "/compressed/small"} {
+		require.Eventually(t, func() bool { return isCached(ts, path) }, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// only the response above the threshold is stored compressed
+	var compressedEntries, entries int
+	store := ts.Gw.apiCacheStore("compressed-cache")
+	for _, key := range store.GetKeys("") {
+		payload, err := store.GetKey(key)
+		if err != nil {
+			// index sets
+			continue
+		}
+
+		entries++
+		if strings.HasPrefix(payload, compressedPayloadPrefix) {
+			compressedEntries++
+		}
+	}
+	assert.Equal(t, 2, entries)
+	assert.Equal(t, 1, compressedEntries)
+
+	_, _ = ts.Run(t, test.TestCase{
+		Path:         "/compressed/large",
+		Code:         http.StatusOK,
+		BodyMatch:    `"field":"value"`,
+		HeadersMatch: map[string]string{cachedResponseHeader: "1"},
+	})
+}
@@ -0,0 +1,144 @@
+package gateway
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+)
+
+// varySuffix suffixes the cache key holding the Vary header names of a response.
+const varySuffix = "-vary"
+
+// composeCacheKey creates the cache key of r from the cache key spec of its
+// endpoint. token is the auth token, or the client IP of keyless requests.
+func (m *RedisCacheMiddleware) composeCacheKey(r *http.Request, token string, spec apidef.CacheKeyConfig, regex string) (string, error) {
+	parts := []string{r.Method, r.URL.Path, cacheKeyQuery(r.URL.RawQuery, spec.QueryParams)}
+
+	for _, component := range spec.Components {
+		switch component {
+		case apidef.CacheKeySession:
+			parts = append(parts, "session:"+token)
+		case apidef.CacheKeyOrg:
+			var orgID string
+			if session := ctxGetSession(r); session != nil {
+				orgID = session.OrgID
+			}
+			parts = append(parts, "org:"+orgID)
+		}
+	}
+
+	for _, name := range spec.Headers {
+		parts = append(parts, "header:"+name+"="+r.Header.Get(name))
+	}
+
+	for _, name := range spec.Cookies {
+		var value string
+		if cookie, err := r.Cookie(name); err == nil {
+			value = cookie.Value
+		}
+		parts = append(parts, "cookie:"+name+"="+value)
+	}
+
+	contextData := ctxGetData(r)
+	for _, name := range spec.Claims {
+		var value string
+		if claim, ok := contextData["jwt_claims_"+name]; ok {
+			value = fmt.Sprint(claim)
+		}
+		parts = append(parts, "claim:"+name+"="+value)
+	}
+
+	h := md5.New()
+	if _, err := io.WriteString(h, strings.Join(parts, "\n")); err != nil {
+		return "", err
+	}
+
+	if err := addBodyHash(r, regex, h); err != nil {
+		return "", err
+	}
+
+	return m.Spec.APIID + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cacheKeyQuery returns the query parameters of rawQuery selected by params.
+func cacheKeyQuery(rawQuery string, params apidef.CacheKeyQueryParams) string {
+	var selected []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+
+		if len(params.Include) > 0 && !slices.Contains(params.Include, name) {
+			continue
+		}
+
+		if slices.Contains(params.Exclude, name) {
+			continue
+		}
+
+		selected = append(selected, param)
+	}
+
+	if params.Sort {
+		sort.Strings(selected)
+	}
+
+	return strings.Join(selected, "&")
+}
+
+// varyCacheKey returns the cache key of the Vary variant of the response of
+// r, as recorded by a previous response, and prepares options for storing
+// the variant of the upstream response.
+func (m *RedisCacheMiddleware) varyCacheKey(r *http.Request, options *cacheOptions) string {
+	options.varyBase = options.key
+	options.varyHeader = r.Header.Clone()
+
+	vary, err := m.store.GetKey(options.key + varySuffix)
+	if err != nil || vary == "" {
+		return options.key
+	}
+
+	options.key = varyKey(options.varyBase, strings.Split(vary, ","), r.Header)
+	return options.key
+}
+
+// varyKey returns the cache key of the variant of the response cached under
+// key for the values of the Vary header names in h.
+func varyKey(key string, names []string, h http.Header) string {
+	hash := md5.New()
+	for _, name := range names {
+		_, _ = io.WriteString(hash, name+"="+strings.Join(h.Values(name), ",")+"\n")
+	}
+
+	return key + "-" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// varyHeaderNames returns the sorted, canonical header names of the Vary
+// header of a response.
+func varyHeaderNames(h http.Header) []string {
+	var names []string
+	for _, value := range h.Values(header.Vary) {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return slices.Compact(names)
+}
@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestCacheKeyQuery(t *testing.T) {
+	const rawQuery = "b=2&utm_source=x&a=1&a=0"
+
+	assert.Equal(t, rawQuery, cacheKeyQuery(rawQuery, apidef.CacheKeyQueryParams{}))
+	assert.Equal(t, "a=0&a=1&b=2", cacheKeyQuery(rawQuery, apidef.CacheKeyQueryParams{
+		Exclude: []string{"utm_source"},
+		Sort:    true,
+	}))
+	assert.Equal(t, "b=2", cacheKeyQuery(rawQuery, apidef.CacheKeyQueryParams{Include: []string{"b"}}))
+	assert.Empty(t, cacheKeyQuery("", apidef.CacheKeyQueryParams{}))
+}
+
+func TestVaryHeaderNames(t *testing.T) {
+	h := http.Header{}
+	h.Add(header.Vary, "accept-language, Accept-Encoding")
+	h.Add(header.Vary, "Accept-Language")
+
+	assert.Equal(t, []string{"Accept-Encoding", "Accept-Language"}, varyHeaderNames(h))
+	assert.Empty(t, varyHeaderNames(http.Header{}))
+
+	en := http.Header{"Accept-Language": []string{"en"}}
+	fr := http.Header{"Accept-Language": []string{"fr"}}
+	names := []string{"Accept-Language"}
+	assert.Equal(t, varyKey("key", names, en), varyKey("key", names, en.Clone()))
+	assert.NotEqual(t, varyKey("key", names, en), varyKey("key", names, fr))
+}
+
+func TestRedisCacheMiddleware_CacheKey(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.Vary, "Accept-Language")
+		_, _ = w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/cache-key/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheTimeout = 60
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.AdvanceCacheConfig = []apidef.CacheMeta{
+				{
+					Method: http.MethodGet,
+					Path:   "/shared",
+					CacheKey: apidef.CacheKeyConfig{
+						Enabled:     true,
+						QueryParams: apidef.CacheKeyQueryParams{Exclude: []string{"utm_source"}, Sort: true},
+					},
+				},
+				{
+					Method: http.MethodGet,
+					Path:   "/vary",
+					CacheKey: apidef.CacheKeyConfig{
+						Enabled:    true,
+						HonourVary: true,
+					},
+				},
+			}
+		})
+	})
+
+	t.Run("normalised query", func(t *testing.T) {
+		require.Eventually(t, func() bool { return isCached(ts, "/cache-key/shared?a=1&b=2&utm_source=x") }, 5*time.Second, 10*time.Millisecond)
+		assert.True(t, isCached(ts, "/cache-key/shared?b=2&a=1"))
+		assert.False(t, isCached(ts, "/cache-key/shared?a=2"))
+	})
+
+	t.Run("vary", func(t *testing.T) {
+		language := func(lang string) test.TestCase {
+			return test.TestCase{Path: "/cache-key/vary", Headers: map[string]string{"Accept-Language": lang}}
+		}
+
+		cachedBody := func(lang string) func() bool {
+			return func() bool {
+				resp, err := ts.Do(language(lang))
+				if err != nil {
+					return false
+				}
+				defer resp.Body.Close()
+
+				return resp.Header.Get(cachedResponseHeader) == "1"
+			}
+		}
+
+		require.Eventually(t, cachedBody("en"), 5*time.Second, 10*time.Millisecond)
+
+		tc := language("fr")
+		tc.BodyMatch = "^fr$"
+		_, _ = ts.Run(t, tc)
+
+		require.Eventually(t, cachedBody("fr"), 5*time.Second, 10*time.Millisecond)
+
+		tc = language("en")
+		tc.BodyMatch = "^en$"
+		tc.HeadersMatch = map[string]string{cachedResponseHeader: "1"}
+		_, _ = ts.Run(t, tc)
+	})
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/cache"
+)
+
+// defaultResponseCacheL1MaxBytes bounds the in-memory response cache tier
+// when no size is configured.
+const defaultResponseCacheL1MaxBytes = 64 << 20
+
+// responseCacheL1 is an in-memory tier in front of the Redis response cache.
+// It holds parsed fresh responses, so that hits skip the Redis round trip and
+// the decoding of the cached payload. A nil *responseCacheL1 is disabled.
+type responseCacheL1 struct {
+	lru *cache.SizedLRU
+}
+
+// l1Response is a parsed cached response.
+type l1Response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+func newResponseCacheL1(conf config.ResponseCacheL1Config) *responseCacheL1 {
+	if !conf.Enabled {
+		return nil
+	}
+
+	maxBytes := conf.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultResponseCacheL1MaxBytes
+	}
+
+	return &responseCacheL1{lru: cache.NewSizedLRU(maxBytes)}
+}
+
+// get returns the response of r cached under key.
+func (c *responseCacheL1) get(r *http.Request, key string) (*http.Response, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	value, ok := c.lru.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	cached := value.(*l1Response)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.statusCode, http.StatusText(cached.statusCode)),
+		StatusCode:    cached.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cached.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(cached.body)),
+		ContentLength: int64(len(cached.body)),
+		Request:       r,
+	}, true
+}
+
+// set caches res under key until it expires. The body of res is read and
+// replaced so that it can still be written out.
+func (c *responseCacheL1) set(key string, res *http.Response, expires time.Time) {
+	if c == nil {
+		return
+	}
+
+	ttl := time.Until(expires)
+	if ttl <= 0 {
+		return
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	cached := &l1Response{
+		statusCode: res.StatusCode,
+		header:     res.Header.Clone(),
+		body:       body,
+	}
+
+	size := int64(len(key) + len(body))
+	for name, values := range cached.header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+
+	c.lru.Set(key, cached, size, ttl)
+}
+
+// delete removes the responses cached under keys.
+func (c *responseCacheL1) delete(keys ...string) {
+	if c == nil {
+		return
+	}
+
+	for _, key := range keys {
+		c.lru.Delete(key)
+	}
+}
+
+// invalidate removes the cached responses of an API.
+func (c *responseCacheL1) invalidate(apiID string) {
+	if c == nil {
+		return
+	}
+
+	c.lru.DeletePrefix(apiID)
+}
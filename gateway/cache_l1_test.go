@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/storage"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestResponseCacheL1(t *testing.T) {
+	assert.Nil(t, newResponseCacheL1(config.ResponseCacheL1Config{}))
+
+	l1 := newResponseCacheL1(config.ResponseCacheL1Config{Enabled: true})
+	require.NotNil(t, l1)
+
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader("cached")),
+	}
+	l1.set("api1-key", res, time.Now().Add(time.Minute))
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "cached", string(body), "body is restored")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for i := 0; i < 2; i++ {
+		cached, ok := l1.get(r, "api1-key")
+		require.True(t, ok)
+		assert.Equal(t, http.StatusOK, cached.StatusCode)
+		assert.Equal(t, "text/plain", cached.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(cached.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "cached", string(body))
+	}
+
+	l1.invalidate("api1")
+	_, ok := l1.get(r, "api1-key")
+	assert.False(t, ok)
+
+	l1.set("api1-expired", res, time.Now().Add(-time.Second))
+	_, ok = l1.get(r, "api1-expired")
+	assert.False(t, ok)
+}
+
+func TestRedisCacheMiddleware_L1(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.ResponseCacheL1.Enabled = true
+	})
+	defer ts.Close()
+
+	api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "l1-cache"
+		spec.Proxy.ListenPath = "/l1/"
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheAllSafeRequests = true
+		spec.CacheOptions.CacheTimeout = 60
+	})[0]
+
+	require.Eventually(t, func() bool {
+		resp, err := ts.Do(test.TestCase{Path: "/l1/"})
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+
+		return resp.Header.Get(cachedResponseHeader) == "1"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, ts.Gw.responseCacheL1.lru.Count())
+
+	_, _ = ts.Run(t, test.TestCase{
+		Path:         "/l1/",
+		Code:         http.StatusOK,
+		HeadersMatch: map[string]string{cachedResponseHeader: "1"},
+	})
+
+	_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: "/tyk/cache/" + api.APIID, AdminAuth: true, Code: http.StatusOK})
+	assert.Equal(t, 0, ts.Gw.responseCacheL1.lru.Count())
+}
+
+func TestGateway_invalidateAPICacheOnce(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	const apiID = "invalidate-once"
+	lockKey := apiCacheInvalidationLockPrefix + apiID
+
+	store := storage.RedisCluster{IsCache: true, ConnectionHandler: ts.Gw.StorageConnectionHandler}
+	store.Connect()
+	require.NoError(t, store.SetKey("cache-"+apiID+"-entry", "payload", 60))
+
+	// another gateway is invalidating the Redis cache
+	locked, err := store.Lock(lockKey, time.Minute)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	assert.True(t, ts.Gw.invalidateAPICacheOnce(apiID))
+	_, err = store.GetKey("cache-" + apiID + "-entry")
+	assert.NoError(t, err, "entries are left to the gateway holding the lock")
+
+	store.DeleteRawKey(lockKey)
+
+	assert.True(t, ts.Gw.invalidateAPICacheOnce(apiID))
+	_, err = store.GetKey("cache-" + apiID + "-entry")
+	assert.Error(t, err)
+
+	locked, err = store.Lock(lockKey, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, locked, "the lock is released after the invalidation")
+	store.DeleteRawKey(lockKey)
+}
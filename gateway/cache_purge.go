@@ -0,0 +1,204 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	// surrogateKeyIndexPrefix prefixes the sets of cache keys tagged with a surrogate key.
+	surrogateKeyIndexPrefix = "surrogate-key-"
+	// pathIndexPrefix prefixes the sets of cache keys of a method and path.
+	pathIndexPrefix = "path-"
+	// pathsIndex is the set of the cached methods and paths of an API.
+	pathsIndex = "paths"
+)
+
+// cachePath returns the method and path indexing a cache entry.
+func cachePath(method, path string) string {
+	return method + " " + path
+}
+
+// surrogateKeysFromHeader returns the surrogate keys set by the upstream in
+// the space separated Surrogate-Key or comma separated Cache-Tag headers.
+func surrogateKeysFromHeader(h http.Header) []string {
+	var keys []string
+	for _, value := range h.Values(header.SurrogateKey) {
+		keys = append(keys, strings.Fields(value)...)
+	}
+
+	for _, value := range h.Values(header.CacheTag) {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				keys = append(keys, tag)
+			}
+		}
+	}
+
+	return keys
+}
+
+// indexCacheEntry indexes the cache entry stored under key by its path and
+// surrogate keys, so that it can be purged. The indexes live as long as their
+// longest lived entry.
+func indexCacheEntry(store storage.Handler, key, path string, surrogateKeys []string, ttl int64) {
+	for _, surrogateKey := range surrogateKeys {
+		addToCacheIndex(store, surrogateKeyIndexPrefix+surrogateKey, key, ttl)
+	}
+
+	addToCacheIndex(store, pathIndexPrefix+path, key, ttl)
+	addToCacheIndex(store, pathsIndex, path, ttl)
+}
+
+// cacheIndexStore is implemented by stores adding to a set and extending its
+// expiry atomically.
+type cacheIndexStore interface {
+	AddToSetWithExp(keyName, value string, ttl int64) error
+}
+
+func addToCacheIndex(store storage.Handler, index, member string, ttl int64) {
+	if indexStore, ok := store.(cacheIndexStore); ok {
+		if err := indexStore.AddToSetWithExp(index, member, ttl); err != nil {
+			log.WithError(err).Debug("Could not add to cache index")
+		}
+		return
+	}
+
+	store.AddToSet(index, member)
+
+	if exp, err := store.GetExp(index); err != nil || exp < ttl {
+		if err := store.SetExp(index, ttl); err != nil {
+			log.WithError(err).Debug("Could not set cache index expiry")
+		}
+	}
+}
+
+// purgeCacheIndex deletes the cache entries listed in index, and the index.
+// It returns the keys of the deleted entries.
+func purgeCacheIndex(store storage.Handler, index string) ([]string, error) {
+	members, err := store.GetSet(index)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(members))
+	for _, key := range members {
+		store.DeleteKey(key)
+		keys = append(keys, key)
+	}
+
+	store.DeleteKey(index)
+	return keys, nil
+}
+
+// apiCacheStore returns the cache store of an API.
+func (gw *Gateway) apiCacheStore(apiID string) storage.Handler {
+	store := &storage.RedisCluster{KeyPrefix: "cache-" + apiID, IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
+	store.Connect()
+
+	return store
+}
+
+// purgeCacheBySurrogateKey deletes the cached responses of an API tagged with
+// surrogateKey and returns their keys.
+func (gw *Gateway) purgeCacheBySurrogateKey(apiID, surrogateKey string) ([]string, error) {
+	return purgeCacheIndex(gw.apiCacheStore(apiID), surrogateKeyIndexPrefix+surrogateKey)
+}
+
+// purgeCacheByPath deletes the cached responses of an API for path, or paths
+// starting with path if prefix is set, and returns their keys. An empty
+// method matches all methods.
+func (gw *Gateway) purgeCacheByPath(apiID, method, path string, prefix bool) ([]string, error) {
+	store := gw.apiCacheStore(apiID)
+
+	paths, err := store.GetSet(pathsIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, cached := range paths {
+		cachedMethod, cachedPath, ok := strings.Cut(cached, " ")
+		if !ok || (method != "" && !strings.EqualFold(method, cachedMethod)) {
+			continue
+		}
+
+		if cachedPath != path && (!prefix || !strings.HasPrefix(cachedPath, path)) {
+			continue
+		}
+
+		purged, err := purgeCacheIndex(store, pathIndexPrefix+cached)
+		if err != nil {
+			return keys, err
+		}
+
+		keys = append(keys, purged...)
+		store.RemoveFromSet(pathsIndex, cached)
+	}
+
+	return keys, nil
+}
+
+// purgeResponseCacheL1 drops purged responses from the in-memory cache tier
+// of every gateway.
+func (gw *Gateway) purgeResponseCacheL1(keys []string) {
+	if len(keys) == 0 || !gw.GetConfig().ResponseCacheL1.Enabled {
+		return
+	}
+
+	gw.responseCacheL1.delete(keys...)
+	gw.MainNotifier.Notify(Notification{
+		Command: NoticePurgeAPICacheKeys,
+		Payload: strings.Join(keys, ","),
+		Gw:      gw,
+	})
+}
+
+func (gw *Gateway) purgeCacheByTagHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	keys, err := gw.purgeCacheBySurrogateKey(vars["apiID"], vars["tag"])
+	gw.writeCachePurgeResult(w, vars["apiID"], keys, err)
+}
+
+func (gw *Gateway) purgeCacheByPathHandler(w http.ResponseWriter, r *http.Request) {
+	apiID := mux.Vars(r)["apiID"]
+	query := r.URL.Query()
+
+	path, prefix := query.Get("path"), query.Get("prefix")
+	if (path == "") == (prefix == "") {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Either path or prefix must be set"))
+		return
+	}
+
+	var (
+		keys []string
+		err  error
+	)
+	if path != "" {
+		keys, err = gw.purgeCacheByPath(apiID, query.Get("method"), path, false)
+	} else {
+		keys, err = gw.purgeCacheByPath(apiID, query.Get("method"), prefix, true)
+	}
+
+	gw.writeCachePurgeResult(w, apiID, keys, err)
+}
+
+func (gw *Gateway) writeCachePurgeResult(w http.ResponseWriter, apiID string, keys []string, err error) {
+	gw.cacheUsage.remove(keys...)
+	gw.purgeResponseCacheL1(keys)
+
+	if err != nil {
+		log.WithError(err).WithField("api_id", apiID).Error("Failed to purge cache")
+		doJSONWrite(w, http.StatusInternalServerError, apiError("Cache purge failed"))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, apiOk(fmt.Sprintf("%d cached responses purged", len(keys))))
+}
@@ -0,0 +1,114 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestSurrogateKeysFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Add(header.SurrogateKey, "article-1  articles")
+	h.Add(header.CacheTag, "author-2, ,news")
+
+	assert.Equal(t, []string{"article-1", "articles", "author-2", "news"}, surrogateKeysFromHeader(h))
+	assert.Empty(t, surrogateKeysFromHeader(http.Header{}))
+}
+
+func TestCachePurge(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.SurrogateKey, "articles "+strings.ReplaceAll(strings.Trim(r.URL.Path, "/"), "/", "-"))
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	const apiID = "cache-purge"
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = apiID
+		spec.Proxy.ListenPath = "/purge/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheTimeout = 60
+		spec.EnableContextVars = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.AdvanceCacheConfig = []apidef.CacheMeta{{
+				Method:        http.MethodGet,
+				Path:          "/articles/{id}",
+				SurrogateKeys: []string{"template-$tyk_context.headers_Host"},
+			}}
+		})
+	})
+
+	cache := func(t *testing.T, paths ...string) {
+		t.Helper()
+
+		for _, path := range paths {
+			require.Eventually(t, func() bool { return isCached(ts, path) }, 5*time.Second, 10*time.Millisecond)
+		}
+	}
+
+	purge := func(t *testing.T, path string) {
+		t.Helper()
+
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: "/tyk/cache/" + apiID + path, AdminAuth: true, Code: http.StatusOK})
+	}
+
+	t.Run("by surrogate key", func(t *testing.T) {
+		cache(t, "/purge/articles/1", "/purge/articles/2")
+
+		purge(t, "/tags/articles-1")
+		assert.False(t, isCached(ts, "/purge/articles/1"))
+		assert.True(t, isCached(ts, "/purge/articles/2"))
+	})
+
+	t.Run("by template surrogate key", func(t *testing.T) {
+		cache(t, "/purge/articles/1", "/purge/articles/2")
+
+		purge(t, "/tags/template-"+strings.TrimPrefix(ts.URL, "http://"))
+		assert.False(t, isCached(ts, "/purge/articles/1"))
+		assert.False(t, isCached(ts, "/purge/articles/2"))
+	})
+
+	t.Run("by path", func(t *testing.T) {
+		cache(t, "/purge/articles/1", "/purge/articles/2")
+
+		purge(t, "/paths?method=GET&path=/articles/1")
+		assert.False(t, isCached(ts, "/purge/articles/1"))
+		assert.True(t, isCached(ts, "/purge/articles/2"))
+	})
+
+	t.Run("by path prefix", func(t *testing.T) {
+		cache(t, "/purge/articles/1", "/purge/articles/2")
+
+		purge(t, "/paths?prefix=/articles/")
+		assert.False(t, isCached(ts, "/purge/articles/1"))
+		assert.False(t, isCached(ts, "/purge/articles/2"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Method: http.MethodDelete, Path: "/tyk/cache/" + apiID + "/paths", AdminAuth: true, Code: http.StatusBadRequest})
+	})
+}
+
+// isCached requests path and returns true if it was served from the cache.
+func isCached(ts *Test, path string) bool {
+	resp, err := ts.Do(test.TestCase{Path: path})
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.Header.Get(cachedResponseHeader) == "1"
+}
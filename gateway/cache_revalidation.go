@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+// hasValidators returns true if h carries an ETag or Last-Modified validator.
+func hasValidators(h http.Header) bool {
+	return h.Get(header.ETag) != "" || h.Get(header.LastModified) != ""
+}
+
+// revalidationWindow returns how long a response with the headers h is kept
+// after it expires, to be revalidated with the upstream.
+func (m *ResponseCacheMiddleware) revalidationWindow(h http.Header) int64 {
+	if !hasValidators(h) {
+		return 0
+	}
+	return m.Spec.CacheOptions.RevalidationWindow
+}
+
+// conditional turns r into a conditional request revalidating the expired
+// cached response, if it carries validators. It returns false if the cached
+// response can't be revalidated.
+func (m *RedisCacheMiddleware) conditional(r *http.Request, options *cacheOptions, cached string) bool {
+	if m.Spec.CacheOptions.RevalidationWindow <= 0 || !isSafeMethod(r.Method) {
+		return false
+	}
+
+	// the client revalidates its own copy
+	if r.Header.Get(header.IfNoneMatch) != "" || r.Header.Get(header.IfModifiedSince) != "" {
+		return false
+	}
+
+	res, err := http.ReadResponse(bufio.NewReader(strings.NewReader(cached)), r)
+	if err != nil {
+		return false
+	}
+	res.Body.Close()
+
+	if !hasValidators(res.Header) {
+		return false
+	}
+
+	if etag := res.Header.Get(header.ETag); etag != "" {
+		r.Header.Set(header.IfNoneMatch, etag)
+	}
+
+	if lastModified := res.Header.Get(header.LastModified); lastModified != "" {
+		r.Header.Set(header.IfModifiedSince, lastModified)
+	}
+
+	options.revalidating = cached
+	return true
+}
+
+// replaceWithRevalidated replaces the 304 response res of a revalidation with
+// the cached response, updated with the headers of res.
+func (m *ResponseCacheMiddleware) replaceWithRevalidated(res *http.Response, r *http.Request, cached string) {
+	cachedRes, err := readCachedResponse(r, cached, "")
+	if err != nil {
+		m.logger().WithError(err).Error("could not create revalidated response object")
+		return
+	}
+
+	m.logger().Debug("Upstream revalidated the cached response")
+
+	cachedRes.Header.Del(cachedResponseHeader)
+	for name, values := range res.Header {
+		switch name {
+		case header.ContentLength, header.ContentEncoding, header.TransferEncoding:
+			continue
+		}
+		cachedRes.Header[name] = values
+	}
+
+	res.Body.Close()
+	res.Status = cachedRes.Status
+	res.StatusCode = cachedRes.StatusCode
+	res.Header = cachedRes.Header
+	res.Body = cachedRes.Body
+	res.ContentLength = cachedRes.ContentLength
+	res.Trailer = cachedRes.Trailer
+}
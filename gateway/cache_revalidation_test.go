@@ -0,0 +1,68 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestRedisCacheMiddleware_Revalidation(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var full, notModified atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(header.ETag, `"v1"`)
+		if r.Header.Get(header.IfNoneMatch) == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		full.Add(1)
+		_, _ = w.Write([]byte("large payload"))
+	}))
+	defer upstream.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/revalidated/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CacheOptions.EnableCache = true
+		spec.CacheOptions.CacheAllSafeRequests = true
+		spec.CacheOptions.CacheTimeout = 1
+		spec.CacheOptions.RevalidationWindow = 60
+	})
+
+	require.Eventually(t, func() bool { return isCached(ts, "/revalidated/") }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(1100 * time.Millisecond)
+
+	_, _ = ts.Run(t, test.TestCase{
+		Path:      "/revalidated/",
+		Code:      http.StatusOK,
+		BodyMatch: "large payload",
+	})
+	assert.Equal(t, int32(1), full.Load())
+	assert.Equal(t, int32(1), notModified.Load())
+
+	// the revalidated entry is cached again
+	assert.Eventually(t, func() bool { return isCached(ts, "/revalidated/") }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), full.Load())
+
+	t.Run("client validators are forwarded", func(t *testing.T) {
+		time.Sleep(1100 * time.Millisecond)
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path:    "/revalidated/",
+			Headers: map[string]string{header.IfNoneMatch: `"v1"`},
+			Code:    http.StatusNotModified,
+		})
+	})
+}
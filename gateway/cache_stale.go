@@ -0,0 +1,210 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/tyk/header"
+)
+
+const (
+	// staleWarning is added to stale responses served while the cache entry
+	// is refreshed, as per RFC 5861.
+	staleWarning = `110 - "Response is Stale"`
+	// revalidationFailedWarning is added to stale responses served because
+	// the upstream failed, as per RFC 5861.
+	revalidationFailedWarning = `111 - "Revalidation Failed"`
+)
+
+// backgroundRefresher is implemented by middleware that respond early but
+// need the rest of the chain to run in the background, e.g. to refresh a
+// stale cache entry. refresh returns nil if there is nothing to refresh.
+type backgroundRefresher interface {
+	refresh(r *http.Request) func(next http.Handler)
+}
+
+// cacheExpiry holds when a cache entry expires and how long it can be served
+// stale afterwards.
+type cacheExpiry struct {
+	expires              time.Time
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+}
+
+// parseCacheExpiry parses the timestamp of a cache entry: the unix time it
+// expires at, optionally followed by its stale-while-revalidate and
+// stale-if-error windows in seconds, e.g. `1700000000:30:600`.
+func parseCacheExpiry(timestamp string) (cacheExpiry, error) {
+	parts := strings.Split(timestamp, ":")
+	if len(parts) != 1 && len(parts) != 3 {
+		return cacheExpiry{}, errors.New("invalid cache entry timestamp")
+	}
+
+	values := make([]int64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return cacheExpiry{}, err
+		}
+		values[i] = value
+	}
+
+	expiry := cacheExpiry{expires: time.Unix(values[0], 0)}
+	if len(values) == 3 {
+		expiry.staleWhileRevalidate = time.Duration(values[1]) * time.Second
+		expiry.staleIfError = time.Duration(values[2]) * time.Second
+	}
+
+	return expiry, nil
+}
+
+// formatCacheExpiry formats the timestamp of a cache entry expiring at the
+// unix time expires, with the given stale windows in seconds.
+func formatCacheExpiry(expires, staleWhileRevalidate, staleIfError int64) string {
+	if staleWhileRevalidate <= 0 && staleIfError <= 0 {
+		return fmt.Sprint(expires)
+	}
+	return fmt.Sprintf("%d:%d:%d", expires, staleWhileRevalidate, staleIfError)
+}
+
+func (e cacheExpiry) expired(now time.Time) bool {
+	return e.expires.Before(now)
+}
+
+// staleWhileRevalidating returns true if the expired entry can be served
+// while it is refreshed.
+func (e cacheExpiry) staleWhileRevalidating(now time.Time) bool {
+	return now.Before(e.expires.Add(e.staleWhileRevalidate))
+}
+
+// staleIfErrorAllowed returns true if the expired entry can be served when
+// the upstream fails.
+func (e cacheExpiry) staleIfErrorAllowed(now time.Time) bool {
+	return now.Before(e.expires.Add(e.staleIfError))
+}
+
+// staleDirectives returns the stale-while-revalidate and stale-if-error
+// directives of a Cache-Control header value in seconds, or the given
+// defaults for directives that aren't set.
+func staleDirectives(cacheControl string, staleWhileRevalidate, staleIfError int64) (int64, int64) {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok {
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64)
+		if err != nil || seconds < 0 {
+			continue
+		}
+
+		switch strings.ToLower(name) {
+		case "stale-while-revalidate":
+			staleWhileRevalidate = seconds
+		case "stale-if-error":
+			staleIfError = seconds
+		}
+	}
+
+	return staleWhileRevalidate, staleIfError
+}
+
+// readCachedResponse creates the response of r from the cached wire format
+// response, marked as cached and carrying warning if set.
+func readCachedResponse(r *http.Request, cached string, warning string) (*http.Response, error) {
+	res, err := http.ReadResponse(bufio.NewReader(strings.NewReader(cached)), r)
+	if err != nil {
+		return nil, err
+	}
+
+	nopCloseResponseBody(res)
+
+	for _, h := range hopHeaders {
+		res.Header.Del(h)
+	}
+
+	res.Header.Set(cachedResponseHeader, "1")
+	if warning != "" {
+		res.Header.Add(header.Warning, warning)
+	}
+
+	return res, nil
+}
+
+// refresh runs the rest of the chain for a request served from a stale cache
+// entry in the background, so that the response cache stores a fresh entry.
+// Only one refresh runs at a time for each entry.
+func (m *RedisCacheMiddleware) refresh(r *http.Request) func(next http.Handler) {
+	options := ctxGetCacheOptions(r)
+	if options == nil || !options.revalidate {
+		return nil
+	}
+
+	if _, running := m.refreshing.LoadOrStore(options.key, struct{}{}); running {
+		return nil
+	}
+
+	refreshReq := r.Clone(context.WithoutCancel(r.Context()))
+	ctxSetDoNotTrack(refreshReq, true)
+
+	refreshOptions := *options
+	refreshOptions.revalidate = false
+	m.conditional(refreshReq, &refreshOptions, options.stale)
+	ctxSetCacheOptions(refreshReq, &refreshOptions)
+
+	return func(next http.Handler) {
+		defer m.refreshing.Delete(options.key)
+
+		m.Logger().WithField("key", options.key).Debug("Refreshing stale cache entry")
+		next.ServeHTTP(newDiscardResponseWriter(), refreshReq)
+	}
+}
+
+// discardResponseWriter drops the responses of background refreshes, which
+// are only needed by the response cache.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func newDiscardResponseWriter() *discardResponseWriter {
+	return &discardResponseWriter{header: http.Header{}}
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+func (w *discardResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *discardResponseWriter) Flush() {}
+
+// staleResponse returns the expired cached response of req to serve in place
+// of an unreachable upstream, if the entry is within its stale-if-error window.
+// The response cache doesn't store it again.
+func (p *ReverseProxy) staleResponse(req *http.Request) *http.Response {
+	options := ctxGetCacheOptions(req)
+	if options == nil || options.stale == "" {
+		return nil
+	}
+
+	res, err := readCachedResponse(req, options.stale, revalidationFailedWarning)
+	if err != nil {
+		p.logger.WithError(err).Error("Could not create stale response object")
+		return nil
+	}
+
+	p.logger.Debug("Upstream failed, serving stale cached response")
+	options.servingStale = true
+
+	return res
+}
@@ -0,0 +1,188 @@
+package gateway
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestCacheExpiry(t *testing.T) {
+	expiry, err := parseCacheExpiry("100")
+	require.NoError(t, err)
+	assert.Equal(t, cacheExpiry{expires: time.Unix(100, 0)}, expiry)
+
+	expiry, err = parseCacheExpiry(formatCacheExpiry(100, 30, 600))
+	require.NoError(t, err)
+	assert.Equal(t, cacheExpiry{
+		expires:              time.Unix(100, 0),
+		staleWhileRevalidate: 30 * time.Second,
+		staleIfError:         600 * time.Second,
+	}, expiry)
+
+	assert.True(t, expiry.expired(time.Unix(101, 0)))
+	assert.True(t, expiry.staleWhileRevalidating(time.Unix(129, 0)))
+	assert.False(t, expiry.staleWhileRevalidating(time.Unix(130, 0)))
+	assert.True(t, expiry.staleIfErrorAllowed(time.Unix(699, 0)))
+	assert.False(t, expiry.staleIfErrorAllowed(time.Unix(700, 0)))
+
+	for _, invalid := range []string{"", "a", "1:2", "1:a:3"} {
+		_, err := parseCacheExpiry(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestStaleDirectives(t *testing.T) {
+	swr, sie := staleDirectives("max-age=60, stale-while-revalidate=30, Stale-If-Error=\"600\"", 1, 2)
+	assert.Equal(t, int64(30), swr)
+	assert.Equal(t, int64(600), sie)
+
+	swr, sie = staleDirectives("no-cache, stale-if-error=-1", 1, 2)
+	assert.Equal(t, int64(1), swr)
+	assert.Equal(t, int64(2), sie)
+}
+
+func TestRedisCacheMiddleware_Stale(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	var (
+		hits   atomic.Int32
+		failed atomic.Bool
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if failed.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "hit-%d", hits.Add(1))
+	}))
+	defer upstream.Close()
+
+	load := func(listenPath string, staleWhileRevalidate, staleIfError int64) {
+		hits.Store(0)
+		failed.Store(false)
+
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = listenPath
+			spec.Proxy.TargetURL = upstream.URL
+			spec.CacheOptions.EnableCache = true
+			spec.CacheOptions.CacheAllSafeRequests = true
+			spec.CacheOptions.CacheTimeout = 1
+			spec.CacheOptions.StaleWhileRevalidate = staleWhileRevalidate
+			spec.CacheOptions.StaleIfError = staleIfError
+		})
+	}
+
+	// cached waits for the response to path to be cached and expire, and
+	// returns the cached body.
+	cached := func(t *testing.T, path string) string {
+		t.Helper()
+
+		var body string
+		require.Eventually(t, func() bool {
+			resp, err := ts.Do(test.TestCase{Path: path})
+			if err != nil {
+				return false
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return false
+			}
+			body = string(data)
+			return resp.Header.Get(cachedResponseHeader) == "1"
+		}, 5*time.Second, 10*time.Millisecond)
+
+		time.Sleep(1100 * time.Millisecond)
+		return body
+	}
+
+	t.Run("stale while revalidate", func(t *testing.T) {
+		load("/stale-while-revalidate/", 60, 0)
+		stale := cached(t, "/stale-while-revalidate/")
+
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/stale-while-revalidate/",
+			BodyMatch:    stale,
+			Code:         http.StatusOK,
+			HeadersMatch: map[string]string{header.Warning: staleWarning},
+		})
+
+		// the entry is refreshed in the background
+		assert.Eventually(t, func() bool {
+			resp, err := ts.Do(test.TestCase{Path: "/stale-while-revalidate/"})
+			if err != nil {
+				return false
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			return err == nil && resp.Header.Get(header.Warning) == "" && string(data) != stale
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("stale if error", func(t *testing.T) {
+		load("/stale-if-error/", 0, 60)
+		stale := cached(t, "/stale-if-error/")
+
+		failed.Store(true)
+		_, _ = ts.Run(t, test.TestCase{
+			Path:         "/stale-if-error/",
+			BodyMatch:    stale,
+			Code:         http.StatusOK,
+			HeadersMatch: map[string]string{header.Warning: revalidationFailedWarning},
+		})
+	})
+
+	t.Run("stale if unreachable", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(w, "unreachable")
+		}))
+
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/unreachable/"
+			spec.Proxy.TargetURL = unreachable.URL
+			spec.CacheOptions.EnableCache = true
+			spec.CacheOptions.CacheAllSafeRequests = true
+			spec.CacheOptions.CacheTimeout = 1
+			spec.CacheOptions.StaleIfError = 60
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.UseExtendedPaths = true
+				v.GlobalResponseHeaders = map[string]string{"X-Response-Chain": "1"}
+			})
+		})
+		stale := cached(t, "/unreachable/")
+
+		// the stale response goes through the response middleware
+		unreachable.Close()
+		_, _ = ts.Run(t, test.TestCase{
+			Path:      "/unreachable/",
+			BodyMatch: stale,
+			Code:      http.StatusOK,
+			HeadersMatch: map[string]string{
+				header.Warning:     revalidationFailedWarning,
+				"X-Response-Chain": "1",
+			},
+		})
+	})
+
+	t.Run("expired without stale windows", func(t *testing.T) {
+		load("/not-stale/", 0, 0)
+		cached(t, "/not-stale/")
+
+		failed.Store(true)
+		_, _ = ts.Run(t, test.TestCase{Path: "/not-stale/", Code: http.StatusInternalServerError})
+	})
+}
@@ -0,0 +1,183 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+)
+
+// cacheUsageSweepInterval is how often expired entries are dropped from the
+// response cache usage.
+const cacheUsageSweepInterval = 30 * time.Second
+
+// cacheUsage keeps track of the response cache entries stored by the gateway,
+// so that the stored entries and size metrics go down again when entries
+// expire, are purged or are found evicted. A nil *cacheUsage is disabled.
+type cacheUsage struct {
+	mu      sync.Mutex
+	entries map[string]cacheUsageEntry
+	totals  map[cacheUsageGroup]cacheUsageTotal
+	record  func(group cacheUsageGroup, total cacheUsageTotal)
+
+	done chan struct{}
+	once sync.Once
+}
+
+// cacheUsageGroup identifies the entries reported together.
+type cacheUsageGroup struct {
+	apiID      string
+	compressed bool
+}
+
+// cacheUsageTotal is the number and size of the entries of a group.
+type cacheUsageTotal struct {
+	entries int64
+	size    int64
+}
+
+type cacheUsageEntry struct {
+	group   cacheUsageGroup
+	size    int64
+	expires time.Time
+}
+
+// newCacheUsage creates a cache usage calling record whenever the total of a
+// group changes, and drops expired entries every interval until closed.
+func newCacheUsage(interval time.Duration, record func(cacheUsageGroup, cacheUsageTotal)) *cacheUsage {
+	u := &cacheUsage{
+		entries: make(map[string]cacheUsageEntry),
+		totals:  make(map[cacheUsageGroup]cacheUsageTotal),
+		record:  record,
+		done:    make(chan struct{}),
+	}
+
+	go u.sweepEvery(interval)
+
+	return u
+}
+
+// add records the entry of an API stored under key for ttl, replacing the
+// entry previously stored under key.
+func (u *cacheUsage) add(apiID, key string, size int, compressed bool, ttl time.Duration) {
+	if u == nil {
+		return
+	}
+
+	entry := cacheUsageEntry{
+		group:   cacheUsageGroup{apiID: apiID, compressed: compressed},
+		size:    int64(size),
+		expires: time.Now().Add(ttl),
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.drop(key)
+	u.entries[key] = entry
+	u.update(entry.group, 1, entry.size)
+}
+
+// remove drops the entries stored under keys.
+func (u *cacheUsage) remove(keys ...string) {
+	if u == nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, key := range keys {
+		u.drop(key)
+	}
+}
+
+// clear drops the entries of an API.
+func (u *cacheUsage) clear(apiID string) {
+	if u == nil {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for key, entry := range u.entries {
+		if entry.group.apiID == apiID {
+			u.drop(key)
+		}
+	}
+}
+
+// sweep drops the entries expired at now.
+func (u *cacheUsage) sweep(now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for key, entry := range u.entries {
+		if !entry.expires.After(now) {
+			u.drop(key)
+		}
+	}
+}
+
+func (u *cacheUsage) sweepEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-u.done:
+			return
+		case now := <-ticker.C:
+			u.sweep(now)
+		}
+	}
+}
+
+// Close stops dropping expired entries.
+func (u *cacheUsage) Close() {
+	if u == nil {
+		return
+	}
+
+	u.once.Do(func() { close(u.done) })
+}
+
+// drop removes the entry stored under key. u.mu must be held.
+func (u *cacheUsage) drop(key string) {
+	entry, ok := u.entries[key]
+	if !ok {
+		return
+	}
+
+	delete(u.entries, key)
+	u.update(entry.group, -1, -entry.size)
+}
+
+// update changes the total of group and reports it. u.mu must be held.
+func (u *cacheUsage) update(group cacheUsageGroup, entries, size int64) {
+	total := u.totals[group]
+	total.entries += entries
+	total.size += size
+
+	if total.entries == 0 {
+		delete(u.totals, group)
+	} else {
+		u.totals[group] = total
+	}
+
+	u.record(group, total)
+}
+
+// newGatewayCacheUsage returns the cache usage reporting to the metric
+// instruments of the gateway, or nil when metrics are disabled.
+func (gw *Gateway) newGatewayCacheUsage() *cacheUsage {
+	metrics := gw.GetConfig().OpenTelemetry.Metrics
+	if metrics.Enabled == nil || !*metrics.Enabled {
+		return nil
+	}
+
+	return newCacheUsage(cacheUsageSweepInterval, func(group cacheUsageGroup, total cacheUsageTotal) {
+		if gw.MetricInstruments != nil {
+			gw.MetricInstruments.RecordCacheUsage(gw.ctx, group.apiID, group.compressed, total.entries, total.size)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheUsage(t *testing.T) {
+	recorded := map[cacheUsageGroup]cacheUsageTotal{}
+	usage := newCacheUsage(time.Hour, func(group cacheUsageGroup, total cacheUsageTotal) {
+		recorded[group] = total
+	})
+	defer usage.Close()
+
+	compressed := cacheUsageGroup{apiID: "api1", compressed: true}
+	plain := cacheUsageGroup{apiID: "api1"}
+	other := cacheUsageGroup{apiID: "api2"}
+
+	usage.add("api1", "api1-a", 100, true, time.Minute)
+	usage.add("api1", "api1-b", 50, true, time.Hour)
+	usage.add("api1", "api1-c", 10, false, time.Hour)
+	usage.add("api2", "api2-a", 20, false, time.Hour)
+	assert.Equal(t, cacheUsageTotal{entries: 2, size: 150}, recorded[compressed])
+	assert.Equal(t, cacheUsageTotal{entries: 1, size: 10}, recorded[plain])
+
+	t.Run("replaced entries are counted once", func(t *testing.T) {
+		usage.add("api1", "api1-b", 60, true, time.Hour)
+		assert.Equal(t, cacheUsageTotal{entries: 2, size: 160}, recorded[compressed])
+	})
+
+	t.Run("expired entries are dropped", func(t *testing.T) {
+		usage.sweep(time.Now().Add(2 * time.Minute))
+		assert.Equal(t, cacheUsageTotal{entries: 1, size: 60}, recorded[compressed])
+	})
+
+	t.Run("purged entries are dropped", func(t *testing.T) {
+		usage.remove("api1-b", "unknown")
+		assert.Equal(t, cacheUsageTotal{}, recorded[compressed])
+	})
+
+	t.Run("invalidated APIs are dropped", func(t *testing.T) {
+		usage.clear("api1")
+		assert.Equal(t, cacheUsageTotal{}, recorded[plain])
+		assert.Equal(t, cacheUsageTotal{entries: 1, size: 20}, recorded[other])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var disabled *cacheUsage
+		disabled.add("api1", "api1-a", 100, true, time.Minute)
+		disabled.remove("api1-a")
+		disabled.clear("api1")
+		disabled.Close()
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/revocation"
 
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/certs"
@@ -138,6 +139,16 @@ func getCertificateIDForHost(host string, certMaps []map[string]string) string {
 	return certID
 }
 
+// certificateSecret returns the secret private keys of certificates are
+// encrypted with.
+func (gw *Gateway) certificateSecret() string {
+	conf := gw.GetConfig()
+	if conf.Security.PrivateCertificateEncodingSecret != "" {
+		return conf.Security.PrivateCertificateEncodingSecret
+	}
+	return conf.Secret
+}
+
 func (gw *Gateway) getUpstreamCertificate(host string, spec *APISpec) (cert *tls.Certificate) {
 	certMaps := []map[string]string{gw.GetConfig().Security.Certificates.Upstream}
 
@@ -328,7 +339,9 @@ var tlsConfigCache = cache.New(60, 3600)
 
 var tlsConfigMu sync.Mutex
 
-func getClientValidator(helloInfo *tls.ClientHelloInfo, certPool *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+// getClientValidator verifies client certificates against certPool, then
+// passes the verified chains to next, if set.
+func getClientValidator(helloInfo *tls.ClientHelloInfo, certPool *x509.CertPool, next func([][]byte, [][]*x509.Certificate) error) func([][]byte, [][]*x509.Certificate) error {
 	return func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 		if len(rawCerts) == 0 {
 			return errors.New("x509: missing client certificate")
@@ -346,9 +359,12 @@ func getClientValidator(helloInfo *tls.ClientHelloInfo, certPool *x509.CertPool)
 			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 		}
 
-		_, err := cert.Verify(opts)
+		chains, err := cert.Verify(opts)
+		if err != nil || next == nil {
+			return err
+		}
 
-		return err
+		return next(rawCerts, chains)
 	}
 }
 
@@ -445,6 +461,10 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 	listenPortStr := strconv.Itoa(listenPort)
 
 	return func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if config := gw.acmeChallengeConfig(hello); config != nil {
+			return config, nil
+		}
+
 		if config, found := tlsConfigCache.Get(hello.ServerName + listenPortStr); found {
 			return config.(*tls.Config).Clone(), nil
 		}
@@ -470,6 +490,12 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 		if isControlAPI && gwConfig.Security.ControlAPIUseMutualTLS {
 			newConfig.ClientAuth = tls.RequireAndVerifyClientCert
 			newConfig.ClientCAs = gw.CertificateManager.CertPool(gwConfig.Security.Certificates.ControlAPI)
+			if gw.revocationEnabled() {
+				newConfig.VerifyPeerCertificate = gw.verifyPeerRevocation
+			}
+			if gwConfig.Security.CertificateRevocation.OCSPStapling && gw.revocationChecker != nil {
+				gw.stapleOCSP(newConfig)
+			}
 
 			tlsConfigCache.Set(hello.ServerName, newConfig, cache.DefaultExpiration)
 			return newConfig, nil
@@ -550,6 +576,8 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 			}
 		}
 
+		gw.addACMECertificate(newConfig, hello.ServerName)
+
 		if clientAuth, found := domainRequireCert[hello.ServerName]; found {
 			newConfig.ClientAuth = clientAuth
 		} else {
@@ -581,9 +609,15 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 			newConfig.ClientAuth = domainRequireCert[""]
 		}
 
+		var verifyRevocation func([][]byte, [][]*x509.Certificate) error
+		if gw.revocationEnabled() && newConfig.ClientAuth == tls.RequireAndVerifyClientCert {
+			verifyRevocation = gw.verifyPeerRevocation
+			newConfig.VerifyPeerCertificate = verifyRevocation
+		}
+
 		if gwConfig.HttpServerOptions.SkipClientCAAnnouncement {
 			if newConfig.ClientAuth == tls.RequireAndVerifyClientCert {
-				newConfig.VerifyPeerCertificate = getClientValidator(hello, newConfig.ClientCAs)
+				newConfig.VerifyPeerCertificate = getClientValidator(hello, newConfig.ClientCAs, verifyRevocation)
 			}
 			newConfig.ClientCAs = x509.NewCertPool()
 			newConfig.ClientAuth = tls.RequestClientCert
@@ -594,6 +628,10 @@ func (gw *Gateway) getTLSConfigForClient(baseConfig *tls.Config, listenPort int)
 			newConfig.ClientAuth = tls.RequestClientCert
 		}
 
+		if gwConfig.Security.CertificateRevocation.OCSPStapling && gw.revocationChecker != nil {
+			gw.stapleOCSP(newConfig)
+		}
+
 		// Cache the config
 		tlsConfigCache.Set(hello.ServerName+listenPortStr, newConfig, cache.DefaultExpiration)
 
@@ -612,6 +650,11 @@ func (gw *Gateway) certHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if revocation.IsCRL(content) {
+			gw.crlHandler(w, content)
+			return
+		}
+
 		orgID := r.URL.Query().Get("org_id")
 		var certID string
 		if certID, err = gw.CertificateManager.Add(content, orgID); err != nil {
@@ -665,6 +708,15 @@ func (gw *Gateway) certHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	case "DELETE":
+		if strings.HasPrefix(certID, revocation.CRLKeyPrefix) && gw.revocationChecker != nil {
+			if !gw.revocationChecker.DeleteCRL(certID) {
+				doJSONWrite(w, http.StatusNotFound, apiError("CRL not found"))
+				return
+			}
+			doJSONWrite(w, http.StatusOK, &apiStatusMessage{"ok", "removed"})
+			return
+		}
+
 		orgID := r.URL.Query().Get("org_id")
 		if orgID == "" && len(certID) >= sha256.Size*2 {
 			orgID = certID[:len(certID)-sha256.Size*2]
@@ -674,6 +726,22 @@ func (gw *Gateway) certHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// crlHandler stores an uploaded certificate revocation list.
+func (gw *Gateway) crlHandler(w http.ResponseWriter, content []byte) {
+	if gw.revocationChecker == nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError("Certificate revocation checking is disabled"))
+		return
+	}
+
+	crlID, err := gw.revocationChecker.AddCRL(content)
+	if err != nil {
+		doJSONWrite(w, http.StatusBadRequest, apiError(err.Error()))
+		return
+	}
+
+	doJSONWrite(w, http.StatusOK, &APICertificateStatusMessage{crlID, "ok", "CRL added"})
+}
+
 func getCipherAliases(ciphers []string) (cipherCodes []uint16) {
 	for _, v := range ciphers {
 		id, err := crypto.ResolveCipher(v)
@@ -0,0 +1,212 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	xacme "golang.org/x/crypto/acme"
+
+	"github.com/TykTechnologies/tyk/certs"
+	"github.com/TykTechnologies/tyk/internal/acme"
+	"github.com/TykTechnologies/tyk/internal/httpclient"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+// defaultACMECheckInterval is the interval between the checks of the ACME
+// certificates that need to be ordered or renewed.
+const defaultACMECheckInterval = time.Hour
+
+// startACME starts the provisioning of certificates with ACME when enabled.
+func (gw *Gateway) startACME() {
+	conf := gw.GetConfig().HttpServerOptions.ACME
+	if !conf.Enabled {
+		return
+	}
+
+	logger := log.WithField("prefix", "acme")
+
+	client, err := NewExternalHTTPClientFactory(gw).CreateACMEClient()
+	if err != nil {
+		// don't bypass a misconfigured mTLS setup with the default client
+		if gw.GetConfig().ExternalServices.ACME.MTLS.Enabled && httpclient.IsMTLSError(err) {
+			logger.WithError(err).Error("ACME mTLS configuration failed, certificate provisioning is disabled")
+			return
+		}
+		logger.WithError(err).Debug("Falling back to the default ACME HTTP client")
+		client = &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}
+	}
+
+	if conf.CAFile != "" {
+		pool, err := acmeCertPool(conf.CAFile)
+		if err != nil {
+			logger.WithError(err).Error("Couldn't load ACME CA file, certificate provisioning is disabled")
+			return
+		}
+		transport, ok := client.Transport.(*http.Transport)
+		if !ok {
+			transport = &http.Transport{Proxy: http.ProxyFromEnvironment}
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = pool
+		client.Transport = transport
+	}
+
+	directoryURL := conf.DirectoryURL
+	if directoryURL == "" {
+		directoryURL = xacme.LetsEncryptURL
+	}
+
+	store := &storage.RedisCluster{KeyPrefix: "acme-", HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
+	store.Connect()
+
+	gw.acmeManager = acme.NewManager(acme.Config{
+		DirectoryURL: directoryURL,
+		Email:        conf.Email,
+		Challenges:   conf.Challenges,
+		RenewBefore:  time.Duration(conf.RenewBeforeDays) * 24 * time.Hour,
+		HTTPClient:   client,
+		Secret:       gw.certificateSecret(),
+	}, store, gw.CertificateManager, logger)
+
+	if conf.HTTPChallengeAddress != "" {
+		gw.startACMEChallengeServer(conf.HTTPChallengeAddress, logger)
+	}
+
+	interval := time.Duration(conf.CheckInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultACMECheckInterval
+	}
+	go gw.acmeManager.Run(gw.ctx, interval, gw.acmeDomains)
+}
+
+// startACMEChallengeServer starts the plain HTTP listener answering HTTP-01
+// challenges.
+func (gw *Gateway) startACMEChallengeServer(addr string, logger *logrus.Entry) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           gw.acmeManager.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-gw.ctx.Done()
+		_ = server.Close()
+	}()
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.WithError(err).Error("ACME HTTP challenge server failed")
+		}
+	}()
+}
+
+// renewACMECertificates orders the certificates of the API domains which
+// don't have one yet, e.g. after APIs were loaded.
+func (gw *Gateway) renewACMECertificates() {
+	if gw.acmeManager == nil {
+		return
+	}
+
+	go gw.acmeManager.Renew(gw.ctx, gw.acmeDomains())
+}
+
+// acmeDomains returns the domains certificates are provisioned for: the
+// configured ones and the custom domains of APIs. Domains with patterns
+// can't be provisioned and are skipped.
+func (gw *Gateway) acmeDomains() []string {
+	gwConfig := gw.GetConfig()
+
+	set := map[string]struct{}{}
+	for _, domain := range gwConfig.HttpServerOptions.ACME.Domains {
+		set[domain] = struct{}{}
+	}
+
+	if gwConfig.EnableCustomDomains {
+		gw.apisMu.RLock()
+		for _, spec := range gw.apisByID {
+			if spec.Domain == "" || spec.DomainDisabled || strings.ContainsAny(spec.Domain, "{}*") {
+				continue
+			}
+			set[spec.Domain] = struct{}{}
+		}
+		gw.apisMu.RUnlock()
+	}
+
+	domains := make([]string, 0, len(set))
+	for domain := range set {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	return domains
+}
+
+// addACMECertificate adds the ACME certificate of serverName to conf, which
+// must be owned by the caller.
+func (gw *Gateway) addACMECertificate(conf *tls.Config, serverName string) {
+	if gw.acmeManager == nil || serverName == "" {
+		return
+	}
+
+	issued, ok := gw.acmeManager.Certificate(serverName)
+	if !ok {
+		return
+	}
+
+	cert := gw.CertificateManager.List([]string{issued.ID}, certs.CertificatePrivate)[0]
+	if cert == nil {
+		return
+	}
+
+	conf.Certificates = append(conf.Certificates, *cert)
+	if conf.NameToCertificate == nil {
+		conf.NameToCertificate = map[string]*tls.Certificate{}
+	}
+	conf.NameToCertificate[serverName] = cert
+}
+
+// acmeChallengeConfig returns the TLS config answering the TLS-ALPN-01
+// challenge of hello, or nil if it isn't a challenge handshake.
+func (gw *Gateway) acmeChallengeConfig(hello *tls.ClientHelloInfo) *tls.Config {
+	if gw.acmeManager == nil {
+		return nil
+	}
+
+	cert, ok := gw.acmeManager.ChallengeCertificate(hello)
+	if !ok {
+		return nil
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		NextProtos:   []string{acme.ALPNProto},
+	}
+}
+
+// acmeCertPool returns the system certificate pool with the certificates of
+// caFile.
+func acmeCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("no certificates found in " + caFile)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/acme"
+)
+
+func TestACMEDomains(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.EnableCustomDomains = true
+		globalConf.HttpServerOptions.ACME.Domains = []string{"static.example.com"}
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(
+		func(spec *APISpec) {
+			spec.APIID = "custom-domain"
+			spec.Domain = "api.example.com"
+			spec.Proxy.ListenPath = "/custom/"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "pattern-domain"
+			spec.Domain = "{tenant:[a-z]+}.example.com"
+			spec.Proxy.ListenPath = "/pattern/"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "disabled-domain"
+			spec.Domain = "disabled.example.com"
+			spec.DomainDisabled = true
+			spec.Proxy.ListenPath = "/disabled/"
+		},
+		func(spec *APISpec) {
+			spec.APIID = "no-domain"
+			spec.Proxy.ListenPath = "/"
+		},
+	)
+
+	assert.Equal(t, []string{"api.example.com", "static.example.com"}, ts.Gw.acmeDomains())
+}
+
+func TestACMEDisabled(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	assert.Nil(t, ts.Gw.acmeManager)
+
+	hello := &tls.ClientHelloInfo{ServerName: "api.example.com", SupportedProtos: []string{acme.ALPNProto}}
+	assert.Nil(t, ts.Gw.acmeChallengeConfig(hello))
+
+	conf := &tls.Config{}
+	ts.Gw.addACMECertificate(conf, "api.example.com")
+	assert.Empty(t, conf.Certificates)
+
+	// doesn't order certificates
+	ts.Gw.renewACMECertificates()
+}
@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+)
+
+// certificateThumbprintClaim is the confirmation member of tokens bound to
+// a client certificate (RFC 8705).
+const certificateThumbprintClaim = "x5t#S256"
+
+var (
+	errCertBindingCertificateMissing = errors.New("client certificate required for certificate-bound access token")
+	errCertBindingMismatch           = errors.New("client certificate doesn't match the access token binding")
+	errCertBindingTokenNotBound      = errors.New("access token isn't bound to a client certificate")
+	errInvalidConfirmationClaim      = errors.New("invalid cnf claim")
+)
+
+// checkCertificateBinding checks that the client certificate of r is the one
+// the access token, whose claims are claims, is bound to with the
+// cnf.x5t#S256 claim, when conf enables it. Other tokens are only accepted
+// when conf allows unbound tokens.
+func checkCertificateBinding(r *http.Request, conf *oas.CertificateBinding, claims jwt.MapClaims) error {
+	if conf == nil || !conf.Enabled {
+		return nil
+	}
+
+	thumbprint, err := confirmationClaim(claims, certificateThumbprintClaim)
+	if err != nil {
+		return err
+	}
+
+	if thumbprint == "" {
+		if conf.AllowUnboundTokens {
+			return nil
+		}
+		return errCertBindingTokenNotBound
+	}
+
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return errCertBindingCertificateMissing
+	}
+
+	if crypto.Base64URLSHA256(r.TLS.PeerCertificates[0].Raw) != thumbprint {
+		return errCertBindingMismatch
+	}
+
+	return nil
+}
+
+// confirmationClaim returns the member of the cnf claim (RFC 7800), or an
+// empty string if the token doesn't have it.
+func confirmationClaim(claims jwt.MapClaims, member string) (string, error) {
+	cnf, ok := claims["cnf"]
+	if !ok {
+		return "", nil
+	}
+
+	confirmation, ok := cnf.(map[string]interface{})
+	if !ok {
+		return "", errInvalidConfirmationClaim
+	}
+
+	value, ok := confirmation[member]
+	if !ok {
+		return "", nil
+	}
+
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return "", errInvalidConfirmationClaim
+	}
+
+	return str, nil
+}
@@ -0,0 +1,107 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+)
+
+func TestCheckCertificateBinding(t *testing.T) {
+	_, _, _, clientCert := crypto.GenCertificate(&x509.Certificate{}, false)
+	leaf, err := x509.ParseCertificate(clientCert.Certificate[0])
+	require.NoError(t, err)
+
+	_, _, _, otherCert := crypto.GenCertificate(&x509.Certificate{}, false)
+	otherLeaf, err := x509.ParseCertificate(otherCert.Certificate[0])
+	require.NoError(t, err)
+
+	boundClaims := jwt.MapClaims{"cnf": map[string]interface{}{
+		certificateThumbprintClaim: crypto.Base64URLSHA256(leaf.Raw),
+	}}
+	enabled := &oas.CertificateBinding{Enabled: true}
+
+	newRequest := func(certs ...*x509.Certificate) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+		if len(certs) == 0 {
+			r.TLS = nil
+		} else {
+			r.TLS = &tls.ConnectionState{PeerCertificates: certs}
+		}
+		return r
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.NoError(t, checkCertificateBinding(newRequest(), nil, boundClaims))
+		assert.NoError(t, checkCertificateBinding(newRequest(), &oas.CertificateBinding{}, boundClaims))
+	})
+
+	t.Run("matching certificate", func(t *testing.T) {
+		assert.NoError(t, checkCertificateBinding(newRequest(leaf), enabled, boundClaims))
+	})
+
+	t.Run("other certificate", func(t *testing.T) {
+		assert.ErrorIs(t, checkCertificateBinding(newRequest(otherLeaf), enabled, boundClaims), errCertBindingMismatch)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		assert.ErrorIs(t, checkCertificateBinding(newRequest(), enabled, boundClaims), errCertBindingCertificateMissing)
+	})
+
+	t.Run("unbound tokens", func(t *testing.T) {
+		assert.ErrorIs(t, checkCertificateBinding(newRequest(leaf), enabled, jwt.MapClaims{}), errCertBindingTokenNotBound)
+
+		allowUnbound := &oas.CertificateBinding{Enabled: true, AllowUnboundTokens: true}
+		assert.NoError(t, checkCertificateBinding(newRequest(leaf), allowUnbound, jwt.MapClaims{}))
+	})
+
+	t.Run("invalid binding", func(t *testing.T) {
+		claims := jwt.MapClaims{"cnf": "thumbprint"}
+		assert.ErrorIs(t, checkCertificateBinding(newRequest(leaf), enabled, claims), errInvalidConfirmationClaim)
+	})
+}
+
+func TestJWTMiddleware_oasJWTConfig(t *testing.T) {
+	var api apidef.APIDefinition
+	api.EnableJWT = true
+	api.AuthConfigs = map[string]apidef.AuthConfig{
+		apidef.JWTType: {
+			Name:           "jwtAuth",
+			AuthHeaderName: "Authorization",
+		},
+	}
+
+	var o oas.OAS
+	o.SetTykExtension(&oas.XTykAPIGateway{})
+	o.Fill(api)
+
+	jwtConfig := &oas.JWT{
+		Enabled:            true,
+		DPoP:               &oas.DPoP{Enabled: true, ProofMaxAge: 30},
+		CertificateBinding: &oas.CertificateBinding{Enabled: true},
+	}
+	o.GetTykExtension().Server.Authentication.SecuritySchemes["jwtAuth"] = jwtConfig
+
+	spec := &APISpec{APIDefinition: &api, OAS: o}
+	jwtMiddleware := &JWTMiddleware{BaseMiddleware: &BaseMiddleware{Spec: spec}}
+
+	// token binding is only supported by OAS APIs
+	assert.Nil(t, jwtMiddleware.oasJWTConfig())
+
+	api.IsOAS = true
+	assert.Equal(t, jwtConfig, jwtMiddleware.oasJWTConfig())
+
+	// the token binding configuration is kept when the API is filled again
+	o.Fill(api)
+	assert.Equal(t, jwtConfig.DPoP, o.GetJWTConfiguration().DPoP)
+	assert.Equal(t, jwtConfig.CertificateBinding, o.GetJWTConfiguration().CertificateBinding)
+}
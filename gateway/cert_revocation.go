@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/revocation"
+)
+
+// newRevocationChecker creates the checker of client certificate revocation
+// and OCSP stapling, or nil if both are disabled. Uploaded CRLs are kept in
+// store, next to the certificates.
+func (gw *Gateway) newRevocationChecker(store revocation.Store) *revocation.Checker {
+	conf := gw.GetConfig().Security.CertificateRevocation
+	if !conf.Enabled && !conf.OCSPStapling {
+		return nil
+	}
+
+	return revocation.NewChecker(revocation.Config{
+		CRL:      conf.CRL,
+		OCSP:     conf.OCSP,
+		HardFail: conf.HardFail,
+		CacheTTL: time.Duration(conf.CacheTTLSeconds) * time.Second,
+		Timeout:  time.Duration(conf.TimeoutSeconds) * time.Second,
+	}, nil, store)
+}
+
+// revocationEnabled reports whether the revocation of client certificates
+// is checked.
+func (gw *Gateway) revocationEnabled() bool {
+	return gw.revocationChecker != nil && gw.GetConfig().Security.CertificateRevocation.Enabled
+}
+
+// checkCertRevocation checks whether the client certificate leaf has been
+// revoked. The issuer is looked up in the verified chain, the certificates
+// presented by the client and the trusted CA certificates, in this order.
+// Self-signed certificates, trusted as such, aren't checked.
+func (gw *Gateway) checkCertRevocation(leaf *x509.Certificate, chain []*x509.Certificate, trusted []*tls.Certificate) error {
+	if bytes.Equal(leaf.RawIssuer, leaf.RawSubject) {
+		return nil
+	}
+
+	issuer := findIssuer(leaf, chain)
+	if issuer == nil {
+		for _, cert := range trusted {
+			if cert == nil {
+				continue
+			}
+			if issuer = findIssuer(leaf, []*x509.Certificate{cert.Leaf}); issuer != nil {
+				break
+			}
+		}
+	}
+
+	err := gw.revocationChecker.Check(leaf, issuer)
+	if err != nil {
+		log.WithError(err).
+			WithField("serial", leaf.SerialNumber.String()).
+			Warning("Client certificate rejected by revocation check")
+	}
+	return err
+}
+
+// checkRequestCertRevocation checks the client certificate of r, trusted by
+// one of apiCerts, for revocation.
+func (gw *Gateway) checkRequestCertRevocation(r *http.Request, apiCerts []*tls.Certificate) error {
+	if !gw.revocationEnabled() || r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+
+	chain := r.TLS.PeerCertificates
+	if len(r.TLS.VerifiedChains) > 0 {
+		chain = r.TLS.VerifiedChains[0]
+	}
+
+	return gw.checkCertRevocation(r.TLS.PeerCertificates[0], chain, apiCerts)
+}
+
+// verifyPeerRevocation is a tls.Config VerifyPeerCertificate callback
+// rejecting revoked client certificates of verified chains. Certificates
+// which weren't verified in the handshake are checked by CertificateCheckMW.
+func (gw *Gateway) verifyPeerRevocation(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+		return nil
+	}
+
+	chain := verifiedChains[0]
+	return gw.checkCertRevocation(chain[0], chain, nil)
+}
+
+// stapleOCSP staples the OCSP responses of the server certificates of conf,
+// which must be owned by conf. Certificates without a valid OCSP response
+// are served without one.
+func (gw *Gateway) stapleOCSP(conf *tls.Config) {
+	staple := func(cert *tls.Certificate) {
+		res, err := gw.revocationChecker.Staple(cert)
+		if err != nil {
+			log.WithError(err).Debug("Couldn't staple OCSP response to server certificate")
+			return
+		}
+		cert.OCSPStaple = res
+	}
+
+	for i := range conf.Certificates {
+		staple(&conf.Certificates[i])
+	}
+
+	// name mapped certificates may be shared with other configs
+	for name, cert := range conf.NameToCertificate {
+		stapled := *cert
+		staple(&stapled)
+		conf.NameToCertificate[name] = &stapled
+	}
+}
+
+// findIssuer returns the certificate of candidates which issued cert.
+func findIssuer(cert *x509.Certificate, candidates []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range candidates {
+		if candidate == nil || candidate == cert || !bytes.Equal(candidate.RawSubject, cert.RawIssuer) {
+			continue
+		}
+		if cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/certs"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/crypto"
+	"github.com/TykTechnologies/tyk/internal/revocation"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestCertificateRevocation(t *testing.T) {
+	_, _, combinedPEM, _ := crypto.GenServerCertificate()
+	serverCertID, _, _ := certs.GetCertIDAndChainPEM(combinedPEM, "")
+
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.HttpServerOptions.UseSSL = true
+		globalConf.HttpServerOptions.SSLCertificates = []string{serverCertID}
+		globalConf.Security.CertificateRevocation = config.CertificateRevocationConfig{
+			Enabled: true,
+			CRL:     true,
+		}
+	})
+	defer ts.Close()
+
+	_, err := ts.Gw.CertificateManager.Add(combinedPEM, "")
+	require.NoError(t, err)
+	ts.ReloadGatewayProxy()
+
+	rootCertPEM, rootKeyPEM, err := crypto.GenerateRootCertAndKey(t)
+	require.NoError(t, err)
+	rootCertID, err := ts.Gw.CertificateManager.Add(rootCertPEM, "")
+	require.NoError(t, err)
+	defer ts.Gw.CertificateManager.Delete(rootCertID, "")
+
+	clientCertPEM, clientKeyPEM, err := crypto.GenerateClientCertAndKeyPEM(t, rootCertPEM, rootKeyPEM)
+	require.NoError(t, err)
+	clientCert, err := tls.X509KeyPair(clientCertPEM.Bytes(), clientKeyPEM.Bytes())
+	require.NoError(t, err)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.UseMutualTLSAuth = true
+		spec.ClientCertificates = []string{rootCertID}
+	})
+
+	request := func() error {
+		client := GetTLSClient(&clientCert, nil)
+		client.Transport.(*http.Transport).DisableKeepAlives = true
+
+		res, err := client.Get(ts.URL)
+		if err == nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	// without a CRL the certificate is accepted in soft-fail mode
+	assert.NoError(t, request())
+
+	crlPEM := newTestCRL(t, rootCertPEM, rootKeyPEM, clientCert.Leaf)
+
+	var crlID string
+	t.Run("upload CRL", func(t *testing.T) {
+		resp, _ := ts.Run(t, test.TestCase{
+			Method: http.MethodPost, Path: "/tyk/certs", Data: string(crlPEM),
+			AdminAuth: true, Code: http.StatusOK, BodyMatch: `"message":"CRL added"`,
+		})
+
+		var status APICertificateStatusMessage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+		crlID = status.CertID
+		assert.Contains(t, crlID, revocation.CRLKeyPrefix)
+
+		assert.Error(t, request())
+	})
+
+	t.Run("delete CRL", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{
+			Method: http.MethodDelete, Path: "/tyk/certs/" + crlID,
+			AdminAuth: true, Code: http.StatusOK,
+		})
+
+		assert.NoError(t, request())
+	})
+}
+
+// newTestCRL returns a PEM encoded CRL of the root CA revoking cert.
+func newTestCRL(t *testing.T, rootCertPEM, rootKeyPEM []byte, cert *x509.Certificate) []byte {
+	t.Helper()
+
+	certBlock, _ := pem.Decode(rootCertPEM)
+	rootCert, err := x509.ParseCertificate(certBlock.Bytes)
+	require.NoError(t, err)
+
+	keyBlock, _ := pem.Decode(rootKeyPEM)
+	rootKey, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	require.NoError(t, err)
+
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: time.Now(),
+		}},
+	}, rootCert, rootKey)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
@@ -0,0 +1,441 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenk/backoff"
+
+	circuit "github.com/TykTechnologies/circuitbreaker"
+
+	"github.com/TykTechnologies/tyk/apidef"
+)
+
+// States of a circuit breaker, as reported in metrics and shared with the cluster.
+const (
+	circuitBreakerOpen     = "open"
+	circuitBreakerHalfOpen = "half_open"
+	circuitBreakerClosed   = "closed"
+)
+
+// defaultCircuitBreakerHalfOpenTimeout is how long half-open probes may take
+// to report their outcome when the breaker doesn't configure it.
+const defaultCircuitBreakerHalfOpenTimeout = 30 * time.Second
+
+// circuitBreakerState is the payload of NoticeCircuitBreakerState, published
+// when a shared circuit breaker opens or closes.
+type circuitBreakerState struct {
+	NodeID string `json:"node_id"`
+	APIID  string `json:"api_id"`
+	Key    string `json:"key"`
+	State  string `json:"state"`
+}
+
+// circuitBreakers holds the circuit breakers of an API by key: the version,
+// method and path of path scoped breakers, the API, or the upstream host.
+// Breakers with the same key share their state, the first configuration
+// creating it wins.
+type circuitBreakers struct {
+	gw   *Gateway
+	spec *APISpec
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+	// hostConf is the configuration of host scoped breakers, used to create
+	// the breakers of hosts that were tripped on other gateways.
+	hostConf *apidef.CircuitBreakerMeta
+}
+
+func newCircuitBreakers(gw *Gateway, spec *APISpec) *circuitBreakers {
+	return &circuitBreakers{
+		gw:       gw,
+		spec:     spec,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// circuitBreaker wraps a rate breaker, which decides when to trip, with the
+// return to service of the configuration: the breaker is reset once
+// ReturnToServiceAfter has passed, or moves to half-open and lets through
+// HalfOpenRequests probes, closing if all of them succeed. Probes that don't
+// report their outcome within the half-open timeout are given up on, so that
+// other requests can probe the upstream.
+type circuitBreaker struct {
+	*circuit.Breaker
+
+	group  *circuitBreakers
+	conf   apidef.CircuitBreakerMeta
+	key    string
+	target string
+
+	mu        sync.Mutex
+	trips     int
+	halfOpen  bool
+	probes    int
+	successes int
+	// probeDeadline is when the outstanding probes are given up on.
+	probeDeadline time.Time
+
+	// remote is set while applying the state published by another gateway,
+	// so that it isn't published again.
+	remote atomic.Bool
+}
+
+// circuitBreakerKey returns the key of the breaker of conf of the API version
+// named version for requests to host.
+func circuitBreakerKey(conf apidef.CircuitBreakerMeta, version, host string) string {
+	switch conf.Scope {
+	case apidef.CircuitBreakerScopeAPI:
+		return apidef.CircuitBreakerScopeAPI
+	case apidef.CircuitBreakerScopeHost:
+		return apidef.CircuitBreakerScopeHost + ":" + host
+	default:
+		return apidef.CircuitBreakerScopePath + ":" + version + ":" + conf.Method + " " + conf.Path
+	}
+}
+
+// get returns the breaker of conf of the API version named version for
+// requests to host, creating it if needed.
+func (g *circuitBreakers) get(conf apidef.CircuitBreakerMeta, version, host string) *circuitBreaker {
+	key := circuitBreakerKey(conf, version, host)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if b, ok := g.breakers[key]; ok {
+		return b
+	}
+
+	target := conf.Path
+	switch conf.Scope {
+	case apidef.CircuitBreakerScopeAPI:
+		target = g.spec.Proxy.ListenPath
+	case apidef.CircuitBreakerScopeHost:
+		target = host
+		if g.hostConf == nil {
+			g.hostConf = &conf
+		}
+	}
+
+	b := &circuitBreaker{
+		Breaker: circuit.NewRateBreaker(conf.ThresholdPercent, conf.Samples),
+		group:   g,
+		conf:    conf,
+		key:     key,
+		target:  target,
+	}
+
+	// override backoff algorithm when is not desired to recheck the upstream before the ReturnToServiceAfter happens,
+	// or when the half-open probes are done once it has passed
+	if conf.DisableHalfOpenState || conf.HalfOpenRequests > 0 {
+		b.BackOff = &backoff.StopBackOff{}
+	}
+
+	g.breakers[key] = b
+	g.watch(b)
+
+	return b
+}
+
+// lookup returns the breaker with key, creating the breakers of upstream
+// hosts that don't have one yet.
+func (g *circuitBreakers) lookup(key string) *circuitBreaker {
+	g.mu.Lock()
+	b, ok := g.breakers[key]
+	hostConf := g.hostConf
+	g.mu.Unlock()
+
+	if ok {
+		return b
+	}
+
+	host, ok := strings.CutPrefix(key, apidef.CircuitBreakerScopeHost+":")
+	if !ok || hostConf == nil {
+		return nil
+	}
+
+	return g.get(*hostConf, "", host)
+}
+
+// watch handles the events of b until it is stopped.
+func (g *circuitBreakers) watch(b *circuitBreaker) {
+	spec := g.spec
+	events := b.Subscribe()
+
+	go func() {
+		for e := range events {
+			switch e {
+			case circuit.BreakerTripped:
+				log.Warning("[PROXY] [CIRCUIT BREAKER] Breaker tripped for: ", b.target)
+				log.Debug("Breaker tripped: ", e)
+
+				b.mu.Lock()
+				b.trips++
+				trip := b.trips
+				b.mu.Unlock()
+
+				go func(timeout int) {
+					log.Debug("-- Sleeping for (s): ", timeout)
+					time.Sleep(time.Duration(timeout) * time.Second)
+					log.Debug("-- Returning breaker to service")
+					b.returnToService(trip)
+				}(b.conf.ReturnToServiceAfter)
+
+				if spec.Proxy.ServiceDiscovery.UseDiscoveryService {
+					log.Warning("[PROXY] [CIRCUIT BREAKER] Refreshing host list")
+					g.gw.ServiceCache.Delete(spec.APIID)
+				}
+
+				spec.FireEvent(EventBreakerTriggered, EventCurcuitBreakerMeta{
+					EventMetaDefault: EventMetaDefault{Message: "Breaker Tripped"},
+					CircuitEvent:     e,
+					Path:             b.target,
+					APIID:            spec.APIID,
+				})
+
+				spec.FireEvent(EventBreakerTripped, EventCurcuitBreakerMeta{
+					EventMetaDefault: EventMetaDefault{Message: "Breaker Tripped"},
+					CircuitEvent:     e,
+					Path:             b.target,
+					APIID:            spec.APIID,
+				})
+
+				g.transition(b, circuitBreakerOpen)
+
+			case circuit.BreakerReset:
+				spec.FireEvent(EventBreakerTriggered, EventCurcuitBreakerMeta{
+					EventMetaDefault: EventMetaDefault{Message: "Breaker Reset"},
+					CircuitEvent:     e,
+					Path:             b.target,
+					APIID:            spec.APIID,
+				})
+
+				spec.FireEvent(EventBreakerReset, EventCurcuitBreakerMeta{
+					EventMetaDefault: EventMetaDefault{Message: "Breaker Reset"},
+					CircuitEvent:     e,
+					Path:             b.target,
+					APIID:            spec.APIID,
+				})
+
+				g.transition(b, circuitBreakerClosed)
+
+			case circuit.BreakerStop:
+				// time to stop this Go-routine
+				return
+			}
+		}
+	}()
+}
+
+// transition records b moving to state, and publishes it to the cluster when
+// b is shared and the transition didn't come from another gateway.
+func (g *circuitBreakers) transition(b *circuitBreaker, state string) {
+	if g.gw.MetricInstruments != nil {
+		scope := b.conf.Scope
+		if scope == "" {
+			scope = apidef.CircuitBreakerScopePath
+		}
+		g.gw.MetricInstruments.RecordCircuitBreakerTransition(g.gw.ctx, g.spec.APIID, scope, b.target, state)
+	}
+
+	if state == circuitBreakerHalfOpen || !b.conf.Shared || b.remote.Swap(false) {
+		return
+	}
+
+	payload, err := json.Marshal(circuitBreakerState{
+		NodeID: g.gw.GetNodeID(),
+		APIID:  g.spec.APIID,
+		Key:    b.key,
+		State:  state,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to encode circuit breaker state")
+		return
+	}
+
+	g.gw.MainNotifier.Notify(Notification{
+		Command: NoticeCircuitBreakerState,
+		Payload: string(payload),
+		Gw:      g.gw,
+	})
+}
+
+// apply moves the breaker with key to the state published by another gateway.
+func (g *circuitBreakers) apply(key, state string) {
+	b := g.lookup(key)
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	halfOpen := b.halfOpen
+	b.halfOpen = false
+	b.mu.Unlock()
+
+	switch state {
+	case circuitBreakerOpen:
+		if b.Tripped() && !halfOpen {
+			return
+		}
+		b.remote.Store(true)
+		b.Trip()
+	case circuitBreakerClosed:
+		if !b.Tripped() {
+			return
+		}
+		b.remote.Store(true)
+		b.Reset()
+	}
+}
+
+// stop stops the event handling of all the breakers.
+func (g *circuitBreakers) stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, b := range g.breakers {
+		b.Stop()
+	}
+}
+
+// returnToService resets b, or moves it to half-open when probes are
+// configured, unless it was tripped again since trip.
+func (b *circuitBreaker) returnToService(trip int) {
+	b.mu.Lock()
+	if trip != b.trips || !b.Tripped() {
+		b.mu.Unlock()
+		return
+	}
+
+	if b.conf.HalfOpenRequests <= 0 {
+		b.mu.Unlock()
+		b.Reset()
+		return
+	}
+
+	b.halfOpen = true
+	b.probes = 0
+	b.successes = 0
+	b.mu.Unlock()
+
+	if b.group != nil {
+		b.group.transition(b, circuitBreakerHalfOpen)
+	}
+}
+
+// Ready returns true if a request can be sent, letting through at most
+// HalfOpenRequests probes while the breaker is half-open. Requests let through
+// must report their outcome with Success or Fail, or call release when they
+// aren't sent after all.
+func (b *circuitBreaker) Ready() bool {
+	b.mu.Lock()
+	if b.halfOpen {
+		defer b.mu.Unlock()
+
+		now := time.Now()
+		if b.probes >= b.conf.HalfOpenRequests {
+			if now.Before(b.probeDeadline) {
+				return false
+			}
+			// the outstanding probes timed out
+			b.probes = b.successes
+		}
+		b.probes++
+		b.probeDeadline = now.Add(b.halfOpenTimeout())
+		return true
+	}
+	b.mu.Unlock()
+
+	return b.Breaker.Ready()
+}
+
+// release gives back the probe taken by a request that Ready let through but
+// that wasn't sent.
+func (b *circuitBreaker) release() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.halfOpen && b.probes > b.successes {
+		b.probes--
+	}
+}
+
+// halfOpenTimeout returns how long half-open probes may take to report their outcome.
+func (b *circuitBreaker) halfOpenTimeout() time.Duration {
+	if b.conf.HalfOpenTimeout > 0 {
+		return time.Duration(b.conf.HalfOpenTimeout) * time.Second
+	}
+	return defaultCircuitBreakerHalfOpenTimeout
+}
+
+// Success records a successful request, closing a half-open breaker once all
+// its probes succeeded.
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	if b.halfOpen {
+		b.successes++
+		if b.successes < b.conf.HalfOpenRequests {
+			b.mu.Unlock()
+			return
+		}
+		b.halfOpen = false
+		b.mu.Unlock()
+
+		b.Reset()
+		return
+	}
+	b.mu.Unlock()
+
+	b.Breaker.Success()
+}
+
+// Fail records a failed request, opening a half-open breaker again.
+func (b *circuitBreaker) Fail() {
+	b.mu.Lock()
+	if b.halfOpen {
+		b.halfOpen = false
+		b.mu.Unlock()
+
+		b.Trip()
+		return
+	}
+	b.mu.Unlock()
+
+	b.Breaker.Fail()
+}
+
+// breaker returns the circuit breaker of requests to host.
+func (m *ExtendedCircuitBreakerMeta) breaker(host string) *circuitBreaker {
+	if m.breakers != nil {
+		return m.breakers.get(m.CircuitBreakerMeta, m.version, host)
+	}
+	return &circuitBreaker{Breaker: m.CB, conf: m.CircuitBreakerMeta}
+}
+
+// handleCircuitBreakerState applies the state of a shared circuit breaker
+// published by another gateway.
+func (gw *Gateway) handleCircuitBreakerState(payload string) {
+	var state circuitBreakerState
+	if err := json.Unmarshal([]byte(payload), &state); err != nil {
+		pubSubLog.WithError(err).Error("Failed to decode circuit breaker state")
+		return
+	}
+
+	if state.NodeID == gw.GetNodeID() {
+		return
+	}
+
+	spec := gw.getApiSpec(state.APIID)
+	if spec == nil || spec.circuitBreakers == nil {
+		return
+	}
+
+	spec.circuitBreakers.apply(state.Key, state.State)
+}
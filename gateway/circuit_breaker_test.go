@@ -0,0 +1,217 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func (b *circuitBreaker) isHalfOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.halfOpen
+}
+
+func TestCircuitBreaker_HalfOpenRequests(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "half-open"}}
+	breakers := newCircuitBreakers(ts.Gw, spec)
+	defer breakers.stop()
+
+	b := breakers.get(apidef.CircuitBreakerMeta{
+		Path:             "/",
+		ThresholdPercent: 0.5,
+		Samples:          1,
+		HalfOpenRequests: 2,
+	}, "", "")
+
+	b.Fail()
+	require.True(t, b.Tripped())
+	require.Eventually(t, b.isHalfOpen, time.Second, 10*time.Millisecond)
+
+	// only the configured probes are let through
+	assert.True(t, b.Ready())
+	assert.True(t, b.Ready())
+	assert.False(t, b.Ready())
+
+	b.Success()
+	assert.True(t, b.Tripped())
+	b.Success()
+	assert.False(t, b.Tripped())
+	assert.True(t, b.Ready())
+
+	// a failed probe opens the breaker again
+	b.Fail()
+	require.Eventually(t, b.isHalfOpen, time.Second, 10*time.Millisecond)
+	assert.True(t, b.Ready())
+	b.Fail()
+	assert.True(t, b.Tripped())
+}
+
+func TestCircuitBreaker_Scopes(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "scopes"}}
+	breakers := newCircuitBreakers(ts.Gw, spec)
+	defer breakers.stop()
+
+	host := apidef.CircuitBreakerMeta{Path: "/a", Scope: apidef.CircuitBreakerScopeHost}
+	assert.Same(t, breakers.get(host, "v1", "one:8080"), breakers.get(host, "v2", "one:8080"))
+	assert.NotSame(t, breakers.get(host, "v1", "one:8080"), breakers.get(host, "v1", "two:8080"))
+
+	api := apidef.CircuitBreakerMeta{Path: "/a", Scope: apidef.CircuitBreakerScopeAPI}
+	assert.Same(t, breakers.get(api, "v1", "one:8080"), breakers.get(apidef.CircuitBreakerMeta{Path: "/b", Scope: apidef.CircuitBreakerScopeAPI}, "v2", "two:8080"))
+
+	path := apidef.CircuitBreakerMeta{Path: "/a"}
+	assert.Same(t, breakers.get(path, "v1", "one:8080"), breakers.get(path, "v1", "two:8080"))
+	assert.NotSame(t, breakers.get(path, "v1", ""), breakers.get(apidef.CircuitBreakerMeta{Path: "/b"}, "v1", ""))
+	assert.NotSame(t, breakers.get(path, "v1", ""), breakers.get(path, "v2", ""))
+}
+
+func TestCircuitBreaker_HalfOpenProbes(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{APIID: "half-open-probes"}}
+	breakers := newCircuitBreakers(ts.Gw, spec)
+	defer breakers.stop()
+
+	b := breakers.get(apidef.CircuitBreakerMeta{
+		Path:             "/",
+		ThresholdPercent: 0.5,
+		Samples:          1,
+		HalfOpenRequests: 1,
+		HalfOpenTimeout:  1,
+	}, "", "")
+
+	b.Fail()
+	require.Eventually(t, b.isHalfOpen, time.Second, 10*time.Millisecond)
+
+	t.Run("released probes can be taken again", func(t *testing.T) {
+		assert.True(t, b.Ready())
+		assert.False(t, b.Ready())
+
+		b.release()
+		assert.True(t, b.Ready())
+		assert.False(t, b.Ready())
+	})
+
+	t.Run("timed out probes are given up on", func(t *testing.T) {
+		require.Eventually(t, b.Ready, 2*time.Second, 50*time.Millisecond)
+		assert.False(t, b.Ready())
+
+		b.Success()
+		assert.False(t, b.Tripped())
+	})
+}
+
+func TestCircuitBreaker_Shared(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "shared-breaker"
+		spec.Proxy.ListenPath = "/shared-breaker/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.CircuitBreakerEnabled = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.CircuitBreaker = []apidef.CircuitBreakerMeta{{
+				Path:                 "/",
+				Method:               http.MethodGet,
+				ThresholdPercent:     0.5,
+				Samples:              10,
+				ReturnToServiceAfter: 60,
+				Scope:                apidef.CircuitBreakerScopeHost,
+				Shared:               true,
+			}}
+		})
+	})
+
+	publish := func(state string) {
+		payload, err := json.Marshal(circuitBreakerState{
+			NodeID: "other-node",
+			APIID:  "shared-breaker",
+			Key:    apidef.CircuitBreakerScopeHost + ":" + upstreamURL.Host,
+			State:  state,
+		})
+		require.NoError(t, err)
+		ts.Gw.handleCircuitBreakerState(string(payload))
+	}
+
+	_, _ = ts.Run(t, test.TestCase{Path: "/shared-breaker/", Code: http.StatusOK})
+
+	publish(circuitBreakerOpen)
+	_, _ = ts.Run(t, test.TestCase{Path: "/shared-breaker/", Code: http.StatusServiceUnavailable})
+
+	publish(circuitBreakerClosed)
+	_, _ = ts.Run(t, test.TestCase{Path: "/shared-breaker/", Code: http.StatusOK})
+}
+
+func TestCircuitBreaker_RetryReleasesProbe(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer upstream.Close()
+
+	conf := apidef.CircuitBreakerMeta{
+		Path:             "/",
+		Method:           http.MethodGet,
+		ThresholdPercent: 0.5,
+		Samples:          10,
+		HalfOpenRequests: 2,
+	}
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "retry-probe"
+		spec.Proxy.ListenPath = "/retry-probe/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.Retry = apidef.UpstreamRetryConfig{
+			Enabled:     true,
+			MaxAttempts: 2,
+			StatusCodes: []int{http.StatusTooManyRequests},
+			BackoffBase: tyktime.ReadableDuration(time.Millisecond),
+		}
+		spec.CircuitBreakerEnabled = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.ExtendedPaths.CircuitBreaker = []apidef.CircuitBreakerMeta{conf}
+		})
+	})
+
+	b := ts.Gw.getApiSpec("retry-probe").circuitBreakers.get(conf, "v1", "")
+	b.Trip()
+	require.Eventually(t, b.isHalfOpen, time.Second, 10*time.Millisecond)
+
+	// the retry takes the second probe, which is given back when the
+	// exhausted retry budget stops it from being sent
+	for exhausted := false; !exhausted; {
+		exhausted = !ts.Gw.retryBudget.Withdraw()
+	}
+	_, _ = ts.Run(t, test.TestCase{Path: "/retry-probe/", Code: http.StatusTooManyRequests})
+
+	assert.True(t, b.isHalfOpen())
+	assert.True(t, b.Ready())
+}
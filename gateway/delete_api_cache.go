@@ -2,13 +2,47 @@ package gateway
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/TykTechnologies/tyk/storage"
 )
 
+// apiCacheInvalidationLockTTL bounds how long a gateway invalidating the Redis
+// cache of an API keeps the other gateways from scanning it too.
+const apiCacheInvalidationLockTTL = 30 * time.Second
+
+const apiCacheInvalidationLockPrefix = "lock-api-cache-"
+
 func (gw *Gateway) invalidateAPICache(apiID string) bool {
+	gw.responseCacheL1.invalidate(apiID)
+	gw.cacheUsage.clear(apiID)
+
 	store := storage.RedisCluster{IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
 	store.Connect()
 
 	return store.DeleteScanMatch(fmt.Sprintf("cache-%s*", apiID))
 }
+
+// invalidateAPICacheOnce handles cache invalidations signalled to every
+// gateway of a cluster. Each gateway clears its in-memory cache, while only
+// the gateway holding the invalidation lock of the API scans Redis.
+func (gw *Gateway) invalidateAPICacheOnce(apiID string) bool {
+	gw.responseCacheL1.invalidate(apiID)
+	gw.cacheUsage.clear(apiID)
+
+	store := storage.RedisCluster{IsCache: true, ConnectionHandler: gw.StorageConnectionHandler}
+	store.Connect()
+
+	lockKey := apiCacheInvalidationLockPrefix + apiID
+	locked, err := store.Lock(lockKey, apiCacheInvalidationLockTTL)
+	if err != nil {
+		return false
+	}
+	if !locked {
+		log.WithField("apiID", apiID).Debug("Cache is being invalidated by another gateway")
+		return true
+	}
+	defer store.DeleteRawKey(lockKey)
+
+	return store.DeleteScanMatch(fmt.Sprintf("cache-%s*", apiID))
+}
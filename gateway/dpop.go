@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/dpop"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+)
+
+var (
+	errDPoPProofMissing  = errors.New("DPoP proof missing")
+	errDPoPProofMultiple = errors.New("multiple DPoP proofs")
+	errDPoPTokenNotBound = errors.New("access token isn't bound to a DPoP key")
+)
+
+// dpopChallenge is the WWW-Authenticate challenge of requests rejected
+// because of their DPoP proof.
+const dpopChallenge = `DPoP error="invalid_dpop_proof"`
+
+// stripDPoP removes the DPoP authorization scheme of sender-constrained tokens.
+func stripDPoP(token string) string {
+	return dpop.StripScheme(token)
+}
+
+// checkDPoP validates the DPoP proof of r for accessToken, whose claims are
+// claims, when conf enables it. Tokens bound to a key with the cnf.jkt claim
+// must come with a proof signed with that key, other tokens are only accepted
+// when conf allows bearer tokens.
+func (gw *Gateway) checkDPoP(w http.ResponseWriter, r *http.Request, conf *oas.DPoP, accessToken string, claims jwt.MapClaims) error {
+	if conf == nil || !conf.Enabled {
+		return nil
+	}
+
+	err := gw.validateDPoP(r, conf, accessToken, claims)
+	if err != nil && w != nil {
+		w.Header().Add(header.WWWAuthenticate, dpopChallenge)
+	}
+
+	return err
+}
+
+func (gw *Gateway) validateDPoP(r *http.Request, conf *oas.DPoP, accessToken string, claims jwt.MapClaims) error {
+	thumbprint, err := confirmationClaim(claims, "jkt")
+	if err != nil {
+		return err
+	}
+
+	proofs := r.Header.Values(dpop.HeaderName)
+	if thumbprint == "" {
+		if conf.AllowBearerTokens && len(proofs) == 0 {
+			return nil
+		}
+		return errDPoPTokenNotBound
+	}
+
+	switch len(proofs) {
+	case 0:
+		return errDPoPProofMissing
+	case 1:
+	default:
+		return errDPoPProofMultiple
+	}
+
+	opts := dpop.Options{
+		MaxAge:    time.Duration(conf.ProofMaxAge) * time.Second,
+		ClockSkew: time.Duration(conf.AllowedClockSkew) * time.Second,
+	}
+	if gw.dpopReplayStore != nil {
+		opts.Replay = gw.dpopReplayStore
+	}
+
+	target := &url.URL{Scheme: httputil.RequestScheme(r), Host: r.Host, Path: r.URL.Path}
+
+	_, err = dpop.NewValidator(opts).Validate(proofs[0], dpop.Request{
+		Method:      r.Method,
+		URL:         target,
+		AccessToken: accessToken,
+		Thumbprint:  thumbprint,
+	})
+
+	return err
+}
@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/dpop"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+func newDPoPProof(t *testing.T, key *ecdsa.PrivateKey, method, uri, accessToken string) string {
+	t.Helper()
+
+	opts := (&jose.SignerOptions{EmbedJWK: true}).WithType("dpop+jwt")
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, opts)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"jti": uuid.New(),
+		"htm": method,
+		"htu": uri,
+		"iat": time.Now().Unix(),
+		"ath": dpop.AccessTokenHash(accessToken),
+	})
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	proof, err := jws.CompactSerialize()
+	require.NoError(t, err)
+
+	return proof
+}
+
+func TestGateway_checkDPoP(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	thumbprint, err := dpop.Thumbprint(&jose.JSONWebKey{Key: key.Public()})
+	require.NoError(t, err)
+
+	const (
+		accessToken = "access-token"
+		uri         = "http://api.example.com/orders"
+	)
+
+	boundClaims := jwt.MapClaims{"cnf": map[string]interface{}{"jkt": thumbprint}}
+	enabled := &oas.DPoP{Enabled: true}
+
+	newRequest := func(proofs ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, uri+"?page=1", nil)
+		for _, proof := range proofs {
+			r.Header.Add(dpop.HeaderName, proof)
+		}
+		return r
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.NoError(t, ts.Gw.checkDPoP(nil, newRequest(), nil, accessToken, boundClaims))
+		assert.NoError(t, ts.Gw.checkDPoP(nil, newRequest(), &oas.DPoP{}, accessToken, boundClaims))
+	})
+
+	t.Run("valid proof", func(t *testing.T) {
+		r := newRequest(newDPoPProof(t, key, http.MethodPost, uri, accessToken))
+		assert.NoError(t, ts.Gw.checkDPoP(nil, r, enabled, accessToken, boundClaims))
+	})
+
+	t.Run("replayed proof", func(t *testing.T) {
+		proof := newDPoPProof(t, key, http.MethodPost, uri, accessToken)
+		require.NoError(t, ts.Gw.checkDPoP(nil, newRequest(proof), enabled, accessToken, boundClaims))
+
+		err := ts.Gw.checkDPoP(nil, newRequest(proof), enabled, accessToken, boundClaims)
+		assert.ErrorIs(t, err, dpop.ErrReplayedProof)
+	})
+
+	t.Run("missing proof", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		err := ts.Gw.checkDPoP(w, newRequest(), enabled, accessToken, boundClaims)
+		assert.ErrorIs(t, err, errDPoPProofMissing)
+		assert.Equal(t, dpopChallenge, w.Header().Get(header.WWWAuthenticate))
+	})
+
+	t.Run("multiple proofs", func(t *testing.T) {
+		r := newRequest(
+			newDPoPProof(t, key, http.MethodPost, uri, accessToken),
+			newDPoPProof(t, key, http.MethodPost, uri, accessToken),
+		)
+		assert.ErrorIs(t, ts.Gw.checkDPoP(nil, r, enabled, accessToken, boundClaims), errDPoPProofMultiple)
+	})
+
+	t.Run("proof for another request", func(t *testing.T) {
+		r := newRequest(newDPoPProof(t, key, http.MethodGet, uri, accessToken))
+		assert.ErrorIs(t, ts.Gw.checkDPoP(nil, r, enabled, accessToken, boundClaims), dpop.ErrInvalidProof)
+
+		r = newRequest(newDPoPProof(t, key, http.MethodPost, "http://api.example.com/users", accessToken))
+		assert.ErrorIs(t, ts.Gw.checkDPoP(nil, r, enabled, accessToken, boundClaims), dpop.ErrInvalidProof)
+	})
+
+	t.Run("proof signed with another key", func(t *testing.T) {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		r := newRequest(newDPoPProof(t, otherKey, http.MethodPost, uri, accessToken))
+		assert.ErrorIs(t, ts.Gw.checkDPoP(nil, r, enabled, accessToken, boundClaims), dpop.ErrKeyMismatch)
+	})
+
+	t.Run("bearer tokens", func(t *testing.T) {
+		err := ts.Gw.checkDPoP(nil, newRequest(), enabled, accessToken, jwt.MapClaims{})
+		assert.ErrorIs(t, err, errDPoPTokenNotBound)
+
+		allowBearer := &oas.DPoP{Enabled: true, AllowBearerTokens: true}
+		assert.NoError(t, ts.Gw.checkDPoP(nil, newRequest(), allowBearer, accessToken, jwt.MapClaims{}))
+	})
+
+	t.Run("invalid binding", func(t *testing.T) {
+		claims := jwt.MapClaims{"cnf": map[string]interface{}{"jkt": 1}}
+		assert.ErrorIs(t, ts.Gw.checkDPoP(nil, newRequest(), enabled, accessToken, claims), errInvalidConfirmationClaim)
+	})
+}
@@ -118,6 +118,21 @@ type EventTokenMeta struct {
 	Key string
 }
 
+// EventTrafficMirrorMismatchMeta is the metadata structure for a shadow
+// response that differs from the primary response (EventTrafficMirrorMismatch).
+type EventTrafficMirrorMismatchMeta struct {
+	EventMetaDefault
+	Path          string
+	APIID         string
+	PrimaryStatus int
+	ShadowStatus  int
+	BodyMismatch  bool
+}
+
+func (e *EventTrafficMirrorMismatchMeta) LogMessage(prefix string) string {
+	return fmt.Sprintf("%s:%s:%s: [MIRROR] primary %d, shadow %d, body mismatch %t", prefix, e.APIID, e.Path, e.PrimaryStatus, e.ShadowStatus, e.BodyMismatch)
+}
+
 // EventHandlerByName is a convenience function to get event handler instances from an API Definition
 func (gw *Gateway) EventHandlerByName(handlerConf apidef.EventHandlerTriggerConfig, spec *APISpec) (config.TykEventHandler, error) {
 
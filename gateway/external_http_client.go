@@ -1,8 +1,10 @@
 package gateway
 
 import (
+	"crypto/tls"
 	"net/http"
 
+	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/internal/httpclient"
 	"github.com/go-jose/go-jose/v3"
 )
@@ -66,6 +68,30 @@ func (f *ExternalHTTPClientFactory) CreateHealthCheckClient() (*http.Client, err
 	return f.factory.CreateHealthCheckClient()
 }
 
+// CreateMirrorClient creates an HTTP client for requests mirrored to shadow upstreams.
+func (f *ExternalHTTPClientFactory) CreateMirrorClient() (*http.Client, error) {
+	log.Debug("[ExternalServices] Creating traffic mirror HTTP client")
+	return f.factory.CreateMirrorClient()
+}
+
+// CreateAuthorizationClient creates an HTTP client for requests to external policy decision points.
+func (f *ExternalHTTPClientFactory) CreateAuthorizationClient() (*http.Client, error) {
+	log.Debug("[ExternalServices] Creating external authorization HTTP client")
+	return f.factory.CreateAuthorizationClient()
+}
+
+// CreateACMEClient creates an HTTP client for requests to the ACME certificate authority.
+func (f *ExternalHTTPClientFactory) CreateACMEClient() (*http.Client, error) {
+	log.Debug("[ExternalServices] Creating ACME HTTP client")
+	return f.factory.CreateACMEClient()
+}
+
+// CreateAuthorizationTLSConfig creates the TLS configuration for gRPC connections to external policy decision points.
+func (f *ExternalHTTPClientFactory) CreateAuthorizationTLSConfig() (*tls.Config, error) {
+	log.Debug("[ExternalServices] Creating external authorization TLS configuration")
+	return f.factory.CreateTLSConfig(config.ServiceTypeAuthorization)
+}
+
 // getJWKWithClient fetches JWK using the provided HTTP client for proxy and mTLS support
 func getJWKWithClient(jwlUrl string, client *http.Client) (*jose.JSONWebKeySet, error) {
 	log.Debug("Pulling JWK with configured client")
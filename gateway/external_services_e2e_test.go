@@ -217,7 +217,7 @@ func TestE2E_OAuthFlowWithProxyAndmTLS(t *testing.T) {
 	}
 
 	// Test OAuth introspection request
-	_, _, err := middleware.introspection("test-access-token")
+	_, _, _, err := middleware.introspection("test-access-token")
 
 	// Should work (we'll get back valid response from mock)
 	assert.NoError(t, err)
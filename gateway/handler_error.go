@@ -164,6 +164,11 @@ func (e *ErrorHandler) HandleError(w http.ResponseWriter, r *http.Request, errMs
 		if len(e.Spec.Tags) > 0 {
 			tags = append(tags, e.Spec.Tags...)
 		}
+
+		tags = upstreamAttemptTags(r, tags)
+		tags = concurrencyLimitTags(r, tags)
+		tags = rateLimitQueueTags(r, tags)
+
 		trackEP := false
 		trackedPath := r.URL.Path
 
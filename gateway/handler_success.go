@@ -34,6 +34,8 @@ type ProxyResponse struct {
 	// UpstreamLatency the time it takes to do roundtrip to upstream. Total time
 	// taken for the gateway to receive response from upstream host.
 	UpstreamLatency time.Duration
+	// attempts holds the upstream attempts when the request was retried.
+	attempts []upstreamAttempt
 }
 
 type ReturningHttpHandler interface {
@@ -232,6 +234,8 @@ func (s *SuccessHandler) RecordHit(r *http.Request, timing analytics.Latency, co
 		}
 
 		tags = s.addTraceIDTag(r.Context(), tags)
+		tags = upstreamAttemptTags(r, tags)
+		tags = rateLimitQueueTags(r, tags)
 
 		rawRequest := ""
 		rawResponse := ""
@@ -454,6 +458,9 @@ func (s *SuccessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) *http
 		}
 
 		s.classifyUpstreamError(r, resp.Response.StatusCode)
+		if len(resp.attempts) > 0 {
+			ctxSetUpstreamAttempts(r, resp.attempts)
+		}
 
 		s.RecordHit(r, latency, resp.Response.StatusCode, resp.Response, false)
 		s.RecordAccessLog(r, resp.Response, latency)
@@ -502,6 +509,9 @@ func (s *SuccessHandler) ServeHTTPWithCache(w http.ResponseWriter, r *http.Reque
 		}
 
 		s.classifyUpstreamError(r, inRes.Response.StatusCode)
+		if len(inRes.attempts) > 0 {
+			ctxSetUpstreamAttempts(r, inRes.attempts)
+		}
 
 		s.RecordHit(r, latency, inRes.Response.StatusCode, inRes.Response, false)
 		s.RecordAccessLog(r, inRes.Response, latency)
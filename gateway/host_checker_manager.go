@@ -239,6 +239,35 @@ func (hc *HostCheckerManager) OnHostReport(ctx context.Context, report HostHealt
 			}
 		}()
 	}
+
+	hc.observeUptimeLatency(report)
+}
+
+// observeUptimeLatency feeds the latency of a successful uptime test into the
+// load balancer of the API, so latency aware strategies learn about slow
+// targets before proxied traffic hits them.
+func (hc *HostCheckerManager) observeUptimeLatency(report HostHealthReport) {
+	if report.IsTCPError || report.ResponseCode != http.StatusOK {
+		return
+	}
+
+	spec := hc.Gw.getApiSpec(report.MetaData[UnHealthyHostMetaDataAPIKey])
+	if spec == nil || !spec.Proxy.EnableLoadBalancing || spec.Proxy.StructuredTargetList == nil {
+		return
+	}
+
+	checked, err := url.Parse(report.CheckURL)
+	if err != nil {
+		return
+	}
+
+	latency := time.Duration(report.Latency * float64(time.Millisecond))
+	for _, target := range spec.Proxy.StructuredTargetList.All() {
+		host := EnsureTransport(target, spec.Protocol)
+		if u, err := url.Parse(host); err == nil && u.Host == checked.Host {
+			spec.LoadBalancer.Observe(host, latency)
+		}
+	}
 }
 
 func (hc *HostCheckerManager) OnHostDown(ctx context.Context, report HostHealthReport) {
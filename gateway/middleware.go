@@ -109,6 +109,12 @@ func (gw *Gateway) createDynamicMiddleware(name string, isPre, useSession bool,
 	return gw.createMiddleware(dMiddleware)
 }
 
+// requestFinisher is implemented by middleware holding resources, such as
+// concurrency slots, until the rest of the chain has handled the request.
+type requestFinisher interface {
+	finishRequest(r *http.Request)
+}
+
 // Generic middleware caller to make extension easier
 func (gw *Gateway) createMiddleware(actualMW TykMiddleware) func(http.Handler) http.Handler {
 	mw := &TraceMiddleware{
@@ -224,11 +230,20 @@ func (gw *Gateway) createMiddleware(actualMW TykMiddleware) func(http.Handler) h
 			logger.WithField("code", errCode).WithField("ns", finishTime.Nanoseconds()).Debug("Finished")
 
 			mw.Base().UpdateRequestSession(r)
+
+			if finisher, ok := actualMW.(requestFinisher); ok {
+				defer finisher.finishRequest(r)
+			}
+
 			// Special code, bypasses all other execution
 			if errCode != middleware.StatusRespond {
 				// No error, carry on...
 				meta["bypass"] = "1"
 				next.ServeHTTP(w, r)
+			} else if refresher, ok := actualMW.(backgroundRefresher); ok {
+				if refresh := refresher.refresh(r); refresh != nil {
+					go refresh(next)
+				}
 			}
 		})
 	}
@@ -338,6 +353,9 @@ func NewBaseMiddleware(gw *Gateway, spec *APISpec, proxy ReturningHttpHandler, l
 		if !v.GlobalEnforceTimeoutDisabled && v.GlobalEnforceTimeout != 0 {
 			baseMid.Spec.EnforcedTimeoutEnabled = true
 		}
+		if len(v.ExtendedPaths.UpstreamRetry) > 0 {
+			baseMid.Spec.UpstreamRetryEnabled = true
+		}
 	}
 
 	return baseMid
@@ -24,7 +24,11 @@ import (
 	"github.com/TykTechnologies/tyk/internal/httpctx"
 	"github.com/TykTechnologies/tyk/internal/httputil"
 	"github.com/TykTechnologies/tyk/internal/jsonrpc"
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
 	restmcpadapter "github.com/TykTechnologies/tyk/internal/mcp/adapter"
+	"github.com/TykTechnologies/tyk/internal/rate/adaptive"
+	"github.com/TykTechnologies/tyk/internal/rate/queue"
+	"github.com/TykTechnologies/tyk/internal/retry"
 
 	_ "github.com/TykTechnologies/tyk/internal/mcp" // registers MCP VEM prefixes
 )
@@ -51,9 +55,15 @@ type APISpec struct {
 	GojaJSVM                 GojaJSVM
 	ResponseChain            []TykResponseHandler
 	RoundRobin               RoundRobin
+	LoadBalancer             loadbalancer.Balancer
+	RetryPolicy              retry.Policy
+	OutlierDetector          *loadbalancer.OutlierDetector
+	AdaptiveConcurrency      *adaptive.Group
+	WaitQueue                *queue.Queue
 	URLRewriteEnabled        bool
 	CircuitBreakerEnabled    bool
 	EnforcedTimeoutEnabled   bool
+	UpstreamRetryEnabled     bool
 	LastGoodHostList         *apidef.HostList
 	HasRun                   bool
 	ServiceRefreshInProgress bool
@@ -67,6 +77,10 @@ type APISpec struct {
 
 	unloadHooks []func()
 
+	circuitBreakers *circuitBreakers
+
+	trafficMirror *trafficMirror
+
 	network analytics.NetworkStats
 
 	GraphEngine graphengine.Engine
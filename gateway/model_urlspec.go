@@ -3,6 +3,7 @@ package gateway
 import (
 	"github.com/TykTechnologies/tyk/apidef"
 	"github.com/TykTechnologies/tyk/apidef/oas"
+	"github.com/TykTechnologies/tyk/internal/retry"
 	"github.com/TykTechnologies/tyk/regexp"
 )
 
@@ -38,6 +39,9 @@ type URLSpec struct {
 	GoPluginMeta              GoPluginMiddleware
 	PersistGraphQL            apidef.PersistGraphQLMeta
 	RateLimit                 apidef.RateLimitMeta
+	ConcurrencyLimit          apidef.ConcurrencyLimitMeta
+	UpstreamRetry             apidef.UpstreamRetryMeta
+	UpstreamRetryPolicy       retry.Policy
 	OASValidateRequestMeta    *oas.ValidateRequest
 	OASMockResponseMeta       *oas.MockResponse
 
@@ -58,6 +62,9 @@ type URLSpec struct {
 	// OASPath stores the original OAS path pattern (e.g., "/users/{id}")
 	// This is used for matching against the OAS router when needed
 	OASPath string
+
+	// hedge holds the hedging state of endpoints with request hedging enabled.
+	hedge *hedgePolicy
 }
 
 // ValidateRequestCandidate represents one OAS endpoint that maps to the same
@@ -175,6 +182,10 @@ func (u *URLSpec) matchesMethod(method string) bool {
 		return method == u.PersistGraphQL.Method
 	case RateLimit:
 		return method == u.RateLimit.Method
+	case ConcurrencyLimit:
+		return method == u.ConcurrencyLimit.Method
+	case UpstreamRetry:
+		return method == u.UpstreamRetry.Method
 	case OASValidateRequest, OASMockResponse:
 		// OAS middleware is method-specific, check against stored method
 		return method == u.OASMethod
@@ -11,6 +11,7 @@ import (
 	"github.com/TykTechnologies/tyk/header"
 	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/internal/rate"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -62,9 +63,10 @@ func (k *RateLimitForAPI) getSession(r *http.Request) *user.SessionState {
 			keyname := k.keyName + "-" + storage.HashStr(fmt.Sprintf("%s:%s", limits.Method, limits.Path))
 
 			session := &user.SessionState{
-				Rate:        limits.Rate,
-				Per:         limits.Per,
-				LastUpdated: k.apiSess.LastUpdated,
+				Rate:               limits.Rate,
+				Per:                limits.Per,
+				RateLimitAlgorithm: limits.Algorithm,
+				LastUpdated:        k.apiSess.LastUpdated,
 			}
 			session.SetKeyHash(storage.HashKey(keyname, k.Gw.GetConfig().HashKeys))
 
@@ -85,9 +87,10 @@ func (k *RateLimitForAPI) EnabledForSpec() bool {
 
 	// Set last updated on each load to ensure we always use a new rate limit bucket
 	k.apiSess = &user.SessionState{
-		Rate:        k.Spec.GlobalRateLimit.Rate,
-		Per:         k.Spec.GlobalRateLimit.Per,
-		LastUpdated: strconv.Itoa(int(time.Now().UnixNano())),
+		Rate:               k.Spec.GlobalRateLimit.Rate,
+		Per:                k.Spec.GlobalRateLimit.Per,
+		RateLimitAlgorithm: k.Spec.GlobalRateLimit.Algorithm,
+		LastUpdated:        strconv.Itoa(int(time.Now().UnixNano())),
 	}
 	k.apiSess.SetKeyHash(storage.HashKey(k.keyName, k.Gw.GetConfig().HashKeys))
 
@@ -107,22 +110,33 @@ func (k *RateLimitForAPI) ProcessRequest(rw http.ResponseWriter, r *http.Request
 
 	limitHeaderSender := k.Gw.limitHeaderFactory(rw.Header())
 	// Only inject API-level rate limit headers if personal rate limit headers
-	// haven't already been injected by RateLimitAndQuotaCheck.
+	// haven't already been injected by RateLimitAndQuotaCheck. The IETF
+	// headers report every limit, so they are injected regardless.
 	if rw.Header().Get(header.XRateLimitLimit) != "" {
 		limitHeaderSender = nil
+		if k.Gw.ietfLimitHeaderFactory != nil {
+			limitHeaderSender = k.Gw.ietfLimitHeaderFactory(rw.Header())
+		}
+	}
+
+	forward := func(dryRun bool) sessionFailReason {
+		return k.Gw.SessionLimiter.ForwardMessage(
+			r,
+			session,
+			k.keyName,
+			k.quotaKey,
+			true,
+			false,
+			k.Spec,
+			dryRun,
+			rate.WithPolicy(limitHeaderSender, rate.PolicyAPI),
+		)
 	}
 
-	reason := k.Gw.SessionLimiter.ForwardMessage(
-		r,
-		session,
-		k.keyName,
-		k.quotaKey,
-		true,
-		false,
-		k.Spec,
-		false,
-		limitHeaderSender,
-	)
+	reason := forward(false)
+	if reason == sessionFailRateLimit {
+		reason = k.queueRateLimited(r, session.KeyHash(), forward)
+	}
 
 	k.emitRateLimitEvents(r, k.keyName)
 
@@ -79,7 +79,7 @@ func TestRateLimitForAPI_EnabledForSpec(t *testing.T) {
 }
 
 func TestAPIRateLimitResponseHeaders(t *testing.T) {
-	limiters := []string{"Redis", "Sentinel", "DRL", "FixedWindow"}
+	limiters := []string{"Redis", "Sentinel", "DRL", "FixedWindow", "GCRA"}
 
 	for _, limiter := range limiters {
 		t.Run("API Rate limit headers for "+limiter, func(t *testing.T) {
@@ -113,6 +113,9 @@ func TestAPIRateLimitResponseHeaders(t *testing.T) {
 					Rate:     rateLimitRate,
 					Per:      rateLimitPer,
 				}
+				if limiter == "GCRA" {
+					spec.GlobalRateLimit.Algorithm = "gcra"
+				}
 			})[0]
 
 			expectedRemaining1 := fmt.Sprintf("%d", int(rateLimitRate)-1)
@@ -126,7 +129,7 @@ func TestAPIRateLimitResponseHeaders(t *testing.T) {
 			}
 
 			// For limiters that don't support Remaining (Sentinel, FixedWindow), it should be assigned to 0.
-			if limiter == "Redis" || limiter == "DRL" {
+			if limiter == "Redis" || limiter == "DRL" || limiter == "GCRA" {
 				headersMatch1[header.XRateLimitRemaining] = expectedRemaining1
 				headersMatch2[header.XRateLimitRemaining] = expectedRemaining2
 			} else {
@@ -134,6 +137,14 @@ func TestAPIRateLimitResponseHeaders(t *testing.T) {
 				headersMatch2[header.XRateLimitRemaining] = "0"
 			}
 
+			// GCRA reports when the next request is allowed, one emission interval later.
+			var headersMatch3 map[string]string
+			if limiter == "GCRA" {
+				headersMatch3 = map[string]string{
+					header.RetryAfter: fmt.Sprintf("%d", int(rateLimitPer/rateLimitRate)),
+				}
+			}
+
 			_, _ = ts.Run(t, []test.TestCase{
 				{
 					Path:         "/api-rate-limit-headers-test",
@@ -146,14 +157,57 @@ func TestAPIRateLimitResponseHeaders(t *testing.T) {
 					HeadersMatch: headersMatch2,
 				},
 				{
-					Path: "/api-rate-limit-headers-test",
-					Code: http.StatusTooManyRequests,
+					Path:         "/api-rate-limit-headers-test",
+					Code:         http.StatusTooManyRequests,
+					HeadersMatch: headersMatch3,
 				},
 			}...)
 		})
 	}
 }
 
+func TestAPIRateLimitIETFHeaders(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.EnableIETFRateLimitHeaders = true
+	})
+	defer ts.Close()
+
+	_ = ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/ietf-rate-limit-headers-test"
+		spec.UseKeylessAccess = true
+		spec.GlobalRateLimit = apidef.GlobalRateLimit{
+			Rate:      2,
+			Per:       10,
+			Algorithm: "gcra",
+		}
+	})
+
+	_, _ = ts.Run(t, []test.TestCase{
+		{
+			Path: "/ietf-rate-limit-headers-test",
+			Code: http.StatusOK,
+			HeadersMatch: map[string]string{
+				header.RateLimitPolicy: `"api";q=2;w=10`,
+				header.RateLimit:       `"api";r=1;t=5`,
+			},
+			HeadersNotMatch: map[string]string{
+				header.XRateLimitLimit: "2",
+			},
+		},
+		{
+			Path: "/ietf-rate-limit-headers-test",
+			Code: http.StatusOK,
+			HeadersMatch: map[string]string{
+				header.RateLimit: `"api";r=0;t=10`,
+			},
+		},
+		{
+			Path: "/ietf-rate-limit-headers-test",
+			Code: http.StatusTooManyRequests,
+		},
+	}...)
+}
+
 func TestRLOpen(t *testing.T) {
 	ts := StartTest(nil)
 	defer ts.Close()
@@ -116,6 +116,10 @@ func (m *CertificateCheckMW) ProcessRequest(w http.ResponseWriter, r *http.Reque
 		}
 
 		m.batchCertificatesExpirationCheck(apiCerts)
+
+		if err := m.Gw.checkRequestCertRevocation(r, apiCerts); err != nil {
+			return err, http.StatusForbidden
+		}
 	}
 
 	return nil, http.StatusOK
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/TykTechnologies/tyk/ctx"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/internal/rate/limiter"
+	"github.com/TykTechnologies/tyk/storage"
+)
+
+const (
+	// concurrencyKeyPrefix prefixes the names of concurrency slot counters.
+	concurrencyKeyPrefix = "concurrency-limit-"
+
+	// concurrencyLimitedTag is added to the analytics of requests rejected
+	// by a concurrency limit.
+	concurrencyLimitedTag = "concurrency-limited"
+)
+
+// ConcurrencyLimitCheck limits the number of requests that are in flight at the
+// same time, for a key as set by `max_concurrent_requests` on its access rights,
+// and for an endpoint across all keys. The slots are held until the rest of the
+// middleware chain and the upstream have handled the request.
+type ConcurrencyLimitCheck struct {
+	*BaseMiddleware
+}
+
+func (k *ConcurrencyLimitCheck) Name() string {
+	return "ConcurrencyLimitCheck"
+}
+
+func (k *ConcurrencyLimitCheck) EnabledForSpec() bool {
+	if !k.Spec.UseKeylessAccess {
+		return true
+	}
+
+	for _, version := range k.Spec.VersionData.Versions {
+		for _, v := range version.ExtendedPaths.ConcurrencyLimit {
+			if !v.Disabled {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ProcessRequest takes a concurrency slot for each limit applying to the request.
+//
+//nolint:staticcheck
+func (k *ConcurrencyLimitCheck) ProcessRequest(_ http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	// Skip concurrency limits for looping
+	if !ctxCheckLimits(r) {
+		return nil, http.StatusOK
+	}
+
+	var releases []func()
+
+	if session := ctxGetSession(r); session != nil {
+		accessDef, allowanceScope, err := GetAccessDefinitionByAPIIDOrSession(session, k.Spec)
+		if err == nil && accessDef.Limit.MaxConcurrentRequests > 0 {
+			key := rate.Prefix(concurrencyKeyPrefix, allowanceScope, session.KeyHash())
+
+			release, err := k.acquire(r, key, accessDef.Limit.MaxConcurrentRequests)
+			if err != nil {
+				return k.handleConcurrencyFailure(r, key)
+			}
+			releases = append(releases, release)
+		}
+	}
+
+	versionInfo, _ := k.Spec.Version(r)
+	versionPaths := k.Spec.RxPaths[versionInfo.Name]
+
+	if spec, ok := k.Spec.FindSpecMatchesStatus(r, versionPaths, ConcurrencyLimit); ok && spec.ConcurrencyLimit.MaxConcurrentRequests > 0 {
+		limit := spec.ConcurrencyLimit
+		// track per-endpoint with a hash of the path
+		key := rate.Prefix(concurrencyKeyPrefix, k.Spec.OrgID+k.Spec.APIID, storage.HashStr(fmt.Sprintf("%s:%s", limit.Method, limit.Path)))
+
+		release, err := k.acquire(r, key, limit.MaxConcurrentRequests)
+		if err != nil {
+			for _, release := range releases {
+				release()
+			}
+			return k.handleConcurrencyFailure(r, key)
+		}
+		releases = append(releases, release)
+	}
+
+	if len(releases) > 0 {
+		ctxSetConcurrencySlots(r, releases)
+	}
+
+	return nil, http.StatusOK
+}
+
+// acquire takes a slot of the named counter, waiting in the rate limit queue
+// if enabled. Requests aren't blocked when the counter is unavailable, e.g.
+// when redis can't be reached.
+func (k *ConcurrencyLimitCheck) acquire(r *http.Request, key string, max int) (func(), error) {
+	release, err := k.Gw.SessionLimiter.AcquireConcurrency(r.Context(), key, max)
+	if errors.Is(err, limiter.ErrConcurrencyExhausted) {
+		k.waitInRateLimitQueue(r, key, func() bool {
+			release, err = k.Gw.SessionLimiter.AcquireConcurrency(r.Context(), key, max)
+			return !errors.Is(err, limiter.ErrConcurrencyExhausted)
+		})
+	}
+
+	if err != nil && !errors.Is(err, limiter.ErrConcurrencyExhausted) {
+		k.Logger().WithError(err).Error("Failed to acquire concurrency slot")
+		return func() {}, nil
+	}
+
+	return release, err
+}
+
+func (k *ConcurrencyLimitCheck) handleConcurrencyFailure(r *http.Request, key string) (error, int) {
+	ctx.SetErrorClassification(r, tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeConcurrencyLimit, k.Name()))
+	return k.handleRateLimitFailure(r, event.RateLimitExceeded, "Concurrency Limit Exceeded", key)
+}
+
+// finishRequest releases the concurrency slots taken for the request.
+func (k *ConcurrencyLimitCheck) finishRequest(r *http.Request) {
+	for _, release := range ctxGetConcurrencySlots(r) {
+		release()
+	}
+}
+
+// concurrencyLimitTags adds a tag to the analytics of requests rejected by a
+// concurrency limit.
+func concurrencyLimitTags(r *http.Request, tags []string) []string {
+	if errClass := ctx.GetErrorClassification(r); errClass != nil && errClass.Flag == tykerrors.CCL {
+		return append(tags, concurrencyLimitedTag)
+	}
+	return tags
+}
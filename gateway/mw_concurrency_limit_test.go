@@ -0,0 +1,185 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/ctx"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// blockingUpstream returns an upstream holding requests until release is closed,
+// and a channel receiving a value for every request it receives.
+func blockingUpstream(t *testing.T) (upstream *httptest.Server, arrived chan struct{}, release chan struct{}) {
+	t.Helper()
+
+	arrived = make(chan struct{}, 10)
+	release = make(chan struct{})
+
+	upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+	}))
+	t.Cleanup(upstream.Close)
+
+	return upstream, arrived, release
+}
+
+// assertSlotReleased checks that tc eventually succeeds. The slot is released
+// after the response is written, so the client can see the response first.
+func assertSlotReleased(t *testing.T, ts *Test, tc test.TestCase) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		resp, err := ts.Do(tc)
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	t.Run("endpoint", func(t *testing.T) {
+		upstream, arrived, release := blockingUpstream(t)
+
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/concurrency-endpoint/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = true
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.UseExtendedPaths = true
+				v.ExtendedPaths.ConcurrencyLimit = []apidef.ConcurrencyLimitMeta{
+					{Path: "/slow", Method: http.MethodGet, MaxConcurrentRequests: 1},
+				}
+			})
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = ts.Run(t, test.TestCase{Path: "/concurrency-endpoint/slow", Code: http.StatusOK})
+		}()
+		<-arrived
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/concurrency-endpoint/slow", Code: http.StatusTooManyRequests})
+
+		close(release)
+		<-done
+
+		assertSlotReleased(t, ts, test.TestCase{Path: "/concurrency-endpoint/slow"})
+	})
+
+	t.Run("key", func(t *testing.T) {
+		upstream, arrived, release := blockingUpstream(t)
+
+		api := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/concurrency-key/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.UseKeylessAccess = false
+		})[0]
+
+		_, limitedKey := ts.CreateSession(func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{
+				api.APIID: {
+					APIName: api.Name,
+					APIID:   api.APIID,
+					Limit:   user.APILimit{MaxConcurrentRequests: 1},
+				},
+			}
+		})
+
+		_, otherKey := ts.CreateSession(func(s *user.SessionState) {
+			s.AccessRights = map[string]user.AccessDefinition{
+				api.APIID: {
+					APIName: api.Name,
+					APIID:   api.APIID,
+					Limit:   user.APILimit{MaxConcurrentRequests: 1},
+				},
+			}
+		})
+
+		limited := map[string]string{"Authorization": limitedKey}
+		other := map[string]string{"Authorization": otherKey}
+
+		done := make(chan struct{}, 2)
+		for _, headers := range []map[string]string{limited, other} {
+			go func() {
+				defer func() { done <- struct{}{} }()
+				_, _ = ts.Run(t, test.TestCase{Path: "/concurrency-key/", Headers: headers, Code: http.StatusOK})
+			}()
+			<-arrived
+		}
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/concurrency-key/", Headers: limited, Code: http.StatusTooManyRequests})
+
+		close(release)
+		<-done
+		<-done
+
+		assertSlotReleased(t, ts, test.TestCase{Path: "/concurrency-key/", Headers: limited})
+	})
+}
+
+func TestConcurrencyLimit_distributedLease(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.EnableDistributedConcurrencyLimit = true
+		globalConf.ConcurrencyLimitLeaseTTL = 1
+	})
+	defer ts.Close()
+
+	upstream, arrived, release := blockingUpstream(t)
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/concurrency-lease/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.UseKeylessAccess = true
+		UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+			v.UseExtendedPaths = true
+			v.ExtendedPaths.ConcurrencyLimit = []apidef.ConcurrencyLimitMeta{
+				{Path: "/slow", Method: http.MethodGet, MaxConcurrentRequests: 1},
+			}
+		})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = ts.Run(t, test.TestCase{Path: "/concurrency-lease/slow", Code: http.StatusOK})
+	}()
+	<-arrived
+
+	// the slot is renewed while the request is in flight, past its lease
+	time.Sleep(2500 * time.Millisecond)
+	_, _ = ts.Run(t, test.TestCase{Path: "/concurrency-lease/slow", Code: http.StatusTooManyRequests})
+
+	close(release)
+	<-done
+
+	assertSlotReleased(t, ts, test.TestCase{Path: "/concurrency-lease/slow"})
+}
+
+func TestConcurrencyLimitTags(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Empty(t, concurrencyLimitTags(r, nil))
+
+	classification := tykerrors.ClassifyRateLimitError(tykerrors.ErrTypeConcurrencyLimit, "ConcurrencyLimitCheck")
+	require.NotNil(t, classification)
+
+	ctx.SetErrorClassification(r, classification)
+	assert.Equal(t, []string{"tag", concurrencyLimitedTag}, concurrencyLimitTags(r, []string{"tag"}))
+}
@@ -0,0 +1,252 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/httpclient"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/pdp"
+	"github.com/TykTechnologies/tyk/request"
+)
+
+// defaultExternalAuthzTimeout is how long policy decisions may take when the
+// API doesn't configure a timeout.
+const defaultExternalAuthzTimeout = time.Second
+
+var errExternalAuthzUnavailable = errors.New("authorization service unavailable")
+
+// ExternalAuthorizationMiddleware allows or denies requests with the decisions
+// of an external or embedded policy decision point.
+type ExternalAuthorizationMiddleware struct {
+	*BaseMiddleware
+
+	evaluator pdp.Evaluator
+	err       error
+}
+
+func (m *ExternalAuthorizationMiddleware) Name() string {
+	return "ExternalAuthorizationMiddleware"
+}
+
+func (m *ExternalAuthorizationMiddleware) EnabledForSpec() bool {
+	return m.Spec.ExternalAuthorization.Enabled
+}
+
+func (m *ExternalAuthorizationMiddleware) Init() {
+	conf := m.Spec.ExternalAuthorization
+
+	m.evaluator, m.err = m.newEvaluator(conf)
+	if m.err != nil {
+		m.Logger().WithError(m.err).Error("Failed to initialize external authorization")
+		return
+	}
+
+	if ttl := time.Duration(conf.CacheTTL); ttl > 0 {
+		m.evaluator = pdp.NewCachedEvaluator(m.evaluator, pdp.CacheOptions{
+			TTL:               ttl,
+			MaxEntries:        conf.CacheMaxEntries,
+			Headers:           conf.CacheKeyHeaders,
+			CredentialHeaders: m.authHeaderNames(),
+			AcrossClients:     conf.CacheAcrossClients,
+		})
+	}
+}
+
+// authHeaderNames returns the names of the headers the API reads credentials from.
+func (m *ExternalAuthorizationMiddleware) authHeaderNames() []string {
+	var names []string
+	for _, conf := range m.Spec.AuthConfigs {
+		if conf.AuthHeaderName != "" {
+			names = append(names, conf.AuthHeaderName)
+		}
+	}
+
+	if name := m.Spec.Auth.AuthHeaderName; name != "" {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (m *ExternalAuthorizationMiddleware) Unload() {
+	if m.evaluator == nil {
+		return
+	}
+
+	if err := m.evaluator.Close(); err != nil {
+		m.Logger().WithError(err).Debug("Failed to close external authorization")
+	}
+}
+
+// newEvaluator creates the evaluator of the policy decision point of conf.
+func (m *ExternalAuthorizationMiddleware) newEvaluator(conf apidef.ExternalAuthzConfig) (pdp.Evaluator, error) {
+	switch conf.Type {
+	case apidef.ExternalAuthzHTTP:
+		client, err := NewExternalHTTPClientFactory(m.Gw).CreateAuthorizationClient()
+		if err != nil {
+			// don't bypass a misconfigured mTLS setup with the default client
+			if m.Gw.GetConfig().ExternalServices.Authorization.MTLS.Enabled && httpclient.IsMTLSError(err) {
+				return nil, err
+			}
+			m.Logger().WithError(err).Debug("Falling back to the default external authorization HTTP client")
+			client = &http.Client{}
+		}
+		// the request context bounds policy decisions
+		client.Timeout = 0
+
+		return pdp.NewHTTPEvaluator(conf.URL, client), nil
+	case apidef.ExternalAuthzGRPC:
+		target, err := url.Parse(conf.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		creds := insecure.NewCredentials()
+		if target.Scheme == "grpcs" {
+			tlsConfig, err := NewExternalHTTPClientFactory(m.Gw).CreateAuthorizationTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			if tlsConfig.MinVersion == 0 {
+				tlsConfig.MinVersion = tls.VersionTLS12
+			}
+			creds = credentials.NewTLS(tlsConfig)
+		}
+
+		return pdp.NewGRPCEvaluator(target.Host,
+			grpc.WithTransportCredentials(creds),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		)
+	case apidef.ExternalAuthzEmbedded:
+		return pdp.NewEmbeddedEvaluator(conf.Policy)
+	default:
+		return nil, fmt.Errorf("unsupported external authorization type %q", conf.Type)
+	}
+}
+
+// ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
+func (m *ExternalAuthorizationMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _ interface{}) (error, int) {
+	if ctxGetRequestStatus(r) == StatusOkAndIgnore {
+		return nil, http.StatusOK
+	}
+
+	conf := m.Spec.ExternalAuthorization
+	logger := m.Logger()
+
+	if m.err != nil {
+		if conf.FailOpen {
+			return nil, http.StatusOK
+		}
+		return errExternalAuthzUnavailable, http.StatusInternalServerError
+	}
+
+	timeout := time.Duration(conf.Timeout)
+	if timeout <= 0 {
+		timeout = defaultExternalAuthzTimeout
+	}
+
+	evalCtx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	decision, err := m.evaluator.Evaluate(evalCtx, m.authorizationInput(r))
+	if err != nil {
+		logger.WithError(err).Error("External authorization failed")
+		if conf.FailOpen {
+			return nil, http.StatusOK
+		}
+		if errors.Is(err, pdp.ErrInvalidDecision) {
+			return errExternalAuthzUnavailable, http.StatusInternalServerError
+		}
+		return errExternalAuthzUnavailable, http.StatusServiceUnavailable
+	}
+
+	if !decision.Allow {
+		for name, value := range decision.ResponseHeaders {
+			w.Header().Set(name, value)
+		}
+
+		reason := decision.Reason
+		if reason == "" {
+			reason = "Access to this resource has been disallowed"
+		}
+
+		logger.WithField("reason", reason).Debug("Request denied by external authorization")
+		return errors.New(reason), decision.DeniedStatus()
+	}
+
+	decision.Apply(r.Header)
+
+	return nil, http.StatusOK
+}
+
+// authorizationInput returns the input document describing r.
+func (m *ExternalAuthorizationMiddleware) authorizationInput(r *http.Request) *pdp.Input {
+	headers := make(map[string]string, len(r.Header))
+	for name, values := range r.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	if r.Host != "" {
+		headers["host"] = r.Host
+	}
+
+	input := &pdp.Input{
+		Request: pdp.Request{
+			Method:   r.Method,
+			Scheme:   httputil.RequestScheme(r),
+			Host:     r.Host,
+			Path:     r.URL.Path,
+			Query:    r.URL.Query(),
+			Headers:  headers,
+			ClientIP: request.RealIP(r),
+		},
+		Claims: ctxGetTokenClaims(r),
+		API: pdp.API{
+			ID:         m.Spec.APIID,
+			Name:       m.Spec.Name,
+			OrgID:      m.Spec.OrgID,
+			ListenPath: m.Spec.Proxy.ListenPath,
+			Tags:       m.Spec.Tags,
+		},
+		Endpoint: pdp.Endpoint{
+			Path: m.Spec.StripListenPath(r.URL.Path),
+		},
+	}
+
+	if session := ctxGetSession(r); session != nil {
+		input.Session = &pdp.Session{
+			Alias:    session.Alias,
+			OrgID:    session.OrgID,
+			Policies: session.PolicyIDs(),
+			Tags:     session.Tags,
+			MetaData: session.MetaData,
+		}
+		if !session.KeyHashEmpty() {
+			input.Session.KeyHash = session.KeyHash()
+		}
+	}
+
+	if m.Spec.IsOAS {
+		if route, params := m.Spec.findOASRoute(r); route != nil {
+			input.Endpoint.Path = route.Path
+			input.Endpoint.Params = params
+			if route.Operation != nil {
+				input.Endpoint.OperationID = route.Operation.OperationID
+			}
+		}
+	}
+
+	return input
+}
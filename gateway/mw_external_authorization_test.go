@@ -0,0 +1,213 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/pdp"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestExternalAuthorizationMiddleware(t *testing.T) {
+	inputs := make(chan pdp.Input, 10)
+	decisionPoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input pdp.Input `json:"input"`
+		}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&body)) {
+			return
+		}
+		inputs <- body.Input
+
+		var result interface{}
+		switch body.Input.Request.Path {
+		case "/authz/allowed":
+			result = pdp.Decision{Allow: true, Headers: map[string]string{"X-Authz-User": "alice"}, RemoveHeaders: []string{"X-Internal"}}
+		case "/authz/denied":
+			result = pdp.Decision{Status: http.StatusUnauthorized, Reason: "not allowed", ResponseHeaders: map[string]string{"X-Authz-Reason": "policy"}}
+		case "/authz/undefined":
+			result = nil
+		default:
+			result = true
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+	}))
+	defer decisionPoint.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	load := func(authz apidef.ExternalAuthzConfig) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "external-authorization"
+			spec.Name = "External authorization"
+			spec.Proxy.ListenPath = "/authz/"
+			spec.UseKeylessAccess = true
+			spec.ExternalAuthorization = authz
+		})
+	}
+
+	t.Run("http", func(t *testing.T) {
+		load(apidef.ExternalAuthzConfig{
+			Enabled: true,
+			Type:    apidef.ExternalAuthzHTTP,
+			URL:     decisionPoint.URL,
+		})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{
+				Path:         "/authz/allowed?q=1",
+				Headers:      map[string]string{"X-Internal": "secret"},
+				Code:         http.StatusOK,
+				BodyMatch:    `"X-Authz-User":"alice"`,
+				BodyNotMatch: "X-Internal",
+			},
+			{
+				Path:         "/authz/denied",
+				Code:         http.StatusUnauthorized,
+				BodyMatch:    "not allowed",
+				HeadersMatch: map[string]string{"X-Authz-Reason": "policy"},
+			},
+			{Path: "/authz/undefined", Code: http.StatusForbidden, BodyMatch: "undefined policy decision"},
+		}...)
+
+		input := <-inputs
+		assert.Equal(t, http.MethodGet, input.Request.Method)
+		assert.Equal(t, "/authz/allowed", input.Request.Path)
+		assert.Equal(t, map[string][]string{"q": {"1"}}, input.Request.Query)
+		assert.Equal(t, "secret", input.Request.Headers["x-internal"])
+		assert.Equal(t, pdp.API{ID: "external-authorization", Name: "External authorization", ListenPath: "/authz/"}, input.API)
+		assert.Equal(t, "/allowed", input.Endpoint.Path)
+	})
+
+	t.Run("cached decisions", func(t *testing.T) {
+		load(apidef.ExternalAuthzConfig{
+			Enabled:         true,
+			Type:            apidef.ExternalAuthzHTTP,
+			URL:             decisionPoint.URL,
+			CacheTTL:        tyktime.ReadableDuration(time.Minute),
+			CacheKeyHeaders: []string{"X-Tenant"},
+		})
+
+		for len(inputs) > 0 {
+			<-inputs
+		}
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/authz/cached", Headers: map[string]string{"X-Tenant": "acme", "X-Request-Id": "1"}, Code: http.StatusOK},
+			{Path: "/authz/cached", Headers: map[string]string{"X-Tenant": "acme", "X-Request-Id": "2"}, Code: http.StatusOK},
+		}...)
+		assert.Len(t, inputs, 1)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/authz/cached", Headers: map[string]string{"X-Tenant": "other"}, Code: http.StatusOK})
+		assert.Len(t, inputs, 2)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer unavailable.Close()
+
+		conf := apidef.ExternalAuthzConfig{
+			Enabled: true,
+			Type:    apidef.ExternalAuthzHTTP,
+			URL:     unavailable.URL,
+		}
+
+		load(conf)
+		_, _ = ts.Run(t, test.TestCase{Path: "/authz/resource", Code: http.StatusServiceUnavailable})
+
+		conf.FailOpen = true
+		load(conf)
+		_, _ = ts.Run(t, test.TestCase{Path: "/authz/resource", Code: http.StatusOK})
+	})
+
+	t.Run("denied requests don't use the quota", func(t *testing.T) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/authz-keyed/"
+			spec.UseKeylessAccess = false
+			spec.ExternalAuthorization = apidef.ExternalAuthzConfig{
+				Enabled: true,
+				Type:    apidef.ExternalAuthzEmbedded,
+				Policy: `function authorize(input) {
+					return input.request.path !== "/authz-keyed/denied";
+				}`,
+			}
+		})
+
+		key := CreateSession(ts.Gw, func(s *user.SessionState) {
+			s.QuotaMax = 1
+		})
+		authHeaders := map[string]string{"authorization": key}
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/authz-keyed/denied", Headers: authHeaders, Code: http.StatusForbidden},
+			{Path: "/authz-keyed/allowed", Headers: authHeaders, Code: http.StatusOK},
+			{Path: "/authz-keyed/allowed", Headers: authHeaders, Code: http.StatusForbidden, BodyMatch: "Quota exceeded"},
+		}...)
+	})
+
+	t.Run("embedded", func(t *testing.T) {
+		load(apidef.ExternalAuthzConfig{
+			Enabled: true,
+			Type:    apidef.ExternalAuthzEmbedded,
+			Policy: `function authorize(input) {
+				if (input.request.method === "GET") {
+					return true;
+				}
+				return {allow: false, status: 405, reason: "read only"};
+			}`,
+		})
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/authz/resource", Method: http.MethodGet, Code: http.StatusOK},
+			{Path: "/authz/resource", Method: http.MethodDelete, Code: http.StatusMethodNotAllowed, BodyMatch: "read only"},
+		}...)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		load(apidef.ExternalAuthzConfig{
+			Enabled: true,
+			Type:    apidef.ExternalAuthzEmbedded,
+			Policy:  "function allow(input) { return true; }",
+		})
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/authz/resource", Code: http.StatusInternalServerError})
+	})
+}
+
+func TestExternalAuthorizationMiddleware_authorizationInput(t *testing.T) {
+	m := &ExternalAuthorizationMiddleware{BaseMiddleware: &BaseMiddleware{Spec: &APISpec{APIDefinition: &apidef.APIDefinition{
+		APIID: "api",
+		OrgID: "org",
+		Tags:  []string{"internal"},
+		Proxy: apidef.ProxyConfig{ListenPath: "/api/", StripListenPath: true},
+	}}}}
+
+	r := httptest.NewRequest(http.MethodPost, "http://example.com/api/users/1", nil)
+	r.Header.Add("Accept", "text/plain")
+	r.Header.Add("Accept", "application/json")
+	ctxSetTokenClaims(r, map[string]interface{}{"sub": "alice"})
+
+	input := m.authorizationInput(r)
+	require.NotNil(t, input)
+
+	assert.Equal(t, "http", input.Request.Scheme)
+	assert.Equal(t, "example.com", input.Request.Host)
+	assert.Equal(t, "example.com", input.Request.Headers["host"])
+	assert.Equal(t, "text/plain,application/json", input.Request.Headers["accept"])
+	assert.Equal(t, map[string]interface{}{"sub": "alice"}, input.Claims)
+	assert.Equal(t, pdp.API{ID: "api", OrgID: "org", ListenPath: "/api/", Tags: []string{"internal"}}, input.API)
+	assert.Equal(t, "/users/1", input.Endpoint.Path)
+	assert.Nil(t, input.Session)
+}
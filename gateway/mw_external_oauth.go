@@ -15,6 +15,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 
 	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/apidef/oas"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
 
@@ -71,12 +72,13 @@ func (k *ExternalOAuthMiddleware) ProcessRequest(w http.ResponseWriter, r *http.
 		return k.prmError(w, r, errors.New("authorization field missing"), missingAuthStatus)
 	}
 
-	token = stripBearer(token)
+	token = stripDPoP(stripBearer(token))
 
 	var (
 		valid      bool
 		err        error
 		identifier string
+		claims     jwt.MapClaims
 	)
 
 	if len(k.Spec.ExternalOAuth.Providers) == 0 {
@@ -87,9 +89,9 @@ func (k *ExternalOAuthMiddleware) ProcessRequest(w http.ResponseWriter, r *http.
 	provider := k.Spec.ExternalOAuth.Providers[0]
 
 	if provider.JWT.Enabled {
-		valid, identifier, err = k.jwt(token)
+		valid, identifier, claims, err = k.jwt(token)
 	} else if provider.Introspection.Enabled {
-		valid, identifier, err = k.introspection(token)
+		valid, identifier, claims, err = k.introspection(token)
 	} else {
 		return errors.New("access token validation method is not specified"), http.StatusInternalServerError
 	}
@@ -108,6 +110,17 @@ func (k *ExternalOAuthMiddleware) ProcessRequest(w http.ResponseWriter, r *http.
 		return k.prmError(w, r, errors.New("access token is not valid"), http.StatusUnauthorized)
 	}
 
+	if externalOAuth := k.oasExternalOAuthConfig(); externalOAuth != nil {
+		if err := k.Gw.checkDPoP(w, r, externalOAuth.DPoP, token, claims); err != nil {
+			return k.prmError(w, r, fmt.Errorf("access token is not valid: %w", err), http.StatusUnauthorized)
+		}
+		if err := checkCertificateBinding(r, externalOAuth.CertificateBinding, claims); err != nil {
+			return k.prmError(w, r, fmt.Errorf("access token is not valid: %w", err), http.StatusUnauthorized)
+		}
+	}
+
+	ctxSetTokenClaims(r, claims)
+
 	sessionID := k.generateSessionID(identifier)
 
 	k.Logger().Debug("External OAuth Temporary session ID is: ", sessionID)
@@ -125,9 +138,18 @@ func (k *ExternalOAuthMiddleware) ProcessRequest(w http.ResponseWriter, r *http.
 	return nil, http.StatusOK
 }
 
+// oasExternalOAuthConfig returns the external OAuth configuration of OAS APIs, or nil for classic APIs.
+func (k *ExternalOAuthMiddleware) oasExternalOAuthConfig() *oas.ExternalOAuth {
+	if !k.Spec.IsOAS {
+		return nil
+	}
+
+	return k.Spec.OAS.GetExternalOAuthConfiguration(k.Spec.AuthConfigs[apidef.ExternalOAuthType].Name)
+}
+
 // jwt makes access token validation without making a network call and validates access token locally.
 // The access token should be JWT type.
-func (k *ExternalOAuthMiddleware) jwt(accessToken string) (bool, string, error) {
+func (k *ExternalOAuthMiddleware) jwt(accessToken string) (bool, string, jwt.MapClaims, error) {
 	jwtValidation := k.Spec.ExternalOAuth.Providers[0].JWT
 	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
 	// Verify the token
@@ -146,29 +168,29 @@ func (k *ExternalOAuthMiddleware) jwt(accessToken string) (bool, string, error)
 		return parseJWTKey(jwtValidation.SigningMethod, val)
 	})
 	if err != nil {
-		return false, "", fmt.Errorf("token verification failed: %w", err)
+		return false, "", nil, fmt.Errorf("token verification failed: %w", err)
 	}
 	if token == nil || !token.Valid {
-		return false, "", errors.New("invalid token")
+		return false, "", nil, errors.New("invalid token")
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return false, "", errors.New("invalid token")
+		return false, "", nil, errors.New("invalid token")
 	}
 
 	if err := timeValidateJWTClaims(claims, jwtValidation.ExpiresAtValidationSkew,
 		jwtValidation.IssuedAtValidationSkew, jwtValidation.NotBeforeValidationSkew); err != nil {
-		return false, "", fmt.Errorf("key not authorized: %w", err)
+		return false, "", nil, fmt.Errorf("key not authorized: %w", err)
 	}
 
 	var userID string
 	userID, err = getUserIDFromClaim(claims, jwtValidation.IdentityBaseField, true)
 	if err != nil {
-		return false, "", err
+		return false, "", nil, err
 	}
 
-	return true, userID, nil
+	return true, userID, claims, nil
 }
 
 // getSecretFromJWKURL gets the secret to verify jwt signature from a JWK URL.
@@ -242,7 +264,7 @@ func (k *ExternalOAuthMiddleware) getSecretFromJWKOrConfig(kid interface{}, jwtV
 
 // introspection makes an introspection request to third-party provider to check whether the access token is valid or not.
 // The access token can be both JWT and opaque type.
-func (k *ExternalOAuthMiddleware) introspection(accessToken string) (bool, string, error) {
+func (k *ExternalOAuthMiddleware) introspection(accessToken string) (bool, string, jwt.MapClaims, error) {
 	opts := k.Spec.ExternalOAuth.Providers[0].Introspection
 
 	var (
@@ -263,7 +285,7 @@ func (k *ExternalOAuthMiddleware) introspection(accessToken string) (bool, strin
 		log.WithError(err).Debug("Doing OAuth introspection call")
 		claims, err = k.introspectWithClient(opts, accessToken)
 		if err != nil {
-			return false, "", fmt.Errorf("introspection err: %w", err)
+			return false, "", nil, fmt.Errorf("introspection err: %w", err)
 		}
 
 		if opts.Cache.Enabled {
@@ -276,25 +298,25 @@ func (k *ExternalOAuthMiddleware) introspection(accessToken string) (bool, strin
 		log.WithError(err).Debug("Found OAuth introspection result in the redis cache")
 
 		if isExpired(claims) {
-			return false, "", jwt.ErrTokenExpired
+			return false, "", nil, jwt.ErrTokenExpired
 		}
 	}
 
 	active, ok := claims["active"]
 	if !ok {
-		return false, "", errors.New("introspection result doesn't have active flag")
+		return false, "", nil, errors.New("introspection result doesn't have active flag")
 	}
 
 	if !active.(bool) {
-		return false, "", nil
+		return false, "", nil, nil
 	}
 
 	userID, err := getUserIDFromClaim(claims, opts.IdentityBaseField, true)
 	if err != nil {
-		return false, "", err
+		return false, "", nil, err
 	}
 
-	return true, userID, nil
+	return true, userID, claims, nil
 }
 
 // generateVirtualSessionFor generates a virtual session for the given access token by using its identifier.
@@ -1306,8 +1306,8 @@ func (k *JWTMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 		return k.prmError(w, r, errors.New("Authorization field missing"), missingAuthStatus)
 	}
 
-	// enable bearer token format
-	rawJWT = stripBearer(rawJWT)
+	// enable bearer and DPoP token formats
+	rawJWT = stripDPoP(stripBearer(rawJWT))
 
 	// Use own validation logic, see below
 	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
@@ -1339,7 +1339,20 @@ func (k *JWTMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Request, _
 			return k.prmError(w, r, errors.New("Key not authorized: "+err.Error()), http.StatusUnauthorized)
 		}
 
+		if jwtConfig := k.oasJWTConfig(); jwtConfig != nil {
+			claims := token.Claims.(jwt.MapClaims)
+			if err := k.Gw.checkDPoP(w, r, jwtConfig.DPoP, rawJWT, claims); err != nil {
+				ctx.SetErrorClassification(r, tykerrors.ClassifyJWTError(tykerrors.ErrTypeTokenInvalid, k.Name()))
+				return k.prmError(w, r, errors.New("Key not authorized: "+err.Error()), http.StatusUnauthorized)
+			}
+			if err := checkCertificateBinding(r, jwtConfig.CertificateBinding, claims); err != nil {
+				ctx.SetErrorClassification(r, tykerrors.ClassifyJWTError(tykerrors.ErrTypeTokenInvalid, k.Name()))
+				return k.prmError(w, r, errors.New("Key not authorized: "+err.Error()), http.StatusUnauthorized)
+			}
+		}
+
 		// Token is valid - let's move on
+		ctxSetTokenClaims(r, token.Claims.(jwt.MapClaims))
 
 		// Are we mapping to a central JWT Secret?
 		hasJWTSource := k.Spec.JWTSource != ""
@@ -1414,6 +1427,15 @@ func (k *JWTMiddleware) validateClaims(token *jwt.Token) error {
 	return nil
 }
 
+// oasJWTConfig returns the JWT configuration of OAS APIs, or nil for classic APIs.
+func (k *JWTMiddleware) oasJWTConfig() *oas.JWT {
+	if !k.Spec.IsOAS {
+		return nil
+	}
+
+	return k.Spec.OAS.GetJWTConfiguration()
+}
+
 func validateIssuer(claims jwt.MapClaims, allowedIssuers []string) error {
 	iss, exists := claims[ISS]
 	if !exists {
@@ -73,17 +73,26 @@ func (k *RateLimitAndQuotaCheck) ProcessRequest(w http.ResponseWriter, r *http.R
 
 	limitHeader := k.Gw.limitHeaderFactory(w.Header())
 
-	reason := k.Gw.SessionLimiter.ForwardMessage(
-		r,
-		session,
-		rateLimitKey,
-		quotaKey,
-		!k.Spec.DisableRateLimit,
-		!k.Spec.DisableQuota,
-		k.Spec,
-		false,
-		limitHeader,
-	)
+	forward := func(dryRun bool) sessionFailReason {
+		return k.Gw.SessionLimiter.ForwardMessage(
+			r,
+			session,
+			rateLimitKey,
+			quotaKey,
+			!k.Spec.DisableRateLimit,
+			!k.Spec.DisableQuota && !dryRun,
+			k.Spec,
+			dryRun,
+			limitHeader,
+		)
+	}
+
+	reason := forward(false)
+	if reason == sessionFailRateLimit {
+		// the rate limit is checked before the quota, so blocked retries
+		// don't use it up
+		reason = k.queueRateLimited(r, rateLimitKey, forward)
+	}
 
 	throttleRetryLimit := session.ThrottleRetryLimit
 	throttleInterval := session.ThrottleInterval
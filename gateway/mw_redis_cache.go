@@ -1,7 +1,6 @@
 package gateway
 
 import (
-	"bufio"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
@@ -10,8 +9,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/TykTechnologies/murmur3"
@@ -33,6 +32,11 @@ type RedisCacheMiddleware struct {
 
 	store storage.Handler
 	sh    SuccessHandler
+
+	// refreshing holds the keys of stale entries being refreshed.
+	refreshing sync.Map
+	// flights holds the requests fetching missing entries, by key.
+	flights sync.Map
 }
 
 func (m *RedisCacheMiddleware) Name() string {
@@ -116,17 +120,20 @@ func isBodyHashRequired(request *http.Request) bool {
 }
 
 func (m *RedisCacheMiddleware) isTimeStampExpired(timestamp string) bool {
-	i, err := strconv.ParseInt(timestamp, 10, 64)
+	expiry, err := parseCacheExpiry(timestamp)
 	if err != nil {
 		m.Logger().Error(err)
 		return true
 	}
 
-	tm := time.Unix(i, 0)
-	return tm.Before(time.Now())
+	return expiry.expired(time.Now())
 }
 
 func (m *RedisCacheMiddleware) decodePayload(payload string) (string, string, error) {
+	if compressed, ok := strings.CutPrefix(payload, compressedPayloadPrefix); ok {
+		return decompressPayload(compressed)
+	}
+
 	data := strings.Split(payload, "|")
 	switch len(data) {
 	case 1:
@@ -147,6 +154,28 @@ type cacheOptions struct {
 	key                    string
 	cacheOnlyResponseCodes []int
 	timeout                int64
+	staleWhileRevalidate   int64
+	staleIfError           int64
+
+	// stale is the expired cached response, served in place of upstream errors.
+	stale string
+	// revalidate is set when a stale response was served and the entry must be refreshed.
+	revalidate bool
+	// servingStale is set when the stale response is served in place of an unreachable upstream.
+	servingStale bool
+	// revalidating is the expired cached response the request revalidates with the upstream.
+	revalidating string
+	// flight is set when the request fetches the entry for coalesced requests.
+	flight *cacheFlight
+	// path is the method and API path of the request, indexing the entry for purges.
+	path string
+	// surrogateKeys are the configured surrogate keys of the entry.
+	surrogateKeys []string
+	// varyBase is the cache key of the request before its Vary variant is applied,
+	// set when the upstream Vary header is honoured.
+	varyBase string
+	// varyHeader holds the request headers selecting the Vary variant.
+	varyHeader http.Header
 }
 
 // ProcessRequest will run any checks on the request on the way through the system, return an error to have the chain fail
@@ -186,8 +215,16 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		token = request.RealIP(r)
 	}
 
-	var retBlob string
-	key, err := m.CreateCheckSum(r, token, cacheKeyRegex, m.getCacheKeyFromHeaders(r))
+	var (
+		retBlob string
+		key     string
+		err     error
+	)
+	if cacheMeta != nil && cacheMeta.CacheKey.Enabled {
+		key, err = m.composeCacheKey(r, token, cacheMeta.CacheKey, cacheKeyRegex)
+	} else {
+		key, err = m.CreateCheckSum(r, token, cacheKeyRegex, m.getCacheKeyFromHeaders(r))
+	}
 	if err != nil {
 		m.Logger().Debug("Error creating checksum. Skipping cache check")
 		return nil, http.StatusOK
@@ -207,47 +244,94 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	ctxSetCacheOptions(r, &cacheOptions{
+	options := &cacheOptions{
 		key:                    key,
 		cacheOnlyResponseCodes: cacheOnlyResponseCodes,
 		timeout:                timeout,
-	})
+		staleWhileRevalidate:   m.Spec.CacheOptions.StaleWhileRevalidate,
+		staleIfError:           m.Spec.CacheOptions.StaleIfError,
+		path:                   cachePath(r.Method, m.Spec.StripListenPath(r.URL.Path)),
+	}
+	if cacheMeta != nil {
+		for _, template := range cacheMeta.SurrogateKeys {
+			if surrogateKey := m.Gw.ReplaceTykVariables(r, template, false); surrogateKey != "" {
+				options.surrogateKeys = append(options.surrogateKeys, surrogateKey)
+			}
+		}
+
+		if cacheMeta.CacheKey.Enabled && cacheMeta.CacheKey.HonourVary {
+			key = m.varyCacheKey(r, options)
+		}
+	}
+	ctxSetCacheOptions(r, options)
+
+	if newRes, ok := m.Gw.responseCacheL1.get(r, key); ok {
+		return m.serveCachedResponse(w, r, newRes, t1)
+	}
 
 	retBlob, err = m.store.GetKey(key)
 	if err != nil {
-		// Record not found, continue with the middleware chain
-		return nil, http.StatusOK
+		// the entry expired or was evicted
+		m.Gw.cacheUsage.remove(key)
+		// Record not found, wait for a concurrent request fetching it or continue with the middleware chain
+		if retBlob, err = m.coalesce(r, options); err != nil {
+			return nil, http.StatusOK
+		}
 	}
 
 	cachedData, timestamp, err := m.decodePayload(retBlob)
 	if err != nil {
 		// Tere was an issue with this cache entry - lets remove it:
-		m.store.DeleteKey(key)
+		m.deleteKey(key)
 		return nil, http.StatusOK
 	}
 
-	if m.isTimeStampExpired(timestamp) || len(cachedData) == 0 {
-		m.store.DeleteKey(key)
+	expiry, err := parseCacheExpiry(timestamp)
+	if err != nil || len(cachedData) == 0 {
+		m.deleteKey(key)
 		return nil, http.StatusOK
 	}
 
-	bufData := bufio.NewReader(strings.NewReader(cachedData))
-	newRes, err := http.ReadResponse(bufData, r)
+	var warning string
+	if now := time.Now(); expiry.expired(now) {
+		switch {
+		case expiry.staleWhileRevalidating(now) && isSafeMethod(r.Method):
+			// serve the stale entry, the rest of the chain refreshes it in the background
+			warning = staleWarning
+			options.stale = cachedData
+			options.revalidate = true
+		case expiry.staleIfErrorAllowed(now):
+			// go upstream, keeping the entry in case it fails
+			options.stale = cachedData
+			m.conditional(r, options, cachedData)
+			return nil, http.StatusOK
+		default:
+			if !m.conditional(r, options, cachedData) {
+				m.deleteKey(key)
+			}
+			return nil, http.StatusOK
+		}
+	}
+
+	newRes, err := readCachedResponse(r, cachedData, warning)
 	if err != nil {
 		m.Logger().WithError(err).Error("Could not create response object")
-		m.store.DeleteKey(key)
+		m.deleteKey(key)
 		return nil, http.StatusOK
 	}
 
-	nopCloseResponseBody(newRes)
+	if warning == "" {
+		m.Gw.responseCacheL1.set(key, newRes, expiry.expires)
+	}
+
+	return m.serveCachedResponse(w, r, newRes, t1)
+}
 
+// serveCachedResponse writes the cached response of r out and records it.
+func (m *RedisCacheMiddleware) serveCachedResponse(w http.ResponseWriter, r *http.Request, newRes *http.Response, t1 time.Time) (error, int) {
 	defer newRes.Body.Close()
-	for _, h := range hopHeaders {
-		newRes.Header.Del(h)
-	}
 
 	m.Gw.limitHeaderFactory(newRes.Header).SendQuotas(ctxGetSession(r), m.Spec.APIID)
-	newRes.Header.Set(cachedResponseHeader, "1")
 
 	copyHeader(w.Header(), newRes.Header, m.Gw.GetConfig().IgnoreCanonicalMIMEHeaderKey)
 
@@ -277,6 +361,12 @@ func (m *RedisCacheMiddleware) ProcessRequest(w http.ResponseWriter, r *http.Req
 	return nil, middleware.StatusRespond
 }
 
+// deleteKey deletes the cache entry stored under key.
+func (m *RedisCacheMiddleware) deleteKey(key string) {
+	m.store.DeleteKey(key)
+	m.Gw.cacheUsage.remove(key)
+}
+
 func isSafeMethod(method string) bool {
 	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
 }
@@ -0,0 +1,79 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
+)
+
+// newOutlierDetector creates the outlier detector of a load balanced API, or
+// returns nil when outlier detection is disabled. Ejections and returns fire
+// the HostDown and HostUp events.
+func newOutlierDetector(spec *APISpec) *loadbalancer.OutlierDetector {
+	conf := spec.Proxy.LoadBalancing.OutlierDetection
+	if !spec.Proxy.EnableLoadBalancing || !conf.Enabled {
+		return nil
+	}
+
+	hostReport := func(host string) HostHealthReport {
+		return HostHealthReport{
+			HostData: HostData{
+				CheckURL: host,
+				MetaData: map[string]string{UnHealthyHostMetaDataAPIKey: spec.APIID},
+			},
+		}
+	}
+
+	return loadbalancer.NewOutlierDetector(loadbalancer.OutlierConfig{
+		ConsecutiveFailures: conf.ConsecutiveFailures,
+		BaseEjectionTime:    time.Duration(conf.BaseEjectionTime),
+		MaxEjectionTime:     time.Duration(conf.MaxEjectionTime),
+		MaxEjectionPercent:  conf.MaxEjectionPercent,
+		OnEject: func(host string, duration time.Duration) {
+			log.WithFields(logrus.Fields{
+				"prefix":   "outlier-detection",
+				"api_id":   spec.APIID,
+				"duration": duration,
+			}).Warning("Host ejected from load balancing pool: ", host)
+
+			spec.FireEvent(EventHOSTDOWN, EventHostStatusMeta{
+				EventMetaDefault: EventMetaDefault{Message: "Outlier detection ejected host"},
+				HostInfo:         hostReport(host),
+			})
+		},
+		OnReturn: func(host string) {
+			log.WithFields(logrus.Fields{
+				"prefix": "outlier-detection",
+				"api_id": spec.APIID,
+			}).Info("Host returned to load balancing pool: ", host)
+
+			spec.FireEvent(EventHOSTUP, EventHostStatusMeta{
+				EventMetaDefault: EventMetaDefault{Message: "Outlier detection returned host"},
+				HostInfo:         hostReport(host),
+			})
+		},
+	})
+}
+
+// reportOutlier reports the outcome of an upstream attempt to the outlier
+// detector. Requests cancelled by the client don't count as failures.
+func (p *ReverseProxy) reportOutlier(outreq *http.Request, res *http.Response, err error) {
+	detector := p.TykAPISpec.OutlierDetector
+	host := ctxGetLoadBalancerTarget(outreq)
+	if detector == nil || host == "" {
+		return
+	}
+
+	if err != nil && (errors.Is(err, context.Canceled) || outreq.Context().Err() == context.Canceled) {
+		return
+	}
+
+	failed := err != nil || (res != nil && res.StatusCode/100 == 5)
+	poolSize := len(loadbalancer.TargetsFromList(p.TykAPISpec.Proxy.StructuredTargetList.All()))
+	detector.Report(host, failed, poolSize)
+}
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestOutlierDetection(t *testing.T) {
+	var failingHits, healthyHits atomic.Int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		failingHits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		healthyHits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = []string{failing.URL, healthy.URL}
+		spec.Proxy.LoadBalancing.OutlierDetection = apidef.OutlierDetectionConfig{
+			Enabled:             true,
+			ConsecutiveFailures: 2,
+			BaseEjectionTime:    tyktime.ReadableDuration(time.Minute),
+			MaxEjectionPercent:  50,
+		}
+	})[0]
+
+	assert.NotNil(t, spec.OutlierDetector)
+
+	for i := 0; i < 10; i++ {
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Method: http.MethodGet})
+	}
+
+	assert.EqualValues(t, 2, failingHits.Load())
+	assert.EqualValues(t, 8, healthyHits.Load())
+	assert.True(t, spec.OutlierDetector.Ejected(failing.URL))
+	assert.False(t, spec.OutlierDetector.Ejected(healthy.URL))
+}
+
+func TestNewOutlierDetector(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{}}
+	spec.Proxy.LoadBalancing.OutlierDetection.Enabled = true
+	assert.Nil(t, newOutlierDetector(spec))
+
+	spec.Proxy.EnableLoadBalancing = true
+	assert.NotNil(t, newOutlierDetector(spec))
+}
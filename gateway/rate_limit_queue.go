@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/rate/queue"
+)
+
+const (
+	// rateLimitPriorityMeta is the key metadata holding the priority class
+	// of its requests in the rate limit queue.
+	rateLimitPriorityMeta = "rate_limit_priority"
+
+	// rateLimitQueuedTag is added to the analytics of requests that waited in
+	// the rate limit queue, along with the buckets of the queue depth and the
+	// wait time.
+	rateLimitQueuedTag           = "rate-limit-queued"
+	rateLimitQueueDepthTagPrefix = "rate-limit-queue-depth-"
+	rateLimitQueueWaitTagPrefix  = "rate-limit-queue-wait-"
+)
+
+var (
+	// rateLimitQueueDepthBuckets are the upper bounds of the queue depth
+	// buckets, so that the number of distinct analytics tags is bounded.
+	rateLimitQueueDepthBuckets = []int{1, 5, 10, 25, 50, 100}
+	// rateLimitQueueWaitBuckets are the upper bounds of the wait time buckets.
+	rateLimitQueueWaitBuckets = []time.Duration{
+		10 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		5 * time.Second,
+	}
+)
+
+// newRateLimitQueue creates the queue of requests exceeding the limits of an
+// API, or returns nil when queuing is disabled.
+func newRateLimitQueue(conf apidef.RateLimitQueueConfig) *queue.Queue {
+	if !conf.Enabled {
+		return nil
+	}
+
+	return queue.NewQueue(queue.Config{
+		MaxSize: conf.MaxSize,
+		MaxWait: time.Duration(conf.MaxWait),
+	})
+}
+
+// rateLimitPriority returns the priority of the request in the rate limit
+// queue, from the priority class in the key metadata or the priority header.
+func (t *BaseMiddleware) rateLimitPriority(r *http.Request) int {
+	conf := t.Spec.RateLimitQueue
+
+	var class string
+	if session := ctxGetSession(r); session != nil {
+		class, _ = session.MetaData[rateLimitPriorityMeta].(string)
+	}
+	if class == "" && conf.PriorityHeader != "" {
+		class = r.Header.Get(conf.PriorityHeader)
+	}
+
+	return conf.PriorityClasses[class]
+}
+
+// waitInRateLimitQueue queues a request that exceeded the limit named key
+// until try lets it through. It returns false if queuing is disabled or the
+// request is rejected by the queue, in which case the limit failure stands.
+func (t *BaseMiddleware) waitInRateLimitQueue(r *http.Request, key string, try func() bool) bool {
+	if t.Spec.WaitQueue == nil {
+		return false
+	}
+
+	result, err := t.Spec.WaitQueue.Wait(r.Context(), key, t.rateLimitPriority(r), try)
+	if result.Depth > 0 {
+		// the request may have waited for more than one limit
+		if previous, ok := ctxGetRateLimitQueueResult(r); ok {
+			result.Depth = max(result.Depth, previous.Depth)
+			result.Waited += previous.Waited
+		}
+		ctxSetRateLimitQueueResult(r, result)
+	}
+
+	if err != nil {
+		t.Logger().WithError(err).Debug("Request rejected by the rate limit queue")
+		return false
+	}
+
+	return true
+}
+
+// queueRateLimited queues a request that exceeded the rate limit named key,
+// and forwards it again whenever a dry run finds room in the limit. Limiters
+// like the sliding log record blocked requests too, so retrying with actual
+// checks would keep filling the window the request waits on. It returns the
+// outcome of the last forward, sessionFailRateLimit if the request is rejected
+// by the queue.
+func (t *BaseMiddleware) queueRateLimited(r *http.Request, key string, forward func(dryRun bool) sessionFailReason) sessionFailReason {
+	reason := sessionFailRateLimit
+	t.waitInRateLimitQueue(r, key, func() bool {
+		if forward(true) == sessionFailRateLimit {
+			return false
+		}
+
+		reason = forward(false)
+		return reason != sessionFailRateLimit
+	})
+
+	return reason
+}
+
+// rateLimitQueueTags adds the buckets of the queue depth and wait time of
+// requests that waited in the rate limit queue to their analytics tags, e.g.
+// rate-limit-queue-depth-le-5 and rate-limit-queue-wait-le-100ms.
+func rateLimitQueueTags(r *http.Request, tags []string) []string {
+	result, ok := ctxGetRateLimitQueueResult(r)
+	if !ok {
+		return tags
+	}
+
+	depthTag := rateLimitQueueDepthTagPrefix + "gt-" + strconv.Itoa(rateLimitQueueDepthBuckets[len(rateLimitQueueDepthBuckets)-1])
+	for _, bound := range rateLimitQueueDepthBuckets {
+		if result.Depth <= bound {
+			depthTag = rateLimitQueueDepthTagPrefix + "le-" + strconv.Itoa(bound)
+			break
+		}
+	}
+
+	waitTag := rateLimitQueueWaitTagPrefix + "gt-" + rateLimitQueueWaitBuckets[len(rateLimitQueueWaitBuckets)-1].String()
+	for _, bound := range rateLimitQueueWaitBuckets {
+		if result.Waited <= bound {
+			waitTag = rateLimitQueueWaitTagPrefix + "le-" + bound.String()
+			break
+		}
+	}
+
+	return append(tags, rateLimitQueuedTag, depthTag, waitTag)
+}
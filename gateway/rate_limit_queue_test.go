@@ -0,0 +1,196 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/rate/queue"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func TestRateLimitQueue(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	build := func(listenPath string, maxWait time.Duration) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = listenPath
+			spec.UseKeylessAccess = true
+			spec.GlobalRateLimit = apidef.GlobalRateLimit{
+				Rate:      1,
+				Per:       1,
+				Algorithm: "gcra",
+			}
+			spec.RateLimitQueue = apidef.RateLimitQueueConfig{
+				Enabled: true,
+				MaxWait: tyktime.ReadableDuration(maxWait),
+			}
+		})
+	}
+
+	t.Run("waits for the limit", func(t *testing.T) {
+		build("/queued/", 3*time.Second)
+
+		start := time.Now()
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/queued/", Code: http.StatusOK},
+			{Path: "/queued/", Code: http.StatusOK},
+		}...)
+		assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("queued requests are served", func(t *testing.T) {
+		build("/queued-served/", 5*time.Second)
+
+		// the limit lets one request through every second, the others
+		// queue and must each get through once the limit frees up
+		codes := make(chan int, 3)
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := ts.Do(test.TestCase{Path: "/queued-served/"})
+				if !assert.NoError(t, err) {
+					return
+				}
+				_ = resp.Body.Close()
+				codes <- resp.StatusCode
+			}()
+		}
+		wg.Wait()
+		close(codes)
+
+		for code := range codes {
+			assert.Equal(t, http.StatusOK, code)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 1500*time.Millisecond)
+	})
+
+	t.Run("queued key requests are served", func(t *testing.T) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.APIID = "queued-key"
+			spec.Proxy.ListenPath = "/queued-key/"
+			spec.UseKeylessAccess = false
+			spec.RateLimitQueue = apidef.RateLimitQueueConfig{
+				Enabled: true,
+				MaxWait: tyktime.ReadableDuration(3 * time.Second),
+			}
+		})
+
+		_, key := ts.CreateSession(func(s *user.SessionState) {
+			s.Rate = 1
+			s.Per = 1
+			s.RateLimitAlgorithm = "gcra"
+			s.AccessRights = map[string]user.AccessDefinition{"queued-key": {
+				APIID: "queued-key", Versions: []string{"v1"},
+			}}
+		})
+
+		authHeaders := map[string]string{"Authorization": key}
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/queued-key/", Headers: authHeaders, Code: http.StatusOK},
+			{Path: "/queued-key/", Headers: authHeaders, Code: http.StatusOK},
+		}...)
+	})
+
+	t.Run("rejects after the max wait", func(t *testing.T) {
+		build("/queue-timeout/", 10*time.Millisecond)
+
+		_, _ = ts.Run(t, []test.TestCase{
+			{Path: "/queue-timeout/", Code: http.StatusOK},
+			{Path: "/queue-timeout/", Code: http.StatusTooManyRequests},
+		}...)
+	})
+}
+
+func TestRateLimitQueue_slidingLog(t *testing.T) {
+	ts := StartTest(func(globalConf *config.Config) {
+		globalConf.EnableRedisRollingLimiter = true
+	})
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/queued-sliding-log/"
+		spec.UseKeylessAccess = true
+		spec.GlobalRateLimit = apidef.GlobalRateLimit{Rate: 1, Per: 1}
+		spec.RateLimitQueue = apidef.RateLimitQueueConfig{
+			Enabled: true,
+			MaxWait: tyktime.ReadableDuration(5 * time.Second),
+		}
+	})
+
+	// the sliding log records blocked requests, queued requests must not keep
+	// filling the window they wait on
+	codes := make(chan int, 3)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := ts.Do(test.TestCase{Path: "/queued-sliding-log/"})
+			if !assert.NoError(t, err) {
+				return
+			}
+			_ = resp.Body.Close()
+			codes <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+}
+
+func TestRateLimitPriority(t *testing.T) {
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{
+		RateLimitQueue: apidef.RateLimitQueueConfig{
+			Enabled:         true,
+			PriorityHeader:  "X-Priority",
+			PriorityClasses: map[string]int{"premium": 10, "standard": 5},
+		},
+	}}
+	mw := &BaseMiddleware{Spec: spec}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, 0, mw.rateLimitPriority(r))
+
+	r.Header.Set("X-Priority", "standard")
+	assert.Equal(t, 5, mw.rateLimitPriority(r))
+
+	session := &user.SessionState{MetaData: map[string]interface{}{rateLimitPriorityMeta: "premium"}}
+	ctxSetSession(r, session, false, false)
+	assert.Equal(t, 10, mw.rateLimitPriority(r))
+}
+
+func TestRateLimitQueueTags(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, []string{"tag"}, rateLimitQueueTags(r, []string{"tag"}))
+
+	ctxSetRateLimitQueueResult(r, queue.Result{Depth: 3, Waited: 250 * time.Millisecond})
+	assert.Equal(t, []string{
+		"tag",
+		rateLimitQueuedTag,
+		"rate-limit-queue-depth-le-5",
+		"rate-limit-queue-wait-le-250ms",
+	}, rateLimitQueueTags(r, []string{"tag"}))
+
+	ctxSetRateLimitQueueResult(r, queue.Result{Depth: 1000, Waited: time.Minute})
+	assert.Equal(t, []string{
+		rateLimitQueuedTag,
+		"rate-limit-queue-depth-gt-100",
+		"rate-limit-queue-wait-gt-5s",
+	}, rateLimitQueueTags(r, nil))
+}
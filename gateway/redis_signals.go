@@ -46,6 +46,12 @@ const (
 	NoticeUserKeyReset              NotificationCommand = "UserKeyReset"
 	NoticeInvalidateJWKSCacheForAPI NotificationCommand = "InvalidateJWKSCacheForAPI"
 	NoticeClientIdPChanged          NotificationCommand = "ClientIdPChanged"
+	// NoticeDeleteAPICacheL1 is the command with which gateways drop the responses of an API from their in-memory cache.
+	NoticeDeleteAPICacheL1 NotificationCommand = "DeleteAPICacheL1"
+	// NoticePurgeAPICacheKeys is the command with which gateways drop purged responses from their in-memory cache.
+	NoticePurgeAPICacheKeys NotificationCommand = "PurgeAPICacheKeys"
+	// NoticeCircuitBreakerState is the command with which gateways share the state of circuit breakers.
+	NoticeCircuitBreakerState NotificationCommand = "CircuitBreakerState"
 )
 
 // Notification is a type that encodes a message published to a pub sub channel (shared between implementations)
@@ -157,9 +163,18 @@ func (gw *Gateway) handleRedisEvent(v interface{}, handled func(NotificationComm
 			log.WithError(err).Errorf("error while purging tokens for event %s", OAuthPurgeLapsedTokens)
 		}
 	case NoticeDeleteAPICache:
-		if ok := gw.invalidateAPICache(notif.Payload); !ok {
+		if ok := gw.invalidateAPICacheOnce(notif.Payload); !ok {
 			log.WithError(err).Errorf("cache invalidation failed for: %s", notif.Payload)
 		}
+	case NoticeDeleteAPICacheL1:
+		gw.responseCacheL1.invalidate(notif.Payload)
+		gw.cacheUsage.clear(notif.Payload)
+	case NoticePurgeAPICacheKeys:
+		keys := strings.Split(notif.Payload, ",")
+		gw.responseCacheL1.delete(keys...)
+		gw.cacheUsage.remove(keys...)
+	case NoticeCircuitBreakerState:
+		gw.handleCircuitBreakerState(notif.Payload)
 	case NoticeInvalidateJWKSCacheForAPI:
 		gw.invalidateJWKSCacheByAPIID(notif.Payload)
 	case NoticeClientIdPChanged:
@@ -5,9 +5,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/TykTechnologies/tyk/header"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/user"
 )
@@ -53,6 +56,33 @@ func (m *ResponseCacheMiddleware) encodePayload(payload string, timestamp int64)
 	return sEnc + "|" + fmt.Sprint(timestamp)
 }
 
+// encodeStalePayload encodes the payload of an entry that can be served stale
+// for the given number of seconds after it expires.
+func (m *ResponseCacheMiddleware) encodeStalePayload(payload string, timestamp, staleWhileRevalidate, staleIfError int64) string {
+	sEnc := base64.StdEncoding.EncodeToString([]byte(payload))
+	return sEnc + "|" + formatCacheExpiry(timestamp, staleWhileRevalidate, staleIfError)
+}
+
+// replaceWithStale replaces the upstream error response res with the stale
+// cached response.
+func (m *ResponseCacheMiddleware) replaceWithStale(res *http.Response, r *http.Request, stale string) {
+	staleRes, err := readCachedResponse(r, stale, revalidationFailedWarning)
+	if err != nil {
+		m.logger().WithError(err).Error("could not create stale response object")
+		return
+	}
+
+	m.logger().Debug("Upstream failed, serving stale cached response")
+
+	res.Body.Close()
+	res.Status = staleRes.Status
+	res.StatusCode = staleRes.StatusCode
+	res.Header = staleRes.Header
+	res.Body = staleRes.Body
+	res.ContentLength = staleRes.ContentLength
+	res.Trailer = staleRes.Trailer
+}
+
 // HandleResponse checks if the http.Response argument can be cached and caches it for future requests.
 func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *http.Response, r *http.Request, ses *user.SessionState) error {
 	// No cache of empty responses
@@ -73,8 +103,25 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 		return nil
 	}
 
+	// the stale response served in place of an unreachable upstream isn't stored again
+	if options.servingStale {
+		return nil
+	}
+
+	// serve the cached response the upstream revalidated, refreshing its TTL
+	if res.StatusCode == http.StatusNotModified && options.revalidating != "" {
+		m.replaceWithRevalidated(res, r, options.revalidating)
+	}
+
+	// keep serving the stale entry instead of the upstream error
+	if res.StatusCode >= http.StatusInternalServerError && options.stale != "" {
+		m.replaceWithStale(res, r, options.stale)
+		return nil
+	}
+
 	cacheThisRequest := true
 	cacheTTL := options.timeout
+	staleWhileRevalidate, staleIfError := options.staleWhileRevalidate, options.staleIfError
 
 	// make sure the status codes match if specified
 	if len(options.cacheOnlyResponseCodes) > 0 {
@@ -108,11 +155,26 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 				cacheTTL = int64(cacheAsInt)
 			}
 		}
+
+		staleWhileRevalidate, staleIfError = staleDirectives(res.Header.Get(header.CacheControl), staleWhileRevalidate, staleIfError)
 	}
 
 	var toStore string
 	var err error
 
+	key := options.key
+	var vary []string
+	if cacheThisRequest && options.varyBase != "" {
+		vary = varyHeaderNames(res.Header)
+		switch {
+		case slices.Contains(vary, "*"):
+			// the response doesn't only vary on request headers
+			cacheThisRequest = false
+		case len(vary) > 0:
+			key = varyKey(options.varyBase, vary, options.varyHeader)
+		}
+	}
+
 	if cacheThisRequest {
 		res.Body, err = newNopCloserBuffer(res.Body)
 		if err != nil {
@@ -128,11 +190,46 @@ func (m *ResponseCacheMiddleware) HandleResponse(w http.ResponseWriter, res *htt
 
 		ts := m.getTimeTTL(cacheTTL)
 		toStore = m.encodePayload(wireFormatReq.String(), ts)
+		if staleWhileRevalidate > 0 || staleIfError > 0 {
+			toStore = m.encodeStalePayload(wireFormatReq.String(), ts, staleWhileRevalidate, staleIfError)
+		}
+
+		compressed, ok := m.compressPayload(wireFormatReq.Bytes(), formatCacheExpiry(ts, staleWhileRevalidate, staleIfError))
+		if ok {
+			toStore = compressed
+		}
+		responseSize := wireFormatReq.Len()
+
+		// keep the entry around for as long as it can be served stale or revalidated
+		cacheTTL += max(staleWhileRevalidate, staleIfError, m.revalidationWindow(res.Header))
+
+		if options.flight != nil && len(vary) == 0 {
+			// share the entry with the requests coalesced on this one
+			options.flight.payload = toStore
+		}
+
+		surrogateKeys := append(surrogateKeysFromHeader(res.Header), options.surrogateKeys...)
 
 		go func() {
-			err := m.store.SetKey(options.key, toStore, cacheTTL)
+			// index the entry first, so that it can be purged as soon as it is served
+			indexCacheEntry(m.store, key, options.path, surrogateKeys, cacheTTL)
+
+			err := m.store.SetKey(key, toStore, cacheTTL)
 			if err != nil {
 				m.logger().WithError(err).Error("could not save key in cache store")
+				return
+			}
+
+			if len(vary) > 0 {
+				// record the headers selecting the variant for the next requests
+				if err := m.store.SetKey(options.varyBase+varySuffix, strings.Join(vary, ","), cacheTTL); err != nil {
+					m.logger().WithError(err).Error("could not save vary headers in cache store")
+				}
+			}
+
+			m.Gw.cacheUsage.add(m.Spec.APIID, key, len(toStore), ok, time.Duration(cacheTTL)*time.Second)
+			if m.Gw.MetricInstruments != nil {
+				m.Gw.MetricInstruments.RecordCacheStore(m.Gw.ctx, m.Spec.APIID, responseSize, ok)
 			}
 		}()
 	}
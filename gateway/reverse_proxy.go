@@ -44,10 +44,12 @@ import (
 	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
 	"github.com/TykTechnologies/tyk/internal/graphengine"
 	"github.com/TykTechnologies/tyk/internal/httputil"
+	"github.com/TykTechnologies/tyk/internal/loadbalancer"
 	"github.com/TykTechnologies/tyk/internal/mcp"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/service/core"
 	"github.com/TykTechnologies/tyk/regexp"
+	"github.com/TykTechnologies/tyk/request"
 	"github.com/TykTechnologies/tyk/storage"
 	"github.com/TykTechnologies/tyk/trace"
 	"github.com/TykTechnologies/tyk/user"
@@ -156,9 +158,31 @@ func EnsureTransport(host, protocol string) string {
 }
 
 func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (string, error) {
+	return gw.nextRequestTarget(nil, targetData, spec)
+}
+
+// nextRequestTarget picks the upstream target for r. The request is used to
+// resolve the hash key of the consistent hash strategy and may be nil.
+func (gw *Gateway) nextRequestTarget(r *http.Request, targetData *apidef.HostList, spec *APISpec) (string, error) {
 	if spec.Proxy.EnableLoadBalancing {
 		log.Debug("[PROXY] [LOAD BALANCING] Load balancer enabled, getting upstream target")
+
+		algorithm := loadbalancer.Algorithm(spec.Proxy.LoadBalancing.Algorithm)
+		if algorithm != "" && algorithm != loadbalancer.RoundRobin {
+			targets := loadbalancer.TargetsFromList(targetData.All())
+			for i := range targets {
+				targets[i].Host = EnsureTransport(targets[i].Host, spec.Protocol)
+			}
+
+			return spec.LoadBalancer.Next(targets, loadbalancer.Options{
+				Algorithm: algorithm,
+				HashKey:   loadBalancingHashKey(r, spec),
+				Healthy:   gw.upstreamHostHealthy(spec),
+			})
+		}
+
 		// Use a HostList
+		healthy := gw.upstreamHostHealthy(spec)
 		startPos := spec.RoundRobin.WithLen(targetData.Len())
 		pos := startPos
 		for {
@@ -168,18 +192,8 @@ func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (strin
 			}
 
 			host := EnsureTransport(gotHost, spec.Protocol)
-			if !spec.Proxy.CheckHostAgainstUptimeTests {
-				return host, nil // we don't care if it's up
-			}
-
-			// GlobalHostCheck has not been initialized, return the host picked
-			// by round-robin algorithm.
-			if gw.GlobalHostChecker == nil {
-				return host, nil // we don't care if it's up
-			}
-			// As checked by HostCheckerManager.AmIPolling
-			if !gw.GlobalHostChecker.HostDown(host) {
-				return host, nil // we do care and it's up
+			if healthy == nil || healthy(host) {
+				return host, nil
 			}
 			// if the host is down, keep trying all the rest
 			// in order from where we started.
@@ -199,6 +213,51 @@ func (gw *Gateway) nextTarget(targetData *apidef.HostList, spec *APISpec) (strin
 	return EnsureTransport(gotHost, spec.Protocol), nil
 }
 
+// upstreamHostHealthy returns the health check used to skip unavailable hosts,
+// or nil when the API neither checks hosts against uptime tests nor ejects
+// outliers. A host is unavailable when it is down according to the uptime
+// tests (as checked by HostCheckerManager.AmIPolling) or has been ejected by
+// outlier detection.
+func (gw *Gateway) upstreamHostHealthy(spec *APISpec) func(string) bool {
+	checkUptime := spec.Proxy.CheckHostAgainstUptimeTests && gw.GlobalHostChecker != nil
+	if !checkUptime && spec.OutlierDetector == nil {
+		return nil
+	}
+
+	return func(host string) bool {
+		if checkUptime && gw.GlobalHostChecker.HostDown(host) {
+			return false
+		}
+		return !spec.OutlierDetector.Ejected(host)
+	}
+}
+
+// loadBalancingHashKey resolves the request attribute hashed by the
+// consistent hash load balancing strategy.
+func loadBalancingHashKey(r *http.Request, spec *APISpec) string {
+	if r == nil {
+		return ""
+	}
+
+	lb := spec.Proxy.LoadBalancing
+	switch lb.HashSource {
+	case loadbalancer.HashSourceHeader:
+		return r.Header.Get(lb.HashName)
+	case loadbalancer.HashSourceCookie:
+		if cookie, err := r.Cookie(lb.HashName); err == nil {
+			return cookie.Value
+		}
+	case loadbalancer.HashSourceSession:
+		if session := ctxGetSession(r); session != nil {
+			return session.KeyHash()
+		}
+	case loadbalancer.HashSourceIP:
+		return request.RealIP(r)
+	}
+
+	return ""
+}
+
 var (
 	onceStartAllHostsDown sync.Once
 
@@ -256,11 +315,13 @@ func (gw *Gateway) TykNewSingleHostReverseProxy(target *url.URL, spec *APISpec,
 			}
 			fallthrough // implies load balancing, with replaced host list
 		case spec.Proxy.EnableLoadBalancing:
-			host, err := gw.nextTarget(hostList, spec)
+			host, err := gw.nextRequestTarget(req, hostList, spec)
 			if err != nil {
 				logger.Error("[PROXY] [LOAD BALANCING] ", err)
 				host = allHostsDownURL
 				ctx.SetErrorClassification(req, tykerrors.ClassifyNoHealthyUpstreamsError(target.Host))
+			} else {
+				ctxSetLoadBalancerTarget(req, host)
 			}
 			lbRemote, err := url.Parse(host)
 			if err != nil {
@@ -1071,9 +1132,11 @@ func handleInMemoryLoop(handler http.Handler, r *http.Request) (resp *http.Respo
 }
 
 func (p *ReverseProxy) handleOutboundRequest(roundTripper *TykRoundTripper, outreq *http.Request, w http.ResponseWriter) (res *http.Response, hijacked bool, latency time.Duration, err error) {
+	done := p.TykAPISpec.LoadBalancer.Begin(ctxGetLoadBalancerTarget(outreq))
 	begin := time.Now()
 	defer func() {
 		latency = time.Since(begin)
+		done(latency)
 	}()
 
 	if p.TykAPISpec.GraphQL.Enabled {
@@ -1348,27 +1411,56 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		res             *http.Response
 		isHijacked      bool
 		upstreamLatency time.Duration
+		attempts        []upstreamAttempt
 		err             error
+		breaker         *ExtendedCircuitBreakerMeta
+		probe           *circuitBreaker
 	)
 
 	if breakerEnforced {
-		if !breakerConf.CB.Ready() {
+		probe = breakerConf.breaker(outreq.URL.Host)
+		if !probe.Ready() {
 			p.logger.Debug("ON REQUEST: Circuit Breaker is in OPEN state")
-			errClass := tykerrors.ClassifyCircuitBreakerError(outreq.URL.Host+outreq.URL.Path, "OPEN")
+			// the stale cached response, if any, goes through the response chain below
+			if res = p.staleResponse(req); res == nil {
+				errClass := tykerrors.ClassifyCircuitBreakerError(outreq.URL.Host+outreq.URL.Path, "OPEN")
+				ctx.SetErrorClassification(logreq, errClass)
+				p.ErrorHandler.HandleError(rw, logreq, "Service temporarily unavailable.", 503, true)
+				return ProxyResponse{}
+			}
+		} else {
+			p.logger.Debug("ON REQUEST: Circuit Breaker is in CLOSED or HALF-OPEN state")
+			breaker = breakerConf
+		}
+	}
+
+	if res == nil {
+		releaseUpstreamSlot, slotErr := p.acquireUpstreamSlot(outreq)
+		if slotErr != nil {
+			// the request isn't sent, don't hold on to a half-open probe
+			probe.release()
+
+			if ctxErr := outreq.Context().Err(); ctxErr != nil {
+				// the client went away while queued, the request wasn't shed
+				p.logger.WithError(ctxErr).Debug("ON REQUEST: Client closed request while queued for the upstream")
+				ctx.SetErrorClassification(logreq, tykerrors.ClassifyUpstreamError(ctxErr, outreq.URL.Host+outreq.URL.Path))
+				p.ErrorHandler.HandleError(rw, logreq, "Client closed request", 499, true)
+				return ProxyResponse{}
+			}
+
+			p.logger.WithError(slotErr).Debug("ON REQUEST: Adaptive concurrency limit reached")
+			errClass := tykerrors.ClassifyAdaptiveConcurrencyError(outreq.URL.Host + outreq.URL.Path)
 			ctx.SetErrorClassification(logreq, errClass)
 			p.ErrorHandler.HandleError(rw, logreq, "Service temporarily unavailable.", 503, true)
 			return ProxyResponse{}
 		}
-		p.logger.Debug("ON REQUEST: Circuit Breaker is in CLOSED or HALF-OPEN state")
 
-		res, isHijacked, upstreamLatency, err = p.handleOutboundRequest(roundTripper, outreq, rw)
-		if err != nil || res.StatusCode/100 == 5 {
-			breakerConf.CB.Fail()
-		} else {
-			breakerConf.CB.Success()
-		}
-	} else {
-		res, isHijacked, upstreamLatency, err = p.handleOutboundRequest(roundTripper, outreq, rw)
+		mirrored := p.TykAPISpec.trafficMirror.mirror(req)
+
+		res, isHijacked, upstreamLatency, attempts, err = p.sendWithRetries(roundTripper, outreq, rw, breaker, p.retryPolicy(req))
+		releaseUpstreamSlot(res, err)
+		mirrored.observe(res, err)
+		p.recordUpstreamAttempts(req, logreq, attempts)
 	}
 
 	if err != nil {
@@ -1376,6 +1468,15 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 		errClass := tykerrors.ClassifyUpstreamError(err, outreq.URL.Host+outreq.URL.Path)
 		ctx.SetErrorClassification(logreq, errClass)
 
+		if !strings.HasPrefix(err.Error(), "mock:") && !errors.Is(err, context.Canceled) {
+			// the stale cached response, if any, goes through the response chain below
+			if stale := p.staleResponse(req); stale != nil {
+				res, err = stale, nil
+			}
+		}
+	}
+
+	if err != nil {
 		token := ctxGetAuthToken(req)
 
 		var alias string
@@ -1513,7 +1614,7 @@ func (p *ReverseProxy) WrappedServeHTTP(rw http.ResponseWriter, req *http.Reques
 	augmentMCPWWWAuthenticate(res, logreq, p.TykAPISpec)
 
 	p.HandleResponse(rw, res, ses)
-	return ProxyResponse{UpstreamLatency: upstreamLatency, Response: inres}
+	return ProxyResponse{UpstreamLatency: upstreamLatency, Response: inres, attempts: attempts}
 }
 
 func (p *ReverseProxy) HandleResponse(rw http.ResponseWriter, res *http.Response, ses *user.SessionState) error {
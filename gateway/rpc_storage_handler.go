@@ -1325,7 +1325,7 @@ func (r *RPCStorageHandler) ProcessKeySpaceChanges(keys []string, orgId string)
 	}
 
 	for _, apiID := range apiIDsToDeleteCache {
-		if r.Gw.invalidateAPICache(apiID) {
+		if r.Gw.invalidateAPICacheOnce(apiID) {
 			log.WithField("apiID", apiID).Info("cache invalidated")
 			continue
 		}
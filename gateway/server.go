@@ -54,6 +54,7 @@ import (
 	"github.com/TykTechnologies/tyk/config"
 	"github.com/TykTechnologies/tyk/dnscache"
 	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/internal/acme"
 	"github.com/TykTechnologies/tyk/internal/cache"
 	"github.com/TykTechnologies/tyk/internal/compression"
 	"github.com/TykTechnologies/tyk/internal/crypto"
@@ -64,6 +65,8 @@ import (
 	"github.com/TykTechnologies/tyk/internal/netutil"
 	"github.com/TykTechnologies/tyk/internal/otel"
 	"github.com/TykTechnologies/tyk/internal/rate"
+	"github.com/TykTechnologies/tyk/internal/retry"
+	"github.com/TykTechnologies/tyk/internal/revocation"
 	"github.com/TykTechnologies/tyk/internal/scheduler"
 	"github.com/TykTechnologies/tyk/internal/service/newrelic"
 	"github.com/TykTechnologies/tyk/internal/uuid"
@@ -165,6 +168,10 @@ type Gateway struct {
 	UtilCache cache.Repository
 	// ServiceCache is the service discovery cache
 	ServiceCache cache.Repository
+	// responseCacheL1 is the in-memory response cache tier, nil if disabled
+	responseCacheL1 *responseCacheL1
+	// cacheUsage tracks the stored response cache entries, nil if metrics are disabled
+	cacheUsage *cacheUsage
 
 	// Nonce to use when interacting with the dashboard service
 	ServiceNonce      string
@@ -186,6 +193,15 @@ type Gateway struct {
 	certUsageTracker *certUsageTracker // nil in non-RPC mode
 	pendingCerts     sync.Map          // certID -> struct{}, certs skipped due to tracker miss
 
+	// revocationChecker checks client certificate revocation and fetches
+	// stapled OCSP responses, nil if both are disabled
+	revocationChecker *revocation.Checker
+	// acmeManager provisions the certificates of API domains, nil if ACME
+	// is disabled
+	acmeManager *acme.Manager
+	// dpopReplayStore remembers the DPoP proofs which were used
+	dpopReplayStore *storage.RedisCluster
+
 	dnsCacheManager dnscache.IDnsCacheManager
 
 	// signatureVerifier is used to verify signatures with config.PublicKeyPath.
@@ -252,6 +268,11 @@ type Gateway struct {
 	idpRegistry *IdPRegistry
 
 	limitHeaderFactory rate.HeaderSenderFactory
+	// ietfLimitHeaderFactory is set when the IETF RateLimit headers are enabled.
+	ietfLimitHeaderFactory rate.HeaderSenderFactory
+
+	// retryBudget limits upstream retries across all APIs.
+	retryBudget *retry.Budget
 
 	BundleChecksumVerifier bundleChecksumVerifyFunction
 
@@ -324,7 +345,12 @@ func NewGateway(config config.Config, ctx context.Context) *Gateway {
 
 	gw.SetNodeID("solo-" + uuid.New())
 	gw.SessionID = uuid.New()
-	gw.limitHeaderFactory = rate.NewSenderFactory(config.RateLimitResponseHeaders)
+	gw.initLimitHeaderFactory(config.RateLimit)
+	gw.retryBudget = retry.NewBudget(
+		config.UpstreamRetryBudget.Ratio,
+		config.UpstreamRetryBudget.MinRetriesPerSecond,
+		time.Duration(config.UpstreamRetryBudget.Window)*time.Second,
+	)
 
 	// Only create registry in RPC mode
 	if config.SlaveOptions.UseRPC {
@@ -374,6 +400,9 @@ func (gw *Gateway) cacheCreate() {
 
 	gw.RPCGlobalCache = cache.New(int64(conf.SlaveOptions.RPCGlobalCacheExpiration), 15)
 	gw.RPCCertCache = cache.New(int64(conf.SlaveOptions.RPCCertCacheExpiration), 15)
+
+	gw.responseCacheL1 = newResponseCacheL1(conf.ResponseCacheL1)
+	gw.cacheUsage = gw.newGatewayCacheUsage()
 }
 
 // cacheClose will close the caches in *Gateway, cleaning up the goroutines.
@@ -384,6 +413,10 @@ func (gw *Gateway) cacheClose() {
 	gw.UtilCache.Close()
 	gw.RPCGlobalCache.Close()
 	gw.RPCCertCache.Close()
+	gw.cacheUsage.Close()
+	if gw.revocationChecker != nil {
+		gw.revocationChecker.Close()
+	}
 }
 
 func (gw *Gateway) saveApi(spec *APISpec) {
@@ -640,10 +673,7 @@ func (gw *Gateway) setupGlobals() {
 		gw.SetConfig(conf)
 	}
 
-	certificateSecret := gw.GetConfig().Secret
-	if gw.GetConfig().Security.PrivateCertificateEncodingSecret != "" {
-		certificateSecret = gw.GetConfig().Security.PrivateCertificateEncodingSecret
-	}
+	certificateSecret := gw.certificateSecret()
 
 	storeCert := &storage.RedisCluster{KeyPrefix: "cert-", HashKeys: false, ConnectionHandler: gw.StorageConnectionHandler}
 	storeCert.Connect()
@@ -699,6 +729,12 @@ func (gw *Gateway) setupGlobals() {
 		gw.CertificateManager = slaveCM
 	}
 
+	gw.revocationChecker = gw.newRevocationChecker(storeCert)
+	gw.startACME()
+
+	gw.dpopReplayStore = &storage.RedisCluster{ConnectionHandler: gw.StorageConnectionHandler}
+	gw.dpopReplayStore.Connect()
+
 	if gw.GetConfig().NewRelic.AppName != "" {
 		gw.NewRelicApplication = gw.SetupNewRelic()
 	}
@@ -973,6 +1009,8 @@ func (gw *Gateway) loadControlAPIEndpoints(muxer *mux.Router) {
 	r.HandleFunc("/cache/jwks/{apiID}", gw.invalidateJWKSCacheForAPIID).Methods("DELETE")
 	r.HandleFunc("/cache/jwks", gw.invalidateJWKSCacheForAllAPIs).Methods("DELETE")
 	r.HandleFunc("/cache/{apiID}", gw.invalidateCacheHandler).Methods("DELETE")
+	r.HandleFunc("/cache/{apiID}/tags/{tag}", gw.purgeCacheByTagHandler).Methods("DELETE")
+	r.HandleFunc("/cache/{apiID}/paths", gw.purgeCacheByPathHandler).Methods("DELETE")
 	r.HandleFunc("/keys", gw.keyHandler).Methods("POST", "PUT", "GET", "DELETE")
 	r.HandleFunc("/keys/preview", gw.previewKeyHandler).Methods("POST")
 	r.HandleFunc("/keys/{keyName:[^/]*}", gw.keyHandler).Methods("POST", "PUT", "GET", "DELETE")
@@ -1842,7 +1880,7 @@ func (gw *Gateway) initSystem() error {
 	// free resources.
 	go cleanIdleMemConnProviders(gw.ctx)
 
-	gw.limitHeaderFactory = rate.NewSenderFactory(gwConfig.RateLimitResponseHeaders)
+	gw.initLimitHeaderFactory(gwConfig.RateLimit)
 	gw.jwkCache = buildJWKSCache(gwConfig)
 
 	gw.initMembers(gwConfig)
@@ -2426,6 +2464,17 @@ func (gw *Gateway) startDRL() {
 	})
 }
 
+// initLimitHeaderFactory sets up the injection of rate limit and quota headers.
+func (gw *Gateway) initLimitHeaderFactory(conf config.RateLimit) {
+	gw.limitHeaderFactory = rate.NewSenderFactory(conf.RateLimitResponseHeaders)
+	gw.ietfLimitHeaderFactory = nil
+
+	if conf.EnableIETFRateLimitHeaders {
+		gw.ietfLimitHeaderFactory = rate.NewIETFSenderFactory()
+		gw.limitHeaderFactory = rate.WithIETFHeaders(gw.limitHeaderFactory)
+	}
+}
+
 func (gw *Gateway) isDRLDisabled() bool {
 	gwConfig := gw.GetConfig()
 
@@ -2652,8 +2701,9 @@ func (gw *Gateway) gracefulShutdown(ctx context.Context) error {
 	if err := gw.DefaultProxyMux.again.Close(); err != nil {
 		mainLog.Error("Closing listeners: ", err)
 	}
-	if gw.GetConfig().EnableAnalytics && gw.Analytics.Store == nil {
-		gw.Analytics.Stop()
+	if gw.GetConfig().EnableAnalytics && gw.Analytics.Store != nil {
+		// write the buffered records and flush and close the sinks and spool
+		gw.Analytics.Close()
 	}
 	writeProfiles()
 
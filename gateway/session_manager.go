@@ -154,6 +154,22 @@ func (l *SessionLimiter) limitRedis(r *http.Request, session *user.SessionState,
 	}
 
 	ratelimit := rate.NewSlidingLogRedis(l.limiterStorage, pipeline, smoothingFn)
+
+	if dryRun {
+		// check the window without adding to it
+		count, err := ratelimit.GetCount(ctx, time.Now(), rateLimiterKey, int64(per))
+		if err != nil {
+			log.WithError(err).Error("error reading sliding log")
+			return rate.NewEmptyStats(), true
+		}
+
+		return rate.Stats{
+			Count:     int(count),
+			Limit:     int(cost),
+			Remaining: max(int(cost)-int(count)-1, 0),
+		}, smoothingFn(ctx, rateLimiterKey, count, int64(cost))
+	}
+
 	stats, shouldBlock, err := ratelimit.Do(ctx, time.Now(), rateLimiterKey, int64(cost), int64(per))
 
 	if shouldBlock && !dryRun && (l.config.EnableSentinelRateLimiter || l.config.DRLEnableSentinelRateLimiter) {
@@ -298,6 +314,11 @@ func (l *SessionLimiter) ForwardMessage(
 		endpointRLKeySuffix = endpointRLInfo.KeySuffix
 	}
 
+	// Limits which don't select an algorithm use the one selected by the API.
+	if apiLimit.Algorithm == "" {
+		apiLimit.Algorithm = api.GlobalRateLimit.Algorithm
+	}
+
 	if rl := l.newRateLimitChecker(r, session, rateLimitKey, quotaKey, enableRL, dryRun, apiLimit, endpointRLKeySuffix, allowanceScope); rl != nil {
 		stats, shouldBlock, err := rl.Check()
 
@@ -306,6 +327,12 @@ func (l *SessionLimiter) ForwardMessage(
 			return sessionFailInternalServerError
 		}
 
+		stats.Window = time.Duration(apiLimit.Per * float64(time.Second))
+		stats.Policy = rate.PolicyRate
+		if doEndpointRL {
+			stats.Policy = rate.PolicyEndpoint
+		}
+
 		// Inject rate limit headers early in the request lifecycle so they are present
 		// even if the request is subsequently blocked (429).
 		// Quota headers are injected later in the chain (e.g., HandleResponse)
@@ -366,8 +393,19 @@ func (l *SessionLimiter) newRateLimitChecker(
 	}
 
 	log.Debug("[RATELIMIT] Rate limiter key is: ", limiterKey)
+
 	limiterFn := rate.Limiter(l.config, l.limiterStorage)
 
+	if dryRun && (apiLimit.Algorithm == rate.LimitGCRA || limiterFn != nil) {
+		// these limiters can't be checked without taking from the limit,
+		// dry runs are left to the actual check
+		return nil
+	}
+
+	if apiLimit.Algorithm == rate.LimitGCRA {
+		return l.newGCRAChecker(r, rate.Prefix(limiterKey, rate.LimitGCRA), apiLimit)
+	}
+
 	switch {
 	case limiterFn != nil:
 
@@ -401,18 +439,7 @@ func (l *SessionLimiter) newRateLimitChecker(
 	case l.config.EnableRedisRollingLimiter:
 		return newStaticTtlChecker(l.limitRedis(r, session, limiterKey, apiLimit, dryRun))
 	default:
-		var n float64
-		if l.drlManager.Servers != nil {
-			n = float64(l.drlManager.Servers.Count())
-		}
-		cost := apiLimit.Rate / apiLimit.Per
-		c := l.config.DRLThreshold
-		if c == 0 {
-			// defaults to 5
-			c = 5
-		}
-
-		if n <= 1 || n*c < cost {
+		if _, ok := l.drlServers(apiLimit); ok {
 			// If we have 1 server, there is no need to strain redis at all the leaky
 			// bucket algorithm will suffice.
 
@@ -432,6 +459,68 @@ func (l *SessionLimiter) newRateLimitChecker(
 	}
 }
 
+// drlServers returns the number of gateways sharing the rate limits, and true
+// if the rate of apiLimit is high enough to be shared between them with the DRL.
+func (l *SessionLimiter) drlServers(apiLimit *user.APILimit) (float64, bool) {
+	var n float64
+	if l.drlManager.Servers != nil {
+		n = float64(l.drlManager.Servers.Count())
+	}
+	cost := apiLimit.Rate / apiLimit.Per
+	c := l.config.DRLThreshold
+	if c == 0 {
+		// defaults to 5
+		c = 5
+	}
+
+	return n, n <= 1 || n*c < cost
+}
+
+// newGCRAChecker returns a checker using the GCRA rate limiter. It's backed by
+// redis when the gateway uses a redis rate limiter, or when the rate is too low
+// to be shared between the gateways like the DRL does. Otherwise, it's kept in
+// memory and each gateway allows its share of the rate.
+func (l *SessionLimiter) newGCRAChecker(r *http.Request, limiterKey string, apiLimit *user.APILimit) rate.Checker {
+	storage, limitRate := l.limiterStorage, apiLimit.Rate
+
+	redisLimiter := l.config.EnableRedisRollingLimiter || l.config.EnableSentinelRateLimiter ||
+		rate.Limiter(l.config, l.limiterStorage) != nil
+	if n, ok := l.drlServers(apiLimit); !redisLimiter && ok {
+		storage = nil
+		if n > 1 {
+			limitRate /= n
+		}
+	}
+
+	return rate.AnonChecker(func() (rate.Stats, bool, error) {
+		res, err := limiter.NewLimiter(storage).GCRA(r.Context(), limiterKey, limitRate, apiLimit.Per)
+		if err != nil {
+			return rate.NewEmptyStats(), true, err
+		}
+
+		return rate.Stats{
+			Limit:      int(apiLimit.Rate),
+			Remaining:  res.Remaining,
+			Reset:      res.ResetAfter,
+			RetryAfter: res.RetryAfter,
+		}, !res.Allowed, nil
+	})
+}
+
+// AcquireConcurrency takes one of max concurrency slots for key, returning a function
+// that releases it. Slots are counted in redis when distributed concurrency limits
+// are enabled, and by each gateway in memory otherwise.
+func (l *SessionLimiter) AcquireConcurrency(ctx context.Context, key string, max int) (func(), error) {
+	var storage redis.UniversalClient
+	if l.config.EnableDistributedConcurrencyLimit {
+		storage = l.limiterStorage
+	}
+
+	lease := time.Duration(l.config.ConcurrencyLimitLeaseTTL) * time.Second
+
+	return limiter.NewLimiter(storage).Concurrency(ctx, key, max, lease)
+}
+
 // RedisQuotaExceeded returns true if the request should be blocked as over quota.
 func (l *SessionLimiter) RedisQuotaExceeded(
 	r *http.Request,
@@ -1,6 +1,7 @@
 package gateway
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -332,6 +333,52 @@ func TestSessionState_QuotaEnforcementWorksAfterOptimization(t *testing.T) {
 	})
 }
 
+func TestSessionLimiter_GCRAStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		conf    func(globalConf *config.Config)
+		inRedis bool
+	}{
+		{name: "DRL keeps it in memory", conf: nil, inRedis: false},
+		{name: "redis rate limiter keeps it in redis", conf: func(globalConf *config.Config) {
+			globalConf.EnableRedisRollingLimiter = true
+		}, inRedis: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := StartTest(tc.conf)
+			defer ts.Close()
+
+			ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+				spec.APIID = "gcra-storage"
+				spec.Proxy.ListenPath = "/gcra-storage/"
+				spec.UseKeylessAccess = false
+			})
+
+			_, key := ts.CreateSession(func(s *user.SessionState) {
+				s.Rate = 1
+				s.Per = 10
+				s.RateLimitAlgorithm = rate.LimitGCRA
+				s.AccessRights = map[string]user.AccessDefinition{"gcra-storage": {
+					APIID: "gcra-storage", Versions: []string{"v1"},
+				}}
+			})
+
+			authHeaders := map[string]string{"Authorization": key}
+			_, _ = ts.Run(t, []test.TestCase{
+				{Path: "/gcra-storage/", Headers: authHeaders, Code: http.StatusOK},
+				{Path: "/gcra-storage/", Headers: authHeaders, Code: http.StatusTooManyRequests},
+			}...)
+
+			keyHash := storage.HashKey(key, ts.Gw.GetConfig().HashKeys)
+			keys, err := ts.Gw.SessionLimiter.limiterStorage.Keys(context.Background(), rate.LimiterKeyPrefix+"*"+keyHash+"*").Result()
+			require.NoError(t, err)
+			assert.Equal(t, tc.inRedis, len(keys) > 0)
+		})
+	}
+}
+
 func TestSessionLimiter_RateLimitInfo(t *testing.T) {
 	limiter := &SessionLimiter{config: &config.Default}
 	spec := BuildAPI(func(a *APISpec) {
@@ -702,6 +749,31 @@ func TestSessionLimiter(t *testing.T) {
 		assert.True(t, block, "third call is blocked")
 	})
 
+	t.Run("limitRedis dry run", func(t *testing.T) {
+		redisKey := key + "_redis_dry_run"
+		r, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+		require.NoError(t, err)
+
+		cmd := limiter.limiterStorage.Del(r.Context(), redisKey)
+		require.NoError(t, cmd.Err())
+
+		session := &user.SessionState{}
+		apiLimit := &user.APILimit{RateLimit: user.RateLimit{Rate: 1, Per: 60}}
+
+		_, block := limiter.limitRedis(r, session, redisKey, apiLimit, true)
+		assert.False(t, block, "dry run is not blocked")
+
+		_, block = limiter.limitRedis(r, session, redisKey, apiLimit, false)
+		assert.False(t, block, "dry runs don't take from the limit")
+
+		_, block = limiter.limitRedis(r, session, redisKey, apiLimit, true)
+		assert.True(t, block, "dry run is blocked once the limit is reached")
+
+		count, err := limiter.limiterStorage.ZCard(r.Context(), redisKey).Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count, "dry runs aren't recorded")
+	})
+
 	t.Run("limitDRL should correctly report blocked status during dry run when remaining tokens are insufficient", func(t *testing.T) {
 		drlManager := &drl.DRL{RequestTokenValue: 2}
 		drlManager.SetCurrentTokenValue(3)
@@ -1348,7 +1348,7 @@ func (s *Test) Close() {
 		log.Info("server exited properly")
 	}
 
-	s.Gw.Analytics.Stop()
+	s.Gw.Analytics.Close()
 	s.Gw.ReloadTestCase.StopTicker()
 	s.Gw.GlobalHostChecker.StopPoller()
 	s.Gw.NewRelicApplication.Shutdown(5 * time.Second)
@@ -0,0 +1,354 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/internal/httpclient"
+	"github.com/TykTechnologies/tyk/internal/httputil"
+)
+
+const (
+	// defaultTrafficMirrorMaxBodySize is the largest request body mirrored
+	// when no limit is configured.
+	defaultTrafficMirrorMaxBodySize = 1 << 20
+	// defaultTrafficMirrorTimeout bounds mirrored requests when no timeout is
+	// configured.
+	defaultTrafficMirrorTimeout = 30 * time.Second
+	// trafficMirrorConcurrency is the number of mirrored requests of an API
+	// in flight at the same time, requests aren't mirrored beyond it.
+	trafficMirrorConcurrency = 100
+)
+
+// trafficMirror copies a share of the requests of an API to a shadow upstream.
+type trafficMirror struct {
+	conf     apidef.TrafficMirrorConfig
+	spec     *APISpec
+	director func(*http.Request)
+	client   *http.Client
+	inflight chan struct{}
+	logger   *logrus.Entry
+}
+
+// newTrafficMirror creates the traffic mirror of spec, or nil if traffic
+// mirroring is disabled.
+func (gw *Gateway) newTrafficMirror(spec *APISpec, logger *logrus.Entry) *trafficMirror {
+	conf := spec.TrafficMirror
+	if !conf.Enabled || conf.Percentage <= 0 {
+		return nil
+	}
+
+	if logger == nil {
+		logger = logrus.NewEntry(log)
+	}
+	logger = logger.WithField("mw", "TrafficMirror")
+
+	target, err := url.Parse(conf.TargetURL)
+	if err != nil {
+		logger.WithError(err).Error("[MIRROR] Couldn't parse shadow target URL, traffic mirroring is disabled")
+		return nil
+	}
+
+	client, err := NewExternalHTTPClientFactory(gw).CreateMirrorClient()
+	if err != nil {
+		// don't bypass a misconfigured mTLS setup with the default client
+		if gw.GetConfig().ExternalServices.Mirror.MTLS.Enabled && httpclient.IsMTLSError(err) {
+			logger.WithError(err).Error("[MIRROR] mTLS configuration failed, traffic mirroring is disabled")
+			return nil
+		}
+		logger.WithError(err).Debug("[MIRROR] Falling back to the default traffic mirror HTTP client")
+		client = &http.Client{}
+	}
+
+	client.Timeout = time.Duration(conf.Timeout)
+	if client.Timeout <= 0 {
+		client.Timeout = defaultTrafficMirrorTimeout
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	// the shadow upstream is proxied to like the primary one, only without
+	// load balancing and service discovery
+	def := *spec.APIDefinition
+	def.Proxy.TargetURL = conf.TargetURL
+	def.Proxy.EnableLoadBalancing = false
+	def.Proxy.ServiceDiscovery.UseDiscoveryService = false
+	shadowSpec := &APISpec{APIDefinition: &def, GlobalConfig: spec.GlobalConfig}
+
+	return &trafficMirror{
+		conf:     conf,
+		spec:     spec,
+		director: gw.TykNewSingleHostReverseProxy(target, shadowSpec, logger).Director,
+		client:   client,
+		inflight: make(chan struct{}, trafficMirrorConcurrency),
+		logger:   logger,
+	}
+}
+
+// maxBodySize returns the size of the largest request body that is mirrored.
+func (m *trafficMirror) maxBodySize() int64 {
+	if m.conf.MaxBodySize > 0 {
+		return m.conf.MaxBodySize
+	}
+	return defaultTrafficMirrorMaxBodySize
+}
+
+// close releases the connections of the mirror.
+func (m *trafficMirror) close() {
+	if m != nil {
+		m.client.CloseIdleConnections()
+	}
+}
+
+// mirror sends a copy of req to the shadow upstream if it is sampled. It
+// returns the mirrored request, to be given the primary response, or nil if
+// req isn't mirrored.
+func (m *trafficMirror) mirror(req *http.Request) *mirroredRequest {
+	if m == nil || rand.Float64()*100 >= m.conf.Percentage {
+		return nil
+	}
+
+	if _, upgrade := httputil.IsUpgrade(req); upgrade || httputil.IsStreamingRequest(req) {
+		return nil
+	}
+
+	body, ok := m.body(req)
+	if !ok {
+		m.logger.Debug("[MIRROR] Request body is too large to be mirrored")
+		return nil
+	}
+
+	select {
+	case m.inflight <- struct{}{}:
+	default:
+		m.logger.Debug("[MIRROR] Too many mirrored requests in flight, not mirroring")
+		return nil
+	}
+
+	shadowReq := req.Clone(context.Background())
+	shadowReq.RequestURI = ""
+	shadowReq.Body = http.NoBody
+	shadowReq.ContentLength = int64(len(body))
+	if len(body) > 0 {
+		shadowReq.Body = io.NopCloser(bytes.NewReader(body))
+		shadowReq.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	for _, h := range hopHeaders {
+		shadowReq.Header.Del(h)
+	}
+	m.director(shadowReq)
+
+	mirrored := &mirroredRequest{
+		mirror:  m,
+		req:     shadowReq,
+		path:    req.URL.Path,
+		primary: make(chan mirrorResponse, 1),
+	}
+	go mirrored.send()
+
+	return mirrored
+}
+
+// body returns the body of req, or false if it is too large to be mirrored
+// or can't be read again for the primary upstream.
+func (m *trafficMirror) body(req *http.Request) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+
+	maxSize := m.maxBodySize()
+	if req.ContentLength > maxSize {
+		return nil, false
+	}
+
+	buf, ok := req.Body.(*nopCloserBuffer)
+	if !ok {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(buf, maxSize+1))
+	buf.Seek(0, io.SeekStart)
+	if err != nil || int64(len(body)) > maxSize {
+		return nil, false
+	}
+
+	return body, true
+}
+
+// mirrorResponse is the outcome of a primary or mirrored request. Its sum is
+// the hash of the body, unset when the body wasn't entirely read or is
+// larger than the body size limit.
+type mirrorResponse struct {
+	status int
+	sum    []byte
+	err    error
+}
+
+// mirroredRequest is a request sent to the shadow upstream.
+type mirroredRequest struct {
+	mirror  *trafficMirror
+	req     *http.Request
+	path    string
+	primary chan mirrorResponse
+}
+
+// observe hands the primary response of the mirrored request over for
+// comparison, hashing its body as it is sent to the client.
+func (r *mirroredRequest) observe(res *http.Response, err error) {
+	if r == nil || !r.mirror.conf.Compare {
+		return
+	}
+
+	switch {
+	case err != nil:
+		r.primary <- mirrorResponse{err: err}
+	case res == nil:
+		r.primary <- mirrorResponse{}
+	case res.Body == nil || res.StatusCode == http.StatusSwitchingProtocols:
+		r.primary <- mirrorResponse{status: res.StatusCode}
+	default:
+		res.Body = &mirrorTap{
+			ReadCloser: res.Body,
+			hash:       sha256.New(),
+			max:        r.mirror.maxBodySize(),
+			status:     res.StatusCode,
+			report:     r.primary,
+		}
+	}
+}
+
+// send sends the mirrored request and compares its response to the primary
+// response when enabled.
+func (r *mirroredRequest) send() {
+	m := r.mirror
+	defer func() {
+		<-m.inflight
+	}()
+
+	var shadow mirrorResponse
+	res, err := m.client.Do(r.req)
+	if err != nil {
+		m.logger.WithError(err).Debug("[MIRROR] Mirrored request failed")
+		shadow.err = err
+	} else {
+		shadow = m.read(res)
+	}
+
+	if !m.conf.Compare {
+		return
+	}
+
+	timer := time.NewTimer(m.client.Timeout)
+	defer timer.Stop()
+
+	select {
+	case primary := <-r.primary:
+		r.compare(primary, shadow)
+	case <-timer.C:
+		m.logger.Debug("[MIRROR] Primary response wasn't completed, not comparing")
+	}
+}
+
+// read reads and discards the body of a shadow response, hashing it up to
+// the body size limit.
+func (m *trafficMirror) read(res *http.Response) mirrorResponse {
+	defer res.Body.Close()
+
+	shadow := mirrorResponse{status: res.StatusCode}
+	if !m.conf.Compare {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return shadow
+	}
+
+	maxSize := m.maxBodySize()
+	h := sha256.New()
+	n, err := io.Copy(h, io.LimitReader(res.Body, maxSize+1))
+	if err == nil && n <= maxSize {
+		shadow.sum = h.Sum(nil)
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	return shadow
+}
+
+// compare fires EventTrafficMirrorMismatch when the shadow response differs
+// from the primary one. Bodies are only compared when both were hashed.
+func (r *mirroredRequest) compare(primary, shadow mirrorResponse) {
+	bodyMismatch := primary.sum != nil && shadow.sum != nil && !bytes.Equal(primary.sum, shadow.sum)
+	if primary.status == shadow.status && !bodyMismatch {
+		return
+	}
+
+	m := r.mirror
+	m.logger.WithFields(logrus.Fields{
+		"path":           r.path,
+		"primary_status": primary.status,
+		"shadow_status":  shadow.status,
+		"body_mismatch":  bodyMismatch,
+	}).Debug("[MIRROR] Shadow response differs from primary response")
+
+	m.spec.FireEvent(event.TrafficMirrorMismatch, EventTrafficMirrorMismatchMeta{
+		EventMetaDefault: EventMetaDefault{Message: "Shadow response differs from primary response"},
+		Path:             r.path,
+		APIID:            m.spec.APIID,
+		PrimaryStatus:    primary.status,
+		ShadowStatus:     shadow.status,
+		BodyMismatch:     bodyMismatch,
+	})
+}
+
+// mirrorTap hashes a primary response body as it is read, and reports the
+// response once the body is read or closed.
+type mirrorTap struct {
+	io.ReadCloser
+
+	hash   hash.Hash
+	size   int64
+	max    int64
+	status int
+	report chan<- mirrorResponse
+	once   sync.Once
+}
+
+func (t *mirrorTap) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if t.size += int64(n); t.size <= t.max {
+		t.hash.Write(p[:n])
+	}
+	if err == io.EOF {
+		t.done(true)
+	}
+	return n, err
+}
+
+func (t *mirrorTap) Close() error {
+	t.done(false)
+	return t.ReadCloser.Close()
+}
+
+// done reports the primary response, with the hash of its body if it was
+// entirely read.
+func (t *mirrorTap) done(complete bool) {
+	t.once.Do(func() {
+		primary := mirrorResponse{status: t.status}
+		if complete && t.size <= t.max {
+			primary.sum = t.hash.Sum(nil)
+		}
+		t.report <- primary
+	})
+}
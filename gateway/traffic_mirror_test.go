@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/internal/event"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestTrafficMirror(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("primary"))
+	}))
+	defer upstream.Close()
+
+	type shadowRequest struct {
+		method, path, body string
+	}
+	shadowRequests := make(chan shadowRequest, 10)
+	var shadowStatus atomic.Int32
+	shadowStatus.Store(http.StatusOK)
+
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		shadowRequests <- shadowRequest{method: r.Method, path: r.URL.Path, body: string(body)}
+		w.WriteHeader(int(shadowStatus.Load()))
+		_, _ = w.Write([]byte("primary"))
+	}))
+	defer shadow.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "traffic-mirror"
+		spec.Proxy.ListenPath = "/mirror/"
+		spec.Proxy.StripListenPath = true
+		spec.Proxy.TargetURL = upstream.URL
+		spec.TrafficMirror = apidef.TrafficMirrorConfig{
+			Enabled:     true,
+			TargetURL:   shadow.URL + "/v2",
+			Percentage:  100,
+			MaxBodySize: 16,
+			Compare:     true,
+		}
+	})
+
+	mismatches := make(chan EventTrafficMirrorMismatchMeta, 10)
+	ts.Gw.getApiSpec("traffic-mirror").EventPaths = map[apidef.TykEvent][]config.TykEventHandler{
+		event.TrafficMirrorMismatch: {&testEventHandler{func(em config.EventMessage) {
+			mismatches <- em.Meta.(EventTrafficMirrorMismatchMeta)
+		}}},
+	}
+
+	t.Run("requests are mirrored", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/mirror/resource", Method: http.MethodPost, Data: "hello", Code: http.StatusOK, BodyMatch: "primary"})
+
+		select {
+		case req := <-shadowRequests:
+			assert.Equal(t, shadowRequest{method: http.MethodPost, path: "/v2/resource", body: "hello"}, req)
+		case <-time.After(time.Second):
+			t.Fatal("request wasn't mirrored")
+		}
+
+		select {
+		case meta := <-mismatches:
+			t.Fatalf("unexpected mismatch: %+v", meta)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("large bodies aren't mirrored", func(t *testing.T) {
+		_, _ = ts.Run(t, test.TestCase{Path: "/mirror/resource", Method: http.MethodPost, Data: strings.Repeat("a", 32), Code: http.StatusOK})
+
+		select {
+		case req := <-shadowRequests:
+			t.Fatalf("unexpected mirrored request: %+v", req)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("mismatches fire events", func(t *testing.T) {
+		shadowStatus.Store(http.StatusInternalServerError)
+		defer shadowStatus.Store(http.StatusOK)
+
+		_, _ = ts.Run(t, test.TestCase{Path: "/mirror/resource", Code: http.StatusOK})
+		<-shadowRequests
+
+		select {
+		case meta := <-mismatches:
+			assert.Equal(t, "traffic-mirror", meta.APIID)
+			assert.Equal(t, http.StatusOK, meta.PrimaryStatus)
+			assert.Equal(t, http.StatusInternalServerError, meta.ShadowStatus)
+			assert.False(t, meta.BodyMismatch)
+		case <-time.After(time.Second):
+			t.Fatal("mismatch event wasn't fired")
+		}
+	})
+}
+
+func TestTrafficMirror_Disabled(t *testing.T) {
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	spec := &APISpec{APIDefinition: &apidef.APIDefinition{
+		TrafficMirror: apidef.TrafficMirrorConfig{TargetURL: "http://shadow", Percentage: 100},
+	}}
+	mirror := ts.Gw.newTrafficMirror(spec, nil)
+	require.Nil(t, mirror)
+
+	// a nil mirror doesn't mirror requests
+	mirrored := mirror.mirror(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Nil(t, mirrored)
+	mirrored.observe(nil, nil)
+}
@@ -0,0 +1,240 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+	"github.com/TykTechnologies/tyk/internal/retry"
+)
+
+const (
+	// hedgedTag is the analytics tag of requests that were hedged.
+	hedgedTag = "hedged"
+	// defaultHedgeBudget is the share of requests that may be hedged when no
+	// budget is configured.
+	defaultHedgeBudget = 0.1
+)
+
+// hedgePolicy holds the hedging state of an endpoint: its recent upstream
+// latencies and the budget of requests that may be hedged.
+type hedgePolicy struct {
+	conf    apidef.HedgeConfig
+	latency *retry.LatencyWindow
+	budget  *retry.Budget
+}
+
+// newHedgePolicy creates the hedging state of an endpoint, or nil if request
+// hedging is disabled.
+func newHedgePolicy(conf apidef.HedgeConfig) *hedgePolicy {
+	if !conf.Enabled {
+		return nil
+	}
+
+	ratio := conf.Budget
+	if ratio <= 0 {
+		ratio = defaultHedgeBudget
+	}
+
+	return &hedgePolicy{
+		conf:    conf,
+		latency: retry.NewLatencyWindow(0),
+		budget:  retry.NewBudget(ratio, 1, 0),
+	}
+}
+
+// delay returns how long to wait for a response before hedging, or 0 if the
+// request shouldn't be hedged.
+func (h *hedgePolicy) delay() time.Duration {
+	if h == nil {
+		return 0
+	}
+
+	if h.conf.Percentile > 0 {
+		if delay, ok := h.latency.Percentile(h.conf.Percentile); ok {
+			return delay
+		}
+	}
+
+	return time.Duration(h.conf.Delay)
+}
+
+// hedgePolicy returns the hedging state of the endpoint of outreq, or nil if
+// outreq can't be hedged.
+func (p *ReverseProxy) hedgePolicy(outreq *http.Request) *hedgePolicy {
+	spec := p.TykAPISpec
+	if !spec.EnforcedTimeoutEnabled || spec.GraphQL.Enabled {
+		return nil
+	}
+
+	switch outreq.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return nil
+	}
+
+	// both requests would read the same body
+	if outreq.Body != nil && outreq.Body != http.NoBody {
+		return nil
+	}
+
+	if _, upgrade := p.IsUpgrade(outreq); upgrade {
+		return nil
+	}
+
+	vInfo, _ := spec.Version(outreq)
+	urlSpec, found := spec.FindSpecMatchesStatus(outreq, spec.RxPaths[vInfo.Name], HardTimeout)
+	if !found {
+		return nil
+	}
+
+	return urlSpec.hedge
+}
+
+// hedgeFlight is an upstream request sent by sendHedged and its outcome.
+type hedgeFlight struct {
+	req     *http.Request
+	cancel  context.CancelFunc
+	res     *http.Response
+	latency time.Duration
+	err     error
+}
+
+// attempt returns the upstream attempt of a completed flight.
+func (f *hedgeFlight) attempt() upstreamAttempt {
+	attempt := upstreamAttempt{Target: f.req.URL.Host, Latency: f.latency}
+	if f.err != nil {
+		attempt.Flag = tykerrors.ClassifyUpstreamError(f.err, f.req.URL.Host+f.req.URL.Path).Flag
+	} else if f.res != nil {
+		attempt.Status = f.res.StatusCode
+	}
+	return attempt
+}
+
+// cancelOnClose cancels the context of a hedged request once its response
+// body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// sendHedged sends outreq upstream and, when the endpoint has request hedging
+// enabled and no response came back within the hedge delay, sends a copy of
+// it to the next load balancing target as long as the hedge budget allows.
+// The first response wins and the other request is cancelled. It returns the
+// request that produced the response, and the attempts of both requests if a
+// hedged request was sent.
+func (p *ReverseProxy) sendHedged(roundTripper *TykRoundTripper, outreq *http.Request, w http.ResponseWriter) (res *http.Response, hijacked bool, latency time.Duration, sent *http.Request, attempts []upstreamAttempt, err error) {
+	policy := p.hedgePolicy(outreq)
+	delay := policy.delay()
+	if delay <= 0 {
+		res, hijacked, latency, err = p.handleOutboundRequest(roundTripper, outreq, w)
+		if policy != nil && err == nil {
+			policy.latency.Observe(latency)
+		}
+		return res, hijacked, latency, outreq, nil, err
+	}
+
+	policy.budget.Request()
+
+	begin := time.Now()
+	results := make(chan *hedgeFlight, 2)
+	send := func(req *http.Request) *hedgeFlight {
+		reqCtx, cancel := context.WithCancel(req.Context())
+		flight := &hedgeFlight{req: req.WithContext(reqCtx), cancel: cancel}
+
+		go func() {
+			res, _, latency, err := p.handleOutboundRequest(roundTripper, flight.req, w)
+			results <- &hedgeFlight{req: flight.req, cancel: cancel, res: res, latency: latency, err: err}
+		}()
+
+		return flight
+	}
+
+	// clone the hedge before the primary is in flight, the round tripper
+	// changes the request headers of tyk:// loops while sending it
+	hedgeReq := outreq.Clone(outreq.Context())
+	primary := send(outreq)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var winner *hedgeFlight
+	select {
+	case winner = <-results:
+	case <-timer.C:
+		if !policy.budget.Withdraw() {
+			p.logger.Debug("[HEDGE] Hedge budget exhausted, not hedging upstream request")
+			winner = <-results
+		}
+	}
+
+	if winner != nil {
+		policy.finish(winner)
+		return winner.res, false, winner.latency, winner.req, nil, winner.err
+	}
+
+	p.retarget(hedgeReq)
+	p.logger.WithField("delay", delay).Debug("[HEDGE] Sending hedged upstream request")
+	hedge := send(hedgeReq)
+
+	// the first response wins, an error only if both requests failed
+	winner = <-results
+	var loser *hedgeFlight
+	if winner.err != nil {
+		loser = <-results
+		if loser.err == nil {
+			winner, loser = loser, winner
+		}
+	}
+
+	policy.finish(winner)
+
+	attempts = make([]upstreamAttempt, 2)
+	for i, flight := range []*hedgeFlight{primary, hedge} {
+		switch {
+		case flight.req == winner.req:
+			attempts[i] = winner.attempt()
+		case loser != nil:
+			attempts[i] = loser.attempt()
+			loser.cancel()
+			if loser.res != nil {
+				loser.res.Body.Close()
+			}
+		default:
+			// the losing request is still in flight
+			attempts[i] = upstreamAttempt{Target: flight.req.URL.Host, Cancelled: true}
+			flight.cancel()
+			go func() {
+				if flight := <-results; flight.res != nil {
+					flight.res.Body.Close()
+				}
+			}()
+		}
+	}
+	attempts[1].Hedged = true
+
+	return winner.res, false, time.Since(begin), winner.req, attempts, winner.err
+}
+
+// finish records the latency of the winning request of sendHedged, and
+// cancels it once its response body is closed.
+func (h *hedgePolicy) finish(winner *hedgeFlight) {
+	if winner.err == nil {
+		h.latency.Observe(winner.latency)
+	}
+
+	if winner.res != nil {
+		winner.res.Body = &cancelOnClose{ReadCloser: winner.res.Body, cancel: winner.cancel}
+		return
+	}
+	winner.cancel()
+}
@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestUpstreamHedge(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte("slow"))
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("fast"))
+	}))
+	defer fast.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.EnableLoadBalancing = true
+		spec.Proxy.Targets = []string{slow.URL, fast.URL}
+		spec.EnforcedTimeoutEnabled = true
+		UpdateAPIVersion(spec, "", func(version *apidef.VersionInfo) {
+			version.UseExtendedPaths = true
+			version.ExtendedPaths.HardTimeouts = []apidef.HardTimeoutMeta{{
+				Path:    "/hedged",
+				Method:  http.MethodGet,
+				TimeOut: 5,
+				Hedge: apidef.HedgeConfig{
+					Enabled: true,
+					Delay:   tyktime.ReadableDuration(50 * time.Millisecond),
+					Budget:  1,
+				},
+			}}
+		})
+	})
+
+	// whichever target is picked first, the fast one answers
+	for i := 0; i < 4; i++ {
+		_, _ = ts.Run(t, test.TestCase{Path: "/hedged", Method: http.MethodGet, Code: http.StatusOK, BodyMatch: "fast"})
+	}
+}
+
+func TestUpstreamHedge_loop(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer slow.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.APIID = "hedge-internal"
+		spec.Proxy.ListenPath = "/hedge-internal/"
+		spec.Proxy.TargetURL = slow.URL
+		spec.Internal = true
+	}, func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/hedge-loop/"
+		spec.Proxy.TargetURL = "tyk://hedge-internal"
+		UpdateAPIVersion(spec, "", func(version *apidef.VersionInfo) {
+			version.UseExtendedPaths = true
+			version.ExtendedPaths.HardTimeouts = []apidef.HardTimeoutMeta{{
+				Path:    "/hedged",
+				Method:  http.MethodGet,
+				TimeOut: 5,
+				Hedge: apidef.HedgeConfig{
+					Enabled: true,
+					Delay:   tyktime.ReadableDuration(50 * time.Millisecond),
+					Budget:  1,
+				},
+			}}
+		})
+	})
+
+	// the hedge is sent while the primary loops through the internal API
+	_, _ = ts.Run(t, test.TestCase{Path: "/hedge-loop/hedged", Method: http.MethodGet, Code: http.StatusOK, BodyMatch: "internal"})
+}
+
+func TestHedgePolicy_Delay(t *testing.T) {
+	assert.Nil(t, newHedgePolicy(apidef.HedgeConfig{Delay: tyktime.ReadableDuration(time.Second)}))
+
+	var disabled *hedgePolicy
+	assert.Zero(t, disabled.delay())
+
+	policy := newHedgePolicy(apidef.HedgeConfig{
+		Enabled:    true,
+		Delay:      tyktime.ReadableDuration(time.Second),
+		Percentile: 90,
+	})
+
+	// the fixed delay is used until enough latencies were observed
+	assert.Equal(t, time.Second, policy.delay())
+
+	for i := 1; i <= 100; i++ {
+		policy.latency.Observe(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 90*time.Millisecond, policy.delay())
+}
@@ -0,0 +1,327 @@
+package gateway
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+	"github.com/TykTechnologies/tyk/internal/otel"
+	"github.com/TykTechnologies/tyk/internal/retry"
+)
+
+// upstreamAttemptTagPrefix prefixes the analytics tags recording the outcome
+// of every upstream attempt of a retried request.
+const upstreamAttemptTagPrefix = "upstream-attempt-"
+
+// retryDrainLimit is the most of a discarded response body that is read so
+// that the connection can be reused for the next attempt.
+const retryDrainLimit = 4 << 10
+
+// upstreamAttempt records the outcome of a single attempt to proxy a request.
+type upstreamAttempt struct {
+	Target  string
+	Status  int
+	Flag    tykerrors.ResponseFlag
+	Latency time.Duration
+	// Hedged is set on attempts sent as hedged requests.
+	Hedged bool
+	// Cancelled is set on attempts cancelled because a hedged attempt won.
+	Cancelled bool
+}
+
+// outcome returns the error flag of a failed attempt, or the response status code.
+func (a upstreamAttempt) outcome() string {
+	if a.Cancelled {
+		return "cancelled"
+	}
+	if a.Flag != "" {
+		return a.Flag.String()
+	}
+	return strconv.Itoa(a.Status)
+}
+
+// upstreamAttemptTags adds a tag per upstream attempt when the request was
+// retried or hedged, and the hedged tag when it was hedged.
+func upstreamAttemptTags(r *http.Request, tags []string) []string {
+	attempts := ctxGetUpstreamAttempts(r)
+	if len(attempts) < 2 {
+		return tags
+	}
+
+	hedged := false
+	for i, attempt := range attempts {
+		tags = append(tags, upstreamAttemptTagPrefix+strconv.Itoa(i+1)+"-"+attempt.outcome())
+		hedged = hedged || attempt.Hedged
+	}
+
+	if hedged {
+		tags = append(tags, hedgedTag)
+	}
+	return tags
+}
+
+// newRetryPolicy converts the API retry configuration into a retry policy.
+func newRetryPolicy(conf apidef.UpstreamRetryConfig) retry.Policy {
+	if !conf.Enabled {
+		return retry.Policy{}
+	}
+
+	policy := retry.Policy{
+		MaxAttempts:   conf.MaxAttempts,
+		StatusCodes:   conf.StatusCodes,
+		BackoffBase:   time.Duration(conf.BackoffBase),
+		BackoffMax:    time.Duration(conf.BackoffMax),
+		NonIdempotent: conf.RetryNonIdempotent,
+	}
+	for _, condition := range conf.RetryOn {
+		policy.Conditions = append(policy.Conditions, retry.Condition(condition))
+	}
+	return policy
+}
+
+// retryPolicy returns the retry policy of the endpoint of req, or the API
+// retry policy when the endpoint doesn't override it.
+func (p *ReverseProxy) retryPolicy(req *http.Request) retry.Policy {
+	spec := p.TykAPISpec
+	if !spec.UpstreamRetryEnabled {
+		return spec.RetryPolicy
+	}
+
+	versionInfo, _ := spec.Version(req)
+	versionPaths := spec.RxPaths[versionInfo.Name]
+	if urlSpec, found := spec.FindSpecMatchesStatus(req, versionPaths, UpstreamRetry); found {
+		return urlSpec.UpstreamRetryPolicy
+	}
+
+	return spec.RetryPolicy
+}
+
+// canRetry returns true if outreq may be sent again under policy after a failed attempt.
+func (p *ReverseProxy) canRetry(outreq *http.Request, policy retry.Policy) bool {
+	if !policy.Enabled() || p.TykAPISpec.GraphQL.Enabled {
+		return false
+	}
+
+	if _, upgrade := p.IsUpgrade(outreq); upgrade {
+		return false
+	}
+
+	_, buffered := outreq.Body.(*nopCloserBuffer)
+	replayable := buffered || outreq.Body == nil || outreq.Body == http.NoBody
+
+	return policy.AllowsMethod(outreq.Method, replayable)
+}
+
+// sendWithRetries sends outreq upstream, hedging slow attempts and retrying
+// failed ones according to policy for as long as the gateway retry budget
+// allows. The circuit breaker and outlier detector, if any, are notified of
+// the outcome of every attempt.
+func (p *ReverseProxy) sendWithRetries(roundTripper *TykRoundTripper, outreq *http.Request, w http.ResponseWriter, breaker *ExtendedCircuitBreakerMeta, policy retry.Policy) (res *http.Response, hijacked bool, latency time.Duration, attempts []upstreamAttempt, err error) {
+	if !p.canRetry(outreq, policy) {
+		var sent *http.Request
+		host := outreq.URL.Host
+		res, hijacked, latency, sent, attempts, err = p.sendHedged(roundTripper, outreq, w)
+		recordBreakerResult(breaker, host, sent.URL.Host, res, err)
+		p.reportOutlier(sent, res, err)
+		return
+	}
+
+	p.Gw.retryBudget.Request()
+
+	for n := 1; ; n++ {
+		var (
+			attemptLatency time.Duration
+			sent           *http.Request
+			hedged         []upstreamAttempt
+		)
+		host := outreq.URL.Host
+		res, hijacked, attemptLatency, sent, hedged, err = p.sendHedged(roundTripper, outreq, w)
+		latency += attemptLatency
+		recordBreakerResult(breaker, host, sent.URL.Host, res, err)
+		p.reportOutlier(sent, res, err)
+
+		attempt := upstreamAttempt{Target: sent.URL.Host, Latency: attemptLatency}
+
+		var retryable bool
+		if err != nil {
+			errClass := tykerrors.ClassifyUpstreamError(err, sent.URL.Host+sent.URL.Path)
+			attempt.Flag = errClass.Flag
+			retryable = policy.RetryError(errClass) && outreq.Context().Err() == nil
+		} else if res != nil {
+			attempt.Status = res.StatusCode
+			retryable = policy.RetryStatus(res.StatusCode)
+		}
+		if hedged != nil {
+			attempts = append(attempts, hedged...)
+		} else {
+			attempts = append(attempts, attempt)
+		}
+
+		if !retryable || hijacked || n >= policy.MaxAttempts {
+			return
+		}
+
+		// the breaker of the target of the retry must let it through, the
+		// probe it may take is given back if the retry isn't sent after all
+		target, targetURL := p.nextUpstreamTarget(outreq)
+		var probe *circuitBreaker
+		if breaker != nil {
+			targetHost := outreq.URL.Host
+			if targetURL != nil {
+				targetHost = targetURL.Host
+			}
+
+			probe = breaker.breaker(targetHost)
+			if !probe.Ready() {
+				p.logger.Debug("[RETRY] Circuit breaker opened, not retrying upstream request")
+				return
+			}
+		}
+
+		if !p.Gw.retryBudget.Withdraw() {
+			probe.release()
+			p.logger.Debug("[RETRY] Retry budget exhausted, not retrying upstream request")
+			return
+		}
+
+		backoff := policy.Backoff(n)
+		p.logger.WithFields(logrus.Fields{
+			"attempt": n,
+			"outcome": attempt.outcome(),
+			"backoff": backoff,
+		}).Debug("[RETRY] Retrying upstream request")
+
+		if res != nil {
+			_, _ = io.CopyN(io.Discard, res.Body, retryDrainLimit)
+			res.Body.Close()
+			res = nil
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-outreq.Context().Done():
+			timer.Stop()
+			probe.release()
+			err = outreq.Context().Err()
+			return
+		case <-timer.C:
+		}
+
+		if body, ok := outreq.Body.(*nopCloserBuffer); ok {
+			if _, err = body.Seek(0, io.SeekStart); err != nil {
+				probe.release()
+				return
+			}
+		}
+
+		p.moveToTarget(outreq, target, targetURL)
+	}
+}
+
+// retarget moves a request that is about to be retried or hedged to the next
+// load balancing target, provided it serves the same path as the previous one.
+func (p *ReverseProxy) retarget(outreq *http.Request) {
+	target, targetURL := p.nextUpstreamTarget(outreq)
+	p.moveToTarget(outreq, target, targetURL)
+}
+
+// nextUpstreamTarget returns the next load balancing target of outreq and its URL,
+// provided it serves the same path as the current one, or a nil URL.
+func (p *ReverseProxy) nextUpstreamTarget(outreq *http.Request) (string, *url.URL) {
+	spec := p.TykAPISpec
+	previous := ctxGetLoadBalancerTarget(outreq)
+	if previous == "" || !spec.Proxy.EnableLoadBalancing || spec.Proxy.ServiceDiscovery.UseDiscoveryService {
+		return "", nil
+	}
+
+	host, err := p.Gw.nextRequestTarget(outreq, spec.Proxy.StructuredTargetList, spec)
+	if err != nil || host == previous {
+		return "", nil
+	}
+
+	prevURL, err := url.Parse(previous)
+	if err != nil {
+		return "", nil
+	}
+	nextURL, err := url.Parse(host)
+	if err != nil || nextURL.Path != prevURL.Path || nextURL.RawQuery != prevURL.RawQuery {
+		return "", nil
+	}
+
+	return host, nextURL
+}
+
+// moveToTarget moves outreq to the load balancing target host with URL
+// nextURL, leaving it unchanged when nextURL is nil.
+func (p *ReverseProxy) moveToTarget(outreq *http.Request, host string, nextURL *url.URL) {
+	if nextURL == nil {
+		return
+	}
+
+	spec := p.TykAPISpec
+	outreq.URL.Scheme = nextURL.Scheme
+	if outreq.URL.Scheme == "h2c" {
+		outreq.URL.Scheme = "http"
+	}
+	outreq.URL.Host = nextURL.Host
+	if !spec.Proxy.PreserveHostHeader {
+		outreq.Host = nextURL.Host
+	}
+
+	ctxSetLoadBalancerTarget(outreq, host)
+}
+
+// recordUpstreamAttempts makes the attempts of a retried request available to
+// analytics, and adds their count and an event per attempt to the request span.
+func (p *ReverseProxy) recordUpstreamAttempts(req, logreq *http.Request, attempts []upstreamAttempt) {
+	if len(attempts) < 2 {
+		return
+	}
+
+	ctxSetUpstreamAttempts(logreq, attempts)
+
+	if !p.Gw.GetConfig().OpenTelemetry.TracesEnabled() {
+		return
+	}
+
+	span := otel.SpanFromContext(req.Context())
+	span.SetAttributes(otel.UpstreamAttemptsAttribute(len(attempts)))
+	for i, attempt := range attempts {
+		otel.AddUpstreamAttemptEvent(span, otel.UpstreamAttempt{
+			Number:    i + 1,
+			Target:    attempt.Target,
+			Status:    attempt.Status,
+			Error:     attempt.Flag.String(),
+			Latency:   attempt.Latency,
+			Hedged:    attempt.Hedged,
+			Cancelled: attempt.Cancelled,
+		})
+	}
+}
+
+// recordBreakerResult reports the outcome of an upstream attempt to the
+// circuit breaker of host, the host the request was sent to. When a hedged
+// request to another host won, the probe taken for the original host, probed,
+// is given back.
+func recordBreakerResult(breaker *ExtendedCircuitBreakerMeta, probed, host string, res *http.Response, err error) {
+	if breaker == nil {
+		return
+	}
+
+	if host != probed {
+		breaker.breaker(probed).release()
+	}
+
+	cb := breaker.breaker(host)
+	if err != nil || (res != nil && res.StatusCode/100 == 5) {
+		cb.Fail()
+	} else {
+		cb.Success()
+	}
+}
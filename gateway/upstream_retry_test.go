@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/apidef"
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+	"github.com/TykTechnologies/tyk/internal/retry"
+	tyktime "github.com/TykTechnologies/tyk/internal/time"
+	"github.com/TykTechnologies/tyk/test"
+)
+
+func TestUpstreamRetry(t *testing.T) {
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// every third request succeeds
+		if hits.Add(1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	ts := StartTest(nil)
+	defer ts.Close()
+
+	ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+		spec.Proxy.ListenPath = "/"
+		spec.Proxy.TargetURL = upstream.URL
+		spec.Proxy.Retry = apidef.UpstreamRetryConfig{
+			Enabled:     true,
+			MaxAttempts: 3,
+			BackoffBase: tyktime.ReadableDuration(time.Millisecond),
+		}
+	})
+
+	t.Run("idempotent request is retried", func(t *testing.T) {
+		hits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Method: http.MethodGet, Code: http.StatusOK})
+		assert.EqualValues(t, 3, hits.Load())
+	})
+
+	t.Run("non-idempotent request is not retried", func(t *testing.T) {
+		hits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Method: http.MethodPost, Data: "body", Code: http.StatusServiceUnavailable})
+		assert.EqualValues(t, 1, hits.Load())
+	})
+
+	t.Run("attempts are limited", func(t *testing.T) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.Retry = apidef.UpstreamRetryConfig{
+				Enabled:     true,
+				MaxAttempts: 2,
+				BackoffBase: tyktime.ReadableDuration(time.Millisecond),
+			}
+		})
+
+		hits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/", Method: http.MethodGet, Code: http.StatusServiceUnavailable})
+		assert.EqualValues(t, 2, hits.Load())
+	})
+
+	t.Run("endpoint overrides the API retry policy", func(t *testing.T) {
+		ts.Gw.BuildAndLoadAPI(func(spec *APISpec) {
+			spec.Proxy.ListenPath = "/"
+			spec.Proxy.TargetURL = upstream.URL
+			spec.Proxy.Retry = apidef.UpstreamRetryConfig{
+				Enabled:     true,
+				MaxAttempts: 3,
+				BackoffBase: tyktime.ReadableDuration(time.Millisecond),
+			}
+			UpdateAPIVersion(spec, "v1", func(v *apidef.VersionInfo) {
+				v.UseExtendedPaths = true
+				v.ExtendedPaths.UpstreamRetry = []apidef.UpstreamRetryMeta{
+					{Path: "/no-retry", Method: http.MethodGet},
+					{Path: "/orders", Method: http.MethodPost, Retry: apidef.UpstreamRetryConfig{
+						Enabled:            true,
+						MaxAttempts:        3,
+						BackoffBase:        tyktime.ReadableDuration(time.Millisecond),
+						RetryNonIdempotent: true,
+					}},
+				}
+			})
+		})
+
+		hits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/no-retry", Method: http.MethodGet, Code: http.StatusServiceUnavailable})
+		assert.EqualValues(t, 1, hits.Load())
+
+		hits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/orders", Method: http.MethodPost, Data: "body", Code: http.StatusOK})
+		assert.EqualValues(t, 3, hits.Load())
+
+		hits.Store(0)
+		_, _ = ts.Run(t, test.TestCase{Path: "/other", Method: http.MethodGet, Code: http.StatusOK})
+		assert.EqualValues(t, 3, hits.Load())
+	})
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	assert.False(t, newRetryPolicy(apidef.UpstreamRetryConfig{MaxAttempts: 3}).Enabled())
+
+	policy := newRetryPolicy(apidef.UpstreamRetryConfig{
+		Enabled:            true,
+		MaxAttempts:        3,
+		RetryOn:            []string{"reset", "5xx"},
+		StatusCodes:        []int{http.StatusTooManyRequests},
+		BackoffBase:        tyktime.ReadableDuration(time.Millisecond),
+		BackoffMax:         tyktime.ReadableDuration(time.Second),
+		RetryNonIdempotent: true,
+	})
+
+	assert.Equal(t, retry.Policy{
+		MaxAttempts:   3,
+		Conditions:    []retry.Condition{retry.Reset, retry.ServerError},
+		StatusCodes:   []int{http.StatusTooManyRequests},
+		BackoffBase:   time.Millisecond,
+		BackoffMax:    time.Second,
+		NonIdempotent: true,
+	}, policy)
+}
+
+func TestUpstreamAttemptTags(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, []string{"a"}, upstreamAttemptTags(r, []string{"a"}))
+
+	ctxSetUpstreamAttempts(r, []upstreamAttempt{
+		{Target: "a:80", Flag: tykerrors.UCF},
+		{Target: "b:80", Status: http.StatusServiceUnavailable},
+		{Target: "a:80", Status: http.StatusOK},
+	})
+
+	assert.Equal(t, []string{
+		"a",
+		"upstream-attempt-1-UCF",
+		"upstream-attempt-2-503",
+		"upstream-attempt-3-200",
+	}, upstreamAttemptTags(r, []string{"a"}))
+}
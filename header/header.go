@@ -18,6 +18,15 @@ const (
 	Cookie                  = "Cookie"
 	TransferEncoding        = "Transfer-Encoding"
 	Host                    = "Host"
+	RetryAfter              = "Retry-After"
+	Warning                 = "Warning"
+	SurrogateKey            = "Surrogate-Key"
+	CacheTag                = "Cache-Tag"
+	ETag                    = "ETag"
+	LastModified            = "Last-Modified"
+	IfNoneMatch             = "If-None-Match"
+	IfModifiedSince         = "If-Modified-Since"
+	Vary                    = "Vary"
 )
 
 const (
@@ -67,4 +76,10 @@ const (
 
 	// XRateLimitReset The number of seconds until the rate limit resets.
 	XRateLimitReset = "X-RateLimit-Reset"
+
+	// RateLimit The remaining quota and reset time of each limit, as per the IETF RateLimit header fields draft.
+	RateLimit = "RateLimit"
+
+	// RateLimitPolicy The quota and window of each limit, as per the IETF RateLimit header fields draft.
+	RateLimitPolicy = "RateLimit-Policy"
 )
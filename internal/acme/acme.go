@@ -0,0 +1,589 @@
+// Package acme issues and renews the TLS certificates of gateway domains with
+// an ACME certificate authority, such as Let's Encrypt, using the HTTP-01 and
+// TLS-ALPN-01 challenges.
+//
+// Challenge responses, the account key and the issued certificates are
+// shared through a Store, with private keys encrypted by the configured secret, so that any gateway of a cluster can answer the
+// challenges of an order placed by another one, and a distributed lock
+// ensures only one gateway orders the certificate of a domain.
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	xacme "golang.org/x/crypto/acme"
+)
+
+const (
+	// ChallengeHTTP01 is the HTTP-01 challenge type.
+	ChallengeHTTP01 = "http-01"
+	// ChallengeTLSALPN01 is the TLS-ALPN-01 challenge type.
+	ChallengeTLSALPN01 = "tls-alpn-01"
+
+	// ALPNProto is the ALPN protocol of TLS-ALPN-01 challenge handshakes.
+	ALPNProto = xacme.ALPNProto
+
+	// DefaultRenewBefore is how long before their expiry certificates are
+	// renewed when no renewal window is configured.
+	DefaultRenewBefore = 30 * 24 * time.Hour
+
+	// LockPrefix is the prefix of the distributed locks of orders.
+	LockPrefix = "acme-lock-"
+
+	// orderTimeout bounds the duration of an order, including the
+	// validation of its challenges.
+	orderTimeout = 5 * time.Minute
+	// lockTTL is how long the order lock of a domain is held. The lock isn't
+	// released after failed orders, so it also delays their retries.
+	lockTTL = 10 * time.Minute
+	// challengeTTL is how long challenge responses are kept.
+	challengeTTL = int64(orderTimeout / time.Second)
+
+	accountKey           = "account"
+	certKeyPrefix        = "cert-"
+	httpChallengePrefix  = "http-01-"
+	alpnChallengePrefix  = "tls-alpn-01-"
+	httpChallengeURLPath = "/.well-known/acme-challenge/"
+)
+
+// ErrLocked is returned when the certificate of a domain is being ordered
+// by another gateway.
+var ErrLocked = errors.New("certificate is being ordered by another gateway")
+
+// Config configures a Manager.
+type Config struct {
+	// DirectoryURL is the URL of the directory of the ACME server.
+	DirectoryURL string
+	// Email is the contact address of the ACME account.
+	Email string
+	// Challenges are the challenge types to use, in order of preference. Both
+	// HTTP-01 and TLS-ALPN-01 are used when empty.
+	Challenges []string
+	// RenewBefore is how long before their expiry certificates are renewed.
+	RenewBefore time.Duration
+	// HTTPClient is the client used to talk to the ACME server.
+	HTTPClient *http.Client
+	// Secret encrypts the private keys kept in the Store: the account key and
+	// the keys of TLS-ALPN-01 challenge certificates.
+	Secret string
+}
+
+// Store stores the state shared by the gateways of a cluster.
+type Store interface {
+	GetKey(key string) (string, error)
+	SetKey(key, value string, ttl int64) error
+	DeleteKey(key string) bool
+	Lock(key string, ttl time.Duration) (bool, error)
+}
+
+// CertStore stores the issued certificates, it is implemented by the
+// certificate manager.
+type CertStore interface {
+	Add(certData []byte, orgID string) (string, error)
+	Delete(certID string, orgID string)
+}
+
+// Certificate is an issued certificate of a domain.
+type Certificate struct {
+	// ID is the ID of the certificate in the certificate store.
+	ID string `json:"id"`
+	// NotAfter is the expiry time of the certificate.
+	NotAfter time.Time `json:"not_after"`
+}
+
+// Manager orders and renews certificates, and answers the challenges of
+// the ACME server. It is safe for concurrent use.
+type Manager struct {
+	conf   Config
+	store  Store
+	certs  CertStore
+	logger *logrus.Entry
+
+	mu     sync.Mutex
+	client *xacme.Client
+}
+
+// NewManager creates an ACME manager keeping its state in store and the
+// issued certificates in certs.
+func NewManager(conf Config, store Store, certs CertStore, logger *logrus.Entry) *Manager {
+	if len(conf.Challenges) == 0 {
+		conf.Challenges = []string{ChallengeTLSALPN01, ChallengeHTTP01}
+	}
+	if conf.RenewBefore <= 0 {
+		conf.RenewBefore = DefaultRenewBefore
+	}
+	if logger == nil {
+		logger = logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	return &Manager{
+		conf:   conf,
+		store:  store,
+		certs:  certs,
+		logger: logger.WithField("prefix", "acme"),
+	}
+}
+
+// Certificate returns the issued certificate of domain, or false if none
+// was issued.
+func (m *Manager) Certificate(domain string) (Certificate, bool) {
+	var cert Certificate
+
+	data, err := m.store.GetKey(certKeyPrefix + domain)
+	if err != nil || json.Unmarshal([]byte(data), &cert) != nil || cert.ID == "" {
+		return Certificate{}, false
+	}
+
+	return cert, true
+}
+
+// NeedsCertificate reports whether the certificate of domain has to be
+// ordered, because none was issued or it expires soon.
+func (m *Manager) NeedsCertificate(domain string) bool {
+	cert, ok := m.Certificate(domain)
+	return !ok || time.Until(cert.NotAfter) < m.conf.RenewBefore
+}
+
+// Renew orders the certificates of the domains which need one. Failures are
+// logged and retried on the next call.
+func (m *Manager) Renew(ctx context.Context, domains []string) {
+	for _, domain := range domains {
+		if ctx.Err() != nil {
+			return
+		}
+		if !m.NeedsCertificate(domain) {
+			continue
+		}
+
+		_, err := m.Obtain(ctx, domain)
+		switch {
+		case errors.Is(err, ErrLocked):
+			m.logger.WithField("domain", domain).Debug("Certificate is being ordered by another gateway")
+		case err != nil:
+			m.logger.WithError(err).WithField("domain", domain).Error("Couldn't obtain certificate")
+		default:
+			m.logger.WithField("domain", domain).Info("Obtained certificate")
+		}
+	}
+}
+
+// Run renews the certificates of the domains returned by domains every
+// interval, until ctx is done.
+func (m *Manager) Run(ctx context.Context, interval time.Duration, domains func() []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.Renew(ctx, domains())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Obtain orders the certificate of domain and adds it to the certificate
+// store, replacing the previous one. It returns ErrLocked if another gateway
+// is ordering it.
+func (m *Manager) Obtain(ctx context.Context, domain string) (Certificate, error) {
+	locked, err := m.store.Lock(LockPrefix+domain, lockTTL)
+	if err != nil {
+		return Certificate{}, err
+	}
+	if !locked {
+		return Certificate{}, ErrLocked
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, orderTimeout)
+	defer cancel()
+
+	client, err := m.acmeClient(ctx)
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, xacme.DomainIDs(domain))
+	if err != nil {
+		return Certificate{}, fmt.Errorf("creating order: %w", err)
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		if err := m.authorize(ctx, client, authzURL); err != nil {
+			return Certificate{}, err
+		}
+	}
+
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return Certificate{}, fmt.Errorf("waiting for order: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: []string{domain},
+	}, key)
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("finalizing order: %w", err)
+	}
+
+	return m.save(domain, chain, key)
+}
+
+// authorize fulfils a challenge of the authorization at authzURL and waits
+// for its validation.
+func (m *Manager) authorize(ctx context.Context, client *xacme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("fetching authorization: %w", err)
+	}
+	if authz.Status == xacme.StatusValid {
+		return nil
+	}
+
+	challenge := m.pickChallenge(authz.Challenges)
+	if challenge == nil {
+		return fmt.Errorf("no supported challenge for %s", authz.Identifier.Value)
+	}
+
+	cleanup, err := m.fulfil(client, challenge, authz.Identifier.Value)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("accepting %s challenge: %w", challenge.Type, err)
+	}
+
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("validating %s challenge: %w", challenge.Type, err)
+	}
+
+	return nil
+}
+
+// pickChallenge returns the preferred challenge of challenges.
+func (m *Manager) pickChallenge(challenges []*xacme.Challenge) *xacme.Challenge {
+	for _, typ := range m.conf.Challenges {
+		for _, challenge := range challenges {
+			if challenge.Type == typ {
+				return challenge
+			}
+		}
+	}
+	return nil
+}
+
+// fulfil stores the response to challenge, for the gateways of the cluster
+// to serve it. It returns a function removing the response.
+func (m *Manager) fulfil(client *xacme.Client, challenge *xacme.Challenge, domain string) (func(), error) {
+	var key, value string
+
+	switch challenge.Type {
+	case ChallengeHTTP01:
+		res, err := client.HTTP01ChallengeResponse(challenge.Token)
+		if err != nil {
+			return nil, err
+		}
+		key, value = httpChallengePrefix+challenge.Token, res
+	case ChallengeTLSALPN01:
+		cert, err := client.TLSALPN01ChallengeCert(challenge.Token, domain)
+		if err != nil {
+			return nil, err
+		}
+		data, err := encodeCertificate(cert.Certificate, cert.PrivateKey, m.conf.Secret)
+		if err != nil {
+			return nil, err
+		}
+		key, value = alpnChallengePrefix+domain, string(data)
+	default:
+		return nil, fmt.Errorf("unsupported challenge %s", challenge.Type)
+	}
+
+	if err := m.store.SetKey(key, value, challengeTTL); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		m.store.DeleteKey(key)
+	}, nil
+}
+
+// save adds an issued certificate to the certificate store and records it
+// as the certificate of domain.
+func (m *Manager) save(domain string, chain [][]byte, key crypto.Signer) (Certificate, error) {
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	// the certificate store encrypts the key itself
+	data, err := encodeCertificate(chain, key, "")
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	id, err := m.certs.Add(data, "")
+	if err != nil {
+		return Certificate{}, fmt.Errorf("storing certificate: %w", err)
+	}
+
+	previous, hasPrevious := m.Certificate(domain)
+
+	cert := Certificate{ID: id, NotAfter: leaf.NotAfter}
+	encoded, err := json.Marshal(cert)
+	if err != nil {
+		return Certificate{}, err
+	}
+	if err := m.store.SetKey(certKeyPrefix+domain, string(encoded), 0); err != nil {
+		return Certificate{}, err
+	}
+
+	if hasPrevious && previous.ID != id {
+		m.certs.Delete(previous.ID, "")
+	}
+
+	return cert, nil
+}
+
+// acmeClient returns the ACME client, registering the account shared by the
+// gateways of the cluster on first use.
+func (m *Manager) acmeClient(ctx context.Context) (*xacme.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	key, err := m.accountKey()
+	if err != nil {
+		return nil, err
+	}
+
+	client := &xacme.Client{
+		Key:          key,
+		DirectoryURL: m.conf.DirectoryURL,
+		HTTPClient:   m.conf.HTTPClient,
+		UserAgent:    "tyk-gateway",
+	}
+
+	account := &xacme.Account{}
+	if m.conf.Email != "" {
+		account.Contact = []string{"mailto:" + m.conf.Email}
+	}
+
+	_, err = client.Register(ctx, account, xacme.AcceptTOS)
+	if err != nil && !errors.Is(err, xacme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("registering account: %w", err)
+	}
+
+	m.client = client
+	return client, nil
+}
+
+// accountKey returns the key of the ACME account, generating it if there is
+// none. Concurrently generated keys are resolved by the ACME server, which
+// accepts registrations of either. Keys stored before they were encrypted
+// are stored again encrypted.
+func (m *Manager) accountKey() (crypto.Signer, error) {
+	if data, err := m.store.GetKey(accountKey); err == nil {
+		block, _ := pem.Decode([]byte(data))
+		if block == nil {
+			return nil, errors.New("invalid ACME account key")
+		}
+		key, err := decodeKey(block, m.conf.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACME account key: %w", err)
+		}
+		if m.conf.Secret != "" && !x509.IsEncryptedPEMBlock(block) {
+			if err := m.storeAccountKey(key); err != nil {
+				m.logger.WithError(err).Warning("Couldn't encrypt the ACME account key")
+			}
+		}
+		return key, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.storeAccountKey(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// storeAccountKey stores the key of the ACME account, encrypted.
+func (m *Manager) storeAccountKey(key crypto.Signer) error {
+	data, err := encodeKey(key, m.conf.Secret)
+	if err != nil {
+		return err
+	}
+	return m.store.SetKey(accountKey, string(data), 0)
+}
+
+// HTTPHandler serves the responses to HTTP-01 challenges, and passes other
+// requests to fallback. A nil fallback responds with 404 Not Found.
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	if fallback == nil {
+		fallback = http.NotFoundHandler()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, httpChallengeURLPath) {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		token := strings.TrimPrefix(r.URL.Path, httpChallengeURLPath)
+		res, err := m.store.GetKey(httpChallengePrefix + token)
+		if token == "" || err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(res))
+	})
+}
+
+// ChallengeCertificate returns the certificate answering the TLS-ALPN-01
+// challenge of the handshake, or false if it isn't a challenge handshake.
+func (m *Manager) ChallengeCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, bool) {
+	if !IsChallengeHello(hello) {
+		return nil, false
+	}
+
+	data, err := m.store.GetKey(alpnChallengePrefix + hello.ServerName)
+	if err != nil {
+		return nil, false
+	}
+
+	cert, err := decodeCertificate([]byte(data), m.conf.Secret)
+	if err != nil {
+		m.logger.WithError(err).Error("Invalid TLS-ALPN-01 challenge certificate")
+		return nil, false
+	}
+
+	return cert, true
+}
+
+// IsChallengeHello reports whether hello is a TLS-ALPN-01 challenge handshake.
+func IsChallengeHello(hello *tls.ClientHelloInfo) bool {
+	return len(hello.SupportedProtos) == 1 && hello.SupportedProtos[0] == ALPNProto
+}
+
+// encodeCertificate PEM encodes a certificate chain followed by its key,
+// encrypted with secret unless it's empty.
+func encodeCertificate(chain [][]byte, key crypto.PrivateKey, secret string) ([]byte, error) {
+	keyData, err := encodeKey(key, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for _, cert := range chain {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})...)
+	}
+	return append(data, keyData...), nil
+}
+
+// decodeCertificate decodes a certificate chain and its key encoded by
+// encodeCertificate.
+func decodeCertificate(data []byte, secret string) (*tls.Certificate, error) {
+	var cert tls.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type == "CERTIFICATE" {
+			cert.Certificate = append(cert.Certificate, block.Bytes)
+			continue
+		}
+
+		key, err := decodeKey(block, secret)
+		if err != nil {
+			return nil, err
+		}
+		cert.PrivateKey = key
+	}
+
+	if len(cert.Certificate) == 0 || cert.PrivateKey == nil {
+		return nil, errors.New("certificate or key missing")
+	}
+	return &cert, nil
+}
+
+// encodeKey PEM encodes a PKCS #8 private key. Unless secret is empty, the
+// key is encrypted with it like the certificate manager encrypts keys.
+func encodeKey(key crypto.PrivateKey, secret string) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == "" {
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+
+	block, err := x509.EncryptPEMBlock(rand.Reader, "ENCRYPTED PRIVATE KEY", der, []byte(secret), x509.PEMCipherAES256)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// decodeKey decodes a private key PEM block encoded by encodeKey.
+func decodeKey(block *pem.Block, secret string) (crypto.Signer, error) {
+	der := block.Bytes
+	if x509.IsEncryptedPEMBlock(block) {
+		var err error
+		if der, err = x509.DecryptPEMBlock(block, []byte(secret)); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
@@ -0,0 +1,254 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{keys: map[string]string{}}
+}
+
+func (s *memoryStore) GetKey(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.keys[key]
+	if !ok {
+		return "", errors.New("key not found")
+	}
+	return v, nil
+}
+
+func (s *memoryStore) SetKey(key, value string, _ int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key] = value
+	return nil
+}
+
+func (s *memoryStore) DeleteKey(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.keys[key]
+	delete(s.keys, key)
+	return ok
+}
+
+func (s *memoryStore) Lock(key string, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+	s.keys[key] = "1"
+	return true, nil
+}
+
+type memoryCertStore struct {
+	mu    sync.Mutex
+	certs map[string][]byte
+}
+
+func (s *memoryCertStore) Add(data []byte, orgID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sum := sha256.Sum256(data)
+	id := orgID + hex.EncodeToString(sum[:])
+	s.certs[id] = data
+	return id, nil
+}
+
+func (s *memoryCertStore) Delete(id, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.certs, id)
+}
+
+func TestManager_NeedsCertificate(t *testing.T) {
+	store := newMemoryStore()
+	m := NewManager(Config{}, store, nil, nil)
+
+	assert.True(t, m.NeedsCertificate("example.com"))
+
+	setCert := func(notAfter time.Time) {
+		data, err := json.Marshal(Certificate{ID: "cert", NotAfter: notAfter})
+		require.NoError(t, err)
+		require.NoError(t, store.SetKey(certKeyPrefix+"example.com", string(data), 0))
+	}
+
+	setCert(time.Now().Add(60 * 24 * time.Hour))
+	assert.False(t, m.NeedsCertificate("example.com"))
+
+	cert, ok := m.Certificate("example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "cert", cert.ID)
+
+	// certificates are renewed within the renewal window
+	setCert(time.Now().Add(10 * 24 * time.Hour))
+	assert.True(t, m.NeedsCertificate("example.com"))
+}
+
+func TestManager_ObtainLocked(t *testing.T) {
+	store := newMemoryStore()
+	_, err := store.Lock(LockPrefix+"example.com", time.Minute)
+	require.NoError(t, err)
+
+	m := NewManager(Config{}, store, nil, nil)
+	_, err = m.Obtain(context.Background(), "example.com")
+	assert.ErrorIs(t, err, ErrLocked)
+}
+
+func TestManager_HTTPHandler(t *testing.T) {
+	store := newMemoryStore()
+	require.NoError(t, store.SetKey(httpChallengePrefix+"token", "token.thumbprint", 0))
+
+	m := NewManager(Config{}, store, nil, nil)
+	handler := m.HTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/.well-known/acme-challenge/token")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "token.thumbprint", w.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, serve("/.well-known/acme-challenge/unknown").Code)
+	assert.Equal(t, http.StatusTeapot, serve("/other").Code)
+}
+
+func TestManager_ChallengeCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"example.com"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	data, err := encodeCertificate([][]byte{der}, key, "secret")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "ENCRYPTED PRIVATE KEY")
+
+	store := newMemoryStore()
+	require.NoError(t, store.SetKey(alpnChallengePrefix+"example.com", string(data), 0))
+	m := NewManager(Config{Secret: "secret"}, store, nil, nil)
+
+	cert, ok := m.ChallengeCertificate(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{ALPNProto}})
+	require.True(t, ok)
+	assert.Equal(t, der, cert.Certificate[0])
+
+	_, ok = m.ChallengeCertificate(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{"h2"}})
+	assert.False(t, ok)
+
+	_, ok = m.ChallengeCertificate(&tls.ClientHelloInfo{ServerName: "other.com", SupportedProtos: []string{ALPNProto}})
+	assert.False(t, ok)
+
+	// keys encrypted with another secret can't be used
+	other := NewManager(Config{Secret: "other"}, store, nil, nil)
+	_, ok = other.ChallengeCertificate(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{ALPNProto}})
+	assert.False(t, ok)
+}
+
+func TestManager_accountKey(t *testing.T) {
+	store := newMemoryStore()
+	m := NewManager(Config{Secret: "secret"}, store, nil, nil)
+
+	key, err := m.accountKey()
+	require.NoError(t, err)
+	assert.Contains(t, store.keys[accountKey], "ENCRYPTED PRIVATE KEY")
+
+	stored, err := m.accountKey()
+	require.NoError(t, err)
+	assert.Equal(t, key, stored)
+
+	_, err = NewManager(Config{Secret: "other"}, store, nil, nil).accountKey()
+	assert.Error(t, err)
+
+	t.Run("unencrypted keys are encrypted", func(t *testing.T) {
+		data, err := encodeKey(key, "")
+		require.NoError(t, err)
+
+		store := newMemoryStore()
+		require.NoError(t, store.SetKey(accountKey, string(data), 0))
+
+		stored, err := NewManager(Config{Secret: "secret"}, store, nil, nil).accountKey()
+		require.NoError(t, err)
+		assert.Equal(t, key, stored)
+		assert.Contains(t, store.keys[accountKey], "ENCRYPTED PRIVATE KEY")
+	})
+}
+
+// TestManager_Pebble orders a certificate from a Pebble ACME test server,
+// started with PEBBLE_VA_ALWAYS_VALID=1, whose directory URL is set in
+// TYK_TEST_ACME_DIRECTORY.
+func TestManager_Pebble(t *testing.T) {
+	directory := os.Getenv("TYK_TEST_ACME_DIRECTORY")
+	if directory == "" {
+		t.Skip("TYK_TEST_ACME_DIRECTORY is not set")
+	}
+
+	store := newMemoryStore()
+	certStore := &memoryCertStore{certs: map[string][]byte{}}
+	m := NewManager(Config{
+		DirectoryURL: directory,
+		Email:        "admin@example.com",
+		Challenges:   []string{ChallengeHTTP01},
+		HTTPClient: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}},
+	}, store, certStore, nil)
+
+	cert, err := m.Obtain(context.Background(), "gateway.example.com")
+	require.NoError(t, err)
+	assert.Contains(t, certStore.certs, cert.ID)
+	assert.False(t, m.NeedsCertificate("gateway.example.com"))
+
+	pair, err := tls.X509KeyPair(certStore.certs[cert.ID], certStore.certs[cert.ID])
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gateway.example.com"}, leaf.DNSNames)
+
+	// the challenge responses are removed
+	for key := range store.keys {
+		assert.NotContains(t, key, httpChallengePrefix)
+	}
+}
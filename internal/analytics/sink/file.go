@@ -0,0 +1,166 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+const (
+	// DefaultFileMaxSize is the size at which a file sink rotates its file.
+	DefaultFileMaxSize = 100 << 20
+	// DefaultFileMaxBackups is the number of rotated files kept by a file sink.
+	DefaultFileMaxBackups = 5
+
+	rotatedFileTimeFormat = "20060102T150405.000000000"
+)
+
+// FileConfig configures a file sink.
+type FileConfig struct {
+	// Path is the path of the file records are written to.
+	Path string
+	// MaxSize is the size in bytes at which the file is rotated.
+	MaxSize int64
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+}
+
+// File writes records as newline delimited JSON to a file. The file is
+// rotated when it grows over the configured size. Rotated files are named
+// after the file, suffixed with the rotation time.
+type File struct {
+	conf FileConfig
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+	now    func() time.Time
+}
+
+// NewFile opens, or creates, the file of a file sink.
+func NewFile(conf FileConfig) (*File, error) {
+	if conf.Path == "" {
+		return nil, fmt.Errorf("file analytics sink: path is required")
+	}
+	if conf.MaxSize <= 0 {
+		conf.MaxSize = DefaultFileMaxSize
+	}
+	if conf.MaxBackups <= 0 {
+		conf.MaxBackups = DefaultFileMaxBackups
+	}
+
+	f := &File{conf: conf, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends records to the file, rotating it first if they don't fit.
+func (f *File) Write(_ context.Context, records []*analytics.AnalyticsRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("file analytics sink: %w", err)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrClosed
+	}
+
+	// the file is reopened after a failed rotation
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+
+	if f.size > 0 && f.size+int64(buf.Len()) > f.conf.MaxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.Write(buf.Bytes())
+	f.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("file analytics sink: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.conf.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("file analytics sink: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("file analytics sink: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file aside, opens a new one and removes the
+// rotated files over the configured number of backups.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("file analytics sink: %w", err)
+	}
+	f.file = nil
+
+	rotated := f.conf.Path + "." + f.now().UTC().Format(rotatedFileTimeFormat)
+	if err := os.Rename(f.conf.Path, rotated); err != nil {
+		return fmt.Errorf("file analytics sink: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	backups, err := filepath.Glob(f.conf.Path + ".*")
+	if err != nil || len(backups) <= f.conf.MaxBackups {
+		return nil
+	}
+
+	// rotation times sort lexically, oldest first
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-f.conf.MaxBackups] {
+		_ = os.Remove(backup)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+func readRecords(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var apiIDs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record analytics.AnalyticsRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		apiIDs = append(apiIDs, record.APIID)
+	}
+	require.NoError(t, scanner.Err())
+	return apiIDs
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analytics.log")
+
+	f, err := NewFile(FileConfig{Path: path})
+	require.NoError(t, err)
+
+	require.NoError(t, f.Write(context.Background(), testRecords("a", "b")))
+	require.NoError(t, f.Write(context.Background(), testRecords("c")))
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, []string{"a", "b", "c"}, readRecords(t, path))
+
+	// records are appended to an existing file
+	f, err = NewFile(FileConfig{Path: path})
+	require.NoError(t, err)
+	require.NoError(t, f.Write(context.Background(), testRecords("d")))
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, readRecords(t, path))
+}
+
+func TestFile_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "analytics.log")
+
+	f, err := NewFile(FileConfig{Path: path, MaxSize: 1, MaxBackups: 2})
+	require.NoError(t, err)
+
+	now := time.Unix(1000, 0)
+	f.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, apiID := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, f.Write(context.Background(), testRecords(apiID)))
+	}
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, []string{"d"}, readRecords(t, path))
+
+	backups, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, []string{"b"}, readRecords(t, backups[0]))
+	assert.Equal(t, []string{"c"}, readRecords(t, backups[1]))
+}
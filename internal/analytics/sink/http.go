@@ -0,0 +1,101 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+// responseDrainLimit is the most of a response body that is read so that the
+// connection can be reused.
+const responseDrainLimit = 4 << 10
+
+// HTTPConfig configures an HTTP batch sink.
+type HTTPConfig struct {
+	// URL is the endpoint batches of records are sent to.
+	URL string
+	// Headers are added to every request.
+	Headers map[string]string
+	// Timeout is the request timeout.
+	Timeout time.Duration
+	// Client is the client used to send requests. Defaults to a client with Timeout.
+	Client *http.Client
+}
+
+func (c HTTPConfig) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// HTTP sends every batch of records as a JSON array in a POST request.
+type HTTP struct {
+	conf   HTTPConfig
+	client *http.Client
+}
+
+// NewHTTP creates an HTTP batch sink.
+func NewHTTP(conf HTTPConfig) (*HTTP, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("http analytics sink: url is required")
+	}
+
+	return &HTTP{conf: conf, client: conf.client()}, nil
+}
+
+// Write sends records to the configured endpoint.
+func (h *HTTP) Write(ctx context.Context, records []*analytics.AnalyticsRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("http analytics sink: %w", err)
+	}
+
+	if err := post(ctx, h.client, h.conf.URL, h.conf.Headers, body); err != nil {
+		return fmt.Errorf("http analytics sink: %w", err)
+	}
+	return nil
+}
+
+// Close closes the idle connections of the sink.
+func (h *HTTP) Close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
+
+// post sends a JSON body to url. Any response other than 2xx is an error.
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	_, _ = io.CopyN(io.Discard, res.Body, responseDrainLimit)
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+	return nil
+}
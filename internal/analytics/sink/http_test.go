@@ -0,0 +1,46 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+func TestHTTP(t *testing.T) {
+	var (
+		status   = http.StatusOK
+		received []analytics.AnalyticsRecord
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		received = nil
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	h, err := NewHTTP(HTTPConfig{URL: server.URL, Headers: map[string]string{"Authorization": "secret"}})
+	require.NoError(t, err)
+	defer h.Close()
+
+	require.NoError(t, h.Write(context.Background(), testRecords("a", "b")))
+	require.Len(t, received, 2)
+	assert.Equal(t, "a", received[0].APIID)
+	assert.Equal(t, "b", received[1].APIID)
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, h.Write(context.Background(), testRecords("a")))
+
+	_, err = NewHTTP(HTTPConfig{})
+	assert.Error(t, err)
+}
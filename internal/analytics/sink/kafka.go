@@ -0,0 +1,122 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+// KafkaConfig configures a Kafka sink.
+type KafkaConfig struct {
+	// Brokers is the list of Kafka compatible brokers.
+	Brokers []string
+	// Topic is the topic records are produced to.
+	Topic string
+	// Timeout is the produce timeout.
+	Timeout time.Duration
+}
+
+// Kafka produces every record as a JSON message to a topic of a Kafka
+// compatible cluster, keyed by API ID. The producer connects on the first
+// write, so that an unavailable cluster doesn't prevent the gateway from
+// starting.
+type Kafka struct {
+	conf        KafkaConfig
+	newProducer func(brokers []string, conf *sarama.Config) (sarama.SyncProducer, error)
+
+	mu       sync.Mutex
+	producer sarama.SyncProducer
+	closed   bool
+}
+
+// NewKafka creates a Kafka sink.
+func NewKafka(conf KafkaConfig) (*Kafka, error) {
+	if len(conf.Brokers) == 0 || conf.Topic == "" {
+		return nil, fmt.Errorf("kafka analytics sink: brokers and topic are required")
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultTimeout
+	}
+
+	return &Kafka{conf: conf, newProducer: sarama.NewSyncProducer}, nil
+}
+
+// Write produces records to the configured topic.
+func (k *Kafka) Write(ctx context.Context, records []*analytics.AnalyticsRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	messages := make([]*sarama.ProducerMessage, 0, len(records))
+	for _, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("kafka analytics sink: %w", err)
+		}
+
+		messages = append(messages, &sarama.ProducerMessage{
+			Topic:     k.conf.Topic,
+			Key:       sarama.StringEncoder(record.APIID),
+			Value:     sarama.ByteEncoder(value),
+			Timestamp: record.TimeStamp,
+		})
+	}
+
+	producer, err := k.getProducer()
+	if err != nil {
+		return err
+	}
+
+	if err := producer.SendMessages(messages); err != nil {
+		return fmt.Errorf("kafka analytics sink: %w", err)
+	}
+	return nil
+}
+
+// Close closes the producer.
+func (k *Kafka) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.closed = true
+	if k.producer == nil {
+		return nil
+	}
+
+	err := k.producer.Close()
+	k.producer = nil
+	return err
+}
+
+func (k *Kafka) getProducer() (sarama.SyncProducer, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.closed {
+		return nil, ErrClosed
+	}
+
+	if k.producer != nil {
+		return k.producer, nil
+	}
+
+	conf := sarama.NewConfig()
+	conf.Producer.Return.Successes = true
+	conf.Producer.RequiredAcks = sarama.WaitForAll
+	conf.Producer.Timeout = k.conf.Timeout
+	conf.Net.DialTimeout = k.conf.Timeout
+
+	producer, err := k.newProducer(k.conf.Brokers, conf)
+	if err != nil {
+		return nil, fmt.Errorf("kafka analytics sink: %w", err)
+	}
+
+	k.producer = producer
+	return producer, nil
+}
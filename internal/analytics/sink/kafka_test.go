@@ -0,0 +1,106 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+// newTestKafka creates a Kafka sink producing with producer.
+func newTestKafka(t *testing.T, producer sarama.SyncProducer) *Kafka {
+	t.Helper()
+
+	k, err := NewKafka(KafkaConfig{Brokers: []string{"kafka:9092"}, Topic: "analytics", Timeout: time.Second})
+	require.NoError(t, err)
+
+	k.newProducer = func(brokers []string, conf *sarama.Config) (sarama.SyncProducer, error) {
+		assert.Equal(t, []string{"kafka:9092"}, brokers)
+		assert.True(t, conf.Producer.Return.Successes)
+		assert.Equal(t, sarama.WaitForAll, conf.Producer.RequiredAcks)
+		assert.Equal(t, time.Second, conf.Producer.Timeout)
+		return producer, nil
+	}
+	return k
+}
+
+func TestNewKafka(t *testing.T) {
+	_, err := NewKafka(KafkaConfig{Topic: "analytics"})
+	assert.Error(t, err)
+
+	_, err = NewKafka(KafkaConfig{Brokers: []string{"kafka:9092"}})
+	assert.Error(t, err)
+
+	k, err := NewKafka(KafkaConfig{Brokers: []string{"kafka:9092"}, Topic: "analytics"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, k.conf.Timeout)
+}
+
+func TestKafka(t *testing.T) {
+	producer := mocks.NewSyncProducer(t, nil)
+	k := newTestKafka(t, producer)
+
+	for _, apiID := range []string{"a", "b"} {
+		producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			assert.Equal(t, "analytics", msg.Topic)
+			assert.Equal(t, sarama.StringEncoder(apiID), msg.Key)
+			assert.Equal(t, time.Unix(1000, 0), msg.Timestamp)
+
+			value, err := msg.Value.Encode()
+			require.NoError(t, err)
+
+			var record analytics.AnalyticsRecord
+			require.NoError(t, json.Unmarshal(value, &record))
+			assert.Equal(t, apiID, record.APIID)
+			return nil
+		})
+	}
+	require.NoError(t, k.Write(context.Background(), testRecords("a", "b")))
+
+	unavailable := errors.New("unavailable")
+	producer.ExpectSendMessageAndFail(unavailable)
+	assert.ErrorIs(t, k.Write(context.Background(), testRecords("a")), unavailable)
+
+	require.NoError(t, k.Close())
+	assert.ErrorIs(t, k.Write(context.Background(), testRecords("a")), ErrClosed)
+	assert.NoError(t, k.Close())
+}
+
+func TestKafka_connect(t *testing.T) {
+	k, err := NewKafka(KafkaConfig{Brokers: []string{"kafka:9092"}, Topic: "analytics"})
+	require.NoError(t, err)
+	defer k.Close()
+
+	var attempts int
+	unavailable := errors.New("no brokers")
+	producer := mocks.NewSyncProducer(t, nil)
+	k.newProducer = func([]string, *sarama.Config) (sarama.SyncProducer, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, unavailable
+		}
+		return producer, nil
+	}
+
+	// the producer is created on the first write, and again after failures
+	assert.ErrorIs(t, k.Write(context.Background(), testRecords("a")), unavailable)
+
+	producer.ExpectSendMessageAndSucceed()
+	require.NoError(t, k.Write(context.Background(), testRecords("a")))
+	producer.ExpectSendMessageAndSucceed()
+	require.NoError(t, k.Write(context.Background(), testRecords("a")))
+	assert.Equal(t, 2, attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, k.Write(ctx, testRecords("a")), context.Canceled)
+	assert.Equal(t, 2, attempts)
+}
@@ -0,0 +1,148 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+const (
+	otlpServiceName = "tyk-gateway"
+	otlpScopeName   = "github.com/TykTechnologies/tyk/analytics"
+
+	// otlpSeverityInfo is the INFO severity number of the OpenTelemetry log data model.
+	otlpSeverityInfo = 9
+)
+
+// OTLP exports records as OpenTelemetry logs, using the JSON encoding of
+// OTLP/HTTP. The log body is the JSON encoded record, and the main request
+// details are also added as log attributes.
+type OTLP struct {
+	conf   HTTPConfig
+	client *http.Client
+}
+
+// NewOTLP creates an OTLP logs sink. conf.URL is the OTLP/HTTP logs endpoint.
+func NewOTLP(conf HTTPConfig) (*OTLP, error) {
+	if conf.URL == "" {
+		return nil, fmt.Errorf("otlp analytics sink: endpoint is required")
+	}
+
+	return &OTLP{conf: conf, client: conf.client()}, nil
+}
+
+// Write exports records to the configured endpoint.
+func (o *OTLP) Write(ctx context.Context, records []*analytics.AnalyticsRecord) error {
+	logRecords := make([]otlpLogRecord, 0, len(records))
+	for _, record := range records {
+		logRecord, err := newOTLPLogRecord(record)
+		if err != nil {
+			return fmt.Errorf("otlp analytics sink: %w", err)
+		}
+		logRecords = append(logRecords, logRecord)
+	}
+
+	body, err := json.Marshal(otlpExportLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{stringAttribute("service.name", otlpServiceName)},
+			},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: otlpScopeName},
+				LogRecords: logRecords,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("otlp analytics sink: %w", err)
+	}
+
+	if err := post(ctx, o.client, o.conf.URL, o.conf.Headers, body); err != nil {
+		return fmt.Errorf("otlp analytics sink: %w", err)
+	}
+	return nil
+}
+
+// Close closes the idle connections of the sink.
+func (o *OTLP) Close() error {
+	o.client.CloseIdleConnections()
+	return nil
+}
+
+func newOTLPLogRecord(record *analytics.AnalyticsRecord) (otlpLogRecord, error) {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return otlpLogRecord{}, err
+	}
+
+	return otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(record.TimeStamp.UnixNano(), 10),
+		SeverityNumber: otlpSeverityInfo,
+		SeverityText:   "INFO",
+		Body:           otlpAnyValue{StringValue: string(body)},
+		Attributes: []otlpKeyValue{
+			stringAttribute("tyk.api.id", record.APIID),
+			stringAttribute("tyk.api.name", record.APIName),
+			stringAttribute("tyk.org.id", record.OrgID),
+			stringAttribute("http.request.method", record.Method),
+			stringAttribute("url.path", record.Path),
+			intAttribute("http.response.status_code", int64(record.ResponseCode)),
+			intAttribute("tyk.request_time_ms", record.RequestTime),
+		},
+	}, nil
+}
+
+// The types below follow the JSON mapping of the OTLP logs protobuf messages,
+// where 64 bit integers are encoded as strings.
+
+type otlpExportLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           otlpAnyValue   `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue,omitempty"`
+	IntValue    string `json:"intValue,omitempty"`
+}
+
+func stringAttribute(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+func intAttribute(key string, value int64) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{IntValue: strconv.FormatInt(value, 10)}}
+}
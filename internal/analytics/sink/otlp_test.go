@@ -0,0 +1,101 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+func TestOTLP(t *testing.T) {
+	var request otlpExportLogsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &request))
+	}))
+	defer server.Close()
+
+	o, err := NewOTLP(HTTPConfig{URL: server.URL})
+	require.NoError(t, err)
+	defer o.Close()
+
+	records := testRecords("a")
+	records[0].ResponseCode = http.StatusOK
+	require.NoError(t, o.Write(context.Background(), records))
+
+	require.Len(t, request.ResourceLogs, 1)
+	assert.Equal(t, []otlpKeyValue{stringAttribute("service.name", otlpServiceName)}, request.ResourceLogs[0].Resource.Attributes)
+	require.Len(t, request.ResourceLogs[0].ScopeLogs, 1)
+	assert.Equal(t, otlpScopeName, request.ResourceLogs[0].ScopeLogs[0].Scope.Name)
+
+	logRecords := request.ResourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, logRecords, 1)
+	assert.Equal(t, "1000000000000", logRecords[0].TimeUnixNano)
+	assert.Equal(t, otlpSeverityInfo, logRecords[0].SeverityNumber)
+	assert.Equal(t, "INFO", logRecords[0].SeverityText)
+	assert.Contains(t, logRecords[0].Attributes, stringAttribute("tyk.api.id", "a"))
+	assert.Contains(t, logRecords[0].Attributes, intAttribute("http.response.status_code", http.StatusOK))
+
+	var record analytics.AnalyticsRecord
+	require.NoError(t, json.Unmarshal([]byte(logRecords[0].Body.StringValue), &record))
+	assert.Equal(t, "/a", record.Path)
+}
+
+func TestOTLP_batches(t *testing.T) {
+	var request otlpExportLogsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+	}))
+	defer server.Close()
+
+	o, err := NewOTLP(HTTPConfig{URL: server.URL, Headers: map[string]string{"Authorization": "secret"}})
+	require.NoError(t, err)
+	defer o.Close()
+
+	require.NoError(t, o.Write(context.Background(), testRecords("a", "b")))
+
+	require.Len(t, request.ResourceLogs, 1)
+	require.Len(t, request.ResourceLogs[0].ScopeLogs, 1)
+	logRecords := request.ResourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, logRecords, 2)
+	assert.Contains(t, logRecords[0].Attributes, stringAttribute("tyk.api.id", "a"))
+	assert.Contains(t, logRecords[1].Attributes, stringAttribute("tyk.api.id", "b"))
+}
+
+func TestOTLP_errors(t *testing.T) {
+	_, err := NewOTLP(HTTPConfig{})
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	o, err := NewOTLP(HTTPConfig{URL: server.URL})
+	require.NoError(t, err)
+	defer o.Close()
+
+	err = o.Write(context.Background(), testRecords("a"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "otlp analytics sink")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, o.Write(ctx, testRecords("a")), context.Canceled)
+}
+
+func TestIntAttribute(t *testing.T) {
+	data, err := json.Marshal(intAttribute("tyk.request_time_ms", 42))
+	require.NoError(t, err)
+	// OTLP/JSON encodes 64 bit integers as strings
+	assert.JSONEq(t, `{"key":"tyk.request_time_ms","value":{"intValue":"42"}}`, string(data))
+}
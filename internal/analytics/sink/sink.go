@@ -0,0 +1,131 @@
+// Package sink provides destinations for the analytics records processed by
+// the gateway analytics workers.
+package sink
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+const (
+	// DefaultBackoffBase is the wait before retrying a failed write the first time.
+	DefaultBackoffBase = 100 * time.Millisecond
+	// DefaultBackoffMax caps the wait between retries of a failed write.
+	DefaultBackoffMax = 5 * time.Second
+	// DefaultMaxAttempts is the number of attempts at a write before it fails.
+	DefaultMaxAttempts = 5
+	// DefaultTimeout is the timeout of a single write to a network sink.
+	DefaultTimeout = 10 * time.Second
+)
+
+// ErrClosed is returned when writing to a closed sink.
+var ErrClosed = errors.New("analytics sink is closed")
+
+// Sink is a destination for analytics records.
+type Sink interface {
+	// Write writes a batch of records. An error means that the batch, or
+	// part of it, hasn't been written and may be written again.
+	Write(ctx context.Context, records []*analytics.AnalyticsRecord) error
+	// Close releases the resources held by the sink.
+	Close() error
+}
+
+// FanOut writes every batch of records to all of its sinks concurrently.
+type FanOut []Sink
+
+// Write writes records to all sinks and returns the joined errors of the
+// sinks that failed.
+func (f FanOut) Write(ctx context.Context, records []*analytics.AnalyticsRecord) error {
+	errs := make([]error, len(f))
+
+	var wg sync.WaitGroup
+	for i, s := range f {
+		wg.Add(1)
+		go func(i int, s Sink) {
+			defer wg.Done()
+			errs[i] = s.Write(ctx, records)
+		}(i, s)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Close closes all sinks.
+func (f FanOut) Close() error {
+	var errs []error
+	for _, s := range f {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// RetryOptions configures how a failed write is retried.
+type RetryOptions struct {
+	// BackoffBase is the wait before the first retry. It doubles with every
+	// retry, up to BackoffMax.
+	BackoffBase time.Duration
+	// BackoffMax caps the wait between retries.
+	BackoffMax time.Duration
+	// MaxAttempts is the number of attempts at a write before it fails.
+	MaxAttempts int
+	// OnError is called with every failed attempt and the wait before the next one.
+	OnError func(err error, attempt int, backoff time.Duration)
+}
+
+type retrySink struct {
+	Sink
+	opts RetryOptions
+}
+
+// WithRetry wraps s so that a failed write is retried until it succeeds, it
+// has been attempted MaxAttempts times or ctx is done. While it's retried,
+// Write blocks, so the attempts are bounded to not stall the caller for
+// longer than an outage of the sink is worth waiting for.
+func WithRetry(s Sink, opts RetryOptions) Sink {
+	if opts.BackoffBase <= 0 {
+		opts.BackoffBase = DefaultBackoffBase
+	}
+	if opts.BackoffMax < opts.BackoffBase {
+		opts.BackoffMax = max(DefaultBackoffMax, opts.BackoffBase)
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return &retrySink{Sink: s, opts: opts}
+}
+
+// Write writes records, retrying with a jittered exponential backoff.
+func (r *retrySink) Write(ctx context.Context, records []*analytics.AnalyticsRecord) error {
+	backoff := r.opts.BackoffBase
+	for attempt := 1; ; attempt++ {
+		err := r.Sink.Write(ctx, records)
+		if err == nil || errors.Is(err, ErrClosed) || attempt >= r.opts.MaxAttempts {
+			return err
+		}
+
+		// wait between half and the full backoff
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		if r.opts.OnError != nil {
+			r.opts.OnError(err, attempt, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+
+		if backoff *= 2; backoff > r.opts.BackoffMax {
+			backoff = r.opts.BackoffMax
+		}
+	}
+}
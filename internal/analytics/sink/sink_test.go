@@ -0,0 +1,120 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/tyk-pump/analytics"
+)
+
+type mockSink struct {
+	mu       sync.Mutex
+	failures int
+	written  []*analytics.AnalyticsRecord
+	closed   bool
+}
+
+func (m *mockSink) Write(_ context.Context, records []*analytics.AnalyticsRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("unavailable")
+	}
+	m.written = append(m.written, records...)
+	return nil
+}
+
+func (m *mockSink) Close() error {
+	m.closed = true
+	return nil
+}
+
+func testRecords(apiIDs ...string) []*analytics.AnalyticsRecord {
+	records := make([]*analytics.AnalyticsRecord, 0, len(apiIDs))
+	for _, apiID := range apiIDs {
+		records = append(records, &analytics.AnalyticsRecord{APIID: apiID, Path: "/" + apiID, TimeStamp: time.Unix(1000, 0)})
+	}
+	return records
+}
+
+func TestFanOut(t *testing.T) {
+	healthy, failing := &mockSink{}, &mockSink{failures: 1}
+	fanOut := FanOut{healthy, failing}
+
+	records := testRecords("a", "b")
+	assert.Error(t, fanOut.Write(context.Background(), records))
+	assert.Equal(t, records, healthy.written)
+	assert.Empty(t, failing.written)
+
+	require.NoError(t, fanOut.Close())
+	assert.True(t, healthy.closed)
+	assert.True(t, failing.closed)
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Run("retries until written", func(t *testing.T) {
+		mock := &mockSink{failures: 3}
+
+		var attempts []int
+		s := WithRetry(mock, RetryOptions{
+			BackoffBase: time.Millisecond,
+			BackoffMax:  2 * time.Millisecond,
+			OnError: func(_ error, attempt int, backoff time.Duration) {
+				attempts = append(attempts, attempt)
+				assert.LessOrEqual(t, backoff, 2*time.Millisecond)
+			},
+		})
+
+		records := testRecords("a")
+		require.NoError(t, s.Write(context.Background(), records))
+		assert.Equal(t, records, mock.written)
+		assert.Equal(t, []int{1, 2, 3}, attempts)
+	})
+
+	t.Run("gives up after the max attempts", func(t *testing.T) {
+		mock := &mockSink{failures: 1000}
+
+		var attempts []int
+		s := WithRetry(mock, RetryOptions{
+			BackoffBase: time.Millisecond,
+			MaxAttempts: 3,
+			OnError: func(_ error, attempt int, _ time.Duration) {
+				attempts = append(attempts, attempt)
+			},
+		})
+
+		assert.Error(t, s.Write(context.Background(), testRecords("a")))
+		assert.Empty(t, mock.written)
+		assert.Equal(t, 997, mock.failures)
+		assert.Equal(t, []int{1, 2}, attempts)
+	})
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		mock := &mockSink{failures: 1000}
+		s := WithRetry(mock, RetryOptions{BackoffBase: time.Millisecond, MaxAttempts: 1000})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := s.Write(ctx, testRecords("a"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, mock.written)
+	})
+
+	t.Run("closed sink is not retried", func(t *testing.T) {
+		f, err := NewFile(FileConfig{Path: t.TempDir() + "/analytics.log"})
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		err = WithRetry(f, RetryOptions{}).Write(context.Background(), testRecords("a"))
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+}
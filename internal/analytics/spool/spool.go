@@ -0,0 +1,461 @@
+// Package spool implements a bounded on-disk write-ahead spool for analytics
+// records that can't be written to the analytics store.
+//
+// The spool is a sequence of segment files. Every entry holds a batch of
+// encoded records together with the key they're stored under, prefixed with
+// its length and a CRC-32C checksum. Batches are replayed in the order they
+// were spooled and segments are removed once fully replayed. Replay is
+// at-least-once: a batch replayed just before the gateway stops may be
+// replayed again after a restart.
+package spool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxSize is the default size limit of a spool.
+	DefaultMaxSize = 1 << 30
+	// DefaultSegmentSize is the default size at which a new segment is started.
+	DefaultSegmentSize = 16 << 20
+
+	segmentExt = ".spool"
+
+	// entryHeaderSize is the size of the length and checksum preceding every entry.
+	entryHeaderSize = 8
+)
+
+var (
+	// ErrFull is returned when a batch doesn't fit in the spool.
+	ErrFull = errors.New("analytics spool is full")
+	// ErrClosed is returned when appending to a closed spool.
+	ErrClosed = errors.New("analytics spool is closed")
+	// ErrCorrupt is reported for entries with an invalid length or checksum.
+	ErrCorrupt = errors.New("corrupt analytics spool entry")
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Config configures a spool.
+type Config struct {
+	// Dir is the directory segment files are stored in.
+	Dir string
+	// MaxSize is the size limit of all segments together, in bytes.
+	MaxSize int64
+	// SegmentSize is the size at which a new segment is started, in bytes.
+	SegmentSize int64
+	// OnCorrupt is called when a corrupt entry is found. The rest of the
+	// segment holding the entry is discarded.
+	OnCorrupt func(segment string, err error)
+}
+
+// Stats describes the content of a spool.
+type Stats struct {
+	// Batches is the number of spooled batches.
+	Batches int
+	// Records is the number of spooled records.
+	Records int
+	// Bytes is the size of the spooled batches, including entry headers.
+	Bytes int64
+}
+
+func (s *Stats) add(batches, records int, bytes int64) {
+	s.Batches += batches
+	s.Records += records
+	s.Bytes += bytes
+}
+
+// Spool is a bounded on-disk write-ahead spool. It is safe for concurrent use.
+type Spool struct {
+	conf Config
+
+	mu       sync.Mutex
+	segments []*segment // oldest first, the last one may be active
+	active   *os.File
+	nextSeq  uint64
+	stats    Stats
+	closed   bool
+
+	replayMu sync.Mutex
+}
+
+type segment struct {
+	path string
+	// offset is where replay continues from.
+	offset int64
+	// size is the size of the segment file.
+	size  int64
+	stats Stats
+}
+
+// Open opens the spool in conf.Dir, creating the directory if needed, and
+// loads the segments left by a previous run. A truncated entry at the end of
+// a segment, left by a crash, is discarded.
+func Open(conf Config) (*Spool, error) {
+	if conf.Dir == "" {
+		return nil, fmt.Errorf("analytics spool: directory is required")
+	}
+	if conf.MaxSize <= 0 {
+		conf.MaxSize = DefaultMaxSize
+	}
+	if conf.SegmentSize <= 0 {
+		conf.SegmentSize = DefaultSegmentSize
+	}
+
+	if err := os.MkdirAll(conf.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("analytics spool: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(conf.Dir, "*"+segmentExt))
+	if err != nil {
+		return nil, fmt.Errorf("analytics spool: %w", err)
+	}
+	// segment names are zero padded sequence numbers
+	sort.Strings(paths)
+
+	s := &Spool{conf: conf}
+	for _, path := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.nextSeq = seq + 1
+
+		seg, err := s.load(path)
+		if err != nil {
+			return nil, err
+		}
+		if seg.stats.Batches == 0 {
+			_ = os.Remove(path)
+			continue
+		}
+
+		s.segments = append(s.segments, seg)
+		s.stats.add(seg.stats.Batches, seg.stats.Records, seg.stats.Bytes)
+	}
+
+	return s, nil
+}
+
+// load scans a segment, truncating it after its last valid entry.
+func (s *Spool) load(path string) (*segment, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("analytics spool: %w", err)
+	}
+	defer file.Close()
+
+	seg := &segment{path: path}
+	r := &entryReader{r: file}
+	for {
+		_, values, n, err := r.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.corrupt(path, err)
+			if err := file.Truncate(seg.size); err != nil {
+				return nil, fmt.Errorf("analytics spool: %w", err)
+			}
+			break
+		}
+
+		seg.size += n
+		seg.stats.add(1, len(values), n)
+	}
+
+	return seg, nil
+}
+
+// Append spools a batch of encoded records stored under key. The batch is
+// synced to disk before Append returns.
+func (s *Spool) Append(key string, values [][]byte) error {
+	entry := encodeEntry(key, values)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	if s.stats.Bytes+int64(len(entry)) > s.conf.MaxSize {
+		return ErrFull
+	}
+
+	if s.active != nil && s.activeSegment().size+int64(len(entry)) > s.conf.SegmentSize {
+		if err := s.seal(); err != nil {
+			return err
+		}
+	}
+
+	if s.active == nil {
+		if err := s.startSegment(); err != nil {
+			return err
+		}
+	}
+
+	seg := s.activeSegment()
+	n, err := s.active.Write(entry)
+	if err == nil {
+		err = s.active.Sync()
+	}
+	if err != nil {
+		// drop the partial entry so that the segment stays readable
+		_ = s.active.Truncate(seg.size)
+		return fmt.Errorf("analytics spool: %w", err)
+	}
+
+	seg.size += int64(n)
+	seg.stats.add(1, len(values), int64(n))
+	s.stats.add(1, len(values), int64(n))
+	return nil
+}
+
+// Replay calls fn with every spooled batch, oldest first, and removes the
+// batches fn accepts. Replay stops at the first error returned by fn, which
+// is returned along with the number of replayed batches. The failed batch is
+// kept to be replayed again. Only one replay runs at a time.
+func (s *Spool) Replay(fn func(key string, values [][]byte) error) (int, error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	// batches spooled from now on go to a new segment, so that the segments
+	// being replayed are never written to
+	s.mu.Lock()
+	if s.active != nil {
+		if err := s.seal(); err != nil {
+			s.mu.Unlock()
+			return 0, err
+		}
+	}
+	segments := append([]*segment(nil), s.segments...)
+	s.mu.Unlock()
+
+	var replayed int
+	for _, seg := range segments {
+		n, err := s.replaySegment(seg, fn)
+		replayed += n
+		if err != nil {
+			return replayed, err
+		}
+	}
+
+	return replayed, nil
+}
+
+func (s *Spool) replaySegment(seg *segment, fn func(key string, values [][]byte) error) (int, error) {
+	file, err := os.Open(seg.path)
+	if err != nil {
+		return 0, fmt.Errorf("analytics spool: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(seg.offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("analytics spool: %w", err)
+	}
+
+	var replayed int
+	r := &entryReader{r: file}
+	for {
+		key, values, n, err := r.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			s.corrupt(seg.path, err)
+			break
+		}
+
+		if err := fn(key, values); err != nil {
+			return replayed, err
+		}
+		replayed++
+
+		s.mu.Lock()
+		seg.offset += n
+		seg.stats.add(-1, -len(values), -n)
+		s.stats.add(-1, -len(values), -n)
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	s.removeSegment(seg)
+	s.mu.Unlock()
+
+	return replayed, nil
+}
+
+// Stats returns the current content of the spool.
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stats
+}
+
+// Close closes the active segment. Spooled batches are kept on disk.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.active == nil {
+		return nil
+	}
+
+	err := s.active.Close()
+	s.active = nil
+	return err
+}
+
+func (s *Spool) activeSegment() *segment {
+	return s.segments[len(s.segments)-1]
+}
+
+func (s *Spool) startSegment() error {
+	path := filepath.Join(s.conf.Dir, fmt.Sprintf("%020d%s", s.nextSeq, segmentExt))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("analytics spool: %w", err)
+	}
+
+	s.nextSeq++
+	s.active = file
+	s.segments = append(s.segments, &segment{path: path})
+	return nil
+}
+
+// seal closes the active segment, so that the next batch starts a new one.
+func (s *Spool) seal() error {
+	err := s.active.Close()
+	s.active = nil
+	if err != nil {
+		return fmt.Errorf("analytics spool: %w", err)
+	}
+	return nil
+}
+
+// removeSegment removes a replayed segment and discards its remaining stats,
+// left by a corrupt entry.
+func (s *Spool) removeSegment(seg *segment) {
+	for i, candidate := range s.segments {
+		if candidate == seg {
+			s.segments = append(s.segments[:i], s.segments[i+1:]...)
+			break
+		}
+	}
+
+	s.stats.add(-seg.stats.Batches, -seg.stats.Records, -seg.stats.Bytes)
+	_ = os.Remove(seg.path)
+}
+
+func (s *Spool) corrupt(path string, err error) {
+	if s.conf.OnCorrupt != nil {
+		s.conf.OnCorrupt(path, err)
+	}
+}
+
+// encodeEntry encodes a batch as an entry. The payload holds the key and the
+// values, each prefixed with its length as an uvarint.
+func encodeEntry(key string, values [][]byte) []byte {
+	size := entryHeaderSize + 2*binary.MaxVarintLen64 + len(key)
+	for _, value := range values {
+		size += binary.MaxVarintLen64 + len(value)
+	}
+
+	entry := make([]byte, entryHeaderSize, size)
+	entry = binary.AppendUvarint(entry, uint64(len(key)))
+	entry = append(entry, key...)
+	entry = binary.AppendUvarint(entry, uint64(len(values)))
+	for _, value := range values {
+		entry = binary.AppendUvarint(entry, uint64(len(value)))
+		entry = append(entry, value...)
+	}
+
+	payload := entry[entryHeaderSize:]
+	binary.LittleEndian.PutUint32(entry[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(entry[4:8], crc32.Checksum(payload, crcTable))
+	return entry
+}
+
+type entryReader struct {
+	r      io.Reader
+	header [entryHeaderSize]byte
+}
+
+// next reads the next entry and returns its content and size. It returns
+// io.EOF at the end of the segment.
+func (e *entryReader) next() (key string, values [][]byte, n int64, err error) {
+	if _, err = io.ReadFull(e.r, e.header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = fmt.Errorf("%w: truncated header", ErrCorrupt)
+		}
+		return
+	}
+
+	length := binary.LittleEndian.Uint32(e.header[0:4])
+	checksum := binary.LittleEndian.Uint32(e.header[4:8])
+	if length > DefaultMaxSize {
+		err = fmt.Errorf("%w: invalid length %d", ErrCorrupt, length)
+		return
+	}
+
+	payload := make([]byte, length)
+	if _, err = io.ReadFull(e.r, payload); err != nil {
+		err = fmt.Errorf("%w: truncated payload", ErrCorrupt)
+		return
+	}
+
+	if crc32.Checksum(payload, crcTable) != checksum {
+		err = fmt.Errorf("%w: checksum mismatch", ErrCorrupt)
+		return
+	}
+
+	key, values, err = decodePayload(payload)
+	return key, values, int64(entryHeaderSize) + int64(length), err
+}
+
+func decodePayload(payload []byte) (string, [][]byte, error) {
+	field := func() ([]byte, bool) {
+		length, n := binary.Uvarint(payload)
+		if n <= 0 || uint64(len(payload)-n) < length {
+			return nil, false
+		}
+		value := payload[n : n+int(length)]
+		payload = payload[n+int(length):]
+		return value, true
+	}
+
+	key, ok := field()
+	if !ok {
+		return "", nil, fmt.Errorf("%w: invalid key", ErrCorrupt)
+	}
+
+	count, n := binary.Uvarint(payload)
+	if n <= 0 || count > uint64(len(payload)) {
+		return "", nil, fmt.Errorf("%w: invalid value count", ErrCorrupt)
+	}
+	payload = payload[n:]
+
+	values := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		value, ok := field()
+		if !ok {
+			return "", nil, fmt.Errorf("%w: invalid value", ErrCorrupt)
+		}
+		values = append(values, value)
+	}
+
+	return string(key), values, nil
+}
@@ -0,0 +1,185 @@
+package spool
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batch struct {
+	key    string
+	values []string
+}
+
+func collect(t *testing.T, s *Spool) []batch {
+	t.Helper()
+
+	var batches []batch
+	_, err := s.Replay(func(key string, values [][]byte) error {
+		b := batch{key: key}
+		for _, value := range values {
+			b.values = append(b.values, string(value))
+		}
+		batches = append(batches, b)
+		return nil
+	})
+	require.NoError(t, err)
+	return batches
+}
+
+func appendBatch(t *testing.T, s *Spool, key string, values ...string) {
+	t.Helper()
+
+	encoded := make([][]byte, 0, len(values))
+	for _, value := range values {
+		encoded = append(encoded, []byte(value))
+	}
+	require.NoError(t, s.Append(key, encoded))
+}
+
+func TestSpool(t *testing.T) {
+	s, err := Open(Config{Dir: t.TempDir(), SegmentSize: 64})
+	require.NoError(t, err)
+	defer s.Close()
+
+	appendBatch(t, s, "analytics", "a", "b")
+	appendBatch(t, s, "analytics_1", "c")
+	appendBatch(t, s, "analytics", "d", "e", "f")
+
+	stats := s.Stats()
+	assert.Equal(t, 3, stats.Batches)
+	assert.Equal(t, 6, stats.Records)
+	assert.Positive(t, stats.Bytes)
+
+	assert.Equal(t, []batch{
+		{key: "analytics", values: []string{"a", "b"}},
+		{key: "analytics_1", values: []string{"c"}},
+		{key: "analytics", values: []string{"d", "e", "f"}},
+	}, collect(t, s))
+
+	assert.Equal(t, Stats{}, s.Stats())
+	assert.Empty(t, collect(t, s))
+
+	segments, err := filepath.Glob(filepath.Join(s.conf.Dir, "*"+segmentExt))
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+}
+
+func TestSpool_ReplayError(t *testing.T) {
+	s, err := Open(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	defer s.Close()
+
+	appendBatch(t, s, "k", "a")
+	appendBatch(t, s, "k", "b")
+
+	unavailable := errors.New("unavailable")
+	replayed, err := s.Replay(func(_ string, values [][]byte) error {
+		if string(values[0]) == "b" {
+			return unavailable
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, 1, replayed)
+	assert.Equal(t, 1, s.Stats().Batches)
+
+	// batches spooled meanwhile are replayed after the failed one
+	appendBatch(t, s, "k", "c")
+
+	assert.Equal(t, []batch{
+		{key: "k", values: []string{"b"}},
+		{key: "k", values: []string{"c"}},
+	}, collect(t, s))
+}
+
+func TestSpool_Full(t *testing.T) {
+	s, err := Open(Config{Dir: t.TempDir(), MaxSize: 32})
+	require.NoError(t, err)
+	defer s.Close()
+
+	appendBatch(t, s, "k", "a")
+	assert.ErrorIs(t, s.Append("k", [][]byte{[]byte("a long value that doesn't fit")}), ErrFull)
+	assert.Equal(t, 1, s.Stats().Batches)
+}
+
+func TestSpool_Reopen(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Open(Config{Dir: dir, SegmentSize: 32})
+	require.NoError(t, err)
+	appendBatch(t, s, "k", "a")
+	appendBatch(t, s, "k", "b")
+	appendBatch(t, s, "k", "c")
+	require.NoError(t, s.Close())
+	assert.ErrorIs(t, s.Append("k", nil), ErrClosed)
+
+	s, err = Open(Config{Dir: dir, SegmentSize: 32})
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, 3, s.Stats().Records)
+
+	appendBatch(t, s, "k", "d")
+	assert.Equal(t, []batch{
+		{key: "k", values: []string{"a"}},
+		{key: "k", values: []string{"b"}},
+		{key: "k", values: []string{"c"}},
+		{key: "k", values: []string{"d"}},
+	}, collect(t, s))
+}
+
+func TestSpool_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Open(Config{Dir: dir})
+	require.NoError(t, err)
+	appendBatch(t, s, "k", "a")
+	appendBatch(t, s, "k", "b")
+	require.NoError(t, s.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+
+	t.Run("truncated tail", func(t *testing.T) {
+		file, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0o600)
+		require.NoError(t, err)
+		_, err = file.Write([]byte{1, 2, 3})
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		var corrupt []error
+		s, err := Open(Config{Dir: dir, OnCorrupt: func(_ string, err error) {
+			corrupt = append(corrupt, err)
+		}})
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+
+		assert.Len(t, corrupt, 1)
+		assert.ErrorIs(t, corrupt[0], ErrCorrupt)
+		assert.Equal(t, 2, s.Stats().Batches)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		data, err := os.ReadFile(segments[0])
+		require.NoError(t, err)
+		// flip the last value byte of the second entry
+		data[len(data)-1] ^= 0xff
+		require.NoError(t, os.WriteFile(segments[0], data, 0o600))
+
+		var corrupt []error
+		s, err := Open(Config{Dir: dir, OnCorrupt: func(_ string, err error) {
+			corrupt = append(corrupt, err)
+		}})
+		require.NoError(t, err)
+		defer s.Close()
+
+		assert.Len(t, corrupt, 1)
+		assert.Equal(t, []batch{{key: "k", values: []string{"a"}}}, collect(t, s))
+	})
+}
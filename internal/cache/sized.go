@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SizedLRU is a least recently used cache bounded by the total size of its
+// items rather than their count. Items also expire after their TTL.
+type SizedLRU struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type sizedEntry struct {
+	key        string
+	value      any
+	size       int64
+	expiration int64
+}
+
+// NewSizedLRU creates a *SizedLRU holding up to maxBytes of items.
+func NewSizedLRU(maxBytes int64) *SizedLRU {
+	return &SizedLRU{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Set adds an item of the given size to the cache, replacing any existing
+// item and evicting the least recently used items to make room. Items larger
+// than the cache aren't added. A ttl <= 0 means the item never expires.
+// It returns the number of evicted items.
+func (c *SizedLRU) Set(key string, value any, size int64, ttl time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+
+	if size > c.maxBytes {
+		return 0
+	}
+
+	var expiration int64
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl).UnixNano()
+	}
+
+	var evicted int
+	for c.size+size > c.maxBytes {
+		c.remove(c.order.Back())
+		evicted++
+	}
+
+	c.items[key] = c.order.PushFront(&sizedEntry{key: key, value: value, size: size, expiration: expiration})
+	c.size += size
+
+	return evicted
+}
+
+// Get returns an unexpired item from the cache, marking it as recently used.
+func (c *SizedLRU) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*sizedEntry)
+	if entry.expiration > 0 && time.Now().UnixNano() > entry.expiration {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// Delete an item from the cache. Does nothing if the key is not in the cache.
+func (c *SizedLRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// DeletePrefix deletes all items with keys starting with prefix.
+func (c *SizedLRU) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(el)
+		}
+	}
+}
+
+// Size returns the total size of the items in the cache, including expired items.
+func (c *SizedLRU) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// Count returns the number of items in the cache, including expired items.
+func (c *SizedLRU) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.items)
+}
+
+// Flush deletes all items from the cache.
+func (c *SizedLRU) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+	c.size = 0
+}
+
+func (c *SizedLRU) remove(el *list.Element) {
+	entry := c.order.Remove(el).(*sizedEntry)
+	delete(c.items, entry.key)
+	c.size -= entry.size
+}
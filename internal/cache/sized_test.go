@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizedLRU(t *testing.T) {
+	t.Run("evicts least recently used", func(t *testing.T) {
+		cache := NewSizedLRU(10)
+
+		assert.Equal(t, 0, cache.Set("a", 1, 4, 0))
+		assert.Equal(t, 0, cache.Set("b", 2, 4, 0))
+
+		_, ok := cache.Get("a")
+		assert.True(t, ok)
+
+		assert.Equal(t, 1, cache.Set("c", 3, 4, 0))
+
+		_, ok = cache.Get("b")
+		assert.False(t, ok)
+		assert.Equal(t, int64(8), cache.Size())
+		assert.Equal(t, 2, cache.Count())
+	})
+
+	t.Run("replaces items", func(t *testing.T) {
+		cache := NewSizedLRU(10)
+
+		cache.Set("a", 1, 4, 0)
+		cache.Set("a", 2, 6, 0)
+
+		value, ok := cache.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 2, value)
+		assert.Equal(t, int64(6), cache.Size())
+	})
+
+	t.Run("skips oversized items", func(t *testing.T) {
+		cache := NewSizedLRU(10)
+
+		cache.Set("a", 1, 11, 0)
+
+		_, ok := cache.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, int64(0), cache.Size())
+	})
+
+	t.Run("expires items", func(t *testing.T) {
+		cache := NewSizedLRU(10)
+
+		cache.Set("a", 1, 4, time.Nanosecond)
+		time.Sleep(time.Millisecond)
+
+		_, ok := cache.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Count())
+	})
+
+	t.Run("deletes by prefix", func(t *testing.T) {
+		cache := NewSizedLRU(10)
+
+		cache.Set("api1-a", 1, 1, 0)
+		cache.Set("api1-b", 1, 1, 0)
+		cache.Set("api2-a", 1, 1, 0)
+
+		cache.DeletePrefix("api1")
+		assert.Equal(t, 1, cache.Count())
+
+		cache.Flush()
+		assert.Equal(t, 0, cache.Count())
+		assert.Equal(t, int64(0), cache.Size())
+	})
+}
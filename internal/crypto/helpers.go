@@ -44,6 +44,14 @@ func HexSHA256(cert []byte) string {
 	return hex.EncodeToString(certSHA[:])
 }
 
+// Base64URLSHA256 calculates the SHA256 hash of the provided certificate bytes
+// and returns the result as an unpadded base64url string, the format of the
+// `x5t#S256` confirmation of certificate-bound access tokens (RFC 8705).
+func Base64URLSHA256(cert []byte) string {
+	certSHA := sha256.Sum256(cert)
+	return base64.RawURLEncoding.EncodeToString(certSHA[:])
+}
+
 // GenCertificate generates a self-signed X.509 certificate based on the provided template.
 // It returns the certificate, private key, combined PEM bytes, and a tls.Certificate.
 //
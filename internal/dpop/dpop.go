@@ -0,0 +1,268 @@
+// Package dpop validates DPoP proofs (RFC 9449), which demonstrate that the
+// client presenting an access token holds the private key the token is bound
+// to, so that stolen tokens can't be replayed by other clients.
+package dpop
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+)
+
+const (
+	// HeaderName is the name of the header carrying DPoP proofs.
+	HeaderName = "DPoP"
+	// Scheme is the authorization scheme of DPoP-bound access tokens.
+	Scheme = "DPoP"
+
+	// DefaultMaxAge is how old proofs can be when no maximum age is configured.
+	DefaultMaxAge = time.Minute
+	// DefaultClockSkew is how far in the future proofs can be issued when no
+	// clock skew is configured.
+	DefaultClockSkew = 5 * time.Second
+
+	// proofType is the typ header of DPoP proofs.
+	proofType = "dpop+jwt"
+	// replayKeyPrefix is the prefix of the replay cache keys.
+	replayKeyPrefix = "dpop-jti-"
+)
+
+var (
+	// ErrInvalidProof is returned for malformed or invalid proofs.
+	ErrInvalidProof = errors.New("invalid DPoP proof")
+	// ErrReplayedProof is returned for proofs which were already used.
+	ErrReplayedProof = errors.New("DPoP proof has already been used")
+	// ErrKeyMismatch is returned when the proof isn't signed by the key the
+	// access token is bound to.
+	ErrKeyMismatch = errors.New("DPoP proof key doesn't match the access token binding")
+)
+
+// signingAlgorithms are the accepted proof signing algorithms. Symmetric
+// algorithms can't prove the possession of a private key.
+var signingAlgorithms = map[string]bool{
+	string(jose.RS256): true, string(jose.RS384): true, string(jose.RS512): true,
+	string(jose.PS256): true, string(jose.PS384): true, string(jose.PS512): true,
+	string(jose.ES256): true, string(jose.ES384): true, string(jose.ES512): true,
+	string(jose.EdDSA): true,
+}
+
+// ReplayStore remembers the proofs which were used. Lock sets key if it
+// doesn't exist yet, for ttl, and reports whether it was set.
+type ReplayStore interface {
+	Lock(key string, ttl time.Duration) (bool, error)
+}
+
+// Options configures a Validator.
+type Options struct {
+	// MaxAge is how old proofs can be.
+	MaxAge time.Duration
+	// ClockSkew is how far in the future proofs can be issued.
+	ClockSkew time.Duration
+	// Replay rejects reused proofs, they aren't checked when nil.
+	Replay ReplayStore
+}
+
+// Request describes the request a proof is validated for.
+type Request struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the absolute URL of the request.
+	URL *url.URL
+	// AccessToken is the access token of the request, the ath claim of the
+	// proof must be its hash.
+	AccessToken string
+	// Thumbprint is the JWK SHA-256 thumbprint the access token is bound to,
+	// from its cnf.jkt claim. The proof must be signed by this key.
+	Thumbprint string
+}
+
+// Proof is a validated DPoP proof.
+type Proof struct {
+	// ID is the jti claim of the proof.
+	ID string
+	// Thumbprint is the JWK SHA-256 thumbprint of the proof key.
+	Thumbprint string
+	// IssuedAt is the iat claim of the proof.
+	IssuedAt time.Time
+}
+
+// claims are the claims of a DPoP proof.
+type claims struct {
+	ID          string `json:"jti"`
+	Method      string `json:"htm"`
+	URI         string `json:"htu"`
+	IssuedAt    int64  `json:"iat"`
+	AccessToken string `json:"ath"`
+}
+
+// Validator validates DPoP proofs.
+type Validator struct {
+	opts Options
+	now  func() time.Time
+}
+
+// NewValidator creates a DPoP proof validator.
+func NewValidator(opts Options) *Validator {
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultMaxAge
+	}
+	if opts.ClockSkew <= 0 {
+		opts.ClockSkew = DefaultClockSkew
+	}
+
+	return &Validator{opts: opts, now: time.Now}
+}
+
+// Validate validates the DPoP proof sent with req.
+func (v *Validator) Validate(proof string, req Request) (*Proof, error) {
+	jws, err := jose.ParseSigned(proof)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if len(jws.Signatures) != 1 {
+		return nil, fmt.Errorf("%w: proof must have a single signature", ErrInvalidProof)
+	}
+
+	header := jws.Signatures[0].Protected
+	if typ, _ := header.ExtraHeaders[jose.HeaderType].(string); typ != proofType {
+		return nil, fmt.Errorf("%w: typ must be %s", ErrInvalidProof, proofType)
+	}
+	if !signingAlgorithms[header.Algorithm] {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidProof, header.Algorithm)
+	}
+
+	key := header.JSONWebKey
+	if key == nil || !key.Valid() || !key.IsPublic() {
+		return nil, fmt.Errorf("%w: jwk must be a public key", ErrInvalidProof)
+	}
+
+	payload, err := jws.Verify(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	thumbprint, err := Thumbprint(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	if err := v.validateClaims(c, req); err != nil {
+		return nil, err
+	}
+
+	if req.Thumbprint != "" && req.Thumbprint != thumbprint {
+		return nil, ErrKeyMismatch
+	}
+
+	if v.opts.Replay != nil {
+		// proofs older than the maximum age are rejected, they don't need to
+		// be remembered longer
+		fresh, err := v.opts.Replay.Lock(replayKeyPrefix+thumbprint+"-"+c.ID, v.opts.MaxAge+v.opts.ClockSkew)
+		if err != nil {
+			return nil, err
+		}
+		if !fresh {
+			return nil, ErrReplayedProof
+		}
+	}
+
+	return &Proof{ID: c.ID, Thumbprint: thumbprint, IssuedAt: time.Unix(c.IssuedAt, 0)}, nil
+}
+
+// validateClaims checks that the proof claims are complete and match req.
+func (v *Validator) validateClaims(c claims, req Request) error {
+	if c.ID == "" {
+		return fmt.Errorf("%w: missing jti", ErrInvalidProof)
+	}
+
+	if c.Method != req.Method {
+		return fmt.Errorf("%w: htm doesn't match the request method", ErrInvalidProof)
+	}
+
+	if !matchURI(c.URI, req.URL) {
+		return fmt.Errorf("%w: htu doesn't match the request URL", ErrInvalidProof)
+	}
+
+	now := v.now()
+	issuedAt := time.Unix(c.IssuedAt, 0)
+	if c.IssuedAt == 0 || issuedAt.After(now.Add(v.opts.ClockSkew)) || issuedAt.Before(now.Add(-v.opts.MaxAge)) {
+		return fmt.Errorf("%w: iat is outside of the acceptable window", ErrInvalidProof)
+	}
+
+	if req.AccessToken != "" && c.AccessToken != AccessTokenHash(req.AccessToken) {
+		return fmt.Errorf("%w: ath doesn't match the access token", ErrInvalidProof)
+	}
+
+	return nil
+}
+
+// Thumbprint returns the base64url encoded JWK SHA-256 thumbprint (RFC 7638)
+// of key, as used in cnf.jkt claims.
+func Thumbprint(key *jose.JSONWebKey) (string, error) {
+	sum, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(sum), nil
+}
+
+// AccessTokenHash returns the ath claim value of accessToken.
+func AccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// StripScheme removes the DPoP authorization scheme from an authorization
+// header value.
+func StripScheme(value string) string {
+	if len(value) > len(Scheme) && strings.EqualFold(value[:len(Scheme)+1], Scheme+" ") {
+		return value[len(Scheme)+1:]
+	}
+	return value
+}
+
+// matchURI reports whether htu is the URI of target, ignoring its query and
+// fragment, case-insensitively for the scheme and host, and default ports.
+func matchURI(htu string, target *url.URL) bool {
+	u, err := url.Parse(htu)
+	if err != nil || target == nil {
+		return false
+	}
+
+	path := func(u *url.URL) string {
+		if u.Path == "" {
+			return "/"
+		}
+		return u.Path
+	}
+
+	return strings.EqualFold(u.Scheme, target.Scheme) &&
+		strings.EqualFold(hostWithoutDefaultPort(u), hostWithoutDefaultPort(target)) &&
+		path(u) == path(target)
+}
+
+// hostWithoutDefaultPort returns the host of u without its scheme's default port.
+func hostWithoutDefaultPort(u *url.URL) string {
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return u.Host
+	}
+	if (port == "443" && strings.EqualFold(u.Scheme, "https")) || (port == "80" && strings.EqualFold(u.Scheme, "http")) {
+		return host
+	}
+	return u.Host
+}
@@ -0,0 +1,141 @@
+package dpop
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func (s *memoryStore) Lock(key string, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key]; ok {
+		return false, nil
+	}
+	s.keys[key] = struct{}{}
+	return true, nil
+}
+
+func newProof(t *testing.T, key *ecdsa.PrivateKey, typ string, claims map[string]interface{}) string {
+	t.Helper()
+
+	opts := (&jose.SignerOptions{EmbedJWK: true}).WithType(jose.ContentType(typ))
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, opts)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	proof, err := jws.CompactSerialize()
+	require.NoError(t, err)
+
+	return proof
+}
+
+func TestValidator_Validate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	thumbprint, err := Thumbprint(&jose.JSONWebKey{Key: key.Public()})
+	require.NoError(t, err)
+
+	target, err := url.Parse("https://api.example.com/orders?page=2")
+	require.NoError(t, err)
+
+	const accessToken = "access-token"
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"jti": "proof-id",
+			"htm": "POST",
+			"htu": "https://API.example.com:443/orders",
+			"iat": time.Now().Unix(),
+			"ath": AccessTokenHash(accessToken),
+		}
+	}
+	request := Request{Method: "POST", URL: target, AccessToken: accessToken, Thumbprint: thumbprint}
+
+	t.Run("valid proof", func(t *testing.T) {
+		v := NewValidator(Options{})
+		proof, err := v.Validate(newProof(t, key, proofType, validClaims()), request)
+		require.NoError(t, err)
+		assert.Equal(t, "proof-id", proof.ID)
+		assert.Equal(t, thumbprint, proof.Thumbprint)
+	})
+
+	t.Run("invalid proofs", func(t *testing.T) {
+		v := NewValidator(Options{})
+
+		with := func(name string, value interface{}) map[string]interface{} {
+			claims := validClaims()
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+			return claims
+		}
+
+		cases := []struct {
+			name  string
+			proof string
+			err   error
+		}{
+			{"malformed", "not-a-jws", ErrInvalidProof},
+			{"wrong type", newProof(t, key, "JWT", validClaims()), ErrInvalidProof},
+			{"missing jti", newProof(t, key, proofType, with("jti", nil)), ErrInvalidProof},
+			{"wrong method", newProof(t, key, proofType, with("htm", "GET")), ErrInvalidProof},
+			{"wrong uri", newProof(t, key, proofType, with("htu", "https://api.example.com/users")), ErrInvalidProof},
+			{"wrong scheme", newProof(t, key, proofType, with("htu", "http://api.example.com/orders")), ErrInvalidProof},
+			{"expired", newProof(t, key, proofType, with("iat", time.Now().Add(-time.Hour).Unix())), ErrInvalidProof},
+			{"issued in the future", newProof(t, key, proofType, with("iat", time.Now().Add(time.Hour).Unix())), ErrInvalidProof},
+			{"wrong token hash", newProof(t, key, proofType, with("ath", AccessTokenHash("other"))), ErrInvalidProof},
+			{"wrong key", newProof(t, otherKey, proofType, validClaims()), ErrKeyMismatch},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := v.Validate(tc.proof, request)
+				assert.ErrorIs(t, err, tc.err)
+			})
+		}
+	})
+
+	t.Run("replayed proof", func(t *testing.T) {
+		v := NewValidator(Options{Replay: &memoryStore{keys: map[string]struct{}{}}})
+		proof := newProof(t, key, proofType, validClaims())
+
+		_, err := v.Validate(proof, request)
+		require.NoError(t, err)
+
+		_, err = v.Validate(proof, request)
+		assert.ErrorIs(t, err, ErrReplayedProof)
+	})
+}
+
+func TestStripScheme(t *testing.T) {
+	assert.Equal(t, "token", StripScheme("DPoP token"))
+	assert.Equal(t, "token", StripScheme("dpop token"))
+	assert.Equal(t, "token", StripScheme("token"))
+	assert.Equal(t, "DPoP", StripScheme("DPoP"))
+}
@@ -36,6 +36,9 @@ const (
 	// Circuit breaker
 	CBO ResponseFlag = "CBO" // Circuit breaker open
 
+	// Load shedding
+	ACL ResponseFlag = "ACL" // Adaptive concurrency limit reached
+
 	// Client errors
 	CDC ResponseFlag = "CDC" // Client disconnected
 
@@ -47,6 +50,7 @@ const (
 
 	// 4XX Gateway Error Flags (client/auth errors)
 	RLT ResponseFlag = "RLT" // Rate limited (429)
+	CCL ResponseFlag = "CCL" // Concurrency limited (429)
 	QEX ResponseFlag = "QEX" // Quota exceeded (403)
 	AMF ResponseFlag = "AMF" // Auth field missing (400/401)
 	AKI ResponseFlag = "AKI" // API key invalid (403)
@@ -112,6 +116,7 @@ const (
 	ErrTypeSessionRateLimit = "session_rate_limit"
 	ErrTypeAPIRateLimit     = "api_rate_limit"
 	ErrTypeOtherRateLimit   = "generic_rate_limit_error"
+	ErrTypeConcurrencyLimit = "concurrency_limit"
 
 	// JSON validation error types
 	ErrTypeJSONParseError         = "json_parse_error"
@@ -140,6 +145,7 @@ const (
 	detailAPIRateLimited     = "api_rate_limited"
 	detailQuotaExceeded      = "quota_exceeded"
 	detailGenericRateLimit   = "generic_rate_limit_error"
+	detailConcurrencyLimited = "concurrency_limited"
 
 	// JWT details
 	detailJWTFieldMissing            = "jwt_field_missing"
@@ -33,6 +33,7 @@ func TestResponseFlagConstants(t *testing.T) {
 		{NRH, "NRH", "No route to host"},
 		{NHU, "NHU", "No healthy upstreams"},
 		{CBO, "CBO", "Circuit breaker open"},
+		{ACL, "ACL", "Adaptive concurrency limit reached"},
 		{CDC, "CDC", "Client disconnected"},
 		{URS, "URS", "Upstream response status"},
 		{UPE, "UPE", "Upstream protocol error"},
@@ -47,8 +48,8 @@ func TestResponseFlagConstants(t *testing.T) {
 		})
 	}
 
-	// Ensure we have exactly 22 flags
-	assert.Len(t, flags, 22, "should have exactly 22 response flags")
+	// Ensure we have exactly 23 flags
+	assert.Len(t, flags, 23, "should have exactly 23 response flags")
 }
 
 func TestNewErrorClassification(t *testing.T) {
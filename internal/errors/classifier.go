@@ -306,6 +306,14 @@ func ClassifyCircuitBreakerError(target, state string) *ErrorClassification {
 		WithCircuitBreakerState(state)
 }
 
+// ClassifyAdaptiveConcurrencyError creates an error classification when a request is shed
+// because the adaptive concurrency limit of the upstream is reached.
+func ClassifyAdaptiveConcurrencyError(target string) *ErrorClassification {
+	return NewErrorClassification(ACL, "adaptive_concurrency_limited").
+		WithSource(sourceReverseProxy).
+		WithTarget(target)
+}
+
 // ClassifyNoHealthyUpstreamsError creates an error classification when no healthy upstreams are available.
 func ClassifyNoHealthyUpstreamsError(target string) *ErrorClassification {
 	return NewErrorClassification(NHU, "no_healthy_upstreams").
@@ -367,6 +375,8 @@ func ClassifyRateLimitError(errorType string, source string) *ErrorClassificatio
 		return NewErrorClassification(RLT, detailAPIRateLimited).WithSource(source)
 	case ErrTypeOtherRateLimit:
 		return NewErrorClassification(RLT, detailGenericRateLimit).WithSource(source)
+	case ErrTypeConcurrencyLimit:
+		return NewErrorClassification(CCL, detailConcurrencyLimited).WithSource(source)
 	default:
 		return nil
 	}
@@ -343,6 +343,15 @@ func TestClassifyCircuitBreakerError(t *testing.T) {
 	})
 }
 
+func TestClassifyAdaptiveConcurrencyError(t *testing.T) {
+	result := ClassifyAdaptiveConcurrencyError("api.backend.com:443")
+	require.NotNil(t, result)
+	assert.Equal(t, ACL, result.Flag)
+	assert.Equal(t, "adaptive_concurrency_limited", result.Details)
+	assert.Equal(t, "ReverseProxy", result.Source)
+	assert.Equal(t, "api.backend.com:443", result.Target)
+}
+
 func TestClassifyNoHealthyUpstreamsError(t *testing.T) {
 	result := ClassifyNoHealthyUpstreamsError("api.backend.com:443")
 	require.NotNil(t, result)
@@ -694,6 +703,7 @@ func TestResponseFlag4XXConstants(t *testing.T) {
 		expected string
 	}{
 		{RLT, "RLT"},
+		{CCL, "CCL"},
 		{QEX, "QEX"},
 		{AMF, "AMF"},
 		{AKI, "AKI"},
@@ -851,6 +861,13 @@ func TestClassifyRateLimitError(t *testing.T) {
 			expectedFlag: RLT,
 			expectedDet:  "generic_rate_limit_error",
 		},
+		{
+			name:         "concurrency_limit",
+			errorType:    ErrTypeConcurrencyLimit,
+			source:       "ConcurrencyLimit",
+			expectedFlag: CCL,
+			expectedDet:  "concurrency_limited",
+		},
 	}
 
 	for _, tc := range testCases {
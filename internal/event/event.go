@@ -60,6 +60,10 @@ const (
 	// oauth2_exchange_outcome meta field distinguishes an IdP error from a
 	// no-matching-provider rejection.
 	OAuth2ExchangeFailed Event = "OAuth2ExchangeFailed"
+
+	// TrafficMirrorMismatch fires when the response of a shadow upstream
+	// differs from the response of the primary upstream.
+	TrafficMirrorMismatch Event = "TrafficMirrorMismatch"
 )
 
 // Rate limiter events
@@ -116,6 +116,28 @@ func (f *ExternalHTTPClientFactory) CreateHealthCheckClient() (*http.Client, err
 	return f.CreateClient(config.ServiceTypeHealth)
 }
 
+// CreateMirrorClient creates an HTTP client for requests mirrored to shadow upstreams.
+func (f *ExternalHTTPClientFactory) CreateMirrorClient() (*http.Client, error) {
+	return f.CreateClient(config.ServiceTypeMirror)
+}
+
+// CreateAuthorizationClient creates an HTTP client for requests to external policy decision points.
+func (f *ExternalHTTPClientFactory) CreateAuthorizationClient() (*http.Client, error) {
+	return f.CreateClient(config.ServiceTypeAuthorization)
+}
+
+// CreateACMEClient creates an HTTP client for requests to the ACME certificate authority.
+func (f *ExternalHTTPClientFactory) CreateACMEClient() (*http.Client, error) {
+	return f.CreateClient(config.ServiceTypeACME)
+}
+
+// CreateTLSConfig creates the TLS configuration of the specified service type
+// for clients that don't use HTTP, such as gRPC. Unlike CreateClient it doesn't
+// require external services to be configured for the service type.
+func (f *ExternalHTTPClientFactory) CreateTLSConfig(serviceType string) (*tls.Config, error) {
+	return f.getTLSConfig(f.getServiceConfig(serviceType))
+}
+
 // GetJWKWithClient fetches JWK using the provided HTTP client for proxy and mTLS support
 func GetJWKWithClient(jwlUrl string, client *http.Client, parseJWK func([]byte) (*jose.JSONWebKeySet, error)) (*jose.JSONWebKeySet, error) {
 	resp, err := client.Get(jwlUrl)
@@ -156,6 +178,12 @@ func (f *ExternalHTTPClientFactory) getServiceConfig(serviceType string) config.
 		serviceConfig = f.config.Health
 	case config.ServiceTypeDiscovery:
 		serviceConfig = f.config.Discovery
+	case config.ServiceTypeMirror:
+		serviceConfig = f.config.Mirror
+	case config.ServiceTypeAuthorization:
+		serviceConfig = f.config.Authorization
+	case config.ServiceTypeACME:
+		serviceConfig = f.config.ACME
 	default:
 		// Use empty service config, will fall back to global settings
 		serviceConfig = config.ServiceConfig{}
@@ -195,6 +223,12 @@ func (f *ExternalHTTPClientFactory) isServiceConfigured(serviceType string) bool
 		serviceConfig = f.config.Health
 	case config.ServiceTypeDiscovery:
 		serviceConfig = f.config.Discovery
+	case config.ServiceTypeMirror:
+		serviceConfig = f.config.Mirror
+	case config.ServiceTypeAuthorization:
+		serviceConfig = f.config.Authorization
+	case config.ServiceTypeACME:
+		serviceConfig = f.config.ACME
 	default:
 		// Unknown service type - no service-specific config available
 		return false
@@ -398,6 +432,15 @@ func (f *ExternalHTTPClientFactory) getServiceTimeout(serviceType string) time.D
 	case config.ServiceTypeDiscovery:
 		// Service discovery needs quick responses for load balancing
 		return 10 * time.Second
+	case config.ServiceTypeMirror:
+		// Mirrored requests are bounded by the API traffic mirror timeout
+		return 30 * time.Second
+	case config.ServiceTypeAuthorization:
+		// Policy decisions are on the request path and need quick responses
+		return 5 * time.Second
+	case config.ServiceTypeACME:
+		// Orders and certificate downloads run in the background
+		return 30 * time.Second
 	case config.ServiceTypeStorage:
 		// Storage operations might need more time
 		return 20 * time.Second
@@ -446,6 +489,24 @@ func (f *ExternalHTTPClientFactory) getServiceTransport(serviceType string) *htt
 			TLSHandshakeTimeout:   5 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
 		}
+	case config.ServiceTypeMirror:
+		// Mirrored traffic follows the API traffic to a few shadow upstreams
+		return &http.Transport{
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   20,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	case config.ServiceTypeAuthorization:
+		// Policy decision points see a request for each API request
+		return &http.Transport{
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   20,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
 	case config.ServiceTypeStorage:
 		// Storage may need longer-lived connections
 		return &http.Transport{
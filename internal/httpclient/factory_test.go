@@ -177,6 +177,59 @@ func TestExternalHTTPClientFactory_CreateJWKClient(t *testing.T) {
 	})
 }
 
+func TestExternalHTTPClientFactory_CreateACMEClient(t *testing.T) {
+	factory := NewExternalHTTPClientFactory(&config.ExternalServiceConfig{
+		ACME: config.ServiceConfig{
+			Proxy: config.ProxyConfig{
+				Enabled:    true,
+				HTTPSProxy: "http://proxy:3128",
+			},
+		},
+	}, nil)
+
+	client, err := factory.CreateACMEClient()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, client.Timeout)
+
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	proxyURL, err := transport.Proxy(httptest.NewRequest(http.MethodGet, "https://acme-v02.api.letsencrypt.org/directory", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy:3128", proxyURL.String())
+}
+
+func TestExternalHTTPClientFactory_CreateTLSConfig(t *testing.T) {
+	t.Run("defaults when not configured", func(t *testing.T) {
+		factory := NewExternalHTTPClientFactory(&config.ExternalServiceConfig{}, nil)
+
+		tlsConfig, err := factory.CreateTLSConfig(config.ServiceTypeAuthorization)
+		require.NoError(t, err)
+		assert.Empty(t, tlsConfig.Certificates)
+		assert.Nil(t, tlsConfig.RootCAs)
+	})
+
+	t.Run("uses the service mTLS configuration", func(t *testing.T) {
+		factory := NewExternalHTTPClientFactory(&config.ExternalServiceConfig{
+			Authorization: config.ServiceConfig{
+				MTLS: config.MTLSConfig{
+					Enabled:            true,
+					CertID:             "cert123",
+					InsecureSkipVerify: true,
+				},
+			},
+		}, &mockCertificateManager{
+			certificates: map[string]*tls.Certificate{
+				"cert123": createMockCertificate(),
+			},
+		})
+
+		tlsConfig, err := factory.CreateTLSConfig(config.ServiceTypeAuthorization)
+		require.NoError(t, err)
+		assert.Len(t, tlsConfig.Certificates, 1)
+		assert.True(t, tlsConfig.InsecureSkipVerify)
+	})
+}
+
 func TestExternalHTTPClientFactory_getServiceConfig(t *testing.T) {
 	factory := &ExternalHTTPClientFactory{
 		config: &config.ExternalServiceConfig{
@@ -0,0 +1,293 @@
+// Package loadbalancer implements the strategies used by the gateway to pick
+// an upstream target when load balancing is enabled for an API.
+package loadbalancer
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Algorithm names a load balancing strategy.
+type Algorithm string
+
+// The following constants enumerate implemented load balancing strategies.
+const (
+	// RoundRobin cycles through the target list in order. Weights are
+	// honoured by repeating a target in the list.
+	RoundRobin Algorithm = "roundRobin"
+	// WeightedRoundRobin implements smooth weighted round robin, spreading
+	// picks of heavier targets evenly across the cycle.
+	WeightedRoundRobin Algorithm = "weightedRoundRobin"
+	// LeastConnections picks the target with the fewest in-flight
+	// requests relative to its weight.
+	LeastConnections Algorithm = "leastConnections"
+	// PeakEWMA picks the cheaper of two random targets, where the cost is
+	// the peak-sensitive moving average of latency times the in-flight load.
+	PeakEWMA Algorithm = "peakEwma"
+	// ConsistentHash maps a request attribute onto a hash ring so that the
+	// same attribute value keeps hitting the same target.
+	ConsistentHash Algorithm = "consistentHash"
+)
+
+// The following constants enumerate the request attributes that can be
+// hashed by the ConsistentHash algorithm.
+const (
+	HashSourceHeader  = "header"
+	HashSourceCookie  = "cookie"
+	HashSourceSession = "session"
+	HashSourceIP      = "ip"
+)
+
+var (
+	// ErrNoTargets is returned when the target list is empty.
+	ErrNoTargets = errors.New("no upstream targets configured")
+
+	// ErrAllTargetsDown is returned when every target is reported unhealthy.
+	ErrAllTargetsDown = errors.New("all hosts are down, uptime tests are failing")
+)
+
+// Valid returns true if the algorithm is empty (default) or implemented.
+func (a Algorithm) Valid() bool {
+	switch a {
+	case "", RoundRobin, WeightedRoundRobin, LeastConnections, PeakEWMA, ConsistentHash:
+		return true
+	}
+	return false
+}
+
+// ValidHashSource returns true if source is a known consistent hash source.
+func ValidHashSource(source string) bool {
+	switch source {
+	case HashSourceHeader, HashSourceCookie, HashSourceSession, HashSourceIP:
+		return true
+	}
+	return false
+}
+
+// Target is an upstream host with its relative weight.
+type Target struct {
+	Host   string
+	Weight int
+}
+
+// TargetsFromList collapses a host list where weights are expressed by
+// repetition into a list of weighted targets. The order of first occurrence
+// is preserved.
+func TargetsFromList(hosts []string) []Target {
+	targets := make([]Target, 0, len(hosts))
+	index := make(map[string]int, len(hosts))
+	for _, host := range hosts {
+		if i, ok := index[host]; ok {
+			targets[i].Weight++
+			continue
+		}
+		index[host] = len(targets)
+		targets = append(targets, Target{Host: host, Weight: 1})
+	}
+	return targets
+}
+
+// Options control a single target selection.
+type Options struct {
+	// Algorithm selects the strategy, empty means RoundRobin.
+	Algorithm Algorithm
+	// HashKey is the request attribute value used by ConsistentHash. When
+	// empty, ConsistentHash falls back to WeightedRoundRobin.
+	HashKey string
+	// Healthy reports whether a host may receive traffic. A nil func
+	// treats every host as healthy.
+	Healthy func(host string) bool
+}
+
+func (o Options) healthy(host string) bool {
+	return o.Healthy == nil || o.Healthy(host)
+}
+
+// Balancer holds the selection state for a single API. The zero value is
+// ready to use and safe for concurrent use.
+type Balancer struct {
+	pos   uint32
+	stats sync.Map // map[string]*hostStats
+
+	mu       sync.Mutex
+	current  map[string]int
+	ring     *ring
+	ringSign string
+}
+
+// Next picks a target host according to opts.
+func (b *Balancer) Next(targets []Target, opts Options) (string, error) {
+	if len(targets) == 0 {
+		return "", ErrNoTargets
+	}
+
+	switch opts.Algorithm {
+	case WeightedRoundRobin:
+		return b.weightedRoundRobin(targets, opts)
+	case LeastConnections:
+		return b.leastConnections(targets, opts)
+	case PeakEWMA:
+		return b.peakEWMA(targets, opts)
+	case ConsistentHash:
+		if opts.HashKey == "" {
+			return b.weightedRoundRobin(targets, opts)
+		}
+		return b.consistentHash(targets, opts)
+	default:
+		return b.roundRobin(targets, opts)
+	}
+}
+
+// Begin marks the start of a request to host and returns a func that must
+// be called once the upstream has answered, with the observed latency.
+func (b *Balancer) Begin(host string) func(latency time.Duration) {
+	if host == "" {
+		return func(time.Duration) {}
+	}
+
+	s := b.hostStats(host)
+	s.inflight.Add(1)
+
+	var once sync.Once
+	return func(latency time.Duration) {
+		once.Do(func() {
+			s.inflight.Add(-1)
+			s.observe(latency, time.Now())
+		})
+	}
+}
+
+// Observe records a latency sample for host that was not produced by a
+// proxied request, for example an uptime test.
+func (b *Balancer) Observe(host string, latency time.Duration) {
+	b.hostStats(host).observe(latency, time.Now())
+}
+
+// InFlight returns the number of requests currently proxied to host.
+func (b *Balancer) InFlight(host string) int64 {
+	return b.hostStats(host).inflight.Load()
+}
+
+func (b *Balancer) hostStats(host string) *hostStats {
+	if s, ok := b.stats.Load(host); ok {
+		return s.(*hostStats)
+	}
+	s, _ := b.stats.LoadOrStore(host, &hostStats{})
+	return s.(*hostStats)
+}
+
+func (b *Balancer) roundRobin(targets []Target, opts Options) (string, error) {
+	start := int(atomic.AddUint32(&b.pos, 1)-1) % len(targets)
+	for i := 0; i < len(targets); i++ {
+		t := targets[(start+i)%len(targets)]
+		if t.Weight > 0 && opts.healthy(t.Host) {
+			return t.Host, nil
+		}
+	}
+	return "", ErrAllTargetsDown
+}
+
+func (b *Balancer) weightedRoundRobin(targets []Target, opts Options) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.current == nil {
+		b.current = make(map[string]int, len(targets))
+	}
+
+	var (
+		best  = -1
+		total int
+	)
+	for i, t := range targets {
+		if t.Weight <= 0 || !opts.healthy(t.Host) {
+			continue
+		}
+		b.current[t.Host] += t.Weight
+		total += t.Weight
+		if best < 0 || b.current[t.Host] > b.current[targets[best].Host] {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return "", ErrAllTargetsDown
+	}
+
+	b.current[targets[best].Host] -= total
+	return targets[best].Host, nil
+}
+
+func (b *Balancer) leastConnections(targets []Target, opts Options) (string, error) {
+	start := int(atomic.AddUint32(&b.pos, 1)-1) % len(targets)
+
+	var (
+		best     string
+		bestLoad float64
+	)
+	for i := 0; i < len(targets); i++ {
+		t := targets[(start+i)%len(targets)]
+		if t.Weight <= 0 || !opts.healthy(t.Host) {
+			continue
+		}
+		load := float64(b.hostStats(t.Host).inflight.Load()) / float64(t.Weight)
+		if best == "" || load < bestLoad {
+			best, bestLoad = t.Host, load
+		}
+	}
+
+	if best == "" {
+		return "", ErrAllTargetsDown
+	}
+	return best, nil
+}
+
+func (b *Balancer) peakEWMA(targets []Target, opts Options) (string, error) {
+	candidates := make([]Target, 0, len(targets))
+	for _, t := range targets {
+		if t.Weight > 0 && opts.healthy(t.Host) {
+			candidates = append(candidates, t)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", ErrAllTargetsDown
+	case 1:
+		return candidates[0].Host, nil
+	}
+
+	i := rand.IntN(len(candidates))
+	j := rand.IntN(len(candidates) - 1)
+	if j >= i {
+		j++
+	}
+
+	now := time.Now()
+	a, c := candidates[i], candidates[j]
+	if b.hostStats(c.Host).cost(now)/float64(c.Weight) < b.hostStats(a.Host).cost(now)/float64(a.Weight) {
+		return c.Host, nil
+	}
+	return a.Host, nil
+}
+
+func (b *Balancer) consistentHash(targets []Target, opts Options) (string, error) {
+	sign := signature(targets)
+
+	b.mu.Lock()
+	if b.ring == nil || b.ringSign != sign {
+		b.ring = newRing(targets)
+		b.ringSign = sign
+	}
+	r := b.ring
+	b.mu.Unlock()
+
+	host, ok := r.lookup(opts.HashKey, opts.healthy)
+	if !ok {
+		return "", ErrAllTargetsDown
+	}
+	return host, nil
+}
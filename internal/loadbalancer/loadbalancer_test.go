@@ -0,0 +1,190 @@
+package loadbalancer
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetsFromList(t *testing.T) {
+	targets := TargetsFromList([]string{"b", "a", "b", "c", "b"})
+
+	assert.Equal(t, []Target{
+		{Host: "b", Weight: 3},
+		{Host: "a", Weight: 1},
+		{Host: "c", Weight: 1},
+	}, targets)
+}
+
+func TestAlgorithm_Valid(t *testing.T) {
+	for _, a := range []Algorithm{"", RoundRobin, WeightedRoundRobin, LeastConnections, PeakEWMA, ConsistentHash} {
+		assert.True(t, a.Valid(), a)
+	}
+	assert.False(t, Algorithm("random").Valid())
+}
+
+func TestBalancer_NoTargets(t *testing.T) {
+	var b Balancer
+
+	_, err := b.Next(nil, Options{})
+	assert.ErrorIs(t, err, ErrNoTargets)
+}
+
+func TestBalancer_AllDown(t *testing.T) {
+	targets := []Target{{"a", 1}, {"b", 2}}
+	down := func(string) bool { return false }
+
+	for _, a := range []Algorithm{RoundRobin, WeightedRoundRobin, LeastConnections, PeakEWMA, ConsistentHash} {
+		t.Run(string(a), func(t *testing.T) {
+			var b Balancer
+			_, err := b.Next(targets, Options{Algorithm: a, HashKey: "key", Healthy: down})
+			assert.ErrorIs(t, err, ErrAllTargetsDown)
+		})
+	}
+}
+
+func TestBalancer_SkipsUnhealthy(t *testing.T) {
+	targets := []Target{{"a", 1}, {"b", 5}, {"c", 1}}
+	healthy := func(host string) bool { return host != "b" }
+
+	for _, a := range []Algorithm{RoundRobin, WeightedRoundRobin, LeastConnections, PeakEWMA, ConsistentHash} {
+		t.Run(string(a), func(t *testing.T) {
+			var b Balancer
+			for i := 0; i < 50; i++ {
+				host, err := b.Next(targets, Options{Algorithm: a, HashKey: fmt.Sprint(i), Healthy: healthy})
+				require.NoError(t, err)
+				assert.NotEqual(t, "b", host)
+			}
+		})
+	}
+}
+
+func TestBalancer_WeightedRoundRobin(t *testing.T) {
+	var b Balancer
+	targets := []Target{{"a", 5}, {"b", 1}, {"c", 1}}
+
+	var got []string
+	for i := 0; i < 7; i++ {
+		host, err := b.Next(targets, Options{Algorithm: WeightedRoundRobin})
+		require.NoError(t, err)
+		got = append(got, host)
+	}
+
+	// Smooth weighted round robin interleaves the lighter targets instead
+	// of sending five consecutive requests to "a".
+	assert.Equal(t, []string{"a", "a", "b", "a", "c", "a", "a"}, got)
+}
+
+func TestBalancer_LeastConnections(t *testing.T) {
+	var b Balancer
+	targets := []Target{{"a", 1}, {"b", 1}, {"c", 2}}
+
+	doneA := b.Begin("a")
+	doneC := b.Begin("c")
+	b.Begin("c")
+	defer doneA(0)
+
+	host, err := b.Next(targets, Options{Algorithm: LeastConnections})
+	require.NoError(t, err)
+	assert.Equal(t, "b", host)
+
+	done := b.Begin("b")
+	b.Begin("b")
+	doneC(time.Millisecond)
+	defer done(0)
+
+	// a: 1/1, b: 2/1, c: 1/2
+	host, err = b.Next(targets, Options{Algorithm: LeastConnections})
+	require.NoError(t, err)
+	assert.Equal(t, "c", host)
+}
+
+func TestBalancer_Begin(t *testing.T) {
+	var b Balancer
+
+	done := b.Begin("a")
+	assert.EqualValues(t, 1, b.InFlight("a"))
+
+	done(time.Millisecond)
+	done(time.Millisecond)
+	assert.EqualValues(t, 0, b.InFlight("a"))
+
+	b.Begin("")(0)
+}
+
+func TestBalancer_PeakEWMA(t *testing.T) {
+	var b Balancer
+	targets := []Target{{"fast", 1}, {"slow", 1}}
+
+	b.Observe("fast", time.Millisecond)
+	b.Observe("slow", time.Second)
+
+	// With two targets, both are always sampled and the cheaper one wins.
+	for i := 0; i < 20; i++ {
+		host, err := b.Next(targets, Options{Algorithm: PeakEWMA})
+		require.NoError(t, err)
+		assert.Equal(t, "fast", host)
+	}
+}
+
+func TestBalancer_ConsistentHash(t *testing.T) {
+	var b Balancer
+	targets := []Target{{"a", 1}, {"b", 1}, {"c", 1}}
+
+	picks := map[string]string{}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		host, err := b.Next(targets, Options{Algorithm: ConsistentHash, HashKey: key})
+		require.NoError(t, err)
+		picks[key] = host
+
+		again, err := b.Next(targets, Options{Algorithm: ConsistentHash, HashKey: key})
+		require.NoError(t, err)
+		assert.Equal(t, host, again)
+	}
+
+	// Removing a target only moves the keys that were mapped to it.
+	reduced := []Target{{"a", 1}, {"c", 1}}
+	for key, host := range picks {
+		got, err := b.Next(reduced, Options{Algorithm: ConsistentHash, HashKey: key})
+		require.NoError(t, err)
+		if host != "b" {
+			assert.Equal(t, host, got)
+		}
+	}
+
+	t.Run("falls back to weighted round robin without a key", func(t *testing.T) {
+		var b Balancer
+		seen := map[string]bool{}
+		for i := 0; i < 3; i++ {
+			host, err := b.Next(targets, Options{Algorithm: ConsistentHash})
+			require.NoError(t, err)
+			seen[host] = true
+		}
+		assert.Len(t, seen, 3)
+	})
+}
+
+func TestHostStats(t *testing.T) {
+	var s hostStats
+	now := time.Now()
+
+	assert.Zero(t, s.cost(now))
+
+	s.observe(100*time.Millisecond, now)
+	assert.InDelta(t, float64(100*time.Millisecond), s.cost(now), 1)
+
+	// a lower sample is averaged in, a higher sample replaces the average
+	s.observe(10*time.Millisecond, now.Add(ewmaDecay))
+	assert.Less(t, s.ewma, float64(100*time.Millisecond))
+	assert.Greater(t, s.ewma, float64(10*time.Millisecond))
+
+	s.observe(time.Second, now.Add(ewmaDecay))
+	assert.Equal(t, float64(time.Second), s.ewma)
+
+	s.inflight.Add(1)
+	assert.InDelta(t, 2*float64(time.Second), s.cost(now.Add(ewmaDecay)), 1)
+}
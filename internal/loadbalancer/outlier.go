@@ -0,0 +1,186 @@
+package loadbalancer
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultConsecutiveFailures is the number of consecutive failures that eject a host.
+	DefaultConsecutiveFailures = 5
+	// DefaultBaseEjectionTime is how long a host is ejected the first time.
+	DefaultBaseEjectionTime = 30 * time.Second
+	// DefaultMaxEjectionTime caps the ejection time of a host that keeps failing.
+	DefaultMaxEjectionTime = 5 * time.Minute
+	// DefaultMaxEjectionPercent is the share of the pool that can be ejected at once.
+	DefaultMaxEjectionPercent = 10
+)
+
+// OutlierConfig configures passive outlier detection.
+type OutlierConfig struct {
+	// ConsecutiveFailures is the number of consecutive failures that eject a host.
+	ConsecutiveFailures int
+	// BaseEjectionTime is the ejection time of a host ejected for the first
+	// time. It doubles with every further ejection, up to MaxEjectionTime.
+	BaseEjectionTime time.Duration
+	// MaxEjectionTime caps the ejection time. A host that stays in the pool
+	// for this long has its ejection time reset.
+	MaxEjectionTime time.Duration
+	// MaxEjectionPercent caps the share of the pool that can be ejected at
+	// once. At least one host can always be ejected, as long as one remains.
+	MaxEjectionPercent int
+
+	// OnEject is called when a host is ejected.
+	OnEject func(host string, duration time.Duration)
+	// OnReturn is called when an ejected host is returned to the pool.
+	OnReturn func(host string)
+}
+
+// OutlierDetector passively tracks the outcome of requests proxied to each
+// host and ejects hosts that keep failing from the load balancing pool. It is
+// safe for concurrent use.
+type OutlierDetector struct {
+	conf OutlierConfig
+	now  func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*outlierHost
+}
+
+type outlierHost struct {
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+	returnedAt   time.Time
+}
+
+func (h *outlierHost) ejected(now time.Time) bool {
+	return !h.ejectedUntil.IsZero() && now.Before(h.ejectedUntil)
+}
+
+// NewOutlierDetector creates an outlier detector. Zero values in conf use the defaults.
+func NewOutlierDetector(conf OutlierConfig) *OutlierDetector {
+	if conf.ConsecutiveFailures <= 0 {
+		conf.ConsecutiveFailures = DefaultConsecutiveFailures
+	}
+	if conf.BaseEjectionTime <= 0 {
+		conf.BaseEjectionTime = DefaultBaseEjectionTime
+	}
+	if conf.MaxEjectionTime <= 0 {
+		conf.MaxEjectionTime = DefaultMaxEjectionTime
+	}
+	if conf.MaxEjectionTime < conf.BaseEjectionTime {
+		conf.MaxEjectionTime = conf.BaseEjectionTime
+	}
+	if conf.MaxEjectionPercent <= 0 || conf.MaxEjectionPercent > 100 {
+		conf.MaxEjectionPercent = DefaultMaxEjectionPercent
+	}
+
+	return &OutlierDetector{
+		conf:  conf,
+		now:   time.Now,
+		hosts: make(map[string]*outlierHost),
+	}
+}
+
+// Report records the outcome of a request proxied to host. poolSize is the
+// number of distinct hosts in the pool, used to cap ejections.
+func (d *OutlierDetector) Report(host string, failed bool, poolSize int) {
+	if d == nil || host == "" {
+		return
+	}
+
+	d.mu.Lock()
+
+	now := d.now()
+	h := d.host(host)
+
+	if !failed {
+		h.failures = 0
+		if h.ejections > 0 && !h.returnedAt.IsZero() && now.Sub(h.returnedAt) >= d.conf.MaxEjectionTime {
+			h.ejections = 0
+		}
+		d.mu.Unlock()
+		return
+	}
+
+	h.failures++
+	if h.failures < d.conf.ConsecutiveFailures || h.ejected(now) || !d.canEject(now, poolSize) {
+		d.mu.Unlock()
+		return
+	}
+
+	duration := d.conf.BaseEjectionTime << h.ejections
+	if duration > d.conf.MaxEjectionTime || duration <= 0 {
+		duration = d.conf.MaxEjectionTime
+	}
+
+	h.failures = 0
+	h.ejections++
+	h.ejectedUntil = now.Add(duration)
+	d.mu.Unlock()
+
+	if d.conf.OnEject != nil {
+		d.conf.OnEject(host, duration)
+	}
+}
+
+// Ejected returns true if host is currently ejected from the pool. A host
+// whose ejection time has passed is returned to the pool.
+func (d *OutlierDetector) Ejected(host string) bool {
+	if d == nil {
+		return false
+	}
+
+	d.mu.Lock()
+
+	h, ok := d.hosts[host]
+	if !ok || h.ejectedUntil.IsZero() {
+		d.mu.Unlock()
+		return false
+	}
+
+	now := d.now()
+	if h.ejected(now) {
+		d.mu.Unlock()
+		return true
+	}
+
+	h.ejectedUntil = time.Time{}
+	h.returnedAt = now
+	d.mu.Unlock()
+
+	if d.conf.OnReturn != nil {
+		d.conf.OnReturn(host)
+	}
+	return false
+}
+
+// canEject returns true if one more host can be ejected from a pool of poolSize hosts.
+func (d *OutlierDetector) canEject(now time.Time, poolSize int) bool {
+	var ejected int
+	for _, h := range d.hosts {
+		if h.ejected(now) {
+			ejected++
+		}
+	}
+
+	limit := poolSize * d.conf.MaxEjectionPercent / 100
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > poolSize-1 {
+		limit = poolSize - 1
+	}
+
+	return ejected < limit
+}
+
+func (d *OutlierDetector) host(host string) *outlierHost {
+	h, ok := d.hosts[host]
+	if !ok {
+		h = &outlierHost{}
+		d.hosts[host] = h
+	}
+	return h
+}
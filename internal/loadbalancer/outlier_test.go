@@ -0,0 +1,116 @@
+package loadbalancer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOutlierDetector(conf OutlierConfig) (*OutlierDetector, *time.Time, *[]string) {
+	var events []string
+	conf.OnEject = func(host string, d time.Duration) {
+		events = append(events, "eject "+host+" "+d.String())
+	}
+	conf.OnReturn = func(host string) {
+		events = append(events, "return "+host)
+	}
+
+	now := time.Unix(1000, 0)
+	d := NewOutlierDetector(conf)
+	d.now = func() time.Time { return now }
+	return d, &now, &events
+}
+
+func TestOutlierDetector(t *testing.T) {
+	d, now, events := newTestOutlierDetector(OutlierConfig{
+		ConsecutiveFailures: 3,
+		BaseEjectionTime:    10 * time.Second,
+		MaxEjectionTime:     30 * time.Second,
+		MaxEjectionPercent:  50,
+	})
+
+	// failures must be consecutive
+	d.Report("a", true, 4)
+	d.Report("a", true, 4)
+	d.Report("a", false, 4)
+	d.Report("a", true, 4)
+	d.Report("a", true, 4)
+	assert.False(t, d.Ejected("a"))
+
+	d.Report("a", true, 4)
+	assert.True(t, d.Ejected("a"))
+	assert.False(t, d.Ejected("b"))
+
+	*now = now.Add(10 * time.Second)
+	assert.False(t, d.Ejected("a"))
+
+	// the ejection time doubles for a host that keeps failing
+	for i := 0; i < 3; i++ {
+		d.Report("a", true, 4)
+	}
+	assert.True(t, d.Ejected("a"))
+	*now = now.Add(20 * time.Second)
+	assert.False(t, d.Ejected("a"))
+
+	// and is capped
+	for i := 0; i < 3; i++ {
+		d.Report("a", true, 4)
+	}
+	*now = now.Add(30 * time.Second)
+	assert.False(t, d.Ejected("a"))
+
+	// staying healthy for the max ejection time resets it
+	*now = now.Add(30 * time.Second)
+	d.Report("a", false, 4)
+	for i := 0; i < 3; i++ {
+		d.Report("a", true, 4)
+	}
+
+	assert.Equal(t, []string{
+		"eject a 10s",
+		"return a",
+		"eject a 20s",
+		"return a",
+		"eject a 30s",
+		"return a",
+		"eject a 10s",
+	}, *events)
+}
+
+func TestOutlierDetector_MaxEjectionPercent(t *testing.T) {
+	d, _, _ := newTestOutlierDetector(OutlierConfig{
+		ConsecutiveFailures: 1,
+		MaxEjectionPercent:  50,
+	})
+
+	for _, host := range []string{"a", "b", "c", "d"} {
+		d.Report(host, true, 4)
+	}
+
+	assert.True(t, d.Ejected("a"))
+	assert.True(t, d.Ejected("b"))
+	assert.False(t, d.Ejected("c"))
+	assert.False(t, d.Ejected("d"))
+}
+
+func TestOutlierDetector_KeepsOneHost(t *testing.T) {
+	d, _, _ := newTestOutlierDetector(OutlierConfig{
+		ConsecutiveFailures: 1,
+		MaxEjectionPercent:  100,
+	})
+
+	d.Report("a", true, 2)
+	d.Report("b", true, 2)
+	assert.True(t, d.Ejected("a"))
+	assert.False(t, d.Ejected("b"))
+
+	d.Report("c", true, 1)
+	assert.False(t, d.Ejected("c"))
+}
+
+func TestOutlierDetector_Nil(t *testing.T) {
+	var d *OutlierDetector
+	d.Report("a", true, 1)
+	assert.False(t, d.Ejected("a"))
+}
@@ -0,0 +1,87 @@
+package loadbalancer
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// replicas is the number of points a target with weight 1 gets on the ring.
+const replicas = 100
+
+type ringPoint struct {
+	hash uint64
+	host string
+}
+
+// ring is an immutable consistent hash ring.
+type ring struct {
+	points []ringPoint
+	hosts  int
+}
+
+func newRing(targets []Target) *ring {
+	r := &ring{}
+	for _, t := range targets {
+		if t.Weight <= 0 {
+			continue
+		}
+		r.hosts++
+		for i := 0; i < replicas*t.Weight; i++ {
+			r.points = append(r.points, ringPoint{
+				hash: hash(t.Host + "#" + strconv.Itoa(i)),
+				host: t.Host,
+			})
+		}
+	}
+
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// lookup returns the first healthy host clockwise from the key's position.
+func (r *ring) lookup(key string, healthy func(string) bool) (string, bool) {
+	if len(r.points) == 0 {
+		return "", false
+	}
+
+	h := hash(key)
+	start := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+
+	checked := make(map[string]struct{}, r.hosts)
+	for i := 0; i < len(r.points) && len(checked) < r.hosts; i++ {
+		p := r.points[(start+i)%len(r.points)]
+		if _, ok := checked[p.host]; ok {
+			continue
+		}
+		if healthy(p.host) {
+			return p.host, true
+		}
+		checked[p.host] = struct{}{}
+	}
+	return "", false
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// signature identifies a target set so that the ring is only rebuilt when
+// the targets or their weights change.
+func signature(targets []Target) string {
+	var sb strings.Builder
+	for _, t := range targets {
+		sb.WriteString(t.Host)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Itoa(t.Weight))
+		sb.WriteByte(';')
+	}
+	return sb.String()
+}
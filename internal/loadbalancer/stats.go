@@ -0,0 +1,64 @@
+package loadbalancer
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ewmaDecay is the time constant of the latency moving average. Samples
+// older than a few decay periods have next to no influence on the cost.
+const ewmaDecay = 10 * time.Second
+
+// hostStats tracks the load and latency of a single upstream host.
+type hostStats struct {
+	inflight atomic.Int64
+
+	mu    sync.Mutex
+	ewma  float64 // nanoseconds
+	stamp time.Time
+}
+
+// observe folds a latency sample into the moving average. The average is
+// peak sensitive: a sample above the current value replaces it outright,
+// so a host that slows down is penalised immediately and recovers gradually.
+func (s *hostStats) observe(latency time.Duration, now time.Time) {
+	rtt := float64(latency)
+	if rtt < 0 {
+		rtt = 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stamp.IsZero() || rtt > s.ewma {
+		s.ewma = rtt
+		s.stamp = now
+		return
+	}
+
+	w := math.Exp(-float64(now.Sub(s.stamp)) / float64(ewmaDecay))
+	s.ewma = s.ewma*w + rtt*(1-w)
+	s.stamp = now
+}
+
+// cost estimates how expensive sending one more request to the host is.
+// A host without latency samples costs nothing so that it gets probed.
+func (s *hostStats) cost(now time.Time) float64 {
+	s.mu.Lock()
+	ewma, stamp := s.ewma, s.stamp
+	s.mu.Unlock()
+
+	if stamp.IsZero() {
+		return 0
+	}
+
+	// Decay the average towards zero while the host is idle, so that a host
+	// that was slow once isn't starved forever.
+	if idle := now.Sub(stamp); idle > 0 {
+		ewma *= math.Exp(-float64(idle) / float64(ewmaDecay))
+	}
+
+	return ewma * float64(s.inflight.Load()+1)
+}
@@ -38,6 +38,47 @@ const (
 	exchangeMetricCacheHit = "tyk.oauth2.exchange.cache_hit"
 )
 
+// Adaptive concurrency instrument names and attribute keys. Upstreams are the
+// hosts of an API's targets, so the labels stay bounded.
+const (
+	upstreamConcurrencyMetricLimit = "tyk.upstream.concurrency.limit"
+	upstreamConcurrencyMetricShed  = "tyk.upstream.concurrency.shed"
+
+	upstreamConcurrencyAttrAPIID    = "api_id"
+	upstreamConcurrencyAttrUpstream = "upstream"
+)
+
+// Analytics instrument names and attribute keys. Records dropped before they
+// reach the sinks, because the records buffer is full, are counted for the
+// "buffer" sink.
+const (
+	analyticsMetricDropped = "tyk.analytics.records.dropped"
+
+	analyticsAttrSink = "sink"
+)
+
+// Response cache instrument names and attribute keys, by API and whether the
+// entries were compressed.
+const (
+	cacheMetricStoredEntries = "tyk.cache.stored.entries"
+	cacheMetricStoredSize    = "tyk.cache.stored.size"
+	cacheMetricResponseSize  = "tyk.cache.stored.response_size"
+
+	cacheAttrAPIID      = "api_id"
+	cacheAttrCompressed = "compressed"
+)
+
+// Circuit breaker instrument names and attribute keys. The target is the path
+// or upstream host of the breaker, depending on its scope.
+const (
+	circuitBreakerMetricTransitions = "tyk.circuit_breaker.transitions"
+
+	circuitBreakerAttrAPIID  = "api_id"
+	circuitBreakerAttrScope  = "scope"
+	circuitBreakerAttrTarget = "target"
+	circuitBreakerAttrState  = "state"
+)
+
 // MetricInstruments encapsulates the OTel metrics provider and all gateway instruments.
 // All methods are safe to call even when the provider is disabled (noop).
 type MetricInstruments struct {
@@ -59,6 +100,25 @@ type MetricInstruments struct {
 	exchangeRequests *tykmetric.Counter
 	exchangeDuration *tykmetric.Histogram
 	exchangeCacheHit *tykmetric.Counter
+
+	// Analytics spool gauges.
+	analyticsSpoolDepth *tykmetric.Gauge
+	analyticsSpoolSize  *tykmetric.Gauge
+
+	// Analytics records dropped, by sink.
+	analyticsDropped *tykmetric.Counter
+
+	// Adaptive concurrency metrics, by API and upstream host.
+	upstreamConcurrencyLimit *tykmetric.Gauge
+	upstreamConcurrencyShed  *tykmetric.Counter
+
+	// Response cache storage metrics, by API.
+	cacheStoredEntries *tykmetric.Gauge
+	cacheStoredSize    *tykmetric.Gauge
+	cacheResponseSize  *tykmetric.Counter
+
+	// Circuit breaker state transitions, by API, scope and target.
+	circuitBreakerTransitions *tykmetric.Counter
 }
 
 // NewMetricInstruments creates gateway metric instruments from an existing provider.
@@ -137,16 +197,109 @@ func NewMetricInstruments(provider tykmetric.Provider, logger *logrus.Logger) *M
 		logger.Errorf("Creating exchange cache_hit counter: %s", err)
 	}
 
+	analyticsSpoolDepth, err := provider.NewGauge(
+		"tyk.analytics.spool.depth",
+		"Number of analytics records spooled to disk, waiting to be written to the analytics store",
+		"{record}",
+	)
+	if err != nil {
+		logger.Errorf("Creating analytics spool depth gauge: %s", err)
+	}
+
+	analyticsSpoolSize, err := provider.NewGauge(
+		"tyk.analytics.spool.size",
+		"Size of the analytics records spooled to disk",
+		"By",
+	)
+	if err != nil {
+		logger.Errorf("Creating analytics spool size gauge: %s", err)
+	}
+
+	analyticsDropped, err := provider.NewCounter(
+		analyticsMetricDropped,
+		"Total analytics records dropped because a sink is unavailable or the records buffer is full",
+		"{record}",
+	)
+	if err != nil {
+		logger.Errorf("Creating analytics dropped records counter: %s", err)
+	}
+
+	upstreamConcurrencyLimit, err := provider.NewGauge(
+		upstreamConcurrencyMetricLimit,
+		"Current adaptive concurrency limit of an upstream host",
+		"{request}",
+	)
+	if err != nil {
+		logger.Errorf("Creating upstream concurrency limit gauge: %s", err)
+	}
+
+	upstreamConcurrencyShed, err := provider.NewCounter(
+		upstreamConcurrencyMetricShed,
+		"Total requests shed because the adaptive concurrency limit of an upstream host was reached",
+		"{request}",
+	)
+	if err != nil {
+		logger.Errorf("Creating upstream concurrency shed counter: %s", err)
+	}
+
+	cacheStoredEntries, err := provider.NewGauge(
+		cacheMetricStoredEntries,
+		"Number of unexpired responses the gateway stored in the response cache",
+		"{entry}",
+	)
+	if err != nil {
+		logger.Errorf("Creating cache stored entries gauge: %s", err)
+	}
+
+	cacheStoredSize, err := provider.NewGauge(
+		cacheMetricStoredSize,
+		"Size of the unexpired entries the gateway stored in the response cache, after encoding",
+		"By",
+	)
+	if err != nil {
+		logger.Errorf("Creating cache stored size gauge: %s", err)
+	}
+
+	cacheResponseSize, err := provider.NewCounter(
+		cacheMetricResponseSize,
+		"Total size of the responses stored in the response cache, before encoding",
+		"By",
+	)
+	if err != nil {
+		logger.Errorf("Creating cache response size counter: %s", err)
+	}
+
+	circuitBreakerTransitions, err := provider.NewCounter(
+		circuitBreakerMetricTransitions,
+		"Total circuit breaker state transitions",
+		"{transition}",
+	)
+	if err != nil {
+		logger.Errorf("Creating circuit breaker transitions counter: %s", err)
+	}
+
 	return &MetricInstruments{
-		provider:         provider,
-		requestCounter:   requestCounter,
-		apisLoaded:       apisLoaded,
-		policiesLoaded:   policiesLoaded,
-		reloadCounter:    reloadCounter,
-		reloadDuration:   reloadDuration,
-		exchangeRequests: exchangeRequests,
-		exchangeDuration: exchangeDuration,
-		exchangeCacheHit: exchangeCacheHit,
+		provider:            provider,
+		requestCounter:      requestCounter,
+		apisLoaded:          apisLoaded,
+		policiesLoaded:      policiesLoaded,
+		reloadCounter:       reloadCounter,
+		reloadDuration:      reloadDuration,
+		exchangeRequests:    exchangeRequests,
+		exchangeDuration:    exchangeDuration,
+		exchangeCacheHit:    exchangeCacheHit,
+		analyticsSpoolDepth: analyticsSpoolDepth,
+		analyticsSpoolSize:  analyticsSpoolSize,
+		analyticsDropped:    analyticsDropped,
+
+		upstreamConcurrencyLimit: upstreamConcurrencyLimit,
+		upstreamConcurrencyShed:  upstreamConcurrencyShed,
+
+		cacheStoredEntries: cacheStoredEntries,
+		cacheStoredSize:    cacheStoredSize,
+		cacheResponseSize:  cacheResponseSize,
+
+		circuitBreakerTransitions: circuitBreakerTransitions,
 	}
 }
 
@@ -231,6 +384,62 @@ func (i *MetricInstruments) RecordReload(ctx context.Context, duration time.Dura
 	i.reloadDuration.Record(ctx, duration.Seconds())
 }
 
+// RecordAnalyticsSpool records the number of records and bytes in the analytics spool.
+func (i *MetricInstruments) RecordAnalyticsSpool(ctx context.Context, records int, bytes int64) {
+	i.analyticsSpoolDepth.Record(ctx, float64(records))
+	i.analyticsSpoolSize.Record(ctx, float64(bytes))
+}
+
+// RecordAnalyticsDropped counts analytics records dropped by a sink.
+func (i *MetricInstruments) RecordAnalyticsDropped(ctx context.Context, sink string, records int) {
+	i.analyticsDropped.Add(ctx, int64(records), attribute.String(analyticsAttrSink, sink))
+}
+
+// RecordUpstreamConcurrencyLimit records the adaptive concurrency limit of an upstream host.
+func (i *MetricInstruments) RecordUpstreamConcurrencyLimit(ctx context.Context, apiID, upstream string, limit int) {
+	i.upstreamConcurrencyLimit.Record(ctx, float64(limit),
+		attribute.String(upstreamConcurrencyAttrAPIID, apiID),
+		attribute.String(upstreamConcurrencyAttrUpstream, upstream),
+	)
+}
+
+// RecordUpstreamConcurrencyShed counts a request shed by the adaptive concurrency limit of an upstream host.
+func (i *MetricInstruments) RecordUpstreamConcurrencyShed(ctx context.Context, apiID, upstream string) {
+	i.upstreamConcurrencyShed.Add(ctx, 1,
+		attribute.String(upstreamConcurrencyAttrAPIID, apiID),
+		attribute.String(upstreamConcurrencyAttrUpstream, upstream),
+	)
+}
+
+// RecordCacheStore records the size of a response stored in the response cache of an API.
+func (i *MetricInstruments) RecordCacheStore(ctx context.Context, apiID string, responseSize int, compressed bool) {
+	i.cacheResponseSize.Add(ctx, int64(responseSize),
+		attribute.String(cacheAttrAPIID, apiID),
+		attribute.Bool(cacheAttrCompressed, compressed),
+	)
+}
+
+// RecordCacheUsage records the number and size of the unexpired entries stored in the response cache of an API.
+func (i *MetricInstruments) RecordCacheUsage(ctx context.Context, apiID string, compressed bool, entries, size int64) {
+	attrs := []attribute.KeyValue{
+		attribute.String(cacheAttrAPIID, apiID),
+		attribute.Bool(cacheAttrCompressed, compressed),
+	}
+	i.cacheStoredEntries.Record(ctx, float64(entries), attrs...)
+	i.cacheStoredSize.Record(ctx, float64(size), attrs...)
+}
+
+// RecordCircuitBreakerTransition counts a circuit breaker of an API moving to state, one of open, half_open or
+// closed.
+func (i *MetricInstruments) RecordCircuitBreakerTransition(ctx context.Context, apiID, scope, target, state string) {
+	i.circuitBreakerTransitions.Add(ctx, 1,
+		attribute.String(circuitBreakerAttrAPIID, apiID),
+		attribute.String(circuitBreakerAttrScope, scope),
+		attribute.String(circuitBreakerAttrTarget, target),
+		attribute.String(circuitBreakerAttrState, state),
+	)
+}
+
 // Shutdown flushes pending metrics and shuts down the provider.
 func (i *MetricInstruments) Shutdown(ctx context.Context) error {
 	if err := i.provider.ForceFlush(ctx); err != nil {
@@ -174,6 +174,95 @@ func TestRecordConfigState_UpdatesGauges(t *testing.T) {
 	metrictest.AssertGauge(t, tp.FindMetric(t, "tyk.gateway.policies.loaded"), float64(4))
 }
 
+func TestRecordAnalyticsSpool_SetsGauges(t *testing.T) {
+	inst, tp := activeProvider(t)
+	ctx := context.Background()
+
+	inst.RecordAnalyticsSpool(ctx, 120, 4096)
+	inst.RecordAnalyticsSpool(ctx, 20, 512)
+
+	metrictest.AssertGauge(t, tp.FindMetric(t, "tyk.analytics.spool.depth"), float64(20))
+	metrictest.AssertGauge(t, tp.FindMetric(t, "tyk.analytics.spool.size"), float64(512))
+}
+
+func TestRecordAnalyticsDropped(t *testing.T) {
+	inst, tp := activeProvider(t)
+	ctx := context.Background()
+
+	inst.RecordAnalyticsDropped(ctx, "http", 10)
+	inst.RecordAnalyticsDropped(ctx, "http", 5)
+	inst.RecordAnalyticsDropped(ctx, "buffer", 1)
+
+	dropped := tp.FindMetric(t, analyticsMetricDropped)
+	metrictest.AssertSumWithAttrs(t, dropped, int64(15), attribute.String(analyticsAttrSink, "http"))
+	metrictest.AssertDataPointCount(t, dropped, 2)
+}
+
+func TestRecordUpstreamConcurrency(t *testing.T) {
+	inst, tp := activeProvider(t)
+	ctx := context.Background()
+
+	inst.RecordUpstreamConcurrencyLimit(ctx, "api1", "backend:8080", 20)
+	inst.RecordUpstreamConcurrencyLimit(ctx, "api1", "backend:8080", 14)
+	inst.RecordUpstreamConcurrencyShed(ctx, "api1", "backend:8080")
+	inst.RecordUpstreamConcurrencyShed(ctx, "api1", "backend:8080")
+
+	metrictest.AssertGauge(t, tp.FindMetric(t, upstreamConcurrencyMetricLimit), float64(14))
+
+	shed := tp.FindMetric(t, upstreamConcurrencyMetricShed)
+	metrictest.AssertSumWithAttrs(t, shed, int64(2),
+		attribute.String(upstreamConcurrencyAttrAPIID, "api1"),
+		attribute.String(upstreamConcurrencyAttrUpstream, "backend:8080"),
+	)
+	metrictest.AssertDataPointCount(t, shed, 1)
+}
+
+func TestRecordCacheStore(t *testing.T) {
+	inst, tp := activeProvider(t)
+	ctx := context.Background()
+
+	inst.RecordCacheStore(ctx, "api1", 4096, true)
+	inst.RecordCacheStore(ctx, "api1", 2048, true)
+	inst.RecordCacheStore(ctx, "api1", 100, false)
+
+	responseSize := tp.FindMetric(t, cacheMetricResponseSize)
+	metrictest.AssertSumWithAttrs(t, responseSize, int64(6144),
+		attribute.String(cacheAttrAPIID, "api1"),
+		attribute.Bool(cacheAttrCompressed, true),
+	)
+	metrictest.AssertDataPointCount(t, responseSize, 2)
+}
+
+func TestRecordCacheUsage(t *testing.T) {
+	inst, tp := activeProvider(t)
+	ctx := context.Background()
+
+	inst.RecordCacheUsage(ctx, "api1", true, 2, 768)
+	inst.RecordCacheUsage(ctx, "api1", true, 1, 512)
+
+	// the gauges follow the entries going away
+	metrictest.AssertGauge(t, tp.FindMetric(t, cacheMetricStoredEntries), float64(1))
+	metrictest.AssertGauge(t, tp.FindMetric(t, cacheMetricStoredSize), float64(512))
+}
+
+func TestRecordCircuitBreakerTransition(t *testing.T) {
+	inst, tp := activeProvider(t)
+	ctx := context.Background()
+
+	inst.RecordCircuitBreakerTransition(ctx, "api1", "host", "backend:8080", "open")
+	inst.RecordCircuitBreakerTransition(ctx, "api1", "host", "backend:8080", "half_open")
+	inst.RecordCircuitBreakerTransition(ctx, "api1", "host", "backend:8080", "open")
+
+	transitions := tp.FindMetric(t, circuitBreakerMetricTransitions)
+	metrictest.AssertSumWithAttrs(t, transitions, int64(2),
+		attribute.String(circuitBreakerAttrAPIID, "api1"),
+		attribute.String(circuitBreakerAttrScope, "host"),
+		attribute.String(circuitBreakerAttrTarget, "backend:8080"),
+		attribute.String(circuitBreakerAttrState, "open"),
+	)
+	metrictest.AssertDataPointCount(t, transitions, 2)
+}
+
 func TestRecordReload_CounterAndHistogram(t *testing.T) {
 	inst, tp := activeProvider(t)
 	ctx := context.Background()
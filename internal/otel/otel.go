@@ -3,8 +3,11 @@ package otel
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
 
 	semconv "github.com/TykTechnologies/opentelemetry/semconv/v1.0.0"
 	tyktrace "github.com/TykTechnologies/opentelemetry/trace"
@@ -106,6 +109,54 @@ func OriginalPathSpanAttribute(path string) SpanAttribute {
 	return tyktrace.NewAttribute("tyk.original_path", path)
 }
 
+// UpstreamAttemptsAttribute creates a span attribute for the number of upstream
+// attempts made for a request that was retried.
+func UpstreamAttemptsAttribute(attempts int) SpanAttribute {
+	return tyktrace.NewAttribute("tyk.upstream.attempts", attempts)
+}
+
+// UpstreamAttemptEvent is the name of the span events recording the attempts
+// of a retried or hedged request.
+const UpstreamAttemptEvent = "tyk.upstream.attempt"
+
+// UpstreamAttempt describes a single upstream attempt of a request.
+type UpstreamAttempt struct {
+	// Number is the 1-based position of the attempt.
+	Number int
+	// Target is the upstream host the attempt was sent to.
+	Target string
+	// Status is the response status code, zero if the attempt failed.
+	Status int
+	// Error is the response flag of a failed attempt.
+	Error     string
+	Latency   time.Duration
+	Hedged    bool
+	Cancelled bool
+}
+
+// AddUpstreamAttemptEvent adds an event describing attempt to span.
+func AddUpstreamAttemptEvent(span tyktrace.Span, attempt UpstreamAttempt) {
+	attrs := []attribute.KeyValue{
+		attribute.Int("tyk.upstream.attempt", attempt.Number),
+		attribute.String("server.address", attempt.Target),
+		attribute.Int64("tyk.upstream.latency_ms", attempt.Latency.Milliseconds()),
+	}
+	if attempt.Status != 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", attempt.Status))
+	}
+	if attempt.Error != "" {
+		attrs = append(attrs, attribute.String("error.type", attempt.Error))
+	}
+	if attempt.Hedged {
+		attrs = append(attrs, attribute.Bool("tyk.upstream.hedged", true))
+	}
+	if attempt.Cancelled {
+		attrs = append(attrs, attribute.Bool("tyk.upstream.cancelled", true))
+	}
+
+	span.AddEvent(UpstreamAttemptEvent, oteltrace.WithAttributes(attrs...))
+}
+
 var APIKeyAttribute = semconv.TykAPIKey
 
 var APIKeyAliasAttribute = semconv.TykAPIKeyAlias
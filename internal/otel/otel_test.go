@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/TykTechnologies/tyk-pump/logger"
 
@@ -17,6 +18,10 @@ import (
 	"github.com/TykTechnologies/tyk/apidef"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"google.golang.org/grpc"
 )
 
@@ -694,3 +699,33 @@ func TestExtractTraceAndSpanID(t *testing.T) {
 		assert.Len(t, spanID, 16, "span_id should be 16 characters long")
 	})
 }
+
+func TestAddUpstreamAttemptEvent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "request")
+	AddUpstreamAttemptEvent(span, UpstreamAttempt{Number: 1, Target: "a:80", Error: "UCF", Latency: 5 * time.Millisecond})
+	AddUpstreamAttemptEvent(span, UpstreamAttempt{Number: 2, Target: "b:80", Status: http.StatusOK, Hedged: true})
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	events := spans[0].Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, UpstreamAttemptEvent, events[0].Name)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.Int("tyk.upstream.attempt", 1),
+		attribute.String("server.address", "a:80"),
+		attribute.Int64("tyk.upstream.latency_ms", 5),
+		attribute.String("error.type", "UCF"),
+	}, events[0].Attributes)
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.Int("tyk.upstream.attempt", 2),
+		attribute.String("server.address", "b:80"),
+		attribute.Int64("tyk.upstream.latency_ms", 0),
+		attribute.Int("http.response.status_code", http.StatusOK),
+		attribute.Bool("tyk.upstream.hedged", true),
+	}, events[1].Attributes)
+}
@@ -0,0 +1,158 @@
+package pdp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/cache"
+)
+
+// DefaultCacheMaxEntries is the number of decisions a CachedEvaluator holds
+// when CacheOptions doesn't configure it.
+const DefaultCacheMaxEntries = 10000
+
+// DefaultCredentialHeaders are the request headers holding credentials that
+// are part of cache keys unless decisions are cached across clients.
+var DefaultCredentialHeaders = []string{"authorization", "proxy-authorization", "cookie", "x-api-key"}
+
+// volatileClaims are the claims that change with every issued token and are
+// left out of cache keys when decisions are cached across clients.
+var volatileClaims = []string{"exp", "iat", "nbf", "jti"}
+
+// CacheOptions configures a CachedEvaluator.
+type CacheOptions struct {
+	// TTL is how long decisions are cached.
+	TTL time.Duration
+	// MaxEntries bounds the number of cached decisions, the least recently
+	// used decisions are evicted first. Defaults to DefaultCacheMaxEntries.
+	MaxEntries int
+	// Headers are the names of the request headers that are part of the
+	// cache key. Other headers aren't, except for the credential headers.
+	Headers []string
+	// CredentialHeaders are the names of the request headers holding
+	// credentials, in addition to DefaultCredentialHeaders.
+	CredentialHeaders []string
+	// AcrossClients leaves the client IP, the credential headers and the
+	// claims that change with every issued token out of the cache key, so
+	// that decisions are shared by the clients making the same request.
+	AcrossClients bool
+}
+
+// CachedEvaluator caches the decisions of an evaluator by the cache key of
+// their input. Errors aren't cached.
+type CachedEvaluator struct {
+	evaluator Evaluator
+	cache     *cache.SizedLRU
+	ttl       time.Duration
+	headers   []string
+	// acrossClients is set when decisions are shared by clients.
+	acrossClients bool
+}
+
+// NewCachedEvaluator caches the decisions of evaluator as configured by opts.
+func NewCachedEvaluator(evaluator Evaluator, opts CacheOptions) *CachedEvaluator {
+	maxEntries := opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+
+	var headers []string
+	for _, name := range opts.Headers {
+		headers = append(headers, strings.ToLower(name))
+	}
+
+	if !opts.AcrossClients {
+		headers = append(headers, DefaultCredentialHeaders...)
+		for _, name := range opts.CredentialHeaders {
+			headers = append(headers, strings.ToLower(name))
+		}
+	}
+
+	return &CachedEvaluator{
+		evaluator: evaluator,
+		// every decision has a size of 1, bounding the number of entries
+		cache:         cache.NewSizedLRU(int64(maxEntries)),
+		ttl:           opts.TTL,
+		headers:       headers,
+		acrossClients: opts.AcrossClients,
+	}
+}
+
+// Evaluate implements Evaluator.
+func (e *CachedEvaluator) Evaluate(ctx context.Context, input *Input) (*Decision, error) {
+	key, err := CacheKey(input, e.headers, e.acrossClients)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := e.cache.Get(key); ok {
+		return cached.(*Decision), nil
+	}
+
+	decision, err := e.evaluator.Evaluate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	e.cache.Set(key, decision, 1, e.ttl)
+
+	return decision, nil
+}
+
+// Close implements Evaluator.
+func (e *CachedEvaluator) Close() error {
+	e.cache.Flush()
+	return e.evaluator.Close()
+}
+
+// CacheKey returns the SHA-256 hash of the JSON encoding of a normalized copy
+// of input. The copy only holds the request headers named in headers, which
+// must be lower case. When acrossClients is set, it also leaves out the client
+// IP and the claims that change with every issued token. Maps are encoded with
+// sorted keys, so equal inputs have equal keys.
+func CacheKey(input *Input, headers []string, acrossClients bool) (string, error) {
+	normalized := *input
+
+	normalized.Request.Headers = nil
+	for _, name := range headers {
+		value, ok := input.Request.Headers[name]
+		if !ok {
+			continue
+		}
+		if normalized.Request.Headers == nil {
+			normalized.Request.Headers = make(map[string]string, len(headers))
+		}
+		normalized.Request.Headers[name] = value
+	}
+
+	if !acrossClients {
+		return hashInput(normalized)
+	}
+
+	normalized.Request.ClientIP = ""
+	if len(input.Claims) > 0 {
+		normalized.Claims = make(map[string]interface{}, len(input.Claims))
+		for name, value := range input.Claims {
+			normalized.Claims[name] = value
+		}
+		for _, name := range volatileClaims {
+			delete(normalized.Claims, name)
+		}
+	}
+
+	return hashInput(normalized)
+}
+
+func hashInput(input Input) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
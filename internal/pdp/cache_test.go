@@ -0,0 +1,171 @@
+package pdp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingEvaluator struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (e *countingEvaluator) Evaluate(context.Context, *Input) (*Decision, error) {
+	e.calls.Add(1)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &Decision{Allow: true}, nil
+}
+
+func (e *countingEvaluator) Close() error {
+	return nil
+}
+
+func TestCachedEvaluator(t *testing.T) {
+	evaluator := &countingEvaluator{}
+	e := NewCachedEvaluator(evaluator, CacheOptions{TTL: time.Minute, Headers: []string{"X-Tenant"}})
+	defer e.Close()
+
+	for i := 0; i < 3; i++ {
+		decision, err := e.Evaluate(context.Background(), testInput())
+		require.NoError(t, err)
+		assert.True(t, decision.Allow)
+	}
+	assert.Equal(t, int32(1), evaluator.calls.Load())
+
+	// different inputs get their own decisions
+	input := testInput()
+	input.Request.Path = "/orders/2"
+	_, err := e.Evaluate(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), evaluator.calls.Load())
+
+	// headers outside of the cache key don't matter
+	input = testInput()
+	input.Request.Headers["user-agent"] = "curl"
+	_, err = e.Evaluate(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), evaluator.calls.Load())
+
+	input.Request.Headers["x-tenant"] = "other"
+	_, err = e.Evaluate(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), evaluator.calls.Load())
+
+	t.Run("clients", func(t *testing.T) {
+		evaluator := &countingEvaluator{}
+		e := NewCachedEvaluator(evaluator, CacheOptions{TTL: time.Minute, CredentialHeaders: []string{"X-Token"}})
+		defer e.Close()
+
+		// the client IP and the credential headers select decisions
+		for _, modify := range []func(*Input){
+			func(*Input) {},
+			func(input *Input) { input.Request.ClientIP = "10.0.0.1" },
+			func(input *Input) { input.Request.Headers["authorization"] = "Bearer other" },
+			func(input *Input) { input.Request.Headers["x-token"] = "other" },
+		} {
+			input := testInput()
+			modify(input)
+			_, err := e.Evaluate(context.Background(), input)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(4), evaluator.calls.Load())
+	})
+
+	t.Run("across clients", func(t *testing.T) {
+		evaluator := &countingEvaluator{}
+		e := NewCachedEvaluator(evaluator, CacheOptions{TTL: time.Minute, AcrossClients: true})
+		defer e.Close()
+
+		for _, clientIP := range []string{"10.0.0.1", "10.0.0.2"} {
+			input := testInput()
+			input.Request.ClientIP = clientIP
+			input.Request.Headers["authorization"] = "Bearer " + clientIP
+			_, err := e.Evaluate(context.Background(), input)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), evaluator.calls.Load())
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		evaluator := &countingEvaluator{}
+		e := NewCachedEvaluator(evaluator, CacheOptions{TTL: time.Minute, MaxEntries: 1})
+		defer e.Close()
+
+		other := testInput()
+		other.Request.Path = "/orders/2"
+		for _, input := range []*Input{testInput(), other, testInput()} {
+			_, err := e.Evaluate(context.Background(), input)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(3), evaluator.calls.Load())
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		evaluator := &countingEvaluator{}
+		e := NewCachedEvaluator(evaluator, CacheOptions{TTL: time.Millisecond})
+		defer e.Close()
+
+		for i := 0; i < 2; i++ {
+			_, err := e.Evaluate(context.Background(), testInput())
+			require.NoError(t, err)
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.Equal(t, int32(2), evaluator.calls.Load())
+	})
+
+	t.Run("errors aren't cached", func(t *testing.T) {
+		failing := &countingEvaluator{err: ErrUnavailable}
+		e := NewCachedEvaluator(failing, CacheOptions{TTL: time.Minute})
+		defer e.Close()
+
+		for i := 0; i < 2; i++ {
+			_, err := e.Evaluate(context.Background(), testInput())
+			assert.ErrorIs(t, err, ErrUnavailable)
+		}
+		assert.Equal(t, int32(2), failing.calls.Load())
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	headers := []string{"x-tenant"}
+
+	a, err := CacheKey(testInput(), headers, true)
+	require.NoError(t, err)
+	b, err := CacheKey(testInput(), headers, true)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	input := testInput()
+	input.Claims["sub"] = "bob"
+	c, err := CacheKey(input, headers, true)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	input = testInput()
+	input.Claims["exp"] = 1700000000
+	input.Claims["jti"] = "token-1"
+	input.Request.Headers["x-request-id"] = "1"
+	input.Request.ClientIP = "10.0.0.1"
+	d, err := CacheKey(input, headers, true)
+	require.NoError(t, err)
+	assert.Equal(t, a, d)
+	assert.Equal(t, 1700000000, input.Claims["exp"], "input must not be modified")
+
+	e, err := CacheKey(testInput(), nil, true)
+	require.NoError(t, err)
+	assert.NotEqual(t, a, e)
+
+	// the client IP and token claims are kept unless caching across clients
+	f, err := CacheKey(input, headers, false)
+	require.NoError(t, err)
+	g, err := CacheKey(testInput(), headers, false)
+	require.NoError(t, err)
+	assert.NotEqual(t, f, g)
+}
@@ -0,0 +1,114 @@
+package pdp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+const (
+	// PolicyFunction is the function embedded policies must define. It's
+	// called with the input document and returns a boolean or a Decision
+	// object.
+	PolicyFunction = "authorize"
+
+	// DefaultEmbeddedTimeout is how long embedded policies may run when the
+	// context has no deadline.
+	DefaultEmbeddedTimeout = time.Second
+)
+
+// ErrPolicyTimeout is returned when an embedded policy runs for too long.
+var ErrPolicyTimeout = errors.New("policy evaluation timed out")
+
+// EmbeddedEvaluator evaluates JavaScript policies in the gateway. The policy
+// is compiled once and run on a fresh runtime for each decision, so that
+// concurrent evaluations don't share state.
+type EmbeddedEvaluator struct {
+	program *goja.Program
+}
+
+// NewEmbeddedEvaluator compiles policy, which must define the PolicyFunction.
+func NewEmbeddedEvaluator(policy string) (*EmbeddedEvaluator, error) {
+	program, err := goja.Compile("policy", policy, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	e := &EmbeddedEvaluator{program: program}
+	if _, _, err := e.runtime(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Evaluate implements Evaluator.
+func (e *EmbeddedEvaluator) Evaluate(ctx context.Context, input *Input) (*Decision, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	vm, authorize, err := e.runtime()
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := DefaultEmbeddedTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	timer := time.AfterFunc(timeout, func() {
+		vm.Interrupt("timeout")
+	})
+	defer timer.Stop()
+
+	value, err := authorize(goja.Undefined(), vm.ToValue(document))
+	if err != nil {
+		var interrupted *goja.InterruptedError
+		if errors.As(err, &interrupted) {
+			return nil, ErrPolicyTimeout
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDecision, err)
+	}
+
+	if goja.IsUndefined(value) || goja.IsNull(value) {
+		return decodeDecision(nil)
+	}
+
+	result, err := json.Marshal(value.Export())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDecision, err)
+	}
+
+	return decodeDecision(result)
+}
+
+// Close implements Evaluator.
+func (e *EmbeddedEvaluator) Close() error {
+	return nil
+}
+
+// runtime returns a new runtime running the policy and its PolicyFunction.
+func (e *EmbeddedEvaluator) runtime() (*goja.Runtime, goja.Callable, error) {
+	vm := goja.New()
+	if _, err := vm.RunProgram(e.program); err != nil {
+		return nil, nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	authorize, ok := goja.AssertFunction(vm.Get(PolicyFunction))
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid policy: %s function isn't defined", PolicyFunction)
+	}
+
+	return vm, authorize, nil
+}
@@ -0,0 +1,80 @@
+package pdp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedEvaluator(t *testing.T) {
+	t.Run("invalid policies", func(t *testing.T) {
+		_, err := NewEmbeddedEvaluator("function authorize(input) {")
+		assert.Error(t, err)
+
+		_, err = NewEmbeddedEvaluator("function allow(input) { return true }")
+		assert.Error(t, err)
+	})
+
+	t.Run("boolean decision", func(t *testing.T) {
+		e, err := NewEmbeddedEvaluator(`function authorize(input) {
+			return (input.session.tags || []).indexOf("admin") >= 0 && input.endpoint.operationId === "getOrder";
+		}`)
+		require.NoError(t, err)
+
+		decision, err := e.Evaluate(context.Background(), testInput())
+		require.NoError(t, err)
+		assert.True(t, decision.Allow)
+
+		input := testInput()
+		input.Session.Tags = nil
+		decision, err = e.Evaluate(context.Background(), input)
+		require.NoError(t, err)
+		assert.False(t, decision.Allow)
+	})
+
+	t.Run("decision object", func(t *testing.T) {
+		e, err := NewEmbeddedEvaluator(`function authorize(input) {
+			if (input.request.method !== "GET") {
+				return {allow: false, status: 405, reason: "read only"};
+			}
+			return {allow: true, headers: {"X-Tenant": input.request.headers["x-tenant"]}};
+		}`)
+		require.NoError(t, err)
+
+		decision, err := e.Evaluate(context.Background(), testInput())
+		require.NoError(t, err)
+		assert.Equal(t, &Decision{Allow: true, Headers: map[string]string{"X-Tenant": "acme"}}, decision)
+
+		input := testInput()
+		input.Request.Method = http.MethodDelete
+		decision, err = e.Evaluate(context.Background(), input)
+		require.NoError(t, err)
+		assert.False(t, decision.Allow)
+		assert.Equal(t, http.StatusMethodNotAllowed, decision.DeniedStatus())
+		assert.Equal(t, "read only", decision.Reason)
+	})
+
+	t.Run("undefined decision", func(t *testing.T) {
+		e, err := NewEmbeddedEvaluator("function authorize(input) {}")
+		require.NoError(t, err)
+
+		decision, err := e.Evaluate(context.Background(), testInput())
+		require.NoError(t, err)
+		assert.False(t, decision.Allow)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		e, err := NewEmbeddedEvaluator("function authorize(input) { for (;;) {} }")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err = e.Evaluate(ctx, testInput())
+		assert.ErrorIs(t, err, ErrPolicyTimeout)
+	})
+}
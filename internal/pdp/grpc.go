@@ -0,0 +1,137 @@
+package pdp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MetadataNamespace is the filter metadata namespace holding the input
+// document in the requests sent to gRPC policy decision points.
+const MetadataNamespace = "tyk"
+
+// GRPCEvaluator gets decisions from a policy decision point implementing the
+// Envoy external authorization service, e.g. OPA with its Envoy plugin. The
+// request attributes are sent as HTTP request attributes and the full input
+// document as filter metadata in the MetadataNamespace namespace.
+type GRPCEvaluator struct {
+	conn   *grpc.ClientConn
+	client authv3.AuthorizationClient
+}
+
+// NewGRPCEvaluator creates an evaluator sending check requests to target.
+func NewGRPCEvaluator(target string, opts ...grpc.DialOption) (*GRPCEvaluator, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GRPCEvaluator{conn: conn, client: authv3.NewAuthorizationClient(conn)}, nil
+}
+
+// Evaluate implements Evaluator.
+func (e *GRPCEvaluator) Evaluate(ctx context.Context, input *Input) (*Decision, error) {
+	req, err := checkRequest(input)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.Check(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	return checkDecision(resp), nil
+}
+
+// Close implements Evaluator.
+func (e *GRPCEvaluator) Close() error {
+	return e.conn.Close()
+}
+
+// checkRequest converts input to an Envoy check request.
+func checkRequest(input *Input) (*authv3.CheckRequest, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	metadata, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	path := input.Request.Path
+	if len(input.Request.Query) > 0 {
+		path += "?" + url.Values(input.Request.Query).Encode()
+	}
+
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Request: &authv3.AttributeContext_Request{
+				Time: timestamppb.Now(),
+				Http: &authv3.AttributeContext_HttpRequest{
+					Method:  input.Request.Method,
+					Headers: input.Request.Headers,
+					Path:    path,
+					Host:    input.Request.Host,
+					Scheme:  input.Request.Scheme,
+				},
+			},
+			MetadataContext: &corev3.Metadata{
+				FilterMetadata: map[string]*structpb.Struct{MetadataNamespace: metadata},
+			},
+		},
+	}, nil
+}
+
+// checkDecision converts an Envoy check response to a decision.
+func checkDecision(resp *authv3.CheckResponse) *Decision {
+	decision := &Decision{Allow: codes.Code(resp.GetStatus().GetCode()) == codes.OK}
+
+	if ok := resp.GetOkResponse(); ok != nil {
+		decision.Headers = headerValues(ok.GetHeaders())
+		decision.RemoveHeaders = ok.GetHeadersToRemove()
+	}
+
+	if denied := resp.GetDeniedResponse(); denied != nil {
+		decision.Status = int(denied.GetStatus().GetCode())
+		decision.Reason = denied.GetBody()
+		decision.ResponseHeaders = headerValues(denied.GetHeaders())
+	}
+
+	if !decision.Allow && decision.Reason == "" {
+		decision.Reason = strings.TrimSpace(resp.GetStatus().GetMessage())
+	}
+
+	return decision
+}
+
+func headerValues(options []*corev3.HeaderValueOption) map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(options))
+	for _, option := range options {
+		if header := option.GetHeader(); header != nil {
+			headers[header.GetKey()] = header.GetValue()
+		}
+	}
+
+	return headers
+}
@@ -0,0 +1,79 @@
+package pdp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type authorizationServer struct {
+	authv3.UnimplementedAuthorizationServer
+}
+
+// Check allows members of the admin group, which is read from the input
+// document, and denies other requests.
+func (s *authorizationServer) Check(_ context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	input := req.GetAttributes().GetMetadataContext().GetFilterMetadata()[MetadataNamespace].AsMap()
+	tags, _ := input["session"].(map[string]interface{})["tags"].([]interface{})
+
+	if req.GetAttributes().GetRequest().GetHttp().GetPath() == "/orders/1?expand=items" && len(tags) > 0 && tags[0] == "admin" {
+		return &authv3.CheckResponse{
+			Status: &status.Status{Code: int32(codes.OK)},
+			HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: &authv3.OkHttpResponse{
+				Headers:         []*corev3.HeaderValueOption{{Header: &corev3.HeaderValue{Key: "X-User", Value: "alice"}}},
+				HeadersToRemove: []string{"Authorization"},
+			}},
+		}, nil
+	}
+
+	return &authv3.CheckResponse{
+		Status: &status.Status{Code: int32(codes.PermissionDenied)},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+			Status: &typev3.HttpStatus{Code: typev3.StatusCode_Unauthorized},
+			Body:   "admins only",
+		}},
+	}, nil
+}
+
+func TestGRPCEvaluator(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	authv3.RegisterAuthorizationServer(server, &authorizationServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	e, err := NewGRPCEvaluator(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer e.Close()
+
+	decision, err := e.Evaluate(context.Background(), testInput())
+	require.NoError(t, err)
+	assert.Equal(t, &Decision{
+		Allow:         true,
+		Headers:       map[string]string{"X-User": "alice"},
+		RemoveHeaders: []string{"Authorization"},
+	}, decision)
+
+	input := testInput()
+	input.Session.Tags = []string{"user"}
+	decision, err = e.Evaluate(context.Background(), input)
+	require.NoError(t, err)
+	assert.False(t, decision.Allow)
+	assert.Equal(t, http.StatusUnauthorized, decision.DeniedStatus())
+	assert.Equal(t, "admins only", decision.Reason)
+}
@@ -0,0 +1,98 @@
+package pdp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxResponseSize is the size of the largest decision read from policy
+// decision points.
+const maxResponseSize = 1 << 20
+
+// HTTPEvaluator gets decisions from a policy decision point implementing the
+// Open Policy Agent data API. The input is posted as `{"input": ...}` and the
+// decision is read from the `result` member of the response, either a boolean
+// or a Decision object. Undefined results deny requests.
+type HTTPEvaluator struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPEvaluator creates an evaluator posting inputs to url with client.
+func NewHTTPEvaluator(url string, client *http.Client) *HTTPEvaluator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &HTTPEvaluator{url: url, client: client}
+}
+
+// Evaluate implements Evaluator.
+func (e *HTTPEvaluator) Evaluate(ctx context.Context, input *Input) (*Decision, error) {
+	body, err := json.Marshal(struct {
+		Input *Input `json:"input"`
+	}{input})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDecision, err)
+	}
+
+	return decodeDecision(result.Result)
+}
+
+// Close implements Evaluator.
+func (e *HTTPEvaluator) Close() error {
+	return nil
+}
+
+// decodeDecision decodes a boolean or a Decision object, undefined decisions
+// deny requests.
+func decodeDecision(data json.RawMessage) (*Decision, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return &Decision{Reason: "undefined policy decision"}, nil
+	}
+
+	var allow bool
+	if err := json.Unmarshal(data, &allow); err == nil {
+		return &Decision{Allow: allow}, nil
+	}
+
+	var decision Decision
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDecision, err)
+	}
+
+	return &decision, nil
+}
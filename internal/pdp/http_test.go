@@ -0,0 +1,117 @@
+package pdp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInput() *Input {
+	return &Input{
+		Request: Request{
+			Method:  http.MethodGet,
+			Scheme:  "https",
+			Host:    "api.example.com",
+			Path:    "/orders/1",
+			Query:   map[string][]string{"expand": {"items"}},
+			Headers: map[string]string{"x-tenant": "acme"},
+		},
+		Session: &Session{Alias: "alice", Tags: []string{"admin"}},
+		Claims:  map[string]interface{}{"sub": "alice"},
+		API:     API{ID: "orders", Name: "Orders", ListenPath: "/orders/"},
+		Endpoint: Endpoint{
+			Path:        "/orders/{id}",
+			OperationID: "getOrder",
+			Params:      map[string]string{"id": "1"},
+		},
+	}
+}
+
+func TestHTTPEvaluator(t *testing.T) {
+	var result string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input Input `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, *testInput(), body.Input)
+
+		_, _ = w.Write([]byte(result))
+	}))
+	defer server.Close()
+
+	e := NewHTTPEvaluator(server.URL, nil)
+	defer e.Close()
+
+	evaluate := func(response string) (*Decision, error) {
+		result = response
+		return e.Evaluate(context.Background(), testInput())
+	}
+
+	t.Run("boolean result", func(t *testing.T) {
+		decision, err := evaluate(`{"result": true}`)
+		require.NoError(t, err)
+		assert.True(t, decision.Allow)
+
+		decision, err = evaluate(`{"result": false}`)
+		require.NoError(t, err)
+		assert.False(t, decision.Allow)
+	})
+
+	t.Run("decision result", func(t *testing.T) {
+		decision, err := evaluate(`{"result": {"allow": true, "headers": {"X-User": "alice"}, "removeHeaders": ["Authorization"]}}`)
+		require.NoError(t, err)
+		assert.Equal(t, &Decision{
+			Allow:         true,
+			Headers:       map[string]string{"X-User": "alice"},
+			RemoveHeaders: []string{"Authorization"},
+		}, decision)
+
+		decision, err = evaluate(`{"result": {"allow": false, "status": 401, "reason": "not a member"}}`)
+		require.NoError(t, err)
+		assert.False(t, decision.Allow)
+		assert.Equal(t, http.StatusUnauthorized, decision.DeniedStatus())
+		assert.Equal(t, "not a member", decision.Reason)
+	})
+
+	t.Run("undefined result", func(t *testing.T) {
+		decision, err := evaluate(`{}`)
+		require.NoError(t, err)
+		assert.False(t, decision.Allow)
+	})
+
+	t.Run("invalid result", func(t *testing.T) {
+		_, err := evaluate(`{"result": "yes"}`)
+		assert.ErrorIs(t, err, ErrInvalidDecision)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		e := NewHTTPEvaluator("http://127.0.0.1:1", nil)
+		_, err := e.Evaluate(context.Background(), testInput())
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+}
+
+func TestDecision_Apply(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer token")
+	header.Set("X-User", "mallory")
+
+	decision := &Decision{
+		Allow:         true,
+		Headers:       map[string]string{"X-User": "alice"},
+		RemoveHeaders: []string{"Authorization"},
+	}
+	decision.Apply(header)
+
+	assert.Empty(t, header.Get("Authorization"))
+	assert.Equal(t, "alice", header.Get("X-User"))
+
+	assert.Equal(t, http.StatusForbidden, (&Decision{}).DeniedStatus())
+	assert.Equal(t, http.StatusForbidden, (&Decision{Status: 200}).DeniedStatus())
+}
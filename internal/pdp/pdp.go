@@ -0,0 +1,134 @@
+// Package pdp sends authorization requests to policy decision points, which
+// allow or deny requests based on a structured input document describing the
+// request, its session and the API, e.g. an Open Policy Agent server.
+package pdp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	// ErrInvalidDecision is returned when a policy decision point returns a
+	// malformed decision.
+	ErrInvalidDecision = errors.New("invalid policy decision")
+	// ErrUnavailable is returned when a policy decision point can't be reached.
+	ErrUnavailable = errors.New("policy decision point unavailable")
+)
+
+// Evaluator makes policy decisions.
+type Evaluator interface {
+	// Evaluate returns the decision for input.
+	Evaluate(ctx context.Context, input *Input) (*Decision, error)
+	// Close releases the resources of the evaluator.
+	Close() error
+}
+
+// Input is the document policies make decisions for.
+type Input struct {
+	// Request describes the request.
+	Request Request `json:"request"`
+	// Session describes the session of authenticated requests.
+	Session *Session `json:"session,omitempty"`
+	// Claims holds the claims of the JWT or introspected access token of the request.
+	Claims map[string]interface{} `json:"claims,omitempty"`
+	// API describes the API the request is made to.
+	API API `json:"api"`
+	// Endpoint describes the endpoint the request is made to.
+	Endpoint Endpoint `json:"endpoint"`
+}
+
+// Request describes the request of an Input.
+type Request struct {
+	// Method is the HTTP method of the request.
+	Method string `json:"method"`
+	// Scheme is the scheme of the request, http or https.
+	Scheme string `json:"scheme"`
+	// Host is the host of the request.
+	Host string `json:"host"`
+	// Path is the path of the request.
+	Path string `json:"path"`
+	// Query holds the query parameters of the request.
+	Query map[string][]string `json:"query,omitempty"`
+	// Headers holds the headers of the request with lower case names, the
+	// values of repeated headers are joined with commas.
+	Headers map[string]string `json:"headers,omitempty"`
+	// ClientIP is the IP address of the client.
+	ClientIP string `json:"clientIp"`
+}
+
+// Session describes the session of an Input.
+type Session struct {
+	// KeyHash is the hash of the key of the session.
+	KeyHash string `json:"keyHash,omitempty"`
+	// Alias is the alias of the session.
+	Alias string `json:"alias,omitempty"`
+	// OrgID is the organisation of the session.
+	OrgID string `json:"orgId,omitempty"`
+	// Policies are the IDs of the policies applied to the session.
+	Policies []string `json:"policies,omitempty"`
+	// Tags are the tags of the session.
+	Tags []string `json:"tags,omitempty"`
+	// MetaData is the metadata of the session.
+	MetaData map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// API describes the API of an Input.
+type API struct {
+	// ID is the ID of the API.
+	ID string `json:"id"`
+	// Name is the name of the API.
+	Name string `json:"name"`
+	// OrgID is the organisation of the API.
+	OrgID string `json:"orgId"`
+	// ListenPath is the listen path of the API.
+	ListenPath string `json:"listenPath"`
+	// Tags are the tags of the API.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Endpoint describes the endpoint of an Input.
+type Endpoint struct {
+	// Path is the path of the endpoint, relative to the listen path. For OAS
+	// APIs it's the path template of the matched operation.
+	Path string `json:"path"`
+	// OperationID is the ID of the matched operation of OAS APIs.
+	OperationID string `json:"operationId,omitempty"`
+	// Params holds the path parameters of the matched operation of OAS APIs.
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Decision is a policy decision.
+type Decision struct {
+	// Allow is true when the request is allowed.
+	Allow bool `json:"allow"`
+	// Status is the response status code of denied requests, defaults to 403.
+	Status int `json:"status,omitempty"`
+	// Reason is the message returned to the client of denied requests.
+	Reason string `json:"reason,omitempty"`
+	// Headers are set on allowed requests before they are proxied.
+	Headers map[string]string `json:"headers,omitempty"`
+	// RemoveHeaders are removed from allowed requests before they are proxied.
+	RemoveHeaders []string `json:"removeHeaders,omitempty"`
+	// ResponseHeaders are set on the responses of denied requests.
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+}
+
+// DeniedStatus returns the response status code of denied requests.
+func (d *Decision) DeniedStatus() int {
+	if d.Status < 400 || d.Status > 599 {
+		return http.StatusForbidden
+	}
+	return d.Status
+}
+
+// Apply sets and removes the header mutations of the decision on header.
+func (d *Decision) Apply(header http.Header) {
+	for _, name := range d.RemoveHeaders {
+		header.Del(name)
+	}
+	for name, value := range d.Headers {
+		header.Set(name, value)
+	}
+}
@@ -55,8 +55,10 @@ func (t *Service) ClearSession(session *user.SessionState) error {
 			session.Rate = 0
 			session.Per = 0
 			session.Smoothing = nil
+			session.RateLimitAlgorithm = ""
 			session.ThrottleRetryLimit = 0
 			session.ThrottleInterval = 0
+			session.MaxConcurrentRequests = 0
 		}
 
 		if policy.Partitions.Complexity || all {
@@ -215,8 +217,10 @@ func (t *Service) Apply(session *user.SessionState) error {
 			v.Limit.Rate = session.Rate
 			v.Limit.Per = session.Per
 			v.Limit.Smoothing = session.Smoothing
+			v.Limit.Algorithm = session.RateLimitAlgorithm
 			v.Limit.ThrottleInterval = session.ThrottleInterval
 			v.Limit.ThrottleRetryLimit = session.ThrottleRetryLimit
+			v.Limit.MaxConcurrentRequests = session.MaxConcurrentRequests
 			v.Endpoints = nil
 		}
 
@@ -287,6 +291,7 @@ func (t *Service) ApplyRateLimits(session *user.SessionState, policy user.Policy
 		apiLimits.Rate = policyLimits.Rate
 		apiLimits.Per = policyLimits.Per
 		apiLimits.Smoothing = policyLimits.Smoothing
+		apiLimits.Algorithm = policyLimits.Algorithm
 	}
 
 	// sessionLimits, similar to apiLimits, get policy
@@ -296,6 +301,7 @@ func (t *Service) ApplyRateLimits(session *user.SessionState, policy user.Policy
 		session.Rate = policyLimits.Rate
 		session.Per = policyLimits.Per
 		session.Smoothing = policyLimits.Smoothing
+		session.RateLimitAlgorithm = policyLimits.Algorithm
 	}
 }
 
@@ -541,6 +547,13 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 					session.ThrottleInterval = policy.ThrottleInterval
 				}
 			}
+
+			if policy.MaxConcurrentRequests > ar.Limit.MaxConcurrentRequests {
+				ar.Limit.MaxConcurrentRequests = policy.MaxConcurrentRequests
+				if policy.MaxConcurrentRequests > session.MaxConcurrentRequests {
+					session.MaxConcurrentRequests = policy.MaxConcurrentRequests
+				}
+			}
 		}
 
 		if !usePartitions || policy.Partitions.Complexity {
@@ -568,8 +581,10 @@ func (t *Service) applyPartitions(policy user.Policy, session *user.SessionState
 			session.Rate = policy.Rate
 			session.Per = policy.Per
 			session.Smoothing = policy.Smoothing
+			session.RateLimitAlgorithm = policy.RateLimitAlgorithm
 			session.ThrottleInterval = policy.ThrottleInterval
 			session.ThrottleRetryLimit = policy.ThrottleRetryLimit
+			session.MaxConcurrentRequests = policy.MaxConcurrentRequests
 		}
 
 		if !usePartitions || policy.Partitions.Complexity {
@@ -602,6 +617,8 @@ func (t *Service) updateSessionRootVars(session *user.SessionState, rights map[s
 				session.Rate = v.Limit.Rate
 				session.Per = v.Limit.Per
 				session.Smoothing = v.Limit.Smoothing
+				session.RateLimitAlgorithm = v.Limit.Algorithm
+				session.MaxConcurrentRequests = v.Limit.MaxConcurrentRequests
 			}
 
 			if len(applyState.didQuota) == 1 {
@@ -623,6 +640,7 @@ func (t *Service) applyAPILevelLimits(policyAD user.AccessDefinition, currAD use
 		policyAD.Limit.Per = currAD.Limit.Per
 		policyAD.Limit.Rate = currAD.Limit.Rate
 		policyAD.Limit.Smoothing = currAD.Limit.Smoothing
+		policyAD.Limit.Algorithm = currAD.Limit.Algorithm
 		updated = true
 	}
 
@@ -631,6 +649,10 @@ func (t *Service) applyAPILevelLimits(policyAD user.AccessDefinition, currAD use
 		updated = true
 	}
 
+	if currAD.Limit.MaxConcurrentRequests > policyAD.Limit.MaxConcurrentRequests {
+		policyAD.Limit.MaxConcurrentRequests = currAD.Limit.MaxConcurrentRequests
+	}
+
 	if greaterThanInt64(currAD.Limit.QuotaRenewalRate, policyAD.Limit.QuotaRenewalRate) {
 		policyAD.Limit.QuotaRenewalRate = currAD.Limit.QuotaRenewalRate
 	}
@@ -873,6 +873,31 @@ func testPrepareApplyPolicies(tb testing.TB) (*policy.Service, []testApplyPolici
 	}
 	tests = append(tests, throttleTCs...)
 
+	concurrencyTCs := []testApplyPoliciesData{
+		{
+			name:     "Max concurrent requests from policy",
+			policies: []string{"concurrency1"},
+			sessMatch: func(t *testing.T, s *user.SessionState) {
+				t.Helper()
+
+				assert.Equal(t, 5, s.MaxConcurrentRequests)
+				assert.Equal(t, 5, s.AccessRights["a"].Limit.MaxConcurrentRequests)
+			},
+		},
+		{
+			name:     "Highest max concurrent requests from policies",
+			policies: []string{"concurrency1", "concurrency2"},
+			sessMatch: func(t *testing.T, s *user.SessionState) {
+				t.Helper()
+
+				assert.Equal(t, 10, s.MaxConcurrentRequests)
+				assert.Equal(t, 10, s.AccessRights["a"].Limit.MaxConcurrentRequests)
+			},
+			reverseOrder: true,
+		},
+	}
+	tests = append(tests, concurrencyTCs...)
+
 	tagsTCs := []testApplyPoliciesData{
 		{
 			"TagMerge", []string{"tags1", "tags2"},
@@ -0,0 +1,346 @@
+// Package adaptive implements concurrency limiters that find the number of
+// requests an upstream can handle in flight from the observed round trip times
+// and failures, instead of relying on a configured rate.
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// The following constants name the supported limit algorithms.
+const (
+	// AlgorithmGradient adjusts the limit by the ratio of the long term to the
+	// current round trip time, shrinking it as soon as latency grows.
+	AlgorithmGradient = "gradient"
+	// AlgorithmAIMD grows the limit by one while requests succeed and cuts it
+	// by a tenth on failures.
+	AlgorithmAIMD = "aimd"
+)
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 1000
+	defaultQueueTimeout = time.Second
+
+	// backoffRatio is the factor the limit is multiplied with on failures.
+	backoffRatio = 0.9
+	// rttTolerance is how much the round trip time may exceed the long term
+	// average before the gradient limit shrinks.
+	rttTolerance = 1.5
+	// rttWindow is the number of samples averaged into the long term round trip time.
+	rttWindow = 100
+	// smoothing is the weight of a new gradient limit against the current one.
+	smoothing = 0.2
+)
+
+// ErrLimitExceeded is returned when a request is shed because the limit is
+// reached and it can't be queued.
+var ErrLimitExceeded = errors.New("adaptive concurrency limit exceeded")
+
+// ValidAlgorithm returns true if name is a supported algorithm. An empty name
+// selects AlgorithmGradient.
+func ValidAlgorithm(name string) bool {
+	switch name {
+	case "", AlgorithmGradient, AlgorithmAIMD:
+		return true
+	}
+	return false
+}
+
+// Config configures a Limiter. Zero values are replaced with defaults.
+type Config struct {
+	// Algorithm is one of AlgorithmGradient (default) or AlgorithmAIMD.
+	Algorithm string
+	// InitialLimit is the limit used before any samples are taken. Default: 20.
+	InitialLimit int
+	// MinLimit is the lowest the limit can go. Default: 1.
+	MinLimit int
+	// MaxLimit is the highest the limit can go. Default: 1000.
+	MaxLimit int
+	// QueueSize is the number of requests that can wait for a slot when the
+	// limit is reached. Requests are shed immediately when it's zero.
+	QueueSize int
+	// QueueTimeout is how long a request waits for a slot. Default: 1s.
+	QueueTimeout time.Duration
+	// OnLimitChange, when set, is called with the new limit whenever it changes.
+	OnLimitChange func(limit int)
+}
+
+func (c Config) withDefaults() Config {
+	if c.MinLimit <= 0 {
+		c.MinLimit = defaultMinLimit
+	}
+	if c.MaxLimit <= 0 {
+		c.MaxLimit = defaultMaxLimit
+	}
+	if c.MaxLimit < c.MinLimit {
+		c.MaxLimit = c.MinLimit
+	}
+	if c.InitialLimit <= 0 {
+		c.InitialLimit = defaultInitialLimit
+	}
+	c.InitialLimit = min(max(c.InitialLimit, c.MinLimit), c.MaxLimit)
+	if c.QueueTimeout <= 0 {
+		c.QueueTimeout = defaultQueueTimeout
+	}
+	return c
+}
+
+// Limiter limits the requests in flight to an upstream to a limit adjusted
+// with every completed request.
+type Limiter struct {
+	conf Config
+	now  func() time.Time
+
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+	longRTT  float64
+	waiters  []*waiter
+}
+
+type waiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewLimiter creates a limiter with conf.
+func NewLimiter(conf Config) *Limiter {
+	conf = conf.withDefaults()
+
+	return &Limiter{
+		conf:  conf,
+		now:   time.Now,
+		limit: float64(conf.InitialLimit),
+	}
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// Inflight returns the number of requests holding a slot.
+func (l *Limiter) Inflight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inflight
+}
+
+// Acquire takes a slot, waiting in the queue if the limit is reached. It
+// returns ErrLimitExceeded if the queue is full or the slot isn't freed in
+// time, and the context error if ctx is done while waiting.
+func (l *Limiter) Acquire(ctx context.Context) (*Token, error) {
+	l.mu.Lock()
+	if l.inflight < int(l.limit) {
+		token := l.take()
+		l.mu.Unlock()
+		return token, nil
+	}
+
+	if len(l.waiters) >= l.conf.QueueSize {
+		l.mu.Unlock()
+		return nil, ErrLimitExceeded
+	}
+
+	w := &waiter{ready: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.conf.QueueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-w.ready:
+	case <-timer.C:
+		err = ErrLimitExceeded
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// a slot may have been handed over just as the wait ended
+	if w.granted {
+		return &Token{limiter: l, start: l.now(), inflight: l.inflight}, nil
+	}
+
+	for i, queued := range l.waiters {
+		if queued == w {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			break
+		}
+	}
+	return nil, err
+}
+
+// take takes a slot, the caller must hold the lock.
+func (l *Limiter) take() *Token {
+	l.inflight++
+	return &Token{limiter: l, start: l.now(), inflight: l.inflight}
+}
+
+// dispatch hands freed slots over to queued requests, the caller must hold the lock.
+func (l *Limiter) dispatch() {
+	for len(l.waiters) > 0 && l.inflight < int(l.limit) {
+		w := l.waiters[0]
+		l.waiters = l.waiters[1:]
+
+		l.inflight++
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// release frees a slot and adjusts the limit with the sample, if any.
+func (l *Limiter) release(t *Token, sample bool, failed bool) {
+	l.mu.Lock()
+
+	previous := int(l.limit)
+	if sample {
+		l.update(l.now().Sub(t.start), t.inflight, failed)
+	}
+	current := int(l.limit)
+
+	l.inflight--
+	l.dispatch()
+	l.mu.Unlock()
+
+	if current != previous && l.conf.OnLimitChange != nil {
+		l.conf.OnLimitChange(current)
+	}
+}
+
+// update adjusts the limit to a sample, the caller must hold the lock.
+func (l *Limiter) update(rtt time.Duration, inflight int, failed bool) {
+	limit := l.limit
+
+	switch {
+	case failed:
+		limit *= backoffRatio
+	case l.conf.Algorithm == AlgorithmAIMD:
+		// only grow when the limit is actually used
+		if float64(inflight)*2 >= limit {
+			limit++
+		}
+	default:
+		limit = l.gradient(float64(rtt), float64(inflight), limit)
+	}
+
+	l.limit = min(max(limit, float64(l.conf.MinLimit)), float64(l.conf.MaxLimit))
+}
+
+// gradient returns the limit scaled by how much rtt deviates from the long
+// term round trip time, plus room for a queue to build up.
+func (l *Limiter) gradient(rtt, inflight, limit float64) float64 {
+	if rtt <= 0 {
+		return limit
+	}
+
+	if l.longRTT == 0 {
+		l.longRTT = rtt
+	} else {
+		l.longRTT += (rtt - l.longRTT) / rttWindow
+	}
+
+	// recover quickly once a brownout that inflated the long term average ends
+	if l.longRTT/rtt > 2 {
+		l.longRTT *= 0.95
+	}
+
+	// don't grow the limit while it isn't used
+	if inflight < limit/2 {
+		return limit
+	}
+
+	gradient := min(max(rttTolerance*l.longRTT/rtt, 0.5), 1)
+	next := limit*gradient + math.Sqrt(limit)
+
+	return limit*(1-smoothing) + next*smoothing
+}
+
+// Token is a slot taken from a Limiter. Exactly one of its methods must be
+// called when the request completes.
+type Token struct {
+	limiter  *Limiter
+	start    time.Time
+	inflight int
+	once     sync.Once
+}
+
+// Success releases the slot, adjusting the limit to the round trip time.
+func (t *Token) Success() {
+	t.once.Do(func() {
+		t.limiter.release(t, true, false)
+	})
+}
+
+// Dropped releases the slot of a failed request, lowering the limit.
+func (t *Token) Dropped() {
+	t.once.Do(func() {
+		t.limiter.release(t, true, true)
+	})
+}
+
+// Ignore releases the slot without adjusting the limit, e.g. when the
+// client cancelled the request.
+func (t *Token) Ignore() {
+	t.once.Do(func() {
+		t.limiter.release(t, false, false)
+	})
+}
+
+// Group holds a limiter per upstream.
+type Group struct {
+	conf     Config
+	onChange func(upstream string, limit int)
+
+	mu       sync.Mutex
+	limiters map[string]*Limiter
+}
+
+// NewGroup creates a group of limiters configured with conf. When set,
+// onChange is called with the upstream and its new limit whenever it changes,
+// and once with the initial limit when the limiter is created.
+func NewGroup(conf Config, onChange func(upstream string, limit int)) *Group {
+	return &Group{
+		conf:     conf.withDefaults(),
+		onChange: onChange,
+		limiters: make(map[string]*Limiter),
+	}
+}
+
+// Get returns the limiter of upstream, creating it on first use.
+func (g *Group) Get(upstream string) *Limiter {
+	g.mu.Lock()
+	l, ok := g.limiters[upstream]
+	if !ok {
+		conf := g.conf
+		if g.onChange != nil {
+			conf.OnLimitChange = func(limit int) {
+				g.onChange(upstream, limit)
+			}
+		}
+
+		l = NewLimiter(conf)
+		g.limiters[upstream] = l
+	}
+	g.mu.Unlock()
+
+	if !ok && g.onChange != nil {
+		g.onChange(upstream, l.Limit())
+	}
+
+	return l
+}
@@ -0,0 +1,191 @@
+package adaptive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock advances by step on every reading.
+type testClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (c *testClock) Now() time.Time {
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func newTestLimiter(conf Config, step time.Duration) (*Limiter, *testClock) {
+	clock := &testClock{now: time.Unix(0, 0), step: step}
+	l := NewLimiter(conf)
+	l.now = clock.Now
+	return l, clock
+}
+
+// saturate takes every slot of l and releases them with release.
+func saturate(t *testing.T, l *Limiter, release func(*Token)) {
+	t.Helper()
+
+	tokens := make([]*Token, 0, l.Limit())
+	for range l.Limit() {
+		token, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+		tokens = append(tokens, token)
+	}
+	for _, token := range tokens {
+		release(token)
+	}
+}
+
+func TestLimiter_Shed(t *testing.T) {
+	l := NewLimiter(Config{InitialLimit: 2})
+
+	first, err := l.Acquire(context.Background())
+	require.NoError(t, err)
+	_, err = l.Acquire(context.Background())
+	require.NoError(t, err)
+
+	_, err = l.Acquire(context.Background())
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Equal(t, 2, l.Inflight())
+
+	first.Ignore()
+	first.Ignore()
+	assert.Equal(t, 1, l.Inflight())
+	assert.Equal(t, 2, l.Limit())
+}
+
+func TestLimiter_Queue(t *testing.T) {
+	t.Run("waits for a slot", func(t *testing.T) {
+		l := NewLimiter(Config{InitialLimit: 1, QueueSize: 1, QueueTimeout: time.Minute})
+
+		held, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+
+		acquired := make(chan *Token)
+		go func() {
+			token, err := l.Acquire(context.Background())
+			assert.NoError(t, err)
+			acquired <- token
+		}()
+
+		assert.Eventually(t, func() bool {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			return len(l.waiters) == 1
+		}, time.Second, time.Millisecond)
+
+		// the queue is full
+		_, err = l.Acquire(context.Background())
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+
+		held.Ignore()
+		token := <-acquired
+		require.NotNil(t, token)
+		assert.Equal(t, 1, l.Inflight())
+		token.Ignore()
+		assert.Equal(t, 0, l.Inflight())
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		l := NewLimiter(Config{InitialLimit: 1, QueueSize: 1, QueueTimeout: 10 * time.Millisecond})
+
+		_, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+
+		_, err = l.Acquire(context.Background())
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		assert.Empty(t, l.waiters)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		l := NewLimiter(Config{InitialLimit: 1, QueueSize: 1, QueueTimeout: time.Minute})
+
+		_, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = l.Acquire(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, l.waiters)
+	})
+}
+
+func TestLimiter_Gradient(t *testing.T) {
+	var changes []int
+	l, clock := newTestLimiter(Config{
+		InitialLimit:  10,
+		MaxLimit:      50,
+		OnLimitChange: func(limit int) { changes = append(changes, limit) },
+	}, 10*time.Millisecond)
+
+	for range 20 {
+		saturate(t, l, (*Token).Success)
+	}
+	grown := l.Limit()
+	assert.Greater(t, grown, 10)
+	assert.NotEmpty(t, changes)
+	assert.Equal(t, grown, changes[len(changes)-1])
+
+	// latency increases tenfold during a brownout
+	clock.step = 100 * time.Millisecond
+	saturate(t, l, (*Token).Success)
+	assert.Less(t, l.Limit(), grown)
+
+	t.Run("doesn't grow when not saturated", func(t *testing.T) {
+		l, _ := newTestLimiter(Config{InitialLimit: 10}, 10*time.Millisecond)
+
+		for range 20 {
+			token, err := l.Acquire(context.Background())
+			require.NoError(t, err)
+			token.Success()
+		}
+		assert.Equal(t, 10, l.Limit())
+	})
+}
+
+func TestLimiter_AIMD(t *testing.T) {
+	l, _ := newTestLimiter(Config{Algorithm: AlgorithmAIMD, InitialLimit: 10, MinLimit: 5}, time.Millisecond)
+
+	// grows while at least half the limit is in flight
+	saturate(t, l, (*Token).Success)
+	assert.Equal(t, 16, l.Limit())
+
+	for range 20 {
+		token, err := l.Acquire(context.Background())
+		require.NoError(t, err)
+		token.Dropped()
+	}
+	assert.Equal(t, 5, l.Limit())
+}
+
+func TestGroup(t *testing.T) {
+	changes := map[string]int{}
+	g := NewGroup(Config{InitialLimit: 3}, func(upstream string, limit int) {
+		changes[upstream] = limit
+	})
+
+	a := g.Get("a:80")
+	assert.Same(t, a, g.Get("a:80"))
+	assert.NotSame(t, a, g.Get("b:80"))
+	assert.Equal(t, map[string]int{"a:80": 3, "b:80": 3}, changes)
+
+	token, err := a.Acquire(context.Background())
+	require.NoError(t, err)
+	token.Dropped()
+	assert.Equal(t, 2, changes["a:80"])
+}
+
+func TestValidAlgorithm(t *testing.T) {
+	assert.True(t, ValidAlgorithm(""))
+	assert.True(t, ValidAlgorithm(AlgorithmGradient))
+	assert.True(t, ValidAlgorithm(AlgorithmAIMD))
+	assert.False(t, ValidAlgorithm("vegas"))
+}
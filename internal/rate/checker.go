@@ -13,6 +13,15 @@ type Stats struct {
 	Limit     int
 	Remaining int
 	Count     int
+
+	// RetryAfter is how long a blocked client should wait before retrying.
+	// It's only set by rate limiters that can compute it exactly.
+	RetryAfter time.Duration
+
+	// Window is the interval the limit applies to.
+	Window time.Duration
+	// Policy names the limit in the IETF RateLimit headers.
+	Policy string
 }
 
 func NewEmptyStats() Stats {
@@ -89,4 +89,10 @@ func (r *rateLimitSender) SendRateLimits(limits Stats) {
 	// for client compatibility and to match industry conventions.
 	resetTime := time.Now().Add(limits.Reset).Unix()
 	r.hdr.Set(header.XRateLimitReset, strconv.FormatInt(resetTime, 10))
+
+	// Retry-After is given in whole seconds, rounded up so that clients
+	// retrying on time are not blocked again.
+	if limits.RetryAfter > 0 {
+		r.hdr.Set(header.RetryAfter, strconv.FormatInt(seconds(limits.RetryAfter), 10))
+	}
 }
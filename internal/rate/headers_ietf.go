@@ -0,0 +1,155 @@
+package rate
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+// The following constants name the limits reported in the IETF RateLimit headers.
+const (
+	// PolicyRate is the rate limit of the key.
+	PolicyRate = "rate"
+	// PolicyEndpoint is the per-endpoint rate limit of the key.
+	PolicyEndpoint = "endpoint"
+	// PolicyAPI is the API level rate limit.
+	PolicyAPI = "api"
+	// PolicyQuota is the quota of the key.
+	PolicyQuota = "quota"
+)
+
+type (
+	// ietfSender injects the IETF draft RateLimit and RateLimit-Policy headers.
+	// Every limit is a separate list member named after its policy, so that
+	// clients can tell which of the rate limits and quota will be hit first.
+	ietfSender struct {
+		hdr http.Header
+	}
+
+	// multiSender injects headers with each of its senders in turn.
+	multiSender []HeaderSender
+
+	// policySender names the rate limits sent by a HeaderSender.
+	policySender struct {
+		HeaderSender
+		policy string
+	}
+)
+
+// NewIETFSenderFactory returns a factory for senders that only inject the IETF
+// RateLimit and RateLimit-Policy headers.
+func NewIETFSenderFactory() HeaderSenderFactory {
+	return func(hdr http.Header) HeaderSender {
+		return &ietfSender{hdr: hdr}
+	}
+}
+
+// WithIETFHeaders returns a factory for senders that inject the headers of
+// senders created by next, as well as the IETF RateLimit headers.
+func WithIETFHeaders(next HeaderSenderFactory) HeaderSenderFactory {
+	return func(hdr http.Header) HeaderSender {
+		return multiSender{next(hdr), &ietfSender{hdr: hdr}}
+	}
+}
+
+// WithPolicy sets the policy name of the rate limits sent by s. It returns
+// nil if s is nil.
+func WithPolicy(s HeaderSender, policy string) HeaderSender {
+	if s == nil {
+		return nil
+	}
+	return &policySender{HeaderSender: s, policy: policy}
+}
+
+func (p *policySender) SendRateLimits(stats Stats) {
+	stats.Policy = p.policy
+	p.HeaderSender.SendRateLimits(stats)
+}
+
+func (m multiSender) SendQuotas(session *user.SessionState, apiId string) {
+	for _, s := range m {
+		s.SendQuotas(session, apiId)
+	}
+}
+
+func (m multiSender) SendRateLimits(stats Stats) {
+	for _, s := range m {
+		s.SendRateLimits(stats)
+	}
+}
+
+// SendRateLimits adds the limit to the RateLimit and RateLimit-Policy headers.
+func (s *ietfSender) SendRateLimits(stats Stats) {
+	policy := stats.Policy
+	if policy == "" {
+		policy = PolicyRate
+	}
+
+	s.set(policy, int64(stats.Limit), stats.Window, int64(stats.Remaining), stats.Reset)
+}
+
+// SendQuotas replaces any RateLimit headers injected by the upstream with the quota of the session.
+func (s *ietfSender) SendQuotas(session *user.SessionState, apiId string) {
+	if s.hdr == nil {
+		return
+	}
+
+	s.hdr.Del(header.RateLimit)
+	s.hdr.Del(header.RateLimitPolicy)
+
+	if session == nil {
+		return
+	}
+
+	quotaMax, quotaRemaining, quotaRenewalRate, quotaRenews := session.GetQuotaLimitByAPIID(apiId)
+	if quotaMax <= 0 {
+		return
+	}
+
+	window := time.Duration(quotaRenewalRate) * time.Second
+	reset := time.Until(time.Unix(quotaRenews, 0))
+
+	s.set(PolicyQuota, quotaMax, window, quotaRemaining, reset)
+}
+
+// set replaces the list members of the named policy in both headers.
+func (s *ietfSender) set(policy string, quota int64, window time.Duration, remaining int64, reset time.Duration) {
+	if s.hdr == nil {
+		return
+	}
+
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	name := strconv.Quote(policy)
+
+	s.replace(header.RateLimitPolicy, name, name+";q="+strconv.FormatInt(quota, 10)+";w="+strconv.FormatInt(seconds(window), 10))
+	s.replace(header.RateLimit, name, name+";r="+strconv.FormatInt(remaining, 10)+";t="+strconv.FormatInt(seconds(reset), 10))
+}
+
+// replace adds member to the list in key, dropping any previous member with the same name.
+func (s *ietfSender) replace(key string, name string, member string) {
+	values := s.hdr.Values(key)
+
+	kept := make([]string, 0, len(values)+1)
+	for _, v := range values {
+		if !strings.HasPrefix(v, name+";") {
+			kept = append(kept, v)
+		}
+	}
+
+	s.hdr[http.CanonicalHeaderKey(key)] = append(kept, member)
+}
+
+// seconds returns d in whole seconds, rounded up and never negative.
+func seconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,93 @@
+package rate
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/TykTechnologies/tyk/config"
+	"github.com/TykTechnologies/tyk/header"
+	"github.com/TykTechnologies/tyk/user"
+)
+
+func Test_ietfSender(t *testing.T) {
+	t.Run("reports every policy", func(t *testing.T) {
+		hdr := http.Header{}
+		s := &ietfSender{hdr: hdr}
+
+		s.SendRateLimits(Stats{
+			Limit:     100,
+			Remaining: 50,
+			Reset:     1500 * time.Millisecond,
+			Window:    time.Minute,
+		})
+		s.SendRateLimits(Stats{
+			Limit:     10,
+			Remaining: -1,
+			Window:    time.Second,
+			Policy:    PolicyEndpoint,
+		})
+
+		assert.Equal(t, []string{`"rate";q=100;w=60`, `"endpoint";q=10;w=1`}, hdr.Values(header.RateLimitPolicy))
+		assert.Equal(t, []string{`"rate";r=50;t=2`, `"endpoint";r=0;t=0`}, hdr.Values(header.RateLimit))
+	})
+
+	t.Run("replaces a policy sent again", func(t *testing.T) {
+		hdr := http.Header{}
+		s := &ietfSender{hdr: hdr}
+
+		s.SendRateLimits(Stats{Limit: 100, Remaining: 50, Window: time.Minute})
+		s.SendRateLimits(Stats{Limit: 100, Remaining: 49, Window: time.Minute})
+
+		assert.Equal(t, []string{`"rate";q=100;w=60`}, hdr.Values(header.RateLimitPolicy))
+		assert.Equal(t, []string{`"rate";r=49;t=0`}, hdr.Values(header.RateLimit))
+	})
+
+	t.Run("sends quota and drops upstream headers", func(t *testing.T) {
+		hdr := http.Header{}
+		hdr.Set(header.RateLimit, `"upstream";r=1;t=1`)
+		s := &ietfSender{hdr: hdr}
+
+		session := &user.SessionState{
+			QuotaMax:         1000,
+			QuotaRemaining:   900,
+			QuotaRenewalRate: 3600,
+			QuotaRenews:      time.Now().Add(time.Hour).Unix(),
+		}
+		s.SendQuotas(session, "api")
+
+		assert.Equal(t, []string{`"quota";q=1000;w=3600`}, hdr.Values(header.RateLimitPolicy))
+		assert.Regexp(t, `^"quota";r=900;t=(3599|3600)$`, hdr.Get(header.RateLimit))
+	})
+
+	t.Run("skips unlimited quota", func(t *testing.T) {
+		hdr := http.Header{}
+		s := &ietfSender{hdr: hdr}
+
+		s.SendQuotas(&user.SessionState{QuotaMax: -1}, "api")
+		s.SendQuotas(nil, "api")
+		assert.Empty(t, hdr)
+	})
+
+	t.Run("nil header", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			s := &ietfSender{}
+			s.SendRateLimits(Stats{Limit: 1})
+			s.SendQuotas(&user.SessionState{QuotaMax: 1}, "")
+		})
+	})
+}
+
+func TestWithIETFHeaders(t *testing.T) {
+	hdr := http.Header{}
+	s := WithPolicy(WithIETFHeaders(NewSenderFactory(config.SourceRateLimits))(hdr), PolicyAPI)
+
+	s.SendRateLimits(Stats{Limit: 10, Remaining: 5, Window: time.Second})
+
+	assert.Equal(t, "10", hdr.Get(header.XRateLimitLimit))
+	assert.Equal(t, `"api";q=10;w=1`, hdr.Get(header.RateLimitPolicy))
+
+	assert.Nil(t, WithPolicy(nil, PolicyAPI))
+}
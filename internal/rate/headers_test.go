@@ -94,5 +94,20 @@ func Test_rateLimitSender(t *testing.T) {
 				assert.Equal(t, "0", hdr.Get(header.XRateLimitRemaining))
 			})
 		})
+
+		t.Run("sends retry after rounded up to seconds", func(t *testing.T) {
+			hdr := http.Header{}
+			rls := &rateLimitSender{hdr: hdr}
+
+			rls.SendRateLimits(Stats{Limit: 200})
+			assert.Empty(t, hdr.Get(header.RetryAfter))
+
+			rls.SendRateLimits(Stats{
+				Limit:      200,
+				Reset:      5 * time.Second,
+				RetryAfter: 1200 * time.Millisecond,
+			})
+			assert.Equal(t, "2", hdr.Get(header.RetryAfter))
+		})
 	})
 }
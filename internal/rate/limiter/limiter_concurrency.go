@@ -0,0 +1,136 @@
+package limiter
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+	"github.com/TykTechnologies/tyk/internal/uuid"
+)
+
+// ErrConcurrencyExhausted is returned when all concurrency slots are in use.
+var ErrConcurrencyExhausted = errors.New("concurrency limit exhausted")
+
+// DefaultConcurrencyLease is how long a distributed concurrency slot is held
+// if it's never released, e.g. when a gateway stops mid request.
+const DefaultConcurrencyLease = time.Minute
+
+//go:embed scripts/concurrency_acquire.lua
+var concurrencyAcquireScript string
+
+var concurrencyAcquire = redis.NewScript(concurrencyAcquireScript)
+
+//go:embed scripts/concurrency_renew.lua
+var concurrencyRenewScript string
+
+var concurrencyRenew = redis.NewScript(concurrencyRenewScript)
+
+// Concurrency acquires one of max concurrency slots for key, returning a
+// function to release it. ErrConcurrencyExhausted is returned if all slots
+// are in use. Slots are counted in redis with lease expiry when a redis
+// client is in use, and with an in-memory semaphore otherwise. The lease of a
+// redis slot is renewed until it's released, so it only expires when the
+// gateway holding it stops renewing it.
+func (l *Limiter) Concurrency(ctx context.Context, key string, max int, lease time.Duration) (func(), error) {
+	if max <= 0 {
+		return func() {}, nil
+	}
+
+	if l.redis == nil {
+		if !localConcurrency.acquire(key, max) {
+			return nil, ErrConcurrencyExhausted
+		}
+
+		var once sync.Once
+		return func() {
+			once.Do(func() {
+				localConcurrency.release(key)
+			})
+		}, nil
+	}
+
+	if lease <= 0 {
+		lease = DefaultConcurrencyLease
+	}
+
+	id := uuid.NewHex()
+
+	acquired, err := concurrencyAcquire.Run(ctx, l.redis, []string{key}, id, max, lease.Milliseconds()).Int()
+	if err != nil {
+		return nil, err
+	}
+	if acquired != 1 {
+		return nil, ErrConcurrencyExhausted
+	}
+
+	stop := make(chan struct{})
+	go l.renewConcurrency(key, id, lease, stop)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			// the request context may be done by now, the slot must be released regardless
+			l.redis.ZRem(context.Background(), key, id)
+		})
+	}, nil
+}
+
+// renewConcurrency renews the lease of slot id every third of the lease until
+// stop is closed, or the slot is no longer held. A failed renewal is retried
+// on the next tick, before the lease runs out.
+func (l *Limiter) renewConcurrency(key, id string, lease time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), lease/3)
+		renewed, err := concurrencyRenew.Run(ctx, l.redis, []string{key}, id, lease.Milliseconds()).Int()
+		cancel()
+
+		if err == nil && renewed != 1 {
+			return
+		}
+	}
+}
+
+// semaphores counts the slots held per key in memory.
+type semaphores struct {
+	mu   sync.Mutex
+	held map[string]int
+}
+
+var localConcurrency = &semaphores{held: make(map[string]int)}
+
+func (s *semaphores) acquire(key string, max int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.held[key] >= max {
+		return false
+	}
+
+	s.held[key]++
+	return true
+}
+
+func (s *semaphores) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.held[key] <= 1 {
+		delete(s.held, key)
+		return
+	}
+
+	s.held[key]--
+}
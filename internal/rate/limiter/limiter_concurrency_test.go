@@ -0,0 +1,49 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Concurrency(t *testing.T) {
+	l := NewLimiter(nil)
+	ctx := context.Background()
+	key := t.Name()
+
+	release1, err := l.Concurrency(ctx, key, 2, 0)
+	require.NoError(t, err)
+
+	release2, err := l.Concurrency(ctx, key, 2, 0)
+	require.NoError(t, err)
+
+	_, err = l.Concurrency(ctx, key, 2, 0)
+	assert.ErrorIs(t, err, ErrConcurrencyExhausted)
+
+	// other keys are counted separately
+	release, err := l.Concurrency(ctx, key+"-other", 1, 0)
+	require.NoError(t, err)
+	release()
+
+	// releasing twice frees a single slot
+	release1()
+	release1()
+
+	release3, err := l.Concurrency(ctx, key, 2, 0)
+	require.NoError(t, err)
+
+	_, err = l.Concurrency(ctx, key, 2, 0)
+	assert.ErrorIs(t, err, ErrConcurrencyExhausted)
+
+	release2()
+	release3()
+	assert.NotContains(t, localConcurrency.held, key)
+}
+
+func TestLimiter_Concurrency_Unlimited(t *testing.T) {
+	release, err := NewLimiter(nil).Concurrency(context.Background(), t.Name(), 0, 0)
+	require.NoError(t, err)
+	assert.NotPanics(t, release)
+}
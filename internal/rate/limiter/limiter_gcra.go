@@ -0,0 +1,137 @@
+package limiter
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/tyk/internal/redis"
+)
+
+// AlgorithmGCRA selects the GCRA rate limiter for an API or a policy.
+const AlgorithmGCRA = "gcra"
+
+// ValidAlgorithm returns true if algorithm can be selected for an API or a
+// policy. An empty algorithm selects the rate limiter configured for the gateway.
+func ValidAlgorithm(algorithm string) bool {
+	return algorithm == "" || algorithm == AlgorithmGCRA
+}
+
+//go:embed scripts/gcra.lua
+var gcraScript string
+
+var gcraRedis = redis.NewScript(gcraScript)
+
+// gcraSweepInterval is how often expired keys are removed from the in-memory GCRA store.
+const gcraSweepInterval = time.Minute
+
+// GCRAResult is the outcome of a GCRA rate limit check.
+type GCRAResult struct {
+	// Allowed is true if the request conforms to the rate limit.
+	Allowed bool
+	// Remaining is the number of requests that can be made right away.
+	Remaining int
+	// RetryAfter is how long a blocked request should wait before retrying.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the limit is fully replenished.
+	ResetAfter time.Duration
+}
+
+// GCRA implements the generic cell rate algorithm, allowing rate requests per
+// seconds with a burst of up to rate requests. A single theoretical arrival
+// time is kept per key, in redis using a Lua script, or in memory when redis
+// is not in use.
+func (l *Limiter) GCRA(ctx context.Context, key string, rate float64, per float64) (GCRAResult, error) {
+	period := time.Duration(per * float64(time.Second))
+	if rate <= 0 || period <= 0 {
+		return GCRAResult{Allowed: true}, nil
+	}
+
+	interval := time.Duration(float64(period) / rate)
+	if interval <= 0 {
+		interval = 1
+	}
+
+	if l.redis != nil {
+		return l.gcraRedis(ctx, key, interval, period)
+	}
+
+	return localGCRA.limit(l.clock.Now(), key, interval, period), nil
+}
+
+func (l *Limiter) gcraRedis(ctx context.Context, key string, interval, period time.Duration) (GCRAResult, error) {
+	intervalUs := interval.Microseconds()
+	if intervalUs < 1 {
+		intervalUs = 1
+	}
+
+	res, err := gcraRedis.Run(ctx, l.redis, []string{key}, intervalUs, period.Microseconds()).Int64Slice()
+	if err != nil {
+		return GCRAResult{}, err
+	}
+	if len(res) != 4 {
+		return GCRAResult{}, fmt.Errorf("unexpected gcra script result: %v", res)
+	}
+
+	return GCRAResult{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Microsecond,
+		ResetAfter: time.Duration(res[3]) * time.Microsecond,
+	}, nil
+}
+
+// gcraStore keeps the theoretical arrival time of every key in memory.
+type gcraStore struct {
+	mu    sync.Mutex
+	tat   map[string]time.Time
+	swept time.Time
+}
+
+var localGCRA = &gcraStore{tat: make(map[string]time.Time)}
+
+func (s *gcraStore) limit(now time.Time, key string, interval, period time.Duration) GCRAResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	tat, ok := s.tat[key]
+	if !ok || tat.Before(now) {
+		tat = now
+	}
+
+	newTat := tat.Add(interval)
+	allowAt := newTat.Add(-period)
+
+	if now.Before(allowAt) {
+		return GCRAResult{
+			RetryAfter: allowAt.Sub(now),
+			ResetAfter: tat.Sub(now),
+		}
+	}
+
+	s.tat[key] = newTat
+
+	return GCRAResult{
+		Allowed:    true,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTat.Sub(now),
+	}
+}
+
+// sweep removes keys which are fully replenished, as they are equivalent to missing keys.
+func (s *gcraStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < gcraSweepInterval {
+		return
+	}
+	s.swept = now
+
+	for key, tat := range s.tat {
+		if !tat.After(now) {
+			delete(s.tat, key)
+		}
+	}
+}
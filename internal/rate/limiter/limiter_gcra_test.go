@@ -0,0 +1,82 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func TestLimiter_GCRA(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	l := NewLimiter(nil)
+	l.clock = clock
+
+	ctx := context.Background()
+	key := t.Name()
+
+	// a burst of up to rate requests is allowed
+	for i := 4; i >= 0; i-- {
+		res, err := l.GCRA(ctx, key, 5, 1)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, i, res.Remaining)
+	}
+
+	res, err := l.GCRA(ctx, key, 5, 1)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+	assert.Equal(t, 200*time.Millisecond, res.RetryAfter)
+	assert.Equal(t, time.Second, res.ResetAfter)
+
+	// a single request is allowed once the emission interval has passed
+	clock.now = clock.now.Add(200 * time.Millisecond)
+
+	res, err = l.GCRA(ctx, key, 5, 1)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+
+	res, err = l.GCRA(ctx, key, 5, 1)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+
+	// the limit is fully replenished after the period
+	clock.now = clock.now.Add(time.Second)
+
+	res, err = l.GCRA(ctx, key, 5, 1)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 4, res.Remaining)
+	assert.Equal(t, 200*time.Millisecond, res.ResetAfter)
+}
+
+func TestLimiter_GCRA_Unlimited(t *testing.T) {
+	res, err := NewLimiter(nil).GCRA(context.Background(), t.Name(), 0, 1)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestGCRAStore_Sweep(t *testing.T) {
+	s := &gcraStore{tat: make(map[string]time.Time)}
+	now := time.Unix(1000, 0)
+
+	s.limit(now, "a", time.Second, time.Second)
+	s.limit(now, "b", time.Minute, time.Minute)
+	assert.Len(t, s.tat, 2)
+
+	s.limit(now.Add(2*gcraSweepInterval), "c", time.Second, time.Second)
+	assert.Len(t, s.tat, 1)
+	assert.Contains(t, s.tat, "c")
+}
@@ -0,0 +1,29 @@
+-- Acquires a concurrency slot held until released or until its lease expires.
+--
+-- KEYS[1] is a sorted set of slot ids scored by lease expiry in milliseconds.
+-- ARGV[1] is the id of the slot to acquire.
+-- ARGV[2] is the maximum number of slots.
+-- ARGV[3] is the lease in milliseconds.
+--
+-- Returns 1 if the slot was acquired, 0 otherwise.
+
+redis.replicate_commands()
+
+local key = KEYS[1]
+local id = ARGV[1]
+local max = tonumber(ARGV[2])
+local lease = tonumber(ARGV[3])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now)
+
+if redis.call("ZCARD", key) >= max then
+	return 0
+end
+
+redis.call("ZADD", key, now + lease, id)
+redis.call("PEXPIRE", key, lease)
+
+return 1
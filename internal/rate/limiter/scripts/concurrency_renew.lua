@@ -0,0 +1,26 @@
+-- Renews the lease of a concurrency slot that is still held.
+--
+-- KEYS[1] is a sorted set of slot ids scored by lease expiry in milliseconds.
+-- ARGV[1] is the id of the slot to renew.
+-- ARGV[2] is the lease in milliseconds.
+--
+-- Returns 1 if the slot was renewed, 0 if it's no longer held.
+
+redis.replicate_commands()
+
+local key = KEYS[1]
+local id = ARGV[1]
+local lease = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local expiry = redis.call("ZSCORE", key, id)
+if not expiry or tonumber(expiry) <= now then
+	return 0
+end
+
+redis.call("ZADD", key, now + lease, id)
+redis.call("PEXPIRE", key, lease)
+
+return 1
@@ -0,0 +1,33 @@
+-- GCRA (generic cell rate algorithm) limiter in a single round trip.
+--
+-- KEYS[1] holds the theoretical arrival time (TAT) in microseconds.
+-- ARGV[1] is the emission interval in microseconds (per / rate).
+-- ARGV[2] is the period in microseconds (per).
+--
+-- Returns { allowed, remaining, retry_after_us, reset_after_us }.
+
+redis.replicate_commands()
+
+local key = KEYS[1]
+local emission_interval = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call("GET", key))
+if not tat or tat < now then
+	tat = now
+end
+
+local new_tat = tat + emission_interval
+local allow_at = new_tat - period
+
+if now < allow_at then
+	return { 0, 0, allow_at - now, tat - now }
+end
+
+local ttl = math.ceil((new_tat - now) / 1000)
+redis.call("SET", key, string.format("%d", new_tat), "PX", ttl)
+
+return { 1, math.floor((now - allow_at) / emission_interval), 0, new_tat - now }
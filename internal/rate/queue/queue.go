@@ -0,0 +1,227 @@
+// Package queue implements a bounded wait queue for requests that exceeded a
+// rate or concurrency limit. Waiting requests retry the limit in priority
+// order instead of being rejected right away.
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSize       = 100
+	defaultMaxWait       = time.Second
+	defaultRetryInterval = 10 * time.Millisecond
+)
+
+var (
+	// ErrFull is returned when a request can't be queued because the queue
+	// holds the maximum number of requests.
+	ErrFull = errors.New("rate limit queue is full")
+
+	// ErrTimeout is returned when a request didn't get through the limit
+	// within the maximum wait time.
+	ErrTimeout = errors.New("timed out waiting in rate limit queue")
+)
+
+// Config configures a Queue. Zero values are replaced with defaults.
+type Config struct {
+	// MaxSize is the number of requests that can wait at the same time,
+	// across all limits. Default: 100.
+	MaxSize int
+	// MaxWait is how long a request waits before it is rejected. Default: 1s.
+	MaxWait time.Duration
+	// RetryInterval is how often the request at the head of the queue of a
+	// limit retries it. Default: 10ms.
+	RetryInterval time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxSize <= 0 {
+		c.MaxSize = defaultMaxSize
+	}
+	if c.MaxWait <= 0 {
+		c.MaxWait = defaultMaxWait
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = defaultRetryInterval
+	}
+	return c
+}
+
+// Result describes the wait of a queued request.
+type Result struct {
+	// Depth is the number of queued requests, including this one, when the
+	// request joined the queue.
+	Depth int
+	// Waited is how long the request spent in the queue.
+	Waited time.Duration
+}
+
+// Queue holds the requests waiting for a limit. Requests waiting for the
+// same limit share a lane; only the request with the highest priority in a
+// lane retries the limit, so requests with a higher priority are served
+// first and requests with the same priority in arrival order.
+type Queue struct {
+	conf Config
+
+	mu    sync.Mutex
+	size  int
+	seq   uint64
+	lanes map[string]*lane
+}
+
+// NewQueue creates a queue with conf.
+func NewQueue(conf Config) *Queue {
+	return &Queue{
+		conf:  conf.withDefaults(),
+		lanes: make(map[string]*lane),
+	}
+}
+
+// Depth returns the number of queued requests.
+func (q *Queue) Depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.size
+}
+
+// Wait queues the request in the lane of the limit named key until try
+// returns true, the maximum wait time passes or ctx is done. Higher
+// priorities are served first. It returns ErrFull if the queue is full,
+// ErrTimeout if the wait timed out and the context error if ctx is done.
+func (q *Queue) Wait(ctx context.Context, key string, priority int, try func() bool) (Result, error) {
+	start := time.Now()
+
+	q.mu.Lock()
+	if q.size >= q.conf.MaxSize {
+		q.mu.Unlock()
+		return Result{}, ErrFull
+	}
+
+	l, ok := q.lanes[key]
+	if !ok {
+		l = &lane{wake: make(chan struct{})}
+		q.lanes[key] = l
+	}
+
+	q.seq++
+	w := &waiter{priority: priority, seq: q.seq}
+	heap.Push(&l.waiters, w)
+	if w.index == 0 {
+		l.notify()
+	}
+
+	q.size++
+	result := Result{Depth: q.size}
+	q.mu.Unlock()
+
+	defer q.leave(key, l, w)
+
+	timer := time.NewTimer(q.conf.MaxWait)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(q.conf.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		q.mu.Lock()
+		head, wake := w.index == 0, l.wake
+		q.mu.Unlock()
+
+		// only the head of the lane retries, the others wait for their turn
+		var tick <-chan time.Time
+		if head {
+			if try() {
+				result.Waited = time.Since(start)
+				return result, nil
+			}
+			tick = ticker.C
+		}
+
+		select {
+		case <-tick:
+		case <-wake:
+		case <-timer.C:
+			result.Waited = time.Since(start)
+			return result, ErrTimeout
+		case <-ctx.Done():
+			result.Waited = time.Since(start)
+			return result, ctx.Err()
+		}
+	}
+}
+
+// leave removes w from its lane, handing the turn to the next request.
+func (q *Queue) leave(key string, l *lane, w *waiter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	head := w.index == 0
+	heap.Remove(&l.waiters, w.index)
+	q.size--
+
+	if len(l.waiters) == 0 {
+		delete(q.lanes, key)
+		return
+	}
+
+	if head {
+		l.notify()
+	}
+}
+
+// lane holds the requests waiting for the same limit.
+type lane struct {
+	waiters waiters
+	// wake is closed when the head of the lane changes.
+	wake chan struct{}
+}
+
+// notify wakes the requests of the lane, the caller must hold the lock.
+func (l *lane) notify() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+type waiter struct {
+	priority int
+	seq      uint64
+	index    int
+}
+
+// waiters is a heap of waiters ordered by priority, then arrival.
+type waiters []*waiter
+
+func (h waiters) Len() int { return len(h) }
+
+func (h waiters) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h waiters) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiters) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiters) Pop() any {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return w
+}
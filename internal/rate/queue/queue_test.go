@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gate is a limit that lets one request through for every open call.
+type gate struct {
+	allowed atomic.Int32
+}
+
+func (g *gate) open() {
+	g.allowed.Add(1)
+}
+
+func (g *gate) try() bool {
+	for {
+		n := g.allowed.Load()
+		if n <= 0 {
+			return false
+		}
+		if g.allowed.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
+
+func TestQueue_Wait(t *testing.T) {
+	q := NewQueue(Config{MaxWait: time.Minute, RetryInterval: time.Millisecond})
+
+	g := &gate{}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		g.open()
+	}()
+
+	res, err := q.Wait(context.Background(), "key", 0, g.try)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Depth)
+	assert.GreaterOrEqual(t, res.Waited, 20*time.Millisecond)
+	assert.Equal(t, 0, q.Depth())
+	assert.Empty(t, q.lanes)
+}
+
+func TestQueue_Priority(t *testing.T) {
+	q := NewQueue(Config{MaxWait: time.Minute, RetryInterval: time.Millisecond})
+	g := &gate{}
+
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+
+	priorities := []int{0, 5, 1, 5}
+	for i, priority := range priorities {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := q.Wait(context.Background(), "key", priority, g.try)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}()
+
+		// queue in a known order
+		require.Eventually(t, func() bool { return q.Depth() == i+1 }, time.Second, time.Millisecond)
+	}
+
+	for range priorities {
+		g.open()
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	assert.Equal(t, []int{1, 3, 2, 0}, order)
+}
+
+func TestQueue_Lanes(t *testing.T) {
+	q := NewQueue(Config{MaxWait: time.Minute, RetryInterval: time.Millisecond})
+
+	blocked := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_, err := q.Wait(ctx, "blocked", 10, func() bool { return false })
+		blocked <- err
+	}()
+	require.Eventually(t, func() bool { return q.Depth() == 1 }, time.Second, time.Millisecond)
+
+	// a request waiting for another limit isn't held up
+	_, err := q.Wait(context.Background(), "other", 0, func() bool { return true })
+	assert.NoError(t, err)
+
+	cancel()
+	assert.ErrorIs(t, <-blocked, context.Canceled)
+	assert.Equal(t, 0, q.Depth())
+}
+
+func TestQueue_Limits(t *testing.T) {
+	t.Run("full", func(t *testing.T) {
+		q := NewQueue(Config{MaxSize: 1, MaxWait: time.Minute})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_, _ = q.Wait(ctx, "key", 0, func() bool { return false })
+		}()
+		require.Eventually(t, func() bool { return q.Depth() == 1 }, time.Second, time.Millisecond)
+
+		_, err := q.Wait(context.Background(), "key", 10, func() bool { return true })
+		assert.ErrorIs(t, err, ErrFull)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		q := NewQueue(Config{MaxWait: 10 * time.Millisecond, RetryInterval: time.Millisecond})
+
+		res, err := q.Wait(context.Background(), "key", 0, func() bool { return false })
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.GreaterOrEqual(t, res.Waited, 10*time.Millisecond)
+		assert.Equal(t, 0, q.Depth())
+	})
+}
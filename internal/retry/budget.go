@@ -0,0 +1,108 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBudgetRatio is the share of requests that may be retries.
+	DefaultBudgetRatio = 0.2
+	// DefaultBudgetMinPerSecond is the number of retries per second allowed
+	// regardless of the ratio, so that low traffic APIs can still retry.
+	DefaultBudgetMinPerSecond = 10
+	// DefaultBudgetWindow is the period over which requests and retries are counted.
+	DefaultBudgetWindow = 10 * time.Second
+)
+
+// Budget limits retries to a share of the requests seen over a sliding
+// window, so that retries can't multiply the load on an upstream that is
+// already failing. It is safe for concurrent use.
+type Budget struct {
+	ratio  float64
+	min    int
+	window int
+
+	mu      sync.Mutex
+	buckets []bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// NewBudget creates a budget allowing ratio retries per request, plus
+// minPerSecond retries per second, over window. Zero values use the defaults.
+func NewBudget(ratio float64, minPerSecond int, window time.Duration) *Budget {
+	if ratio <= 0 {
+		ratio = DefaultBudgetRatio
+	}
+	if minPerSecond <= 0 {
+		minPerSecond = DefaultBudgetMinPerSecond
+	}
+	if window < time.Second {
+		window = DefaultBudgetWindow
+	}
+
+	seconds := int(window / time.Second)
+	return &Budget{
+		ratio:   ratio,
+		min:     minPerSecond,
+		window:  seconds,
+		buckets: make([]bucket, seconds),
+		now:     time.Now,
+	}
+}
+
+// Request records an upstream request that may later be retried.
+func (b *Budget) Request() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current().requests++
+}
+
+// Withdraw reserves a retry. It returns false if the budget is exhausted,
+// in which case the retry must not be attempted.
+func (b *Budget) Withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cur := b.current()
+
+	var requests, retries int
+	for _, bk := range b.buckets {
+		if cur.second-bk.second < int64(b.window) {
+			requests += bk.requests
+			retries += bk.retries
+		}
+	}
+
+	allowed := int(float64(requests)*b.ratio) + b.min*b.window
+	if retries >= allowed {
+		return false
+	}
+
+	cur.retries++
+	return true
+}
+
+func (b *Budget) current() *bucket {
+	sec := b.now().Unix()
+	bk := &b.buckets[int(sec%int64(b.window))]
+	if bk.second != sec {
+		*bk = bucket{second: sec}
+	}
+	return bk
+}
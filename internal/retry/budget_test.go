@@ -0,0 +1,46 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := NewBudget(0.5, 1, 2*time.Second)
+	b.now = func() time.Time { return now }
+
+	// two retries are always allowed over the two second window
+	assert.True(t, b.Withdraw())
+	assert.True(t, b.Withdraw())
+	assert.False(t, b.Withdraw())
+
+	// every request adds half a retry
+	for i := 0; i < 4; i++ {
+		b.Request()
+	}
+	assert.True(t, b.Withdraw())
+	assert.True(t, b.Withdraw())
+	assert.False(t, b.Withdraw())
+
+	// once the window has passed the budget is replenished
+	now = now.Add(2 * time.Second)
+	assert.True(t, b.Withdraw())
+	assert.True(t, b.Withdraw())
+	assert.False(t, b.Withdraw())
+}
+
+func TestBudget_Nil(t *testing.T) {
+	var b *Budget
+	b.Request()
+	assert.True(t, b.Withdraw())
+}
+
+func TestNewBudget_Defaults(t *testing.T) {
+	b := NewBudget(0, 0, 0)
+	assert.Equal(t, DefaultBudgetRatio, b.ratio)
+	assert.Equal(t, DefaultBudgetMinPerSecond, b.min)
+	assert.Equal(t, int(DefaultBudgetWindow/time.Second), b.window)
+}
@@ -0,0 +1,79 @@
+package retry
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLatencyWindowSize is the number of recent latencies kept.
+	DefaultLatencyWindowSize = 1000
+	// MinLatencySamples is the number of latencies needed before percentiles
+	// are computed.
+	MinLatencySamples = 20
+)
+
+// LatencyWindow keeps the most recent latencies of an upstream to compute
+// their percentiles, e.g. to derive a hedge delay. It is safe for concurrent
+// use, and a nil window has no latencies.
+type LatencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+}
+
+// NewLatencyWindow creates a window keeping the last size latencies. A zero
+// size uses the default.
+func NewLatencyWindow(size int) *LatencyWindow {
+	if size <= 0 {
+		size = DefaultLatencyWindowSize
+	}
+	return &LatencyWindow{samples: make([]time.Duration, size)}
+}
+
+// Observe records a latency, replacing the oldest one when the window is full.
+func (w *LatencyWindow) Observe(latency time.Duration) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.samples[w.next] = latency
+	w.next++
+	if w.next == len(w.samples) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// Percentile returns the p-th percentile of the latencies in the window,
+// with p between 0 and 100. It returns false until MinLatencySamples were
+// observed.
+func (w *LatencyWindow) Percentile(p float64) (time.Duration, bool) {
+	if w == nil {
+		return 0, false
+	}
+
+	w.mu.Lock()
+	n := w.next
+	if w.full {
+		n = len(w.samples)
+	}
+	if n < MinLatencySamples {
+		w.mu.Unlock()
+		return 0, false
+	}
+	samples := slices.Clone(w.samples[:n])
+	w.mu.Unlock()
+
+	slices.Sort(samples)
+
+	i := int(math.Ceil(p/100*float64(n))) - 1
+	i = max(0, min(i, n-1))
+	return samples[i], true
+}
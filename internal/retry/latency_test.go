@@ -0,0 +1,44 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyWindow(t *testing.T) {
+	w := NewLatencyWindow(100)
+
+	for i := 1; i < MinLatencySamples; i++ {
+		w.Observe(time.Duration(i) * time.Millisecond)
+	}
+	_, ok := w.Percentile(50)
+	assert.False(t, ok)
+
+	for i := MinLatencySamples; i <= 100; i++ {
+		w.Observe(time.Duration(i) * time.Millisecond)
+	}
+
+	p, ok := w.Percentile(95)
+	assert.True(t, ok)
+	assert.Equal(t, 95*time.Millisecond, p)
+
+	p, _ = w.Percentile(0)
+	assert.Equal(t, time.Millisecond, p)
+
+	// the oldest latencies are replaced
+	for i := 0; i < 100; i++ {
+		w.Observe(time.Second)
+	}
+	p, _ = w.Percentile(50)
+	assert.Equal(t, time.Second, p)
+}
+
+func TestLatencyWindow_Nil(t *testing.T) {
+	var w *LatencyWindow
+	w.Observe(time.Second)
+
+	_, ok := w.Percentile(95)
+	assert.False(t, ok)
+}
@@ -0,0 +1,163 @@
+// Package retry decides whether a failed upstream request may be sent again,
+// how long to wait before doing so and whether the gateway can afford it.
+package retry
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+)
+
+// Condition is a class of upstream failure that can be retried.
+type Condition string
+
+// The following constants enumerate the failure classes a retry can be configured for.
+const (
+	// ConnectFailure covers failures to establish a connection, including
+	// DNS failures, refused connections and connect timeouts. The upstream
+	// never saw the request.
+	ConnectFailure Condition = "connectFailure"
+	// Reset covers connections reset or aborted after the request was sent.
+	Reset Condition = "reset"
+	// Timeout covers upstream requests that timed out awaiting a response.
+	Timeout Condition = "timeout"
+	// GatewayError covers 502, 503 and 504 upstream responses.
+	GatewayError Condition = "gatewayError"
+	// ServerError covers any 5xx upstream response.
+	ServerError Condition = "5xx"
+)
+
+// DefaultConditions is used when a policy doesn't list any conditions.
+var DefaultConditions = []Condition{ConnectFailure, GatewayError}
+
+const (
+	// DefaultBackoffBase is used when a policy doesn't set a base backoff.
+	DefaultBackoffBase = 25 * time.Millisecond
+	// DefaultBackoffMax is used when a policy doesn't set a maximum backoff.
+	DefaultBackoffMax = time.Second
+)
+
+// Valid returns true if the condition is known.
+func (c Condition) Valid() bool {
+	switch c {
+	case ConnectFailure, Reset, Timeout, GatewayError, ServerError:
+		return true
+	}
+	return false
+}
+
+// Policy describes how failed upstream requests of an API are retried.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// Conditions are the failure classes that are retried.
+	Conditions []Condition
+	// StatusCodes are additional upstream response codes that are retried.
+	StatusCodes []int
+	// BackoffBase is the backoff before the first retry, doubled for every
+	// further retry.
+	BackoffBase time.Duration
+	// BackoffMax caps the backoff between two attempts.
+	BackoffMax time.Duration
+	// NonIdempotent allows retrying requests with non-idempotent methods.
+	// Their body must be buffered so that it can be replayed.
+	NonIdempotent bool
+}
+
+// Enabled returns true if the policy allows more than one attempt.
+func (p Policy) Enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// AllowsMethod returns true if requests using method may be retried.
+// Requests whose body can't be replayed are never retried.
+func (p Policy) AllowsMethod(method string, replayableBody bool) bool {
+	if !replayableBody {
+		return false
+	}
+	return p.NonIdempotent || IdempotentMethod(method)
+}
+
+// RetryError returns true if an attempt that failed with the given
+// classification should be retried.
+func (p Policy) RetryError(ec *tykerrors.ErrorClassification) bool {
+	if ec == nil {
+		return false
+	}
+	return p.has(conditionForFlag(ec.Flag))
+}
+
+// RetryStatus returns true if an attempt answered with the given status code
+// should be retried.
+func (p Policy) RetryStatus(code int) bool {
+	if slices.Contains(p.StatusCodes, code) {
+		return true
+	}
+
+	switch {
+	case code == http.StatusBadGateway, code == http.StatusServiceUnavailable, code == http.StatusGatewayTimeout:
+		return p.has(GatewayError) || p.has(ServerError)
+	case code/100 == 5:
+		return p.has(ServerError)
+	}
+	return false
+}
+
+// Backoff returns the delay before the given retry, starting at 1. It uses
+// exponential backoff with full jitter.
+func (p Policy) Backoff(retry int) time.Duration {
+	base, limit := p.BackoffBase, p.BackoffMax
+	if base <= 0 {
+		base = DefaultBackoffBase
+	}
+	if limit <= 0 {
+		limit = DefaultBackoffMax
+	}
+
+	d := base
+	for i := 1; i < retry && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+
+	return rand.N(d) + 1
+}
+
+func (p Policy) has(c Condition) bool {
+	if c == "" {
+		return false
+	}
+	if len(p.Conditions) == 0 {
+		return slices.Contains(DefaultConditions, c)
+	}
+	return slices.Contains(p.Conditions, c)
+}
+
+// conditionForFlag maps an upstream error classification to a retry condition.
+// Failures that retrying can't fix, such as TLS errors or a client that went
+// away, map to no condition.
+func conditionForFlag(flag tykerrors.ResponseFlag) Condition {
+	switch flag {
+	case tykerrors.UCF, tykerrors.UCT, tykerrors.NRH, tykerrors.DNS:
+		return ConnectFailure
+	case tykerrors.URR, tykerrors.EPI, tykerrors.CAB, tykerrors.NRS:
+		return Reset
+	case tykerrors.URT:
+		return Timeout
+	}
+	return ""
+}
+
+// IdempotentMethod returns true for the methods RFC 9110 defines as idempotent.
+func IdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
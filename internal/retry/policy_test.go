@@ -0,0 +1,90 @@
+package retry
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tykerrors "github.com/TykTechnologies/tyk/internal/errors"
+)
+
+func TestPolicy_AllowsMethod(t *testing.T) {
+	var p Policy
+
+	assert.True(t, p.AllowsMethod(http.MethodGet, true))
+	assert.True(t, p.AllowsMethod(http.MethodPut, true))
+	assert.False(t, p.AllowsMethod(http.MethodPut, false))
+	assert.False(t, p.AllowsMethod(http.MethodPost, true))
+
+	p.NonIdempotent = true
+	assert.False(t, p.AllowsMethod(http.MethodPost, false))
+	assert.True(t, p.AllowsMethod(http.MethodPost, true))
+}
+
+func TestPolicy_RetryError(t *testing.T) {
+	ec := func(flag tykerrors.ResponseFlag) *tykerrors.ErrorClassification {
+		return tykerrors.NewErrorClassification(flag, "")
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		var p Policy
+		assert.True(t, p.RetryError(ec(tykerrors.UCF)))
+		assert.True(t, p.RetryError(ec(tykerrors.DNS)))
+		assert.False(t, p.RetryError(ec(tykerrors.URR)))
+		assert.False(t, p.RetryError(ec(tykerrors.URT)))
+		assert.False(t, p.RetryError(nil))
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		p := Policy{Conditions: []Condition{Reset, Timeout}}
+		assert.False(t, p.RetryError(ec(tykerrors.UCF)))
+		assert.True(t, p.RetryError(ec(tykerrors.URR)))
+		assert.True(t, p.RetryError(ec(tykerrors.EPI)))
+		assert.True(t, p.RetryError(ec(tykerrors.URT)))
+	})
+
+	t.Run("never retried", func(t *testing.T) {
+		p := Policy{Conditions: []Condition{ConnectFailure, Reset, Timeout, ServerError}}
+		assert.False(t, p.RetryError(ec(tykerrors.CDC)))
+		assert.False(t, p.RetryError(ec(tykerrors.TLE)))
+		assert.False(t, p.RetryError(ec(tykerrors.UPE)))
+	})
+}
+
+func TestPolicy_RetryStatus(t *testing.T) {
+	var p Policy
+	assert.True(t, p.RetryStatus(http.StatusServiceUnavailable))
+	assert.False(t, p.RetryStatus(http.StatusInternalServerError))
+	assert.False(t, p.RetryStatus(http.StatusTooManyRequests))
+
+	p = Policy{Conditions: []Condition{ServerError}, StatusCodes: []int{http.StatusTooManyRequests}}
+	assert.True(t, p.RetryStatus(http.StatusInternalServerError))
+	assert.True(t, p.RetryStatus(http.StatusBadGateway))
+	assert.True(t, p.RetryStatus(http.StatusTooManyRequests))
+	assert.False(t, p.RetryStatus(http.StatusOK))
+
+	p = Policy{Conditions: []Condition{ConnectFailure}}
+	assert.False(t, p.RetryStatus(http.StatusBadGateway))
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := Policy{BackoffBase: 10 * time.Millisecond, BackoffMax: 50 * time.Millisecond}
+
+	for i := 0; i < 100; i++ {
+		assert.LessOrEqual(t, p.Backoff(1), 10*time.Millisecond)
+		assert.LessOrEqual(t, p.Backoff(2), 20*time.Millisecond)
+		assert.LessOrEqual(t, p.Backoff(10), 50*time.Millisecond)
+		assert.Positive(t, p.Backoff(1))
+	}
+
+	assert.LessOrEqual(t, Policy{}.Backoff(1), DefaultBackoffBase)
+}
+
+func TestCondition_Valid(t *testing.T) {
+	for _, c := range []Condition{ConnectFailure, Reset, Timeout, GatewayError, ServerError} {
+		assert.True(t, c.Valid(), c)
+	}
+	assert.False(t, Condition("4xx").Valid())
+}
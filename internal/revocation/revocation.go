@@ -0,0 +1,485 @@
+// Package revocation checks the revocation status of certificates using OCSP
+// and certificate revocation lists (CRLs), and fetches the OCSP responses
+// stapled to the TLS handshakes of server certificates.
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/TykTechnologies/tyk/internal/cache"
+)
+
+const (
+	// DefaultCacheTTL is how long CRLs and OCSP responses without a next
+	// update time are cached.
+	DefaultCacheTTL = time.Hour
+	// DefaultTimeout is the timeout of CRL and OCSP requests.
+	DefaultTimeout = 5 * time.Second
+	// CRLKeyPrefix is the prefix of the storage keys, and IDs, of uploaded CRLs.
+	CRLKeyPrefix = "crl-"
+
+	// failureTTL is how long a failure to determine the revocation status of
+	// a certificate is cached, so that unreachable responders aren't queried
+	// on every request.
+	failureTTL = 30 * time.Second
+	// maxResponseSize limits the size of fetched CRLs and OCSP responses.
+	maxResponseSize = 10 << 20
+)
+
+var (
+	// ErrRevoked is returned for revoked certificates.
+	ErrRevoked = errors.New("certificate has been revoked")
+	// ErrUnknownStatus is returned in hard-fail mode for certificates whose
+	// revocation status can't be determined.
+	ErrUnknownStatus = errors.New("certificate revocation status could not be determined")
+	// ErrInvalidCRL is returned when uploading data that isn't a CRL.
+	ErrInvalidCRL = errors.New("invalid certificate revocation list")
+)
+
+// Config configures a Checker.
+type Config struct {
+	// CRL enables checking certificates against uploaded CRLs and the CRLs
+	// published at their distribution points.
+	CRL bool
+	// OCSP enables checking certificates with their OCSP responders.
+	OCSP bool
+	// HardFail rejects certificates whose revocation status can't be determined.
+	HardFail bool
+	// CacheTTL is how long CRLs and OCSP responses without a next update time
+	// are cached.
+	CacheTTL time.Duration
+	// Timeout is the timeout of CRL and OCSP requests.
+	Timeout time.Duration
+}
+
+// Store stores the uploaded CRLs.
+type Store interface {
+	GetKey(key string) (string, error)
+	SetKey(key, value string, ttl int64) error
+	DeleteKey(key string) bool
+}
+
+// status is the revocation status of a certificate.
+type status int
+
+const (
+	statusUnknown status = iota
+	statusGood
+	statusRevoked
+)
+
+// Checker checks the revocation status of certificates. CRLs and OCSP
+// responses are cached until their next update, it is safe for concurrent use.
+type Checker struct {
+	conf   Config
+	client *http.Client
+	store  Store
+
+	cache *cache.Cache
+	group singleflight.Group
+}
+
+// NewChecker creates a revocation checker. Uploaded CRLs are kept in store,
+// which may be nil if CRLs can't be uploaded.
+func NewChecker(conf Config, client *http.Client, store Store) *Checker {
+	if conf.CacheTTL <= 0 {
+		conf.CacheTTL = DefaultCacheTTL
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultTimeout
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &Checker{
+		conf:   conf,
+		client: client,
+		store:  store,
+		cache:  cache.NewCache(conf.CacheTTL, time.Minute),
+	}
+}
+
+// Close stops the cache cleanup of the checker.
+func (c *Checker) Close() {
+	c.cache.Close()
+}
+
+// Check returns ErrRevoked if cert, issued by issuer, was revoked. In
+// hard-fail mode it returns ErrUnknownStatus if the revocation status of cert
+// can't be determined, e.g. because its issuer is unknown.
+func (c *Checker) Check(cert, issuer *x509.Certificate) error {
+	st := statusUnknown
+	if issuer != nil {
+		if c.conf.OCSP {
+			st = c.checkOCSP(cert, issuer)
+		}
+		if st == statusUnknown && c.conf.CRL {
+			st = c.checkCRL(cert, issuer)
+		}
+	}
+
+	switch {
+	case st == statusRevoked:
+		return ErrRevoked
+	case st == statusUnknown && c.conf.HardFail:
+		return ErrUnknownStatus
+	default:
+		return nil
+	}
+}
+
+// checkOCSP returns the status of cert reported by its OCSP responders.
+func (c *Checker) checkOCSP(cert, issuer *x509.Certificate) status {
+	for _, server := range cert.OCSPServer {
+		res, err := c.ocspResponse(server, cert, issuer)
+		if err != nil {
+			continue
+		}
+
+		switch res.Status {
+		case ocsp.Good:
+			return statusGood
+		case ocsp.Revoked:
+			return statusRevoked
+		}
+	}
+
+	return statusUnknown
+}
+
+// ocspResponse returns the OCSP response of server for cert, fetching it
+// unless it is cached.
+func (c *Checker) ocspResponse(server string, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	key := "ocsp-" + server + "-" + issuerKey(issuer) + "-" + cert.SerialNumber.String()
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		if cached, ok := c.cache.Get(key); ok {
+			return cached, nil
+		}
+
+		res, err := c.fetchOCSP(server, cert, issuer)
+		if err != nil {
+			c.cache.Set(key, err, failureTTL)
+			return nil, err
+		}
+
+		c.cache.Set(key, res, c.ttl(res.NextUpdate))
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err, ok := v.(error); ok {
+		return nil, err
+	}
+	return v.(*ocsp.Response), nil
+}
+
+// fetchOCSP queries server for the status of cert.
+func (c *Checker) fetchOCSP(server string, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	req, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.post(server, "application/ocsp-request", req)
+	if err != nil {
+		return nil, err
+	}
+
+	return ocsp.ParseResponseForCert(body, cert, issuer)
+}
+
+// checkCRL returns the status of cert in the uploaded CRL of its issuer and
+// in the CRLs published at its distribution points.
+func (c *Checker) checkCRL(cert, issuer *x509.Certificate) status {
+	st := statusUnknown
+
+	if crl, err := c.uploadedCRL(issuer); err == nil && crl != nil {
+		if revoked(crl, cert) {
+			return statusRevoked
+		}
+		st = statusGood
+	}
+
+	for _, point := range cert.CRLDistributionPoints {
+		crl, err := c.distributedCRL(point, issuer)
+		if err != nil {
+			continue
+		}
+		if revoked(crl, cert) {
+			return statusRevoked
+		}
+		st = statusGood
+	}
+
+	return st
+}
+
+// uploadedCRL returns the uploaded CRL of issuer, or nil if there is none.
+func (c *Checker) uploadedCRL(issuer *x509.Certificate) (*x509.RevocationList, error) {
+	if c.store == nil {
+		return nil, nil
+	}
+
+	id := CRLID(issuer.RawSubject)
+	v, err, _ := c.group.Do(id, func() (any, error) {
+		if cached, ok := c.cache.Get(id); ok {
+			return cached, nil
+		}
+
+		// uploads are picked up within a minute by other gateways
+		data, err := c.store.GetKey(id)
+		if err != nil {
+			c.cache.Set(id, (*x509.RevocationList)(nil), time.Minute)
+			return (*x509.RevocationList)(nil), nil
+		}
+
+		crl, err := ParseCRL([]byte(data))
+		if err == nil {
+			err = crl.CheckSignatureFrom(issuer)
+		}
+		if err != nil {
+			c.cache.Set(id, err, time.Minute)
+			return nil, err
+		}
+
+		c.cache.Set(id, crl, time.Minute)
+		return crl, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err, ok := v.(error); ok {
+		return nil, err
+	}
+	return v.(*x509.RevocationList), nil
+}
+
+// distributedCRL returns the CRL published at point, fetching it unless it
+// is cached.
+func (c *Checker) distributedCRL(point string, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	key := "crl-" + point + "-" + issuerKey(issuer)
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		if cached, ok := c.cache.Get(key); ok {
+			return cached, nil
+		}
+
+		crl, err := c.fetchCRL(point, issuer)
+		if err != nil {
+			c.cache.Set(key, err, failureTTL)
+			return nil, err
+		}
+
+		c.cache.Set(key, crl, c.ttl(crl.NextUpdate))
+		return crl, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err, ok := v.(error); ok {
+		return nil, err
+	}
+	return v.(*x509.RevocationList), nil
+}
+
+// fetchCRL downloads the CRL published at point and checks it was signed by
+// issuer.
+func (c *Checker) fetchCRL(point string, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	body, err := c.get(point)
+	if err != nil {
+		return nil, err
+	}
+
+	crl, err := ParseCRL(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return nil, err
+	}
+
+	return crl, nil
+}
+
+// AddCRL stores an uploaded PEM or DER encoded CRL, replacing the CRL of the
+// same issuer unless it is more recent. It returns the ID of the CRL.
+func (c *Checker) AddCRL(data []byte) (string, error) {
+	if c.store == nil {
+		return "", errors.New("CRL storage is not available")
+	}
+
+	crl, err := ParseCRL(data)
+	if err != nil {
+		return "", err
+	}
+
+	id := CRLID(crl.RawIssuer)
+	if existing, err := c.store.GetKey(id); err == nil {
+		if current, err := ParseCRL([]byte(existing)); err == nil && current.ThisUpdate.After(crl.ThisUpdate) {
+			return "", fmt.Errorf("a more recent CRL of the same issuer exists: %s", id)
+		}
+	}
+
+	encoded := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl.Raw})
+	if err := c.store.SetKey(id, string(encoded), 0); err != nil {
+		return "", err
+	}
+	c.cache.Delete(id)
+
+	return id, nil
+}
+
+// DeleteCRL removes the uploaded CRL with id.
+func (c *Checker) DeleteCRL(id string) bool {
+	if c.store == nil {
+		return false
+	}
+
+	c.cache.Delete(id)
+	return c.store.DeleteKey(id)
+}
+
+// Staple returns the OCSP response of the leaf of cert, to be stapled to TLS
+// handshakes. The issuer must be the second certificate of the chain.
+func (c *Checker) Staple(cert *tls.Certificate) ([]byte, error) {
+	if len(cert.Certificate) < 2 {
+		return nil, errors.New("certificate chain has no issuer")
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := x509.ParseCertificate(cert.Certificate[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr = errors.New("certificate has no OCSP responder")
+	for _, server := range leaf.OCSPServer {
+		res, err := c.ocspResponse(server, leaf, issuer)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if res.Status == ocsp.Good {
+			return res.Raw, nil
+		}
+		lastErr = fmt.Errorf("OCSP responder returned status %d", res.Status)
+	}
+
+	return nil, lastErr
+}
+
+// ttl returns how long a response with the given next update time is cached.
+func (c *Checker) ttl(nextUpdate time.Time) time.Duration {
+	if nextUpdate.IsZero() {
+		return c.conf.CacheTTL
+	}
+
+	ttl := time.Until(nextUpdate)
+	if ttl <= 0 {
+		return failureTTL
+	}
+	return ttl
+}
+
+func (c *Checker) get(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+func (c *Checker) post(url, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.do(req)
+}
+
+func (c *Checker) do(req *http.Request) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.conf.Timeout)
+	defer cancel()
+
+	res, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from %s", res.StatusCode, req.URL)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+}
+
+// ParseCRL parses a PEM or DER encoded CRL.
+func ParseCRL(data []byte) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "X509 CRL" {
+			return nil, ErrInvalidCRL
+		}
+		data = block.Bytes
+	}
+
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCRL, err)
+	}
+	return crl, nil
+}
+
+// IsCRL returns true if data is a PEM encoded CRL.
+func IsCRL(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil && block.Type == "X509 CRL"
+}
+
+// CRLID returns the ID of the uploaded CRL of the issuer with the given raw
+// subject.
+func CRLID(rawIssuer []byte) string {
+	sum := sha256.Sum256(rawIssuer)
+	return CRLKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// issuerKey identifies issuer in cache keys.
+func issuerKey(issuer *x509.Certificate) string {
+	sum := sha256.Sum256(issuer.Raw)
+	return hex.EncodeToString(sum[:8])
+}
+
+// revoked returns true if cert is listed in crl.
+func revoked(crl *x509.RevocationList, cert *x509.Certificate) bool {
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,269 @@
+package revocation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	keys map[string]string
+}
+
+func (s *memoryStore) GetKey(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.keys[key]
+	if !ok {
+		return "", errors.New("key not found")
+	}
+	return v, nil
+}
+
+func (s *memoryStore) SetKey(key, value string, _ int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key] = value
+	return nil
+}
+
+func (s *memoryStore) DeleteKey(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.keys[key]
+	delete(s.keys, key)
+	return ok
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64, modify func(*x509.Certificate)) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	if modify != nil {
+		modify(template)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func (ca *testCA) crl(t *testing.T, number int64, revoked ...*x509.Certificate) []byte {
+	t.Helper()
+
+	template := &x509.RevocationList{
+		Number:     big.NewInt(number),
+		ThisUpdate: time.Now().Add(time.Duration(number) * time.Second),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, cert := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: time.Now(),
+		})
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.cert, ca.key)
+	require.NoError(t, err)
+	return der
+}
+
+func TestChecker_UploadedCRL(t *testing.T) {
+	ca := newTestCA(t)
+	good := ca.issue(t, 2, nil)
+	bad := ca.issue(t, 3, nil)
+
+	checker := NewChecker(Config{CRL: true, HardFail: true}, nil, &memoryStore{keys: map[string]string{}})
+	defer checker.Close()
+
+	// without a CRL the status is unknown
+	assert.ErrorIs(t, checker.Check(good, ca.cert), ErrUnknownStatus)
+
+	id, err := checker.AddCRL(ca.crl(t, 2, bad))
+	require.NoError(t, err)
+	assert.Equal(t, CRLID(ca.cert.RawSubject), id)
+
+	assert.NoError(t, checker.Check(good, ca.cert))
+	assert.ErrorIs(t, checker.Check(bad, ca.cert), ErrRevoked)
+
+	// older CRLs don't replace newer ones
+	_, err = checker.AddCRL(ca.crl(t, 1))
+	assert.Error(t, err)
+
+	_, err = checker.AddCRL([]byte("not a crl"))
+	assert.ErrorIs(t, err, ErrInvalidCRL)
+
+	assert.True(t, checker.DeleteCRL(id))
+	assert.ErrorIs(t, checker.Check(bad, ca.cert), ErrUnknownStatus)
+}
+
+func TestChecker_DistributionPoint(t *testing.T) {
+	ca := newTestCA(t)
+
+	var crl []byte
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write(crl)
+	}))
+	defer server.Close()
+
+	withCRL := func(cert *x509.Certificate) {
+		cert.CRLDistributionPoints = []string{server.URL + "/ca.crl"}
+	}
+	good := ca.issue(t, 2, withCRL)
+	bad := ca.issue(t, 3, withCRL)
+	crl = ca.crl(t, 1, bad)
+
+	checker := NewChecker(Config{CRL: true}, nil, nil)
+	defer checker.Close()
+
+	assert.NoError(t, checker.Check(good, ca.cert))
+	assert.ErrorIs(t, checker.Check(bad, ca.cert), ErrRevoked)
+
+	// the CRL is cached until its next update
+	assert.EqualValues(t, 1, fetches.Load())
+
+	// CRLs not signed by the issuer are ignored
+	other := newTestCA(t)
+	assert.NoError(t, checker.Check(other.issue(t, 3, withCRL), other.cert))
+}
+
+func TestChecker_OCSP(t *testing.T) {
+	ca := newTestCA(t)
+
+	var serial atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		status := ocsp.Good
+		if req.SerialNumber.Int64() == serial.Load() {
+			status = ocsp.Revoked
+		}
+
+		res, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       status,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now(),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now(),
+		}, ca.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(res)
+	}))
+	defer server.Close()
+
+	withOCSP := func(cert *x509.Certificate) {
+		cert.OCSPServer = []string{server.URL}
+	}
+	good := ca.issue(t, 2, withOCSP)
+	bad := ca.issue(t, 3, withOCSP)
+	serial.Store(3)
+
+	checker := NewChecker(Config{OCSP: true, HardFail: true}, nil, nil)
+	defer checker.Close()
+
+	assert.NoError(t, checker.Check(good, ca.cert))
+	assert.ErrorIs(t, checker.Check(bad, ca.cert), ErrRevoked)
+
+	// unknown issuers fail in hard-fail mode
+	assert.ErrorIs(t, checker.Check(good, nil), ErrUnknownStatus)
+
+	t.Run("staple", func(t *testing.T) {
+		staple, err := checker.Staple(&tls.Certificate{Certificate: [][]byte{good.Raw, ca.cert.Raw}})
+		require.NoError(t, err)
+
+		res, err := ocsp.ParseResponseForCert(staple, good, ca.cert)
+		require.NoError(t, err)
+		assert.Equal(t, ocsp.Good, res.Status)
+
+		_, err = checker.Staple(&tls.Certificate{Certificate: [][]byte{bad.Raw, ca.cert.Raw}})
+		assert.Error(t, err)
+
+		_, err = checker.Staple(&tls.Certificate{Certificate: [][]byte{good.Raw}})
+		assert.Error(t, err)
+	})
+}
+
+func TestChecker_SoftFail(t *testing.T) {
+	ca := newTestCA(t)
+	cert := ca.issue(t, 2, func(cert *x509.Certificate) {
+		cert.OCSPServer = []string{"http://127.0.0.1:1/ocsp"}
+		cert.CRLDistributionPoints = []string{"http://127.0.0.1:1/ca.crl"}
+	})
+
+	checker := NewChecker(Config{OCSP: true, CRL: true, Timeout: time.Second}, nil, nil)
+	defer checker.Close()
+
+	assert.NoError(t, checker.Check(cert, ca.cert))
+}
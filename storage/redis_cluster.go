@@ -800,22 +800,23 @@ func (r *RedisCluster) GetListRange(keyName string, from, to int64) ([]string, e
 	return elements, nil
 }
 
-func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) {
+func (r *RedisCluster) AppendToSetPipelined(key string, values [][]byte) error {
 	if len(values) == 0 {
-		return
+		return nil
 	}
 
 	fixedKey := r.fixKey(key)
 	storage, err := r.list()
 	if err != nil {
 		log.Error(err)
-		return
+		return err
 	}
 
 	err = storage.Append(context.Background(), true, fixedKey, values...)
 	if err != nil {
 		log.WithError(err).Error("Error trying to append to set keys")
 	}
+	return err
 }
 
 func (r *RedisCluster) GetSet(keyName string) (map[string]string, error) {
@@ -856,6 +857,33 @@ func (r *RedisCluster) AddToSet(keyName, value string) {
 	}
 }
 
+// addToSetWithExpScript adds a member to a set and extends the expiry of the
+// set to at least the given number of seconds.
+var addToSetWithExpScript = redis.NewScript(`
+redis.call("SADD", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if redis.call("TTL", KEYS[1]) < ttl then
+	redis.call("EXPIRE", KEYS[1], ttl)
+end
+return 1
+`)
+
+// AddToSetWithExp adds value to the set keyName and extends the expiry of the
+// set to at least ttl seconds, atomically.
+func (r *RedisCluster) AddToSetWithExp(keyName, value string, ttl int64) error {
+	singleton, err := r.Client()
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	err = addToSetWithExpScript.Run(context.Background(), singleton, []string{r.fixKey(keyName)}, value, ttl).Err()
+	if err != nil {
+		log.Error("Error trying to append to set: ", err)
+	}
+	return err
+}
+
 func (r *RedisCluster) RemoveFromSet(keyName, value string) {
 	log.Debug("Removing from raw key set: ", keyName)
 	log.Debug("Removing from fixed key set: ", r.fixKey(keyName))
@@ -222,6 +222,35 @@ func TestRedisExpirationTime(t *testing.T) {
 	assert.Equal(t, nil, errGetExp)
 }
 
+func TestAddToSetWithExp(t *testing.T) {
+	storage := &RedisCluster{KeyPrefix: "test-", ConnectionHandler: rc}
+	storage.DeleteAllKeys()
+
+	assert.NoError(t, storage.AddToSetWithExp("set", "a", 40))
+	ttl, err := storage.GetExp("set")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(40), ttl)
+
+	// the expiry is only ever extended
+	assert.NoError(t, storage.AddToSetWithExp("set", "b", 20))
+	ttl, err = storage.GetExp("set")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(40), ttl)
+
+	assert.NoError(t, storage.AddToSetWithExp("set", "c", 60))
+	ttl, err = storage.GetExp("set")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), ttl)
+
+	members, err := storage.GetSet("set")
+	assert.NoError(t, err)
+	var values []string
+	for _, member := range members {
+		values = append(values, member)
+	}
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, values)
+}
+
 func TestLock(t *testing.T) {
 	t.Run("redis down", func(t *testing.T) {
 		mockedKv := tempmocks.NewKeyValue(t)
@@ -1573,7 +1602,7 @@ func TestAppendToSetPipelined(t *testing.T) {
 		fixedKey := storage.fixKey(keyName)
 		mockList.On("Append", mock.Anything, true, fixedKey, values[0], values[1]).Return(nil)
 
-		storage.AppendToSetPipelined(keyName, values)
+		assert.NoError(t, storage.AppendToSetPipelined(keyName, values))
 		mockList.AssertExpectations(t)
 	})
 
@@ -1584,7 +1613,7 @@ func TestAppendToSetPipelined(t *testing.T) {
 		keyName := "key"
 		values := [][]byte{}
 
-		storage.AppendToSetPipelined(keyName, values)
+		assert.NoError(t, storage.AppendToSetPipelined(keyName, values))
 		mockList.AssertExpectations(t)
 	})
 
@@ -1597,7 +1626,7 @@ func TestAppendToSetPipelined(t *testing.T) {
 		fixedKey := storage.fixKey(keyName)
 		mockList.On("Append", mock.Anything, true, fixedKey, values[0], values[1]).Return(errors.New("error appending to set"))
 
-		storage.AppendToSetPipelined(keyName, values)
+		assert.Error(t, storage.AppendToSetPipelined(keyName, values))
 		mockList.AssertExpectations(t)
 	})
 
@@ -1606,7 +1635,7 @@ func TestAppendToSetPipelined(t *testing.T) {
 		storage.ConnectionHandler.storageUp.Store(false)
 		defer storage.ConnectionHandler.storageUp.Store(true)
 
-		storage.AppendToSetPipelined("key", [][]byte{[]byte("value")})
+		assert.Error(t, storage.AppendToSetPipelined("key", [][]byte{[]byte("value")}))
 	})
 }
 
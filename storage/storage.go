@@ -57,7 +57,7 @@ type Handler interface {
 
 type AnalyticsHandler interface {
 	Connect() bool
-	AppendToSetPipelined(string, [][]byte)
+	AppendToSetPipelined(string, [][]byte) error
 	GetAndDeleteSet(string) []interface{}
 	SetExp(string, int64) error   // Set key expiration
 	GetExp(string) (int64, error) // Returns expiry of a key
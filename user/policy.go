@@ -22,6 +22,7 @@ type Policy struct {
 	ThrottleInterval              float64                          `bson:"throttle_interval" json:"throttle_interval"`
 	ThrottleRetryLimit            int                              `bson:"throttle_retry_limit" json:"throttle_retry_limit"`
 	MaxQueryDepth                 int                              `bson:"max_query_depth" json:"max_query_depth"`
+	MaxConcurrentRequests         int                              `bson:"max_concurrent_requests" json:"max_concurrent_requests,omitempty"`
 	AccessRights                  map[string]AccessDefinition      `bson:"access_rights" json:"access_rights"`
 	HMACEnabled                   bool                             `bson:"hmac_enabled" json:"hmac_enabled"`
 	EnableHTTPSignatureValidation bool                             `json:"enable_http_signature_validation" msg:"enable_http_signature_validation"`
@@ -38,19 +39,24 @@ type Policy struct {
 
 	// Smoothing contains rate limit smoothing settings.
 	Smoothing *apidef.RateLimitSmoothing `json:"smoothing" bson:"smoothing"`
+
+	// RateLimitAlgorithm selects the rate limiting algorithm, e.g. "gcra".
+	RateLimitAlgorithm string `json:"rate_limit_algorithm,omitempty" bson:"rate_limit_algorithm,omitempty"`
 }
 
 func (p *Policy) APILimit() APILimit {
 	return APILimit{
-		QuotaMax:           p.QuotaMax,
-		QuotaRenewalRate:   p.QuotaRenewalRate,
-		ThrottleInterval:   p.ThrottleInterval,
-		ThrottleRetryLimit: p.ThrottleRetryLimit,
-		MaxQueryDepth:      p.MaxQueryDepth,
+		QuotaMax:              p.QuotaMax,
+		QuotaRenewalRate:      p.QuotaRenewalRate,
+		ThrottleInterval:      p.ThrottleInterval,
+		ThrottleRetryLimit:    p.ThrottleRetryLimit,
+		MaxQueryDepth:         p.MaxQueryDepth,
+		MaxConcurrentRequests: p.MaxConcurrentRequests,
 		RateLimit: RateLimit{
 			Rate:      p.Rate,
 			Per:       p.Per,
 			Smoothing: p.Smoothing,
+			Algorithm: p.RateLimitAlgorithm,
 		},
 	}
 }
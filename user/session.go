@@ -59,19 +59,24 @@ type RateLimit struct {
 
 	// Smoothing contains rate limit smoothing settings.
 	Smoothing *apidef.RateLimitSmoothing `json:"smoothing,omitzero" bson:"smoothing,omitempty"`
+
+	// Algorithm selects the rate limiting algorithm, e.g. "gcra". When empty,
+	// the algorithm configured for the API or the gateway is used.
+	Algorithm string `json:"algorithm,omitzero" msg:"algorithm" bson:"algorithm"`
 }
 
 // APILimit stores quota and rate limit on ACL level (per API)
 type APILimit struct {
 	RateLimit
-	ThrottleInterval   float64 `json:"throttle_interval,omitzero" msg:"throttle_interval"`
-	ThrottleRetryLimit int     `json:"throttle_retry_limit,omitzero" msg:"throttle_retry_limit"`
-	MaxQueryDepth      int     `json:"max_query_depth,omitzero" msg:"max_query_depth"`
-	QuotaMax           int64   `json:"quota_max,omitzero" msg:"quota_max"`
-	QuotaRenews        int64   `json:"quota_renews,omitzero" msg:"quota_renews"`
-	QuotaRemaining     int64   `json:"quota_remaining,omitzero" msg:"quota_remaining"`
-	QuotaRenewalRate   int64   `json:"quota_renewal_rate,omitzero" msg:"quota_renewal_rate"`
-	SetBy              string  `json:"-" msg:"-"`
+	ThrottleInterval      float64 `json:"throttle_interval,omitzero" msg:"throttle_interval"`
+	ThrottleRetryLimit    int     `json:"throttle_retry_limit,omitzero" msg:"throttle_retry_limit"`
+	MaxQueryDepth         int     `json:"max_query_depth,omitzero" msg:"max_query_depth"`
+	MaxConcurrentRequests int     `json:"max_concurrent_requests,omitzero" msg:"max_concurrent_requests"`
+	QuotaMax              int64   `json:"quota_max,omitzero" msg:"quota_max"`
+	QuotaRenews           int64   `json:"quota_renews,omitzero" msg:"quota_renews"`
+	QuotaRemaining        int64   `json:"quota_remaining,omitzero" msg:"quota_remaining"`
+	QuotaRenewalRate      int64   `json:"quota_renewal_rate,omitzero" msg:"quota_renewal_rate"`
+	SetBy                 string  `json:"-" msg:"-"`
 }
 
 // Clone does a deepcopy of APILimit.
@@ -87,15 +92,17 @@ func (a APILimit) Clone() *APILimit {
 			Rate:      a.Rate,
 			Per:       a.Per,
 			Smoothing: smoothingRef,
+			Algorithm: a.Algorithm,
 		},
-		ThrottleInterval:   a.ThrottleInterval,
-		ThrottleRetryLimit: a.ThrottleRetryLimit,
-		MaxQueryDepth:      a.MaxQueryDepth,
-		QuotaMax:           a.QuotaMax,
-		QuotaRenews:        a.QuotaRenews,
-		QuotaRemaining:     a.QuotaRemaining,
-		QuotaRenewalRate:   a.QuotaRenewalRate,
-		SetBy:              a.SetBy,
+		ThrottleInterval:      a.ThrottleInterval,
+		ThrottleRetryLimit:    a.ThrottleRetryLimit,
+		MaxQueryDepth:         a.MaxQueryDepth,
+		MaxConcurrentRequests: a.MaxConcurrentRequests,
+		QuotaMax:              a.QuotaMax,
+		QuotaRenews:           a.QuotaRenews,
+		QuotaRemaining:        a.QuotaRemaining,
+		QuotaRenewalRate:      a.QuotaRenewalRate,
+		SetBy:                 a.SetBy,
 	}
 }
 
@@ -155,6 +162,10 @@ func (a APILimit) IsEmpty() bool {
 		return false
 	}
 
+	if a.MaxConcurrentRequests != 0 {
+		return false
+	}
+
 	if a.QuotaMax != 0 {
 		return false
 	}
@@ -186,7 +197,7 @@ func (a APILimit) IsZero() bool {
 
 // IsZero returns true if RateLimit is empty (for omitzero support).
 func (r RateLimit) IsZero() bool {
-	return r.Rate == 0 && r.Per == 0 && r.Smoothing == nil
+	return r.Rate == 0 && r.Per == 0 && r.Smoothing == nil && r.Algorithm == ""
 }
 
 // IsZero returns true if FieldLimits is empty (for omitzero support).
@@ -302,6 +313,7 @@ type SessionState struct {
 	ThrottleInterval              float64                     `json:"throttle_interval,omitzero" msg:"throttle_interval"`
 	ThrottleRetryLimit            int                         `json:"throttle_retry_limit,omitzero" msg:"throttle_retry_limit"`
 	MaxQueryDepth                 int                         `json:"max_query_depth,omitzero" msg:"max_query_depth"`
+	MaxConcurrentRequests         int                         `json:"max_concurrent_requests,omitzero" msg:"max_concurrent_requests"`
 	DateCreated                   time.Time                   `json:"date_created,omitzero" msg:"date_created"`
 	Expires                       int64                       `json:"expires,omitzero" msg:"expires"`
 	QuotaMax                      int64                       `json:"quota_max,omitzero" msg:"quota_max"`
@@ -345,6 +357,9 @@ type SessionState struct {
 	// Smoothing contains rate limit smoothing settings.
 	Smoothing *apidef.RateLimitSmoothing `json:"smoothing,omitzero" bson:"smoothing"`
 
+	// RateLimitAlgorithm selects the rate limiting algorithm, e.g. "gcra".
+	RateLimitAlgorithm string `json:"rate_limit_algorithm,omitzero" msg:"rate_limit_algorithm" bson:"rate_limit_algorithm"`
+
 	// modified holds the hint if a session has been modified for update.
 	// use Touch() to set it, and IsModified() to get it.
 	modified bool
@@ -366,13 +381,15 @@ func (s *SessionState) APILimit() APILimit {
 			Rate:      s.Rate,
 			Per:       s.Per,
 			Smoothing: s.Smoothing,
+			Algorithm: s.RateLimitAlgorithm,
 		},
-		QuotaMax:           s.QuotaMax,
-		QuotaRenewalRate:   s.QuotaRenewalRate,
-		QuotaRenews:        s.QuotaRenews,
-		ThrottleInterval:   s.ThrottleInterval,
-		ThrottleRetryLimit: s.ThrottleRetryLimit,
-		MaxQueryDepth:      s.MaxQueryDepth,
+		QuotaMax:              s.QuotaMax,
+		QuotaRenewalRate:      s.QuotaRenewalRate,
+		QuotaRenews:           s.QuotaRenews,
+		ThrottleInterval:      s.ThrottleInterval,
+		ThrottleRetryLimit:    s.ThrottleRetryLimit,
+		MaxQueryDepth:         s.MaxQueryDepth,
+		MaxConcurrentRequests: s.MaxConcurrentRequests,
 	}
 }
 